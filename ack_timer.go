@@ -28,17 +28,23 @@ const (
 
 // ackTimer provides the retnransmission timer conforms with RFC 4960 Sec 6.3.1.
 type ackTimer struct {
-	timer    *time.Timer
+	timer    ClockTimer
 	observer ackTimerObserver
 	mutex    sync.Mutex
 	state    ackTimerState
 	pending  uint8
+	interval time.Duration
 }
 
 // newAckTimer creates a new acknowledgement timer used to enable delayed ack.
-func newAckTimer(observer ackTimerObserver) *ackTimer {
-	t := &ackTimer{observer: observer}
-	t.timer = time.AfterFunc(math.MaxInt64, t.timeout)
+func newAckTimer(observer ackTimerObserver, interval time.Duration) *ackTimer {
+	return newAckTimerWithClock(systemClock{}, observer, interval)
+}
+
+// newAckTimerWithClock creates a new acknowledgement timer scheduled by clock.
+func newAckTimerWithClock(clock Clock, observer ackTimerObserver, interval time.Duration) *ackTimer {
+	t := &ackTimer{observer: observer, interval: interval}
+	t.timer = clock.AfterFunc(math.MaxInt64, t.timeout)
 	t.timer.Stop()
 
 	return t
@@ -65,7 +71,7 @@ func (t *ackTimer) start() bool {
 
 	t.state = ackTimerStarted
 	t.pending++
-	t.timer.Reset(ackInterval)
+	t.timer.Reset(t.interval)
 
 	return true
 }
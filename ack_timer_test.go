@@ -29,7 +29,7 @@ func TestAckTimer(t *testing.T) {
 				t.Log("ack timed out")
 				atomic.AddUint32(&nCbs, 1)
 			},
-		})
+		}, ackInterval)
 
 		for range 2 {
 			// should start ok
@@ -68,7 +68,7 @@ func TestAckTimer(t *testing.T) {
 				t.Log("ack timed out")
 				atomic.AddUint32(&nCbs, 1)
 			},
-		})
+		}, ackInterval)
 
 		for range 2 {
 			// should start ok
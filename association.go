@@ -4,7 +4,6 @@
 package sctp
 
 import (
-	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -12,6 +11,7 @@ import (
 	"io"
 	"math"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -44,6 +44,7 @@ func generateInitiateTag() uint32 {
 var (
 	ErrChunk                         = errors.New("abort chunk, with following errors")
 	ErrShutdownNonEstablished        = errors.New("shutdown called in non-established state")
+	ErrShutdownTransportClosed       = errors.New("shutdown failed: underlying transport closed")
 	ErrAssociationClosedBeforeConn   = errors.New("association closed before connecting")
 	ErrAssociationClosed             = errors.New("association closed")
 	ErrSilentlyDiscard               = errors.New("silently discard")
@@ -62,20 +63,35 @@ var (
 	ErrResetPacketInStateNotExist = errors.New("sending reset packet in non-established state")
 	ErrParamterType               = errors.New("unexpected parameter type")
 	ErrPayloadDataStateNotExist   = errors.New("sending payload data in non-established state")
+	ErrShuttingDown               = errors.New("sending payload data while the association is shutting down")
 	ErrChunkTypeUnhandled         = errors.New("unhandled chunk type")
 	ErrHandshakeInitAck           = errors.New("handshake failed (INIT ACK)")
 	ErrHandshakeCookieEcho        = errors.New("handshake failed (COOKIE ECHO)")
+	ErrHandshakeTimeout           = errors.New("handshake retransmissions exhausted")
 	ErrTooManyReconfigRequests    = errors.New("too many outstanding reconfig requests")
+	ErrStreamResetTimeout         = errors.New("stream reset timed out: no RECONFIG response")
+	ErrStreamResetRejected        = errors.New("stream reset rejected by the peer")
+	ErrPingNonEstablished         = errors.New("ping called in non-established state")
+	ErrPingTimeout                = errors.New("ping timed out waiting for HEARTBEAT ACK")
+	ErrPeerUnreachable            = errors.New("peer unreachable: retransmission limit exceeded")
+	ErrStreamAlreadyExists        = errors.New("stream identifier already in use")
+	ErrStreamIDOutOfRange         = errors.New("stream identifier exceeds the negotiated outbound streams")
 )
 
 const (
 	receiveMTU            uint32 = 8192 // MTU for inbound packet (from DTLS)
+	minReceiveMTU         uint32 = 1200 // smallest packet size a DTLS peer may use (RFC 8261 section 5)
+	defaultWriteBatchSize uint32 = 64   // packets written per iteration of the write loop
 	initialMTU            uint32 = 1228 // initial MTU for outgoing packets (to DTLS)
 	initialRecvBufSize    uint32 = 1024 * 1024
 	commonHeaderSize      uint32 = 12
 	dataChunkHeaderSize   uint32 = 16
 	iDataChunkHeaderSize  uint32 = 20
 	defaultMaxMessageSize uint32 = 65536
+
+	// defaultFastRetransmitThreshold is the number of miss indications
+	// that trigger fast retransmit (RFC 9260 sec 7.2.4).
+	defaultFastRetransmitThreshold uint32 = 3
 )
 
 // PartialReliabilityMode indicates the negotiated partial reliability mode.
@@ -103,6 +119,23 @@ type AssociationMetadata struct {
 	ZeroChecksumReceivingEnabled bool `json:"zeroChecksumReceivingEnabled"`
 }
 
+// Extension identifies an SCTP extension by the chunk type it adds, as
+// listed in the Supported Extensions parameter (RFC 5061 section 4.2.7).
+type Extension uint8
+
+// Extension values.
+const (
+	ExtensionReconfig    = Extension(ctReconfig)
+	ExtensionForwardTSN  = Extension(ctForwardTSN)
+	ExtensionIData       = Extension(ctIData)
+	ExtensionIForwardTSN = Extension(ctIForwardTSN)
+	ExtensionTimestamp   = Extension(ctTimestamp)
+)
+
+func (e Extension) String() string {
+	return chunkType(e).String()
+}
+
 // association state enums.
 const (
 	closed uint32 = iota
@@ -122,6 +155,7 @@ const (
 	timerT2Shutdown
 	timerT3RTX
 	timerReconfig
+	timerHeartbeat
 )
 
 // ack mode (for testing).
@@ -141,9 +175,19 @@ const (
 // other constants.
 const (
 	acceptChSize = 16
-	// avgChunkSize is an estimate of the average chunk size. There is no theory behind
-	// this estimate.
-	avgChunkSize = 500
+	// defaultAvgChunkSize is an estimate of the average chunk size. There is no theory behind
+	// this estimate. See Config.AvgChunkSizeHint.
+	defaultAvgChunkSize = 500
+	// finalSackTimeout bounds how long Close waits for the final SACK to be
+	// written.
+	finalSackTimeout = 50 * time.Millisecond
+	// ootbReplyBurst and ootbReplyRate bound the replies to out of the blue
+	// packets: at most ootbReplyBurst at once, then ootbReplyRate per second.
+	ootbReplyBurst = 10
+	ootbReplyRate  = 10
+	// recvAutotuneDefaultRTT is the round trip time in msec assumed by receive
+	// buffer autotuning until an RTT has been measured.
+	recvAutotuneDefaultRTT = 100.0
 	// minTSNOffset is the minimum offset over the cummulative TSN that we will enqueue
 	// irrespective of the receive buffer size
 	// see getMaxTSNOffset.
@@ -152,8 +196,17 @@ const (
 	// irrespective of the receive buffer size
 	// see getMaxTSNOffset.
 	maxTSNOffset = 40000
-	// maxReconfigRequests is the maximum number of reconfig requests we will keep outstanding.
-	maxReconfigRequests = 1000
+	// defaultMaxReconfigRequests is the default maximum number of reconfig requests we will keep outstanding.
+	defaultMaxReconfigRequests = 1000
+	// defaultReconfigRequestLifetime is how long an outstanding reconfig request
+	// is kept by default, counted from when it was first received. A peer still
+	// waiting for the request to complete retransmits it.
+	defaultReconfigRequestLifetime = 30 * time.Second
+
+	// Heartbeat Info sizes for HEARTBEATs originated by this association:
+	// a timestamp followed by a nonce.
+	heartbeatInfoTimestampSize = 8
+	heartbeatInfoPingSize      = 16
 
 	// TLR Adaptive burst mitigation uses quarter-MTU units.
 	// 1 MTU == 4 units, 0.25 MTU == 1 unit.
@@ -218,10 +271,14 @@ type Association struct {
 
 	lock sync.RWMutex
 
-	netConn net.Conn
+	netConn   net.Conn
+	transport Transport
 
 	peerVerificationTag    uint32
 	myVerificationTag      uint32
+	verificationTagCheck   bool
+	ootbReplyTokens        float64   // replies to out of the blue packets allowed now
+	ootbReplyRefilled      time.Time // last refill of ootbReplyTokens, zero before the first
 	state                  uint32
 	initialTSN             uint32
 	myNextTSN              uint32 // nextTSN
@@ -243,6 +300,17 @@ type Association struct {
 	myNextRSN        uint32
 	reconfigs        map[uint32]*chunkReconfig
 	reconfigRequests map[uint32]*paramOutgoingResetRequest
+	// time each entry of reconfigRequests was first received
+	reconfigRequestsSeen    map[uint32]time.Time
+	maxReconfigRequests     int
+	reconfigRequestLifetime time.Duration
+	// streams waiting for the peer to confirm the reset of their outgoing side
+	outgoingResets map[uint16]*Stream
+	maxStreams     uint16 // cap on open streams; 0 means unlimited
+
+	// last fragments of the messages written with WriteWithAck that are not
+	// acknowledged yet
+	ackNotifies map[*chunkPayloadData]struct{}
 
 	// Non-RFC internal data
 	sourcePort              uint16
@@ -250,14 +318,24 @@ type Association struct {
 	myMaxNumInboundStreams  uint16
 	myMaxNumOutboundStreams uint16
 	myCookie                *paramStateCookie
+	cookieGenerator         CookieGenerator
+	cookieLifetime          time.Duration
+	cookieLifeSpanIncrement time.Duration // granted for the current INIT, carried in the cookie
 	payloadQueue            *receivePayloadQueue
 	inflightQueue           *payloadQueue
 	pendingQueue            *pendingQueue
 	controlQueue            *controlQueue
 	mtu                     uint32
+	recvMTU                 uint32       // size of the inbound packet buffer
+	writeBatchSize          uint32       // max packets written per iteration of the write loop
 	maxPayloadSize          uint32       // max DATA chunk payload size
 	srtt                    atomic.Value // type float64
+	instantRTTEnabled       bool
+	instantRTT              atomic.Value // type float64, sampled per SACK
 	cumulativeTSNAckPoint   uint32
+	// cumulative TSN ack plus the number of TSNs gap-acked by the last SACK,
+	// to tell reneging from an out-of-order SACK
+	lastSackCoverage        uint32
 	advancedPeerTSNAckPoint uint32
 	useForwardTSN           bool
 	useIForwardTSN          bool
@@ -265,12 +343,32 @@ type Association struct {
 	localInterleaving       bool
 	peerInterleaving        bool
 	peerForwardTSN          bool
+	peerReconfig            bool
 	peerIForwardTSN         bool
 	sendZeroChecksum        bool
 	recvZeroChecksum        bool
+	lenientChunkParsing     bool
+	ignoreInboundChecksum   bool
+	dwellTimeStats          bool
+	zeroChecksumEDMID       uint32
+	localECN                bool
+	useECN                  bool
+	ecnRecoveryPoint        uint32 // highest TSN outstanding at the last ECN reaction
+	localTimestamps         bool
+	peerTimestamps          bool
+	useTimestamps           bool
+	tsBase                  time.Time // origin of the timestamps we send
+	tsRecent                uint32    // peer timestamp to echo in the next SACK
+	tsRecentValid           bool
+	tsEcr                   uint32 // timestamp echoed in the packet being processed
+	tsEcrValid              bool
+	peerAddresses           []net.IP    // advertised in the peer's INIT or INIT ACK
+	peerAddressTypes        []paramType // Supported Address Types of the peer's INIT
 
 	// Congestion control parameters
 	maxReceiveBufferSize uint32
+	advertisedRWND       uint32 // a_rwnd sent in INIT and INIT ACK
+	avgChunkSize         uint32 // expected DATA chunk size, to bound the TSN offset
 	maxMessageSize       uint32
 	cwnd                 uint32 // my congestion window size
 	rwnd                 uint32 // calculated peer's receiver windows size
@@ -279,18 +377,39 @@ type Association struct {
 	inFastRecovery       bool
 	fastRecoverExitPoint uint32
 	minCwnd              uint32 // Minimum congestion window
+	maxCwnd              uint32 // Maximum congestion window, 0 if unlimited
+	initialCwnd          uint32 // Configured initial congestion window, 0 for the default
+	minCwndWarned        bool   // minCwnd overriding a loss reduction was logged
 	fastRtxWnd           uint32 // Send window for fast retransmit
 	cwndCAStep           uint32 // Step of congestion window increase at Congestion Avoidance
+	byteCountingLimit    uint32 // Slow start growth limit per SACK in MTUs; 0 means unlimited
+	fastRtxThreshold     uint32 // Miss indications that trigger fast retransmit
+	maxOutstandingBytes  uint32 // Cap on DATA bytes in flight; 0 means unlimited
+	maxBurst             uint32 // Cap on new DATA packets sent at once; 0 means unlimited
+
+	// cwnd reset after idle periods, RFC 9260 sec 7.2.1
+	idleCwndReset        bool
+	idleCwndResetTimeout time.Duration // 0 to use the RTO
+	lastDataSent         time.Time     // when new DATA was last sent
+
+	// spurious T3-rtx timeout detection, RFC 5682
+	frtoEnabled   bool
+	frto          frtoState
+	rtoGeneration uint32 // number of T3-rtx timeouts
 
 	// RTX & Ack timer
 	rtoMgr     *rtoManager
+	minT3RTX   float64 // floor applied to the T3-rtx timeout in msec
 	t1Init     *rtxTimer
 	t1Cookie   *rtxTimer
 	t2Shutdown *rtxTimer
 	t3RTX      *rtxTimer
 	tReconfig  *rtxTimer
+	tHeartbeat *rtxTimer
 	ackTimer   *ackTimer
 
+	heartbeatInterval float64 // idle heartbeat interval in msec; 0 disables
+
 	// RACK / TLP state
 	rack                        rackSettings  // rack configurable options
 	rackReoWnd                  time.Duration // dynamic reordering window
@@ -310,11 +429,23 @@ type Association struct {
 	rackDeadline  time.Time
 	ptoDeadline   time.Time
 
+	// Outstanding Ping() calls keyed by the nonce carried in the HEARTBEAT
+	pings map[uint64]chan struct{}
+
+	// closed and cleared once all buffered data is acked or abandoned,
+	// to wake up Flush callers
+	flushCh chan struct{}
+	// Nonce of the last HEARTBEAT sent for liveness or RTT probing, until
+	// it is acknowledged, or 0
+	heartbeatNonce uint64
+
 	// Chunks stored for retransmission
 	storedInit       *chunkInit
 	storedCookieEcho *chunkCookieEcho
+	staleCookieCount uint // handshake restarts caused by a stale cookie
 
 	streams              map[uint16]*Stream
+	streamStats          sync.Map // *streamStats of streams by id, read by Stats without the lock
 	acceptCh             chan *Stream
 	readLoopCloseCh      chan struct{}
 	awakeWriteLoopCh     chan struct{}
@@ -326,12 +457,74 @@ type Association struct {
 	// local error
 	silentError error
 
+	// first netConn read/write failure not caused by closing the association
+	transportErr error
+	// set by Abort, whose read deadline must not count as a transport failure
+	abortedLocally bool
+
+	// error that terminated the association; nil if closed locally or gracefully
+	closeErr error
+
 	ackState int
 	ackMode  int // for testing
 
+	// ack and send behavior selected by Config.Profile
+	sackFreq          int  // packets carrying DATA per SACK
+	delayedAckPackets int  // packets whose SACK is currently being delayed
+	nagle             bool // hold back small writes while data is outstanding
+
+	// SACK timing selected by Config.SackPolicy
+	sackPolicy SackPolicy
+
+	// final SACK sent by Close when one is delayed
+	finalSack  bool
+	sackSentCh chan struct{} // signaled by the write loop after writing a SACK
+
 	// stats
 	stats *associationStats
 
+	// reassembly memory accounting
+	onReassemblyMemoryChange func(delta int)
+	reassemblyMemoryDelta    int64 // pending delta; accessed atomically
+
+	// receive buffer full reporting
+	onReceiveBufferFull       func(streamID uint16)
+	lastReceiveBufferFullCall time.Time
+
+	// congestion event reporting
+	onCongestionEvent       func(CongestionEvent)
+	pendingCongestionEvents []CongestionEvent // the caller should hold the lock
+	congestionEventQueue    callbackQueue
+
+	// zero receiver window stall reporting; the caller should hold the lock
+	onPeerStalled     func()
+	peerStallTimeout  time.Duration
+	zeroRWNDSince     time.Time // first SACK of the current zero window episode
+	peerStallReported bool
+
+	// age at which incomplete received messages are discarded, 0 to keep
+	// them; copied to each stream
+	reassemblyTimeout time.Duration
+	// discards the incomplete messages of streams that receive no more
+	// DATA, armed while there are some; protected with timerMu
+	reassemblyTimer *time.Timer
+
+	// ERROR chunk reporting
+	onProtocolError       func(causes []ErrorCause)
+	pendingProtocolErrors [][]ErrorCause // the caller should hold the lock
+	protocolErrorQueue    callbackQueue
+
+	// DATA chunk tracing, called with the lock held
+	onTrace func(TraceEvent)
+
+	// decides whether the read loop survives a transport read error
+	onReadError func(error) bool
+
+	// receive buffer autotuning; the caller should hold the lock
+	recvAutotuneMax   uint32    // 0 when disabled
+	recvAutotuneStart time.Time // start of the current measurement round
+	recvAutotuneBytes uint64    // bytes received in the current round
+
 	// per inbound packet context
 	delayedAckTriggered   bool
 	immediateAckTriggered bool
@@ -372,17 +565,243 @@ type Config struct {
 	EnableZeroChecksum bool
 	MTU                uint32
 
+	// Transport replaces NetConn as the carrier of SCTP packets when set.
+	Transport Transport
+
+	// Clock schedules the retransmission and delayed ack timers. Defaults to
+	// the system clock.
+	Clock Clock
+
+	// CookieGenerator generates and validates the State Cookie sent in the
+	// INIT ACK. Defaults to a random cookie compared byte for byte.
+	CookieGenerator CookieGenerator
+
+	// CookieLifetime is how long the State Cookie of an INIT ACK is valid
+	// (Valid.Cookie.Life). A COOKIE ECHO with an older cookie is answered
+	// with a Stale Cookie error, prompting the peer to restart the handshake.
+	// Defaults to 60 seconds.
+	CookieLifetime time.Duration
+
+	// ZeroChecksumEDMID is the RFC 9653 Error Detection Method Identifier
+	// advertised when EnableZeroChecksum is set. Zero checksums are only
+	// sent when the peer advertises the same identifier. Defaults to
+	// ZeroChecksumEDMIDDTLS.
+	ZeroChecksumEDMID uint32
+
+	// EnableECN advertises ECN capability in INIT/INIT ACK. When both sides
+	// support it, a received ECNE chunk halves the congestion window and is
+	// answered with a CWR chunk.
+	EnableECN bool
+
+	// EnableTimestamps advertises support of the TIMESTAMP chunk. When both
+	// sides support it, packets carrying DATA or SACK chunks include a
+	// timestamp that the peer echoes back, and RTT is measured from the
+	// echoes instead of from non-retransmitted DATA only.
+	EnableTimestamps bool
+
+	// LenientChunkParsing keeps the well-formed chunks of a received packet
+	// when one of its chunks is malformed or truncated, instead of dropping
+	// the whole packet. The malformed chunk is dropped, and the rest of the
+	// packet is processed only if the chunk type asks to skip unrecognized
+	// chunks and its length is valid.
+	LenientChunkParsing bool
+
+	// IgnoreInboundChecksum processes received packets whose CRC32c checksum
+	// does not match, logging the mismatch instead of dropping them. This
+	// also applies to INIT and COOKIE ECHO chunks, which are otherwise always
+	// verified. UNSAFE: it disables the detection of corrupted packets and
+	// is only meant to diagnose middleboxes that mangle checksums.
+	IgnoreInboundChecksum bool
+
+	// EnableInstantRTT samples the RTT on every SACK that acknowledges an
+	// original transmission, instead of once per round trip, and smooths
+	// the samples into InstantRTT. This is telemetry only: the RTO is still
+	// computed from the RFC 9260 samples.
+	EnableInstantRTT bool
+
+	// EnableDwellTimeStats records how long each DATA chunk waits in the
+	// pending queue before it is sent, and in flight before it is
+	// acknowledged, in the PendingDwell and InflightDwell stats. This tells
+	// application backlog from network latency, at the cost of reading the
+	// clock for each chunk.
+	EnableDwellTimeStats bool
+
+	// ReceiveMTU is the size of the buffer each association reads inbound
+	// packets into. It can be lowered to save memory when the peer is known
+	// to send smaller packets, since a larger packet is dropped. It must be
+	// at least 1200, the smallest MTU a DTLS peer may use. Defaults to 8192.
+	ReceiveMTU uint32
+
 	// congestion control configuration
 	MaxReceiveBufferSize uint32
 	MaxMessageSize       uint32
+	// ReceiveBufferAutotuneMax enables receive buffer autotuning when set
+	// above MaxReceiveBufferSize. The receive buffer, and with it the
+	// advertised receiver window, then starts at MaxReceiveBufferSize and
+	// grows up to this value to hold twice the data received per round trip.
+	// Zero keeps the receive buffer fixed at MaxReceiveBufferSize.
+	ReceiveBufferAutotuneMax uint32
+	// AdvertisedRWND is the receiver window advertised in INIT and INIT ACK.
+	// It may be set below MaxReceiveBufferSize to exercise flow control
+	// without shrinking the actual receive buffer, and must not exceed it.
+	// Defaults to MaxReceiveBufferSize.
+	AdvertisedRWND uint32
+	// AvgChunkSizeHint is the expected average size of received DATA chunks.
+	// Together with MaxReceiveBufferSize, it bounds how far beyond the
+	// cumulative TSN received DATA is queued: a smaller hint allows more
+	// chunks. Defaults to 500 bytes.
+	AvgChunkSizeHint uint32
 	// RTOMax is the maximum retransmission timeout in milliseconds
 	RTOMax float64
-	// Minimum congestion window
+	// MinCwnd is the minimum congestion window. It also applies after a loss
+	// or ECN event, so a floor at or above ssthresh cancels the congestion
+	// window reduction of fast recovery, ECN and T3-rtx expiry; a warning is
+	// logged the first time this happens.
 	MinCwnd uint32
+	// InitialCwnd overrides the initial congestion window, for example to
+	// use 10 packets as in RFC 6928. It must not be below MinCwnd. Defaults
+	// to min(4*MTU, max(2*MTU, 4380)) as in RFC 9260 section 7.2.1.
+	InitialCwnd uint32
+	// MaxCwnd caps the growth of the congestion window. It must not be below
+	// MinCwnd or InitialCwnd. Zero means unlimited.
+	MaxCwnd uint32
+	// DisableFinalSack disables the SACK that Close sends when one was being
+	// delayed, and the wait of at most 50ms for it to be written. The peer
+	// may then retransmit data it does not know was received.
+	DisableFinalSack bool
+	// DisableIdleCwndReset disables the reset of cwnd to its initial value
+	// when no DATA was sent for IdleCwndResetTimeout (RFC 9260 section
+	// 7.2.1), which avoids sending a burst of a stale cwnd after an idle
+	// period.
+	DisableIdleCwndReset bool
+	// IdleCwndResetTimeout is the idle time after which cwnd is reset.
+	// Defaults to the current RTO.
+	IdleCwndResetTimeout time.Duration
+	// EnableFRTO enables the detection of spurious T3-rtx timeouts, such as
+	// after a latency spike, in the manner of F-RTO (RFC 5682). The cwnd
+	// and ssthresh before a spurious timeout are restored, and the DATA not
+	// retransmitted yet is not retransmitted.
+	EnableFRTO bool
 	// Send window for fast retransmit
 	FastRtxWnd uint32
 	// Step of congestion window increase at Congestion Avoidance
 	CwndCAStep uint32
+	// ByteCountingLimit enables appropriate byte counting (RFC 3465) in slow
+	// start: cwnd grows by at most this many MTUs per SACK, instead of by
+	// up to the acknowledged bytes. RFC 3465 recommends 2. Zero keeps the
+	// default growth.
+	ByteCountingLimit uint32
+	// FastRetransmitThreshold is the number of SACKs reporting a DATA chunk
+	// missing before it is fast retransmitted. Defaults to 3.
+	FastRetransmitThreshold uint32
+	// MaxOutstandingBytes caps the bytes of DATA in flight regardless of
+	// cwnd and rwnd. Zero means unlimited.
+	MaxOutstandingBytes uint32
+	// MaxBurst caps the number of packets of new DATA sent at once, for
+	// example when a SACK opens a large part of cwnd. The remaining data is
+	// sent on the next wakeup of the write loop, such as the next SACK.
+	// Zero means unlimited.
+	MaxBurst uint32
+	// WriteBatchSize caps the number of packets the write loop writes
+	// before checking whether the association is closing, such as after a
+	// large retransmission. The remaining packets are written on the next
+	// iteration, so none are lost. Defaults to 64.
+	WriteBatchSize uint32
+	// MinT3RTX is the minimum T3-rtx timeout in milliseconds. It is applied
+	// on top of the computed RTO so that the retransmission timer does not
+	// fire on small RTT jitter over very fast links.
+	MinT3RTX float64
+	// RTOJitter randomizes the retransmission timeouts by up to this fraction
+	// of the RTO in either direction (0.1 means +/-10%), so that many
+	// associations sharing a path do not retransmit in lock step. The jittered
+	// timeout never drops below RTO.Min, nor the T3-rtx one below MinT3RTX.
+	// Must be in [0, 1); 0 (the default) disables jitter.
+	RTOJitter float64
+	// PeerStallTimeout is how long the peer may advertise a zero receiver
+	// window without accepting any data before the peer stalled callback set
+	// by WithOnPeerStalled is called. Zero (the default) disables the check.
+	PeerStallTimeout time.Duration
+	// ReassemblyTimeout discards a partially received message once one of
+	// its fragments has waited this long for the rest, so that a peer that
+	// never sends the end of a message cannot pin receive buffer memory. It
+	// is checked when DATA arrives for the stream, and by a timer armed
+	// while there are incomplete messages, so a message is discarded at most
+	// twice this long after its first fragment arrived.
+	// This breaks reliable delivery: ordered delivery resumes with the next
+	// message. An ordered message is only discarded once the messages before
+	// it were delivered. Discarded messages are counted in StreamStats.
+	// Zero (the default) disables the timeout.
+	ReassemblyTimeout time.Duration
+
+	// HeartbeatInterval enables idle-path heartbeats. When no packet is
+	// received from the peer for this long while established, a HEARTBEAT is
+	// sent. Zero disables idle heartbeats. Intervals below one millisecond
+	// are rounded up to one millisecond.
+	HeartbeatInterval time.Duration
+	// HeartbeatMaxRetrans is the number of consecutive unacknowledged idle
+	// heartbeats after which the peer is considered unreachable and the
+	// association is closed. Defaults to 5 (Path.Max.Retrans).
+	HeartbeatMaxRetrans uint
+
+	// MaxInitRetransmits is the number of INIT or COOKIE ECHO
+	// retransmissions after which the handshake fails with
+	// ErrHandshakeTimeout (Max.Init.Retransmits). It also caps the handshake
+	// restarts caused by a stale cookie. Defaults to 8.
+	MaxInitRetransmits uint
+
+	// MaxShutdownRetrans is the number of SHUTDOWN or SHUTDOWN ACK
+	// retransmissions after which the peer is considered unreachable and the
+	// association is closed. Defaults to 10 (Association.Max.Retrans).
+	MaxShutdownRetrans uint
+
+	// MaxReconfigRetrans is the number of RECONFIG retransmissions after
+	// which outgoing stream reset requests are given up: the streams being
+	// reset are closed and their Read returns ErrStreamResetTimeout. Zero,
+	// the default, retransmits until the peer responds.
+	MaxReconfigRetrans uint
+
+	// MaxReconfigRequests is the maximum number of incoming stream reset
+	// requests kept while waiting for the data sent before them. Further
+	// requests are dropped until older ones complete or expire. Defaults to
+	// 1000.
+	MaxReconfigRequests uint32
+	// ReconfigRequestLifetime is how long an incoming stream reset request
+	// is kept, counted from when it was first received. Expired requests
+	// are dropped as new ones arrive; a peer still waiting for one
+	// retransmits it. Defaults to 30 seconds.
+	ReconfigRequestLifetime time.Duration
+
+	// MaxStreams caps the number of streams open at the same time, to bound
+	// the memory a peer can make the association use. DATA that would open
+	// a new stream beyond the cap is acknowledged and discarded, and the
+	// peer is sent an ERROR with an Invalid Stream Identifier cause. The
+	// numbers of inbound and outbound streams advertised in INIT and INIT
+	// ACK are capped too. Zero means no limit.
+	MaxStreams uint16
+
+	// Profile selects delayed-ack, SACK frequency and Nagle defaults that
+	// favor either latency or throughput. See Profile for the exact settings.
+	Profile Profile
+
+	// SackPolicy selects when SACKs for received DATA are sent: delayed as
+	// recommended by the RFC, immediately for every packet, or delayed but
+	// bundled with outgoing DATA when possible. Defaults to
+	// SackPolicyDelayed.
+	SackPolicy SackPolicy
+
+	// SackFrequency is the number of packets carrying DATA received before
+	// a delayed SACK is sent without waiting for the delayed-ack timer.
+	// Acking less often saves SACKs on fast links at the cost of coarser
+	// feedback to the sender. A SACK is still sent right away when a gap is
+	// detected. Zero keeps the value of the Profile, 2 by default.
+	SackFrequency int
+
+	// DisableVerificationTagCheck accepts packets whose verification tag does
+	// not match the association (RFC 9260 section 8.5). By default they are
+	// not processed, so that packets of another association or spoofed
+	// packets are ignored, and they are answered as out of the blue packets
+	// (RFC 9260 section 8.4), with at most 10 replies per second.
+	DisableVerificationTagCheck bool
 
 	// RACK config options
 	rack rackSettings
@@ -390,6 +809,9 @@ type Config struct {
 	// User message interleaving config options
 	interleaving *interleavingSettings
 
+	// Application callbacks
+	callbacks *callbackSettings
+
 	// SNAP/sctp-init
 	snapConfig *snapConfig
 
@@ -406,6 +828,13 @@ func Server(config Config) (*Association, error) {
 
 // ServerWithOptions accepts a SCTP stream over a conn.
 func ServerWithOptions(opts ...ServerOption) (*Association, error) {
+	return ServerWithContext(context.Background(), opts...)
+}
+
+// ServerWithContext accepts a SCTP stream over a conn. If ctx is done before
+// the peer completes the handshake, the association is closed and ctx.Err()
+// is returned.
+func ServerWithContext(ctx context.Context, opts ...ServerOption) (*Association, error) {
 	assoc, err := createServerAssociation(opts...)
 	if err != nil {
 		return nil, err
@@ -413,14 +842,22 @@ func ServerWithOptions(opts ...ServerOption) (*Association, error) {
 	assoc.initServer()
 
 	select {
+	case <-ctx.Done():
+		assoc.log.Errorf("[%s] server handshake canceled: state=%s", assoc.name, getAssociationStateString(assoc.getState()))
+		assoc.Close() // nolint:errcheck,gosec
+
+		return nil, ctx.Err()
 	case err := <-assoc.handshakeCompletedCh:
 		if err != nil {
-			return nil, err
+			handshakeErr := assoc.handshakeError(err)
+			assoc.closeKeepingTransport()
+
+			return nil, handshakeErr
 		}
 
 		return assoc, nil
 	case <-assoc.readLoopCloseCh:
-		return nil, ErrAssociationClosedBeforeConn
+		return nil, assoc.handshakeError(ErrAssociationClosedBeforeConn)
 	}
 }
 
@@ -483,12 +920,15 @@ func createClientWithOptionsWithContext(ctx context.Context, opts ...ClientOptio
 		return nil, ctx.Err()
 	case err := <-assoc.handshakeCompletedCh:
 		if err != nil {
-			return nil, err
+			handshakeErr := assoc.handshakeError(err)
+			assoc.closeKeepingTransport()
+
+			return nil, handshakeErr
 		}
 
 		return assoc, nil
 	case <-assoc.readLoopCloseCh:
-		return nil, ErrAssociationClosedBeforeConn
+		return nil, assoc.handshakeError(ErrAssociationClosedBeforeConn)
 	}
 }
 
@@ -506,6 +946,12 @@ func (c *Config) applyDefaults() {
 	if c.MTU == 0 {
 		c.MTU = initialMTU
 	}
+	if c.ReceiveMTU == 0 {
+		c.ReceiveMTU = receiveMTU
+	}
+	if c.ZeroChecksumEDMID == zeroChecksumEDMIDReserved {
+		c.ZeroChecksumEDMID = ZeroChecksumEDMIDDTLS
+	}
 	if !c.enableInterleavingSet {
 		c.enableInterleaving = true
 	}
@@ -517,6 +963,29 @@ func (c *Config) applyDefaults() {
 	}
 }
 
+// maxNumStreams returns the number of inbound and outbound streams to
+// advertise.
+func (c *Config) maxNumStreams() uint16 {
+	if c.MaxStreams != 0 {
+		return c.MaxStreams
+	}
+
+	return math.MaxUint16
+}
+
+// checkCwndLimits checks that the configured congestion window bounds are
+// consistent with each other.
+func (c *Config) checkCwndLimits() error {
+	if c.InitialCwnd != 0 && c.InitialCwnd < c.MinCwnd {
+		return errInitialCwndBelowMinCwnd
+	}
+	if c.MaxCwnd != 0 && (c.MaxCwnd < c.MinCwnd || c.MaxCwnd < c.InitialCwnd) {
+		return errMaxCwndTooSmall
+	}
+
+	return nil
+}
+
 func createServerAssociation(opts ...ServerOption) (*Association, error) {
 	cfg, err := buildServerConfig(opts...)
 	if err != nil {
@@ -545,16 +1014,49 @@ func (c Config) applyServer(cfg *Config) error { //nolint:dupl,cyclop
 	if c.NetConn != nil {
 		cfg.NetConn = c.NetConn
 	}
+	if c.Transport != nil {
+		cfg.Transport = c.Transport
+	}
+	if c.Clock != nil {
+		cfg.Clock = c.Clock
+	}
+	if c.CookieGenerator != nil {
+		cfg.CookieGenerator = c.CookieGenerator
+	}
+	if c.CookieLifetime != 0 {
+		cfg.CookieLifetime = c.CookieLifetime
+	}
 
 	cfg.BlockWrite = c.BlockWrite
 	cfg.EnableZeroChecksum = c.EnableZeroChecksum
+	if c.ZeroChecksumEDMID != 0 {
+		cfg.ZeroChecksumEDMID = c.ZeroChecksumEDMID
+	}
+	cfg.EnableECN = c.EnableECN
+	cfg.EnableTimestamps = c.EnableTimestamps
+	cfg.LenientChunkParsing = c.LenientChunkParsing
+	cfg.IgnoreInboundChecksum = c.IgnoreInboundChecksum
+	cfg.EnableDwellTimeStats = c.EnableDwellTimeStats
+	cfg.EnableInstantRTT = c.EnableInstantRTT
 
 	if c.MTU != 0 {
 		cfg.MTU = c.MTU
 	}
+	if c.ReceiveMTU != 0 {
+		cfg.ReceiveMTU = c.ReceiveMTU
+	}
 	if c.MaxReceiveBufferSize != 0 {
 		cfg.MaxReceiveBufferSize = c.MaxReceiveBufferSize
 	}
+	if c.ReceiveBufferAutotuneMax != 0 {
+		cfg.ReceiveBufferAutotuneMax = c.ReceiveBufferAutotuneMax
+	}
+	if c.AdvertisedRWND != 0 {
+		cfg.AdvertisedRWND = c.AdvertisedRWND
+	}
+	if c.AvgChunkSizeHint != 0 {
+		cfg.AvgChunkSizeHint = c.AvgChunkSizeHint
+	}
 	if c.MaxMessageSize != 0 {
 		cfg.MaxMessageSize = c.MaxMessageSize
 	}
@@ -564,15 +1066,95 @@ func (c Config) applyServer(cfg *Config) error { //nolint:dupl,cyclop
 	if c.MinCwnd != 0 {
 		cfg.MinCwnd = c.MinCwnd
 	}
+	if c.InitialCwnd != 0 {
+		cfg.InitialCwnd = c.InitialCwnd
+	}
+	if c.MaxCwnd != 0 {
+		cfg.MaxCwnd = c.MaxCwnd
+	}
+	cfg.DisableIdleCwndReset = c.DisableIdleCwndReset
+	cfg.DisableFinalSack = c.DisableFinalSack
+	cfg.EnableFRTO = c.EnableFRTO
+	cfg.DisableVerificationTagCheck = c.DisableVerificationTagCheck
+	if c.IdleCwndResetTimeout != 0 {
+		cfg.IdleCwndResetTimeout = c.IdleCwndResetTimeout
+	}
 	if c.FastRtxWnd != 0 {
 		cfg.FastRtxWnd = c.FastRtxWnd
 	}
 	if c.CwndCAStep != 0 {
 		cfg.CwndCAStep = c.CwndCAStep
 	}
+	if c.ByteCountingLimit != 0 {
+		cfg.ByteCountingLimit = c.ByteCountingLimit
+	}
+	if c.FastRetransmitThreshold != 0 {
+		cfg.FastRetransmitThreshold = c.FastRetransmitThreshold
+	}
+	if c.MaxOutstandingBytes != 0 {
+		cfg.MaxOutstandingBytes = c.MaxOutstandingBytes
+	}
+	if c.MaxBurst != 0 {
+		cfg.MaxBurst = c.MaxBurst
+	}
+	if c.WriteBatchSize != 0 {
+		cfg.WriteBatchSize = c.WriteBatchSize
+	}
+	if c.MinT3RTX != 0 {
+		cfg.MinT3RTX = c.MinT3RTX
+	}
+	if c.RTOJitter != 0 {
+		cfg.RTOJitter = c.RTOJitter
+	}
+	if c.PeerStallTimeout != 0 {
+		cfg.PeerStallTimeout = c.PeerStallTimeout
+	}
+	if c.ReassemblyTimeout != 0 {
+		cfg.ReassemblyTimeout = c.ReassemblyTimeout
+	}
+	if c.HeartbeatInterval != 0 {
+		cfg.HeartbeatInterval = c.HeartbeatInterval
+	}
+	if c.HeartbeatMaxRetrans != 0 {
+		cfg.HeartbeatMaxRetrans = c.HeartbeatMaxRetrans
+	}
+	if c.MaxInitRetransmits != 0 {
+		cfg.MaxInitRetransmits = c.MaxInitRetransmits
+	}
+	if c.MaxShutdownRetrans != 0 {
+		cfg.MaxShutdownRetrans = c.MaxShutdownRetrans
+	}
+	if c.MaxReconfigRetrans != 0 {
+		cfg.MaxReconfigRetrans = c.MaxReconfigRetrans
+	}
+	if c.MaxReconfigRequests != 0 {
+		cfg.MaxReconfigRequests = c.MaxReconfigRequests
+	}
+	if c.ReconfigRequestLifetime != 0 {
+		cfg.ReconfigRequestLifetime = c.ReconfigRequestLifetime
+	}
+	if c.MaxStreams != 0 {
+		cfg.MaxStreams = c.MaxStreams
+	}
+	if c.Profile != ProfileDefault {
+		cfg.Profile = c.Profile
+	}
+	if c.SackPolicy > SackPolicyBundle {
+		return errInvalidSackPolicy
+	}
+	if c.SackPolicy != SackPolicyDelayed {
+		cfg.SackPolicy = c.SackPolicy
+	}
+	if c.SackFrequency < 0 {
+		return errInvalidSackFrequency
+	}
+	if c.SackFrequency != 0 {
+		cfg.SackFrequency = c.SackFrequency
+	}
 
 	cfg.rack = c.rack
 	cfg.interleaving = cloneInterleavingSettings(c.interleaving)
+	cfg.callbacks = cloneCallbackSettings(c.callbacks)
 	if c.enableInterleavingSet {
 		cfg.enableInterleaving = c.enableInterleaving
 		cfg.enableInterleavingSet = true
@@ -596,9 +1178,18 @@ func buildServerConfig(opts ...ServerOption) (*Config, error) {
 
 	cfg.applyDefaults()
 
-	if cfg.NetConn == nil {
+	if cfg.NetConn == nil && cfg.Transport == nil {
 		return nil, errNilNetConn
 	}
+	if cfg.AdvertisedRWND > cfg.MaxReceiveBufferSize {
+		return nil, errAdvertisedRWNDTooLarge
+	}
+	if cfg.ReceiveMTU < minReceiveMTU {
+		return nil, errReceiveMTUTooSmall
+	}
+	if err := cfg.checkCwndLimits(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
@@ -619,19 +1210,7 @@ func (a *Association) initClient() {
 	go a.readLoop()
 	go a.writeLoop()
 
-	init := &chunkInit{}
-	init.initialTSN = a.myNextTSN
-	init.numOutboundStreams = a.myMaxNumOutboundStreams
-	init.numInboundStreams = a.myMaxNumInboundStreams
-	init.initiateTag = a.myVerificationTag
-	init.advertisedReceiverWindowCredit = a.maxReceiveBufferSize
-	setSupportedExtensions(&init.chunkInitCommon, a.localInterleaving)
-
-	if a.recvZeroChecksum {
-		init.params = append(init.params, &paramZeroChecksumAcceptable{edmid: dtlsErrorDetectionMethod})
-	}
-
-	a.storedInit = init
+	a.storedInit = a.createInitChunk()
 
 	err := a.sendInit()
 	if err != nil {
@@ -646,6 +1225,27 @@ func (a *Association) initClient() {
 	a.t1Init.start(a.rtoMgr.getRTO())
 }
 
+// createInitChunk creates the INIT chunk sent by the client.
+// The caller should hold the lock.
+func (a *Association) createInitChunk() *chunkInit {
+	init := &chunkInit{}
+	init.initialTSN = a.myNextTSN
+	init.numOutboundStreams = a.myMaxNumOutboundStreams
+	init.numInboundStreams = a.myMaxNumInboundStreams
+	init.initiateTag = a.myVerificationTag
+	init.advertisedReceiverWindowCredit = a.advertisedRWND
+	setSupportedExtensions(&init.chunkInitCommon, a.localInterleaving, a.localTimestamps)
+
+	if a.recvZeroChecksum {
+		init.params = append(init.params, &paramZeroChecksumAcceptable{edmid: a.zeroChecksumEDMID})
+	}
+	if a.localECN {
+		init.params = append(init.params, &paramECNCapable{})
+	}
+
+	return init
+}
+
 // applyClient allows the exported Config to act as a ClientOption.
 // this is currently the same as applyServer.
 func (c Config) applyClient(cfg *Config) error { //nolint:dupl,cyclop
@@ -658,16 +1258,49 @@ func (c Config) applyClient(cfg *Config) error { //nolint:dupl,cyclop
 	if c.NetConn != nil {
 		cfg.NetConn = c.NetConn
 	}
+	if c.Transport != nil {
+		cfg.Transport = c.Transport
+	}
+	if c.Clock != nil {
+		cfg.Clock = c.Clock
+	}
+	if c.CookieGenerator != nil {
+		cfg.CookieGenerator = c.CookieGenerator
+	}
+	if c.CookieLifetime != 0 {
+		cfg.CookieLifetime = c.CookieLifetime
+	}
 
 	cfg.BlockWrite = c.BlockWrite
 	cfg.EnableZeroChecksum = c.EnableZeroChecksum
+	if c.ZeroChecksumEDMID != 0 {
+		cfg.ZeroChecksumEDMID = c.ZeroChecksumEDMID
+	}
+	cfg.EnableECN = c.EnableECN
+	cfg.EnableTimestamps = c.EnableTimestamps
+	cfg.LenientChunkParsing = c.LenientChunkParsing
+	cfg.IgnoreInboundChecksum = c.IgnoreInboundChecksum
+	cfg.EnableDwellTimeStats = c.EnableDwellTimeStats
+	cfg.EnableInstantRTT = c.EnableInstantRTT
 
 	if c.MTU != 0 {
 		cfg.MTU = c.MTU
 	}
+	if c.ReceiveMTU != 0 {
+		cfg.ReceiveMTU = c.ReceiveMTU
+	}
 	if c.MaxReceiveBufferSize != 0 {
 		cfg.MaxReceiveBufferSize = c.MaxReceiveBufferSize
 	}
+	if c.ReceiveBufferAutotuneMax != 0 {
+		cfg.ReceiveBufferAutotuneMax = c.ReceiveBufferAutotuneMax
+	}
+	if c.AdvertisedRWND != 0 {
+		cfg.AdvertisedRWND = c.AdvertisedRWND
+	}
+	if c.AvgChunkSizeHint != 0 {
+		cfg.AvgChunkSizeHint = c.AvgChunkSizeHint
+	}
 	if c.MaxMessageSize != 0 {
 		cfg.MaxMessageSize = c.MaxMessageSize
 	}
@@ -677,15 +1310,95 @@ func (c Config) applyClient(cfg *Config) error { //nolint:dupl,cyclop
 	if c.MinCwnd != 0 {
 		cfg.MinCwnd = c.MinCwnd
 	}
+	if c.InitialCwnd != 0 {
+		cfg.InitialCwnd = c.InitialCwnd
+	}
+	if c.MaxCwnd != 0 {
+		cfg.MaxCwnd = c.MaxCwnd
+	}
+	cfg.DisableIdleCwndReset = c.DisableIdleCwndReset
+	cfg.DisableFinalSack = c.DisableFinalSack
+	cfg.EnableFRTO = c.EnableFRTO
+	cfg.DisableVerificationTagCheck = c.DisableVerificationTagCheck
+	if c.IdleCwndResetTimeout != 0 {
+		cfg.IdleCwndResetTimeout = c.IdleCwndResetTimeout
+	}
 	if c.FastRtxWnd != 0 {
 		cfg.FastRtxWnd = c.FastRtxWnd
 	}
 	if c.CwndCAStep != 0 {
 		cfg.CwndCAStep = c.CwndCAStep
 	}
+	if c.ByteCountingLimit != 0 {
+		cfg.ByteCountingLimit = c.ByteCountingLimit
+	}
+	if c.FastRetransmitThreshold != 0 {
+		cfg.FastRetransmitThreshold = c.FastRetransmitThreshold
+	}
+	if c.MaxOutstandingBytes != 0 {
+		cfg.MaxOutstandingBytes = c.MaxOutstandingBytes
+	}
+	if c.MaxBurst != 0 {
+		cfg.MaxBurst = c.MaxBurst
+	}
+	if c.WriteBatchSize != 0 {
+		cfg.WriteBatchSize = c.WriteBatchSize
+	}
+	if c.MinT3RTX != 0 {
+		cfg.MinT3RTX = c.MinT3RTX
+	}
+	if c.RTOJitter != 0 {
+		cfg.RTOJitter = c.RTOJitter
+	}
+	if c.PeerStallTimeout != 0 {
+		cfg.PeerStallTimeout = c.PeerStallTimeout
+	}
+	if c.ReassemblyTimeout != 0 {
+		cfg.ReassemblyTimeout = c.ReassemblyTimeout
+	}
+	if c.HeartbeatInterval != 0 {
+		cfg.HeartbeatInterval = c.HeartbeatInterval
+	}
+	if c.HeartbeatMaxRetrans != 0 {
+		cfg.HeartbeatMaxRetrans = c.HeartbeatMaxRetrans
+	}
+	if c.MaxInitRetransmits != 0 {
+		cfg.MaxInitRetransmits = c.MaxInitRetransmits
+	}
+	if c.MaxShutdownRetrans != 0 {
+		cfg.MaxShutdownRetrans = c.MaxShutdownRetrans
+	}
+	if c.MaxReconfigRetrans != 0 {
+		cfg.MaxReconfigRetrans = c.MaxReconfigRetrans
+	}
+	if c.MaxReconfigRequests != 0 {
+		cfg.MaxReconfigRequests = c.MaxReconfigRequests
+	}
+	if c.ReconfigRequestLifetime != 0 {
+		cfg.ReconfigRequestLifetime = c.ReconfigRequestLifetime
+	}
+	if c.MaxStreams != 0 {
+		cfg.MaxStreams = c.MaxStreams
+	}
+	if c.Profile != ProfileDefault {
+		cfg.Profile = c.Profile
+	}
+	if c.SackPolicy > SackPolicyBundle {
+		return errInvalidSackPolicy
+	}
+	if c.SackPolicy != SackPolicyDelayed {
+		cfg.SackPolicy = c.SackPolicy
+	}
+	if c.SackFrequency < 0 {
+		return errInvalidSackFrequency
+	}
+	if c.SackFrequency != 0 {
+		cfg.SackFrequency = c.SackFrequency
+	}
 
 	cfg.rack = c.rack
 	cfg.interleaving = cloneInterleavingSettings(c.interleaving)
+	cfg.callbacks = cloneCallbackSettings(c.callbacks)
 	if c.enableInterleavingSet {
 		cfg.enableInterleaving = c.enableInterleaving
 		cfg.enableInterleavingSet = true
@@ -711,9 +1424,18 @@ func buildClientConfig(opts ...ClientOption) (*Config, error) {
 
 	cfg.applyDefaults()
 
-	if cfg.NetConn == nil {
+	if cfg.NetConn == nil && cfg.Transport == nil {
 		return nil, errNilNetConn
 	}
+	if cfg.AdvertisedRWND > cfg.MaxReceiveBufferSize {
+		return nil, errAdvertisedRWNDTooLarge
+	}
+	if cfg.ReceiveMTU < minReceiveMTU {
+		return nil, errReceiveMTUTooSmall
+	}
+	if err := cfg.checkCwndLimits(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
@@ -729,6 +1451,14 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 	if maxReceiveBufferSize == 0 {
 		maxReceiveBufferSize = initialRecvBufSize
 	}
+	advertisedRWND := cfg.AdvertisedRWND
+	if advertisedRWND == 0 {
+		advertisedRWND = maxReceiveBufferSize
+	}
+	avgChunkSize := cfg.AvgChunkSizeHint
+	if avgChunkSize == 0 {
+		avgChunkSize = defaultAvgChunkSize
+	}
 
 	maxMessageSize := cfg.MaxMessageSize
 	if maxMessageSize == 0 {
@@ -739,8 +1469,67 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 	if mtu == 0 {
 		mtu = initialMTU
 	}
+	recvMTU := cfg.ReceiveMTU
+	if recvMTU == 0 {
+		recvMTU = receiveMTU
+	}
+	writeBatchSize := cfg.WriteBatchSize
+	if writeBatchSize == 0 {
+		writeBatchSize = defaultWriteBatchSize
+	}
 
 	rtoMax := cfg.RTOMax
+	profile := cfg.Profile.settings()
+	if cfg.SackFrequency > 0 {
+		profile.sackFreq = cfg.SackFrequency
+	}
+	zeroChecksumEDMID := cfg.ZeroChecksumEDMID
+	if zeroChecksumEDMID == zeroChecksumEDMIDReserved {
+		zeroChecksumEDMID = ZeroChecksumEDMIDDTLS
+	}
+	heartbeatInterval := float64(cfg.HeartbeatInterval.Milliseconds())
+	if cfg.HeartbeatInterval > 0 && heartbeatInterval == 0 {
+		heartbeatInterval = 1 // round up rather than disable heartbeats
+	}
+	cookieLifetime := cfg.CookieLifetime
+	if cookieLifetime == 0 {
+		cookieLifetime = defaultCookieLifetime
+	}
+	heartbeatMaxRetrans := cfg.HeartbeatMaxRetrans
+	if heartbeatMaxRetrans == 0 {
+		heartbeatMaxRetrans = pathMaxRetrans
+	}
+	maxInitRetransmits := cfg.MaxInitRetransmits
+	if maxInitRetransmits == 0 {
+		maxInitRetransmits = maxInitRetrans
+	}
+	maxShutdownRetrans := cfg.MaxShutdownRetrans
+	if maxShutdownRetrans == 0 {
+		maxShutdownRetrans = assocMaxRetrans
+	}
+	netConn := cfg.NetConn
+	var transport Transport = netConnTransport{netConn}
+	if cfg.Transport != nil {
+		// Config.Transport replaces NetConn.
+		netConn = nil
+		transport = cfg.Transport
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	maxReconfigRequests := int(cfg.MaxReconfigRequests)
+	if maxReconfigRequests == 0 {
+		maxReconfigRequests = defaultMaxReconfigRequests
+	}
+	reconfigRequestLifetime := cfg.ReconfigRequestLifetime
+	if reconfigRequestLifetime == 0 {
+		reconfigRequestLifetime = defaultReconfigRequestLifetime
+	}
+	fastRtxThreshold := cfg.FastRetransmitThreshold
+	if fastRtxThreshold == 0 {
+		fastRtxThreshold = defaultFastRetransmitThreshold
+	}
 	interleaving := cfg.interleaving
 	if interleaving == nil {
 		interleaving = &interleavingSettings{}
@@ -748,23 +1537,48 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 	}
 
 	assoc := &Association{
-		netConn:              cfg.NetConn,
+		netConn:              netConn,
+		transport:            transport,
 		maxReceiveBufferSize: maxReceiveBufferSize,
+		advertisedRWND:       advertisedRWND,
+		avgChunkSize:         avgChunkSize,
+		recvAutotuneMax:      cfg.ReceiveBufferAutotuneMax,
 		maxMessageSize:       maxMessageSize,
 		minCwnd:              cfg.MinCwnd,
+		maxCwnd:              cfg.MaxCwnd,
+		initialCwnd:          cfg.InitialCwnd,
+		idleCwndReset:        !cfg.DisableIdleCwndReset,
+		finalSack:            !cfg.DisableFinalSack,
+		frtoEnabled:          cfg.EnableFRTO,
+		idleCwndResetTimeout: cfg.IdleCwndResetTimeout,
 		fastRtxWnd:           cfg.FastRtxWnd,
 		cwndCAStep:           cfg.CwndCAStep,
-
-		myMaxNumOutboundStreams: math.MaxUint16,
-		myMaxNumInboundStreams:  math.MaxUint16,
-
-		payloadQueue:            newReceivePayloadQueue(getMaxTSNOffset(maxReceiveBufferSize)),
+		byteCountingLimit:    cfg.ByteCountingLimit,
+		fastRtxThreshold:     fastRtxThreshold,
+		maxOutstandingBytes:  cfg.MaxOutstandingBytes,
+		maxBurst:             cfg.MaxBurst,
+		minT3RTX:             cfg.MinT3RTX,
+		peerStallTimeout:     cfg.PeerStallTimeout,
+		reassemblyTimeout:    cfg.ReassemblyTimeout,
+		heartbeatInterval:    heartbeatInterval,
+		sackFreq:             profile.sackFreq,
+		sackPolicy:           cfg.SackPolicy,
+		nagle:                profile.nagle,
+
+		myMaxNumOutboundStreams: cfg.maxNumStreams(),
+		myMaxNumInboundStreams:  cfg.maxNumStreams(),
+		maxStreams:              cfg.MaxStreams,
+
+		payloadQueue:            newReceivePayloadQueue(getMaxTSNOffset(maxReceiveBufferSize, avgChunkSize)),
 		inflightQueue:           newPayloadQueue(),
 		pendingQueue:            newPendingQueue(interleaving.newStreamScheduler),
 		controlQueue:            newControlQueue(),
 		mtu:                     mtu,
+		recvMTU:                 recvMTU,
+		writeBatchSize:          writeBatchSize,
 		maxPayloadSize:          mtu - (commonHeaderSize + dataChunkHeaderSize),
 		myVerificationTag:       generateInitiateTag(),
+		verificationTagCheck:    !cfg.DisableVerificationTagCheck,
 		initialTSN:              tsn,
 		myNextTSN:               tsn,
 		myNextRSN:               tsn,
@@ -774,14 +1588,32 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 		streams:                 map[uint16]*Stream{},
 		reconfigs:               map[uint32]*chunkReconfig{},
 		reconfigRequests:        map[uint32]*paramOutgoingResetRequest{},
+		reconfigRequestsSeen:    map[uint32]time.Time{},
+		outgoingResets:          map[uint16]*Stream{},
+		ackNotifies:             map[*chunkPayloadData]struct{}{},
+		maxReconfigRequests:     maxReconfigRequests,
+		reconfigRequestLifetime: reconfigRequestLifetime,
+		pings:                   map[uint64]chan struct{}{},
 		acceptCh:                make(chan *Stream, acceptChSize),
 		readLoopCloseCh:         make(chan struct{}),
 		awakeWriteLoopCh:        make(chan struct{}, 1),
 		closeWriteLoopCh:        make(chan struct{}),
 		handshakeCompletedCh:    make(chan error),
 		cumulativeTSNAckPoint:   tsn - 1,
+		lastSackCoverage:        tsn - 1,
+		ecnRecoveryPoint:        tsn - 1,
 		advancedPeerTSNAckPoint: tsn - 1,
 		recvZeroChecksum:        cfg.EnableZeroChecksum,
+		lenientChunkParsing:     cfg.LenientChunkParsing,
+		ignoreInboundChecksum:   cfg.IgnoreInboundChecksum,
+		dwellTimeStats:          cfg.EnableDwellTimeStats,
+		instantRTTEnabled:       cfg.EnableInstantRTT,
+		cookieGenerator:         cfg.CookieGenerator,
+		cookieLifetime:          cookieLifetime,
+		zeroChecksumEDMID:       zeroChecksumEDMID,
+		localECN:                cfg.EnableECN,
+		localTimestamps:         cfg.EnableTimestamps,
+		tsBase:                  time.Now(),
 		localInterleaving:       cfg.enableInterleaving,
 		silentError:             ErrSilentlyDiscard,
 		stats:                   &associationStats{},
@@ -790,6 +1622,17 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 		blockWrite:              cfg.BlockWrite,
 		writeNotify:             make(chan struct{}, 1),
 		abortSentCh:             make(chan struct{}),
+		sackSentCh:              make(chan struct{}, 1),
+	}
+
+	if cfg.callbacks != nil {
+		assoc.onReassemblyMemoryChange = cfg.callbacks.onReassemblyMemoryChange
+		assoc.onReceiveBufferFull = cfg.callbacks.onReceiveBufferFull
+		assoc.onCongestionEvent = cfg.callbacks.onCongestionEvent
+		assoc.onProtocolError = cfg.callbacks.onProtocolError
+		assoc.onPeerStalled = cfg.callbacks.onPeerStalled
+		assoc.onTrace = cfg.callbacks.onTrace
+		assoc.onReadError = cfg.callbacks.onReadError
 	}
 
 	// adaptive burst mitigation defaults
@@ -817,17 +1660,25 @@ func createAssociationFromConfigWithTsn(cfg *Config, tsn uint32) *Association {
 		assoc.name = fmt.Sprintf("%p", assoc)
 	}
 
-	assoc.setCWND(min32(4*assoc.MTU(), max32(2*assoc.MTU(), 4380)))
+	assoc.setCWND(assoc.getInitialCwnd())
 	assoc.log.Tracef("[%s] updated cwnd=%d ssthresh=%d inflight=%d (INI)",
 		assoc.name, assoc.CWND(), assoc.ssthresh, assoc.inflightQueue.getNumBytes())
 
 	assoc.srtt.Store(float64(0))
-	assoc.t1Init = newRTXTimer(timerT1Init, assoc, maxInitRetrans, rtoMax)
-	assoc.t1Cookie = newRTXTimer(timerT1Cookie, assoc, maxInitRetrans, rtoMax)
-	assoc.t2Shutdown = newRTXTimer(timerT2Shutdown, assoc, noMaxRetrans, rtoMax)
-	assoc.t3RTX = newRTXTimer(timerT3RTX, assoc, noMaxRetrans, rtoMax)
-	assoc.tReconfig = newRTXTimer(timerReconfig, assoc, noMaxRetrans, rtoMax)
-	assoc.ackTimer = newAckTimer(assoc)
+	assoc.instantRTT.Store(float64(0))
+	assoc.t1Init = newRTXTimerWithClock(clock, timerT1Init, assoc, maxInitRetransmits, rtoMax)
+	assoc.t1Cookie = newRTXTimerWithClock(clock, timerT1Cookie, assoc, maxInitRetransmits, rtoMax)
+	assoc.t2Shutdown = newRTXTimerWithClock(clock, timerT2Shutdown, assoc, maxShutdownRetrans, rtoMax)
+	assoc.t3RTX = newRTXTimerWithClock(clock, timerT3RTX, assoc, noMaxRetrans, rtoMax)
+	assoc.tReconfig = newRTXTimerWithClock(clock, timerReconfig, assoc, cfg.MaxReconfigRetrans, rtoMax)
+	// rtoMax equal to the interval keeps idle heartbeats periodic (no backoff).
+	assoc.tHeartbeat = newRTXTimerWithClock(clock, timerHeartbeat, assoc, heartbeatMaxRetrans, heartbeatInterval)
+	for _, timer := range []*rtxTimer{assoc.t1Init, assoc.t1Cookie, assoc.t2Shutdown, assoc.t3RTX, assoc.tReconfig} {
+		timer.jitter = cfg.RTOJitter
+	}
+	// jitter must not undo the T3-rtx floor
+	assoc.t3RTX.jitterMin = math.Max(rtoMin, cfg.MinT3RTX)
+	assoc.ackTimer = newAckTimerWithClock(clock, assoc, profile.ackDelay)
 
 	return assoc
 }
@@ -840,17 +1691,21 @@ func (a *Association) initWithOutOfBandTokens(localInit *chunkInit, remoteInit *
 	go a.writeLoop()
 
 	a.payloadQueue.init(remoteInit.initialTSN - 1)
-	a.myMaxNumInboundStreams = min16(localInit.numInboundStreams, remoteInit.numInboundStreams)
-	a.myMaxNumOutboundStreams = min16(localInit.numOutboundStreams, remoteInit.numOutboundStreams)
+	a.myMaxNumInboundStreams = min16(localInit.numInboundStreams, remoteInit.numOutboundStreams)
+	a.myMaxNumOutboundStreams = min16(localInit.numOutboundStreams, remoteInit.numInboundStreams)
 	a.setRWND(remoteInit.advertisedReceiverWindowCredit)
+	a.myVerificationTag = localInit.initiateTag
 	a.peerVerificationTag = remoteInit.initiateTag
 	a.sourcePort = defaultSCTPSrcDstPort
 	a.destinationPort = defaultSCTPSrcDstPort
 
 	localExtensions := getSupportedExtensions(localInit.params)
 	a.localInterleaving = localExtensions.interleaving
+	a.localTimestamps = localExtensions.timestamps
 	a.setPeerSupportedExtensions(getSupportedExtensions(remoteInit.params))
 	a.setSendZeroChecksum(remoteInit.params)
+	a.localECN = hasECNCapable(localInit.params)
+	a.useECN = a.localECN && hasECNCapable(remoteInit.params)
 
 	a.ssthresh = a.RWND()
 
@@ -865,14 +1720,25 @@ func (a *Association) setPeerSupportedExtensions(extensions supportedExtensions)
 	a.peerForwardTSN = extensions.forwardTSN
 	a.peerInterleaving = extensions.interleaving
 	a.peerIForwardTSN = extensions.iForwardTSN
+	a.peerTimestamps = extensions.timestamps
 }
 
 func (a *Association) setSendZeroChecksum(params []param) {
 	for _, param := range params {
 		if zeroChecksum, ok := param.(*paramZeroChecksumAcceptable); ok {
-			a.sendZeroChecksum = zeroChecksum.edmid == dtlsErrorDetectionMethod
+			a.sendZeroChecksum = zeroChecksum.edmid == a.zeroChecksumEDMID
+		}
+	}
+}
+
+func hasECNCapable(params []param) bool {
+	for _, param := range params {
+		if _, ok := param.(*paramECNCapable); ok {
+			return true
 		}
 	}
+
+	return false
 }
 
 func (a *Association) logNegotiatedExtensions(stage string) {
@@ -897,10 +1763,25 @@ func (a *Association) establish(stage string) error {
 	}
 	a.logNegotiatedExtensions(stage)
 	a.setState(established)
+	a.restartHeartbeatTimer()
 
 	return nil
 }
 
+// restartHeartbeatTimer (re)arms the idle heartbeat timer, clearing the count
+// of unacknowledged heartbeats. The timer is stopped outside ESTABLISHED.
+// The caller should hold the lock.
+func (a *Association) restartHeartbeatTimer() {
+	if a.heartbeatInterval <= 0 {
+		return
+	}
+
+	a.tHeartbeat.stop()
+	if a.getState() == established {
+		a.tHeartbeat.start(a.heartbeatInterval)
+	}
+}
+
 // caller must hold a.lock.
 func (a *Association) sendInit() error {
 	a.log.Debugf("[%s] sending INIT", a.name)
@@ -923,8 +1804,9 @@ func (a *Association) sendInit() error {
 	return nil
 }
 
+// sendCookieEcho sends the stored COOKIE ECHO, followed by the bundled chunks.
 // caller must hold a.lock.
-func (a *Association) sendCookieEcho() error {
+func (a *Association) sendCookieEcho(bundled ...chunk) error {
 	if a.storedCookieEcho == nil {
 		return ErrCookieEchoNotStoredToSend
 	}
@@ -935,7 +1817,7 @@ func (a *Association) sendCookieEcho() error {
 	outbound.verificationTag = a.peerVerificationTag
 	outbound.sourcePort = a.sourcePort
 	outbound.destinationPort = a.destinationPort
-	outbound.chunks = []chunk{a.storedCookieEcho}
+	outbound.chunks = append([]chunk{a.storedCookieEcho}, bundled...)
 
 	a.controlQueue.push(outbound)
 	a.awakeWriteLoop()
@@ -943,15 +1825,24 @@ func (a *Association) sendCookieEcho() error {
 	return nil
 }
 
-// Shutdown initiates the shutdown sequence. The method blocks until the
-// shutdown sequence is completed and the connection is closed, or until the
-// passed context is done, in which case the context's error is returned.
+// Shutdown initiates the shutdown sequence. New writes are rejected with
+// ErrShuttingDown until the association is closed, but the
+// data already written is still sent, including data waiting in the pending
+// queue for cwnd or rwnd to open, and SHUTDOWN is only sent once all of it
+// has been acknowledged. Use Abort or Close to discard it instead.
+// The method blocks until the shutdown sequence is completed and the
+// connection is closed, or until the passed context is done, in which case
+// the context's error is returned.
 func (a *Association) Shutdown(ctx context.Context) error {
 	a.log.Debugf("[%s] closing association..", a.name)
 
 	state := a.getState()
 
 	if state != established {
+		if err := a.getTransportErr(); err != nil {
+			return fmt.Errorf("%w: %w", ErrShutdownTransportClosed, err)
+		}
+
 		return fmt.Errorf("%w: shutdown %s", ErrShutdownNonEstablished, a.name)
 	}
 
@@ -960,27 +1851,84 @@ func (a *Association) Shutdown(ctx context.Context) error {
 
 	a.lock.Lock()
 
-	if a.inflightQueue.size() == 0 {
+	if a.inflightQueue.size() == 0 && a.pendingQueue.size() == 0 {
 		// No more outstanding, send shutdown.
 		a.willSendShutdown = true
 		a.awakeWriteLoop()
 		a.setState(shutdownSent)
+	} else {
+		// Drain the pending queue first.
+		a.awakeWriteLoop()
 	}
 
 	a.lock.Unlock()
 
 	select {
 	case <-a.closeWriteLoopCh:
-		return nil
+		// The write loop also stops when netConn fails, in which case the
+		// SHUTDOWN handshake could not complete.
+		if err := a.getTransportErr(); err != nil {
+			return fmt.Errorf("%w: %w", ErrShutdownTransportClosed, err)
+		}
+
+		// The association may also have been closed by an ABORT or because
+		// the peer stopped acknowledging SHUTDOWN. Whoever closed it set
+		// the close error before stopping the write loop.
+		return a.CloseError()
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
+// CloseWithTimeout gracefully shuts down the association, waiting at most
+// timeout for the peer to complete the SHUTDOWN sequence, and then closes it.
+// An association that is not established is closed immediately. If the
+// graceful shutdown fails or times out, the association is still closed and
+// the Shutdown error is returned.
+func (a *Association) CloseWithTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := a.Shutdown(ctx)
+	if err == nil {
+		// Wait for readLoop to end
+		<-a.readLoopCloseCh
+
+		return nil
+	}
+
+	closeErr := a.Close()
+	if errors.Is(err, ErrShutdownNonEstablished) {
+		return closeErr
+	}
+
+	return err
+}
+
+// CloseError returns the error that terminated the association, such as an
+// *AbortError carrying the causes of a peer ABORT, ErrPeerUnreachable or a
+// transport failure. It returns nil while the association is open and when
+// it was closed locally or by a graceful SHUTDOWN. It is safe to call after
+// Close.
+func (a *Association) CloseError() error {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.closeErr
+}
+
+// Closed returns a channel that is closed once the association has torn
+// down and its read loop exited, whether by Close, Shutdown, an ABORT or a
+// transport failure. CloseError then tells why it was closed.
+func (a *Association) Closed() <-chan struct{} {
+	return a.readLoopCloseCh
+}
+
 // Close ends the SCTP Association and cleans up any state.
 func (a *Association) Close() error {
 	a.log.Debugf("[%s] closing association..", a.name)
 
+	a.sendFinalSack()
 	err := a.close()
 
 	// Wait for readLoop to end
@@ -999,12 +1947,43 @@ func (a *Association) Close() error {
 	return err
 }
 
+// sendFinalSack has the write loop send the SACK being delayed, if any, and
+// waits at most finalSackTimeout for it to be written.
+func (a *Association) sendFinalSack() {
+	a.lock.Lock()
+	if !a.finalSack || a.getState() != established || a.ackState != ackStateDelay {
+		a.lock.Unlock()
+
+		return
+	}
+
+	// Discard the signal of a SACK written earlier.
+	select {
+	case <-a.sackSentCh:
+	default:
+	}
+	a.ackState = ackStateImmediate
+	a.delayedAckPackets = 0
+	a.ackTimer.stop()
+	a.awakeWriteLoop()
+	a.lock.Unlock()
+
+	timer := time.NewTimer(finalSackTimeout)
+	defer timer.Stop()
+	select {
+	case <-a.sackSentCh:
+	case <-a.closeWriteLoopCh:
+	case <-timer.C:
+		a.log.Debugf("[%s] final SACK not written before close", a.name)
+	}
+}
+
 func (a *Association) close() error {
 	a.log.Debugf("[%s] closing association..", a.name)
 
 	a.setState(closed)
 
-	err := a.netConn.Close()
+	err := a.transport.Close()
 
 	a.closeAllTimers()
 
@@ -1014,24 +1993,64 @@ func (a *Association) close() error {
 	return err
 }
 
+// closeKeepingTransport tears the association down like Close but leaves
+// the transport, which belongs to the caller, open. The read loop is
+// unblocked with a read deadline, which is cleared once it exits; a
+// transport without deadlines has to be closed after all.
+func (a *Association) closeKeepingTransport() {
+	a.log.Debugf("[%s] closing association, keeping the transport..", a.name)
+
+	a.setState(closed)
+	a.closeAllTimers()
+	a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
+
+	deadlines, hasDeadlines := a.transport.(deadlineTransport)
+	if !hasDeadlines {
+		_ = a.transport.Close()
+		<-a.readLoopCloseCh
+
+		return
+	}
+	_ = deadlines.SetReadDeadline(time.Now())
+	<-a.readLoopCloseCh
+	_ = deadlines.SetReadDeadline(time.Time{})
+}
+
 // Abort sends the abort packet with user initiated abort and immediately
 // closes the connection.
 func (a *Association) Abort(reason string) {
 	a.log.Debugf("[%s] aborting association: %s", a.name, reason)
 
+	a.abort(&errorCauseUserInitiatedAbort{
+		upperLayerAbortReason: []byte(reason),
+	})
+}
+
+// AbortWithCause sends the abort packet with the given error cause, such as
+// one returned by NewProtocolViolationCause, and immediately closes the
+// connection.
+func (a *Association) AbortWithCause(cause ErrorCause) {
+	a.log.Debugf("[%s] aborting association with cause: %s", a.name, cause)
+
+	a.abort(cause.toErrorCause())
+}
+
+func (a *Association) abort(cause errorCause) {
 	a.lock.Lock()
 
+	a.abortedLocally = true
 	a.willSendAbort = true
-	a.willSendAbortCause = &errorCauseUserInitiatedAbort{
-		upperLayerAbortReason: []byte(reason),
-	}
+	a.willSendAbortCause = cause
 
 	a.lock.Unlock()
 
 	flushTimeout := 200 * time.Millisecond
 
 	// short bound for abort flush.
-	_ = a.netConn.SetWriteDeadline(time.Now().Add(flushTimeout))
+	deadlines, hasDeadlines := a.transport.(deadlineTransport)
+	if hasDeadlines {
+		_ = deadlines.SetWriteDeadline(time.Now().Add(flushTimeout))
+	}
 	a.awakeWriteLoop()
 
 	// Give writeLoop a chance to write the ABORT before we force readLoop to exit
@@ -1042,8 +2061,14 @@ func (a *Association) Abort(reason string) {
 	}
 
 	// unblock readLoop even if the underlying connection is half-open.
-	// We want Abort to return promptly during shutdown.
-	_ = a.netConn.SetReadDeadline(time.Now())
+	// We want Abort to return promptly during shutdown, so the read error
+	// must not be retried.
+	a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
+	if hasDeadlines {
+		_ = deadlines.SetReadDeadline(time.Now())
+	} else {
+		_ = a.transport.Close()
+	}
 
 	// Wait for readLoop to end
 	<-a.readLoopCloseCh
@@ -1062,25 +2087,34 @@ func (a *Association) closeAllTimers() {
 	a.t2Shutdown.close()
 	a.t3RTX.close()
 	a.tReconfig.close()
+	a.tHeartbeat.close()
 	a.ackTimer.close()
 	a.stopRackTimer()
 	a.stopPTOTimer()
+	a.stopReassemblyTimer()
 }
 
 func (a *Association) readLoop() {
 	var closeErr error
 	defer func() {
-		// also stop writeLoop, otherwise writeLoop can be leaked
-		// if connection is lost when there is no writing packet.
-		a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
-
 		a.lock.Lock()
+		if a.closeErr == nil {
+			a.closeErr = a.transportErr
+		}
 		a.setState(closed)
 		for _, s := range a.streams {
 			a.unregisterStream(s, closeErr)
 		}
 		a.unblockPendingWrites()
+		for c := range a.ackNotifies {
+			a.notifyAck(c, ErrAssociationClosed)
+		}
 		a.lock.Unlock()
+
+		// also stop writeLoop, otherwise writeLoop can be leaked
+		// if connection is lost when there is no writing packet.
+		// This comes after closeErr is set, so that Shutdown returns it.
+		a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
 		close(a.acceptCh)
 		close(a.readLoopCloseCh)
 
@@ -1093,15 +2127,27 @@ func (a *Association) readLoop() {
 	}()
 
 	a.log.Debugf("[%s] readLoop entered", a.name)
-	buffer := make([]byte, receiveMTU)
+	// one extra byte to detect packets truncated to the buffer size
+	buffer := make([]byte, a.recvMTU+1)
+	var readErrorBackoff time.Duration
 
 	for {
-		n, err := a.netConn.Read(buffer)
+		n, err := a.transport.ReadPacket(buffer)
 		if err != nil {
+			if a.retryRead(err, &readErrorBackoff) {
+				continue
+			}
+			a.setTransportErr(err)
 			closeErr = err
 
 			break
 		}
+		readErrorBackoff = 0
+		if n > int(a.recvMTU) {
+			a.log.Warnf("[%s] dropped a packet larger than the receive MTU of %d bytes", a.name, a.recvMTU)
+
+			continue
+		}
 		// Make a buffer sized to what we read, then copy the data we
 		// read from the underlying transport. We do this because the
 		// user data is passed to the reassembly queue without
@@ -1110,6 +2156,11 @@ func (a *Association) readLoop() {
 		copy(inbound, buffer[:n])
 		atomic.AddUint64(&a.bytesReceived, uint64(n)) //nolint:gosec // G115
 		if err = a.handleInbound(inbound); err != nil {
+			a.lock.Lock()
+			if a.closeErr == nil {
+				a.closeErr = err
+			}
+			a.lock.Unlock()
 			closeErr = err
 
 			break
@@ -1123,28 +2174,69 @@ func (a *Association) writeLoop() { // nolint:cyclop
 	a.log.Debugf("[%s] writeLoop entered", a.name)
 	defer a.log.Debugf("[%s] writeLoop exited", a.name)
 
+	// deferred holds the packets gathered but not written yet because of
+	// writeBatchSize. They are written before gathering new ones.
+	var deferred [][]byte
+	ok := true
+
 loop:
 	for {
-		rawPackets, ok := a.gatherOutbound()
+		rawPackets := deferred
+		if len(rawPackets) == 0 || a.isAbortPending() {
+			// An ABORT discards whatever was still to be sent.
+			rawPackets, ok = a.gatherOutbound()
+		}
+		deferred = nil
+		if len(rawPackets) > int(a.writeBatchSize) {
+			rawPackets, deferred = rawPackets[:a.writeBatchSize], rawPackets[a.writeBatchSize:]
+		}
 
 		for _, raw := range rawPackets {
 			isAbortPacket := len(raw) > int(commonHeaderSize) && raw[commonHeaderSize] == byte(ctAbort)
-			_, err := a.netConn.Write(raw)
+			// a TIMESTAMP chunk may come before the SACK
+			isSackPacket := hasChunkType(raw, ctSack)
+			err := a.transport.WritePacket(raw)
 			if isAbortPacket {
 				a.abortSentOnce.Do(func() { close(a.abortSentCh) })
 			}
+			if isSackPacket {
+				select {
+				case a.sackSentCh <- struct{}{}:
+				default:
+				}
+			}
 			if err != nil {
 				if !errors.Is(err, io.EOF) {
-					a.log.Warnf("[%s] failed to write packets on netConn: %v", a.name, err)
+					a.log.Warnf("[%s] failed to write packets on transport: %v", a.name, err)
 				}
 				a.log.Debugf("[%s] writeLoop ended", a.name)
 
-				break loop
+				// Tear down so that readLoop and Shutdown do not wait on a
+				// transport that can no longer be written to.
+				a.setTransportErr(err)
+				if err := a.close(); err != nil {
+					a.log.Debugf("[%s] failed to close association: %v", a.name, err)
+				}
+
+				return
 			}
 			atomic.AddUint64(&a.bytesSent, uint64(len(raw)))
 			a.stats.incPacketsSent()
 		}
 
+		if len(deferred) > 0 {
+			select {
+			case <-a.closeWriteLoopCh:
+				if a.isAbortPending() {
+					continue
+				}
+
+				break loop
+			default:
+				continue
+			}
+		}
+
 		if !ok {
 			if err := a.close(); err != nil {
 				a.log.Warnf("[%s] failed to close association: %v", a.name, err)
@@ -1156,10 +2248,7 @@ loop:
 		select {
 		case <-a.awakeWriteLoopCh:
 		case <-a.closeWriteLoopCh:
-			a.lock.Lock()
-			abortPending := a.willSendAbort
-			a.lock.Unlock()
-			if abortPending {
+			if a.isAbortPending() {
 				// If an ABORT is pending, prefer sending it even if readLoop has
 				// already ended and closed closeWriteLoopCh.
 				continue
@@ -1173,6 +2262,32 @@ loop:
 	a.closeAllTimers()
 }
 
+// isAbortPending reports whether an ABORT is waiting to be sent.
+func (a *Association) isAbortPending() bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.willSendAbort
+}
+
+// setTransportErr records a netConn failure unless the association has
+// already been closed locally (which also fails pending reads and writes).
+func (a *Association) setTransportErr(err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.transportErr == nil && !a.abortedLocally && a.getState() != closed {
+		a.transportErr = err
+	}
+}
+
+func (a *Association) getTransportErr() error {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.transportErr
+}
+
 func (a *Association) awakeWriteLoop() {
 	select {
 	case a.awakeWriteLoopCh <- struct{}{}:
@@ -1206,6 +2321,15 @@ func (a *Association) unblockPendingWrites() {
 	a.writeNotify = make(chan struct{}, 1)
 }
 
+// notifyAck sends the outcome of a message written with WriteWithAck, whose
+// last fragment is c.
+// The caller should hold the lock.
+func (a *Association) notifyAck(c *chunkPayloadData, err error) {
+	c.ackNotify <- err
+	c.ackNotify = nil
+	delete(a.ackNotifies, c)
+}
+
 // unregisterStream un-registers a stream from the association
 // The caller should hold the association write lock.
 func (a *Association) unregisterStream(s *Stream, err error) {
@@ -1213,6 +2337,7 @@ func (a *Association) unregisterStream(s *Stream, err error) {
 	defer s.lock.Unlock()
 
 	delete(a.streams, s.streamIdentifier)
+	a.streamStats.Delete(s.streamIdentifier)
 	s.readErr = err
 	s.readNotifier.Broadcast()
 }
@@ -1229,12 +2354,19 @@ func chunkMandatoryChecksum(cc []chunk) bool {
 }
 
 func (a *Association) marshalPacket(p *packet) ([]byte, error) {
+	p = a.addTimestampChunk(p)
+
 	return p.marshal(!a.sendZeroChecksum || chunkMandatoryChecksum(p.chunks))
 }
 
 func (a *Association) unmarshalPacket(raw []byte) (*packet, error) {
 	p := &packet{}
-	if err := p.unmarshal(!a.recvZeroChecksum, raw); err != nil {
+	err := p.unmarshalChunks(!a.recvZeroChecksum, a.lenientChunkParsing, raw)
+	if errors.Is(err, ErrChecksumMismatch) && a.ignoreInboundChecksum {
+		a.log.Warnf("[%s] processing packet despite %s", a.name, err)
+		err = p.unmarshalUnchecked(a.lenientChunkParsing, raw)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -1256,10 +2388,20 @@ func (a *Association) handleInbound(raw []byte) error {
 		return nil
 	}
 
+	if a.isOutOfTheBlue(pkt) {
+		a.handleOutOfTheBlue(pkt)
+
+		return nil
+	}
+
 	a.handleChunksStart()
 
 	for _, c := range pkt.chunks {
-		if err := a.handleChunk(pkt, c); err != nil {
+		err := a.handleChunk(pkt, c)
+		a.flushReassemblyMemoryChange()
+		a.flushCongestionEvents()
+		a.flushProtocolErrors()
+		if err != nil {
 			return err
 		}
 	}
@@ -1299,8 +2441,11 @@ func (a *Association) gatherOutboundDataAndReconfigPackets(
 	if len(chunks) > 0 {
 		// Start timer. (noop if already started)
 		a.log.Tracef("[%s] T3-rtx timer start (pt1)", a.name)
-		a.t3RTX.start(a.rtoMgr.getRTO())
-		for _, p := range a.bundleDataChunksIntoPackets(chunks) {
+		a.t3RTX.start(a.getT3RTXTimeout())
+		for i, p := range a.bundleDataChunksIntoPackets(chunks) {
+			if i == 0 {
+				a.bundleSack(p)
+			}
 			raw, err := a.marshalPacket(p)
 			if err != nil {
 				a.log.Warnf("[%s] failed to serialize a DATA packet", a.name)
@@ -1390,7 +2535,7 @@ func (a *Association) gatherOutboundFastRetransmissionPackets( //nolint:gocognit
 			continue
 		}
 
-		if chunkPayload.nSent > 1 || chunkPayload.missIndicator < 3 {
+		if chunkPayload.nSent > 1 || chunkPayload.missIndicator < a.fastRtxThreshold {
 			continue
 		}
 
@@ -1450,17 +2595,23 @@ func (a *Association) gatherOutboundFastRetransmissionPackets( //nolint:gocognit
 
 		fastRetransSize += chunkBytes
 		a.stats.incFastRetrans()
+		a.incStreamRetransmissions(chunkPayload.streamIdentifier)
 
 		// Update for retransmission
 		chunkPayload.nSent++
 		chunkPayload.since = now
+		chunkPayload.rtoGeneration = a.rtoGeneration
 		a.rackRemove(chunkPayload)
 		a.rackInsert(chunkPayload)
 
 		a.checkPartialReliabilityStatus(chunkPayload)
 		toFastRetrans = append(toFastRetrans, chunkPayload)
-		a.log.Tracef("[%s] fast-retransmit: tsn=%d sent=%d htna=%d",
-			a.name, chunkPayload.tsn, chunkPayload.nSent, a.fastRecoverExitPoint)
+		if a.onTrace != nil {
+			a.onTrace(newTraceEvent(TraceEventFastRetransmit, chunkPayload))
+		} else {
+			a.log.Tracef("[%s] fast-retransmit: tsn=%d sent=%d htna=%d",
+				a.name, chunkPayload.tsn, chunkPayload.nSent, a.fastRecoverExitPoint)
+		}
 	}
 
 	if len(toFastRetrans) == 0 {
@@ -1498,6 +2649,41 @@ func (a *Association) gatherOutboundSackPackets(rawPackets [][]byte) [][]byte {
 	return rawPackets
 }
 
+// bundleSack puts the pending SACK, if any, in front of the DATA chunks of p
+// when SackPolicyBundle is used and the packet stays within the MTU.
+// The caller should hold the lock.
+func (a *Association) bundleSack(p *packet) {
+	if a.sackPolicy != SackPolicyBundle || a.ackState == ackStateIdle {
+		return
+	}
+
+	sack := a.createSelectiveAckChunk()
+	raw, err := sack.marshal()
+	if err != nil {
+		return
+	}
+
+	size := int(commonHeaderSize) + len(raw) + getPadding(len(raw))
+	if a.useTimestamps {
+		size += int(timestampChunkSize)
+	}
+	for _, c := range p.chunks {
+		if chunkPayload, ok := c.(*chunkPayloadData); ok {
+			size += chunkPayload.chunkSizeInPacket()
+		}
+	}
+	if size > int(a.MTU()) {
+		return
+	}
+
+	a.ackState = ackStateIdle
+	a.delayedAckPackets = 0
+	a.ackTimer.stop()
+	a.stats.incSACKsSent()
+	a.log.Debugf("[%s] bundling SACK with DATA: %s", a.name, sack)
+	p.chunks = append([]chunk{sack}, p.chunks...)
+}
+
 // The caller should hold the lock.
 func (a *Association) gatherOutboundForwardTSNPackets(rawPackets [][]byte) [][]byte {
 	if a.willSendForwardTSN { //nolint:nestif
@@ -1644,6 +2830,10 @@ func (a *Association) gatherOutbound() ([][]byte, bool) {
 		consumed := false
 
 		rawPackets = a.gatherDataPacketsToRetransmit(rawPackets, &budgetUnits, &consumed)
+		if state == shutdownPending {
+			// Data written before Shutdown is still sent.
+			rawPackets = a.gatherOutboundDataAndReconfigPackets(rawPackets, &budgetUnits, &consumed)
+		}
 		rawPackets = a.gatherOutboundFastRetransmissionPackets(rawPackets, &budgetUnits, &consumed)
 
 		rawPackets = a.gatherOutboundSackPackets(rawPackets)
@@ -1697,6 +2887,126 @@ func checkPacket(pkt *packet) error {
 	return nil
 }
 
+// isOutOfTheBlue reports whether pkt does not belong to the association:
+// its verification tag is unexpected, or it is not part of a handshake while
+// the association is closed.
+func (a *Association) isOutOfTheBlue(pkt *packet) bool {
+	if !a.checkVerificationTag(pkt) {
+		return true
+	}
+	if a.getState() != closed || len(pkt.chunks) == 0 {
+		return false
+	}
+
+	switch pkt.chunks[0].(type) {
+	case *chunkInit, *chunkCookieEcho:
+		return false
+	default:
+		return true
+	}
+}
+
+// handleOutOfTheBlue responds to a packet that does not belong to the
+// association as in RFC 9260 section 8.4: the sender is told to abort with
+// its own verification tag reflected, so that it does not keep a half-open
+// association. Replies are rate limited, see allowOutOfTheBlueReply.
+func (a *Association) handleOutOfTheBlue(pkt *packet) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.log.Debugf("[%s] out of the blue packet: verificationTag=%d state=%s",
+		a.name, pkt.verificationTag, getAssociationStateString(a.getState()))
+
+	var reply chunk = &chunkAbort{reflectedTag: true}
+	for _, c := range pkt.chunks {
+		switch c := c.(type) {
+		case *chunkAbort, *chunkShutdownComplete, *chunkCookieAck:
+			return
+		case *chunkError:
+			for _, e := range c.errorCauses {
+				if _, ok := e.(*errorCauseStaleCookie); ok {
+					return
+				}
+			}
+		case *chunkShutdownAck:
+			reply = &chunkShutdownComplete{reflectedTag: true}
+		}
+	}
+
+	if !a.allowOutOfTheBlueReply(time.Now()) {
+		a.log.Tracef("[%s] out of the blue reply rate limited", a.name)
+
+		return
+	}
+
+	a.controlQueue.push(&packet{
+		sourcePort:      pkt.destinationPort,
+		destinationPort: pkt.sourcePort,
+		verificationTag: pkt.verificationTag,
+		chunks:          []chunk{reply},
+	})
+	a.awakeWriteLoop()
+}
+
+// allowOutOfTheBlueReply takes a token from the bucket of replies to out of
+// the blue packets, refilled at ootbReplyRate per second up to
+// ootbReplyBurst, and reports whether there was one. RFC 9260 section 8.5
+// has packets with a wrong verification tag silently discarded: replies are
+// bounded so that a flood of them cannot be reflected at full rate.
+// The caller should hold the lock.
+func (a *Association) allowOutOfTheBlueReply(now time.Time) bool {
+	if a.ootbReplyRefilled.IsZero() {
+		a.ootbReplyTokens = ootbReplyBurst
+	} else {
+		elapsed := now.Sub(a.ootbReplyRefilled).Seconds()
+		a.ootbReplyTokens = min(a.ootbReplyTokens+elapsed*ootbReplyRate, ootbReplyBurst)
+	}
+	a.ootbReplyRefilled = now
+	if a.ootbReplyTokens < 1 {
+		return false
+	}
+	a.ootbReplyTokens--
+
+	return true
+}
+
+// checkVerificationTag reports whether the verification tag of pkt is valid
+// for the association (RFC 9260 section 8.5). The tag of INIT packets is
+// checked by checkPacket.
+func (a *Association) checkVerificationTag(pkt *packet) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if !a.verificationTagCheck {
+		return true
+	}
+
+	for _, c := range pkt.chunks {
+		switch c := c.(type) {
+		case *chunkInit:
+			return true
+		case *chunkAbort:
+			return a.checkReflectableTag(pkt.verificationTag, c.reflectedTag)
+		case *chunkShutdownComplete:
+			return a.checkReflectableTag(pkt.verificationTag, c.reflectedTag)
+		}
+	}
+
+	return pkt.verificationTag == a.myVerificationTag
+}
+
+// checkReflectableTag checks the verification tag of a packet carrying
+// ABORT or SHUTDOWN COMPLETE, which is the peer's own tag if the T bit is
+// set (RFC 9260 section 8.5.1).
+// The caller should hold the lock.
+func (a *Association) checkReflectableTag(tag uint32, reflected bool) bool {
+	if reflected {
+		return a.peerVerificationTag != 0 && tag == a.peerVerificationTag
+	}
+
+	return tag == a.myVerificationTag
+}
+
 func min16(a, b uint16) uint16 {
 	if a < b {
 		return a
@@ -1743,6 +3053,37 @@ func (a *Association) getState() uint32 {
 	return atomic.LoadUint32(&a.state)
 }
 
+// Stats returns a snapshot of the association's counters, including those
+// of its open streams.
+func (a *Association) Stats() AssociationStats {
+	stats := a.stats.snapshot()
+	stats.Streams = map[uint16]StreamStats{}
+	a.streamStats.Range(func(id, s any) bool {
+		stats.Streams[id.(uint16)] = s.(*streamStats).snapshot() //nolint:forcetypeassert
+
+		return true
+	})
+
+	return stats
+}
+
+// incStreamRetransmissions counts a retransmitted DATA chunk in the stats of
+// its stream, if still open. The caller should hold the lock.
+func (a *Association) incStreamRetransmissions(streamIdentifier uint16) {
+	if s, ok := a.streams[streamIdentifier]; ok {
+		s.stats.incRetransmissions()
+	}
+}
+
+// Transport returns the net.Conn the association reads from and writes to,
+// or nil if it was created with Config.Transport instead. It can be used to
+// read RemoteAddr or tune the underlying socket. The association still owns
+// the conn: closing it or reading from it while the association is open is
+// not supported.
+func (a *Association) Transport() net.Conn {
+	return a.netConn
+}
+
 // BytesSent returns the number of bytes sent.
 func (a *Association) BytesSent() uint64 {
 	return atomic.LoadUint64(&a.bytesSent)
@@ -1753,6 +3094,18 @@ func (a *Association) BytesReceived() uint64 {
 	return atomic.LoadUint64(&a.bytesReceived)
 }
 
+// GoodputBytesSent returns the number of user bytes cumulatively acknowledged
+// by the peer. Each TSN is counted once, however often it was retransmitted.
+func (a *Association) GoodputBytesSent() uint64 {
+	return a.stats.getGoodputBytesSent()
+}
+
+// GoodputBytesReceived returns the number of user bytes of the unique DATA
+// chunks delivered to streams. Duplicates are not counted.
+func (a *Association) GoodputBytesReceived() uint64 {
+	return a.stats.getGoodputBytesReceived()
+}
+
 // MTU returns the association's current MTU.
 func (a *Association) MTU() uint32 {
 	return atomic.LoadUint32(&a.mtu)
@@ -1767,9 +3120,66 @@ func (a *Association) setCWND(cwnd uint32) {
 	if cwnd < a.minCwnd {
 		cwnd = a.minCwnd
 	}
+	if a.maxCwnd != 0 && cwnd > a.maxCwnd {
+		cwnd = a.maxCwnd
+	}
 	atomic.StoreUint32(&a.cwnd, cwnd)
 }
 
+// getInitialCwnd returns the initial congestion window.
+func (a *Association) getInitialCwnd() uint32 {
+	if a.initialCwnd != 0 {
+		return a.initialCwnd
+	}
+
+	return min32(4*a.MTU(), max32(2*a.MTU(), 4380))
+}
+
+// resetCwndAfterIdle resets cwnd to its initial value when no DATA was sent
+// for the idle timeout and nothing is outstanding (RFC 9260 section 7.2.1).
+// The caller should hold the lock.
+func (a *Association) resetCwndAfterIdle(now time.Time) {
+	if !a.idleCwndReset || a.lastDataSent.IsZero() || a.inflightQueue.size() > 0 {
+		return
+	}
+
+	timeout := a.idleCwndResetTimeout
+	if timeout == 0 {
+		timeout = time.Duration(a.rtoMgr.getRTO() * float64(time.Millisecond))
+	}
+	if now.Sub(a.lastDataSent) <= timeout {
+		return
+	}
+
+	initialCwnd := a.getInitialCwnd()
+	if a.CWND() > initialCwnd {
+		a.setCWND(initialCwnd)
+		a.log.Tracef("[%s] updated cwnd=%d ssthresh=%d (idle)", a.name, a.CWND(), a.ssthresh)
+	}
+	a.partialBytesAcked = 0
+}
+
+// warnMinCwndOverridesLoss logs once when the configured minCwnd is at or
+// above the ssthresh just computed for a loss event, which means the
+// congestion window is not reduced. This is the intended behavior of the
+// floor, but is likely unexpected.
+// The caller should hold the lock.
+func (a *Association) warnMinCwndOverridesLoss() {
+	if a.minCwndWarned || a.minCwnd < a.ssthresh {
+		return
+	}
+
+	a.minCwndWarned = true
+	a.log.Warnf("[%s] MinCwnd=%d is not below ssthresh=%d: congestion window is not reduced on loss",
+		a.name, a.minCwnd, a.ssthresh)
+}
+
+// getT3RTXTimeout returns the RTO to start the T3-rtx timer with, raised to
+// the configured T3-rtx floor if any.
+func (a *Association) getT3RTXTimeout() float64 {
+	return math.Max(a.rtoMgr.getRTO(), a.minT3RTX)
+}
+
 // RWND returns the association's current receiver window (rwnd).
 func (a *Association) RWND() uint32 {
 	return atomic.LoadUint32(&a.rwnd)
@@ -1784,6 +3194,23 @@ func (a *Association) SRTT() float64 {
 	return a.srtt.Load().(float64) //nolint:forcetypeassert
 }
 
+// InstantRTT returns the round-trip time in milliseconds sampled on every
+// SACK, smoothed as the SRTT, or 0 without Config.EnableInstantRTT. It
+// reacts faster than SRTT to RTT changes on high-throughput links.
+func (a *Association) InstantRTT() float64 {
+	return a.instantRTT.Load().(float64) //nolint:forcetypeassert
+}
+
+// updateInstantRTT smooths an RTT sample into InstantRTT.
+// The caller should hold the lock.
+func (a *Association) updateInstantRTT(rtt time.Duration) {
+	sample := rtt.Seconds() * 1000.0
+	if curr := a.InstantRTT(); curr != 0 {
+		sample = (1-rtoAlpha)*curr + rtoAlpha*sample
+	}
+	a.instantRTT.Store(sample)
+}
+
 // Metadata returns negotiated association metadata. The ok return value is false
 // until the SCTP handshake has completed.
 func (a *Association) Metadata() (AssociationMetadata, bool) {
@@ -1810,17 +3237,50 @@ func (a *Association) Metadata() (AssociationMetadata, bool) {
 	}, true
 }
 
+// NegotiatedExtensions returns the extensions in use on the association, as
+// agreed on in the Supported Extensions parameters of the INIT and INIT ACK.
+// FORWARD-TSN is not listed when I-DATA replaces it with I-FORWARD-TSN.
+// It returns nil until the SCTP handshake has completed.
+func (a *Association) NegotiatedExtensions() []Extension {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if a.getState() != established {
+		return nil
+	}
+
+	extensions := []Extension{}
+	if a.peerReconfig {
+		extensions = append(extensions, ExtensionReconfig)
+	}
+	if a.useForwardTSN {
+		extensions = append(extensions, ExtensionForwardTSN)
+	}
+	if a.useInterleaving {
+		extensions = append(extensions, ExtensionIData)
+	}
+	if a.useIForwardTSN {
+		extensions = append(extensions, ExtensionIForwardTSN)
+	}
+	if a.useTimestamps {
+		extensions = append(extensions, ExtensionTimestamp)
+	}
+
+	return extensions
+}
+
 // getMaxTSNOffset returns the maximum offset over the current cummulative TSN that
 // we are willing to enqueue. This ensures that we keep the bytes utilized in the receive
-// buffer within a small multiple of the user provided max receive buffer size.
-func getMaxTSNOffset(maxReceiveBufferSize uint32) uint32 {
+// buffer within a small multiple of the user provided max receive buffer size,
+// assuming chunks of avgChunkSize bytes.
+func getMaxTSNOffset(maxReceiveBufferSize, avgChunkSize uint32) uint32 {
 	// 4 is a magic number here. There is no theory behind this.
 	offset := min(max((maxReceiveBufferSize*4)/avgChunkSize, minTSNOffset), maxTSNOffset)
 
 	return offset
 }
 
-func setSupportedExtensions(init *chunkInitCommon, enableInterleaving bool) {
+func setSupportedExtensions(init *chunkInitCommon, enableInterleaving, enableTimestamps bool) {
 	// nolint:godox
 	// TODO RFC5061 https://tools.ietf.org/html/rfc6525#section-5.2
 	// An implementation supporting this (Supported Extensions Parameter)
@@ -1830,15 +3290,20 @@ func setSupportedExtensions(init *chunkInitCommon, enableInterleaving bool) {
 	if enableInterleaving {
 		chunkTypes = append(chunkTypes, ctIData, ctIForwardTSN)
 	}
+	if enableTimestamps {
+		chunkTypes = append(chunkTypes, ctTimestamp)
+	}
 	init.params = append(init.params, &paramSupportedExtensions{
 		ChunkTypes: chunkTypes,
 	})
 }
 
 type supportedExtensions struct {
+	reconfig     bool
 	forwardTSN   bool
 	interleaving bool
 	iForwardTSN  bool
+	timestamps   bool
 }
 
 func getSupportedExtensions(params []param) supportedExtensions {
@@ -1846,9 +3311,11 @@ func getSupportedExtensions(params []param) supportedExtensions {
 	for _, param := range params {
 		if supported, ok := param.(*paramSupportedExtensions); ok {
 			parsed := supportedExtensionsFromChunkTypes(supported.ChunkTypes)
+			extensions.reconfig = extensions.reconfig || parsed.reconfig
 			extensions.forwardTSN = extensions.forwardTSN || parsed.forwardTSN
 			extensions.interleaving = extensions.interleaving || parsed.interleaving
 			extensions.iForwardTSN = extensions.iForwardTSN || parsed.iForwardTSN
+			extensions.timestamps = extensions.timestamps || parsed.timestamps
 		}
 	}
 
@@ -1859,12 +3326,16 @@ func supportedExtensionsFromChunkTypes(chunkTypes []chunkType) supportedExtensio
 	var extensions supportedExtensions
 	for _, t := range chunkTypes {
 		switch t {
+		case ctReconfig:
+			extensions.reconfig = true
 		case ctForwardTSN:
 			extensions.forwardTSN = true
 		case ctIData:
 			extensions.interleaving = true
 		case ctIForwardTSN:
 			extensions.iForwardTSN = true
+		case ctTimestamp:
+			extensions.timestamps = true
 		default:
 		}
 	}
@@ -1880,12 +3351,9 @@ func (a *Association) updateInterleavingState() error {
 		}
 
 		a.useInterleaving = useInterleaving
-		if useInterleaving {
-			a.maxPayloadSize = a.mtu - (commonHeaderSize + iDataChunkHeaderSize)
-		} else {
-			a.maxPayloadSize = a.mtu - (commonHeaderSize + dataChunkHeaderSize)
-		}
 	}
+	a.useTimestamps = a.localTimestamps && a.peerTimestamps
+	a.updateMaxPayloadSize()
 
 	if useInterleaving {
 		a.useIForwardTSN = a.peerIForwardTSN && a.localInterleaving
@@ -1895,7 +3363,21 @@ func (a *Association) updateInterleavingState() error {
 		a.useForwardTSN = a.peerForwardTSN
 	}
 
-	return nil
+	return nil
+}
+
+// updateMaxPayloadSize sets the largest DATA or I-DATA payload that fits in
+// a packet together with the chunk header and, when in use, the TIMESTAMP
+// chunk. The caller should hold the lock.
+func (a *Association) updateMaxPayloadSize() {
+	overhead := commonHeaderSize + dataChunkHeaderSize
+	if a.useInterleaving {
+		overhead = commonHeaderSize + iDataChunkHeaderSize
+	}
+	if a.useTimestamps {
+		overhead += timestampChunkSize
+	}
+	atomic.StoreUint32(&a.maxPayloadSize, a.mtu-overhead)
 }
 
 func (a *Association) partialReliabilityEnabled() bool {
@@ -1913,6 +3395,23 @@ func (a *Association) abortProtocolViolation(reason string) {
 	a.awakeWriteLoop()
 }
 
+// abortUnresolvableAddress aborts the association because the peer sent a
+// Host Name Address parameter in its INIT or INIT ACK.
+// https://www.rfc-editor.org/rfc/rfc9260#section-3.3.2.1
+//
+//	The receiver of an INIT chunk or an INIT ACK containing a Host Name
+//	Address parameter MUST send an ABORT chunk and MAY include an
+//	"Unresolvable Address" error cause.
+//
+// The caller should hold the lock.
+func (a *Association) abortUnresolvableAddress(hostName *paramHeader) {
+	a.log.Warnf("[%s] peer sent a Host Name Address", a.name)
+	address, _ := hostName.marshal()
+	a.willSendAbort = true
+	a.willSendAbortCause = &errorCauseHeader{code: unresolvableAddress, raw: address}
+	a.awakeWriteLoop()
+}
+
 // The caller should hold the lock.
 //
 //nolint:cyclop
@@ -1937,12 +3436,20 @@ func (a *Association) handleInit(pkt *packet, initChunk *chunkInit) ([]*packet,
 	// our cookie is not compliant with https://www.rfc-editor.org/rfc/rfc9260#section-5.1-2.2.3.
 	// It makes us more vulnerable to resource attacks, albeit minimally so.
 	//  https://www.rfc-editor.org/rfc/rfc9260#sec_handle_stream_parameters
-	a.myMaxNumInboundStreams = min16(initChunk.numInboundStreams, a.myMaxNumInboundStreams)
-	a.myMaxNumOutboundStreams = min16(initChunk.numOutboundStreams, a.myMaxNumOutboundStreams)
+	// The peer sends on our inbound streams and receives on our outbound
+	// ones.
+	a.myMaxNumInboundStreams = min16(initChunk.numOutboundStreams, a.myMaxNumInboundStreams)
+	a.myMaxNumOutboundStreams = min16(initChunk.numInboundStreams, a.myMaxNumOutboundStreams)
 	a.peerVerificationTag = initChunk.initiateTag
 	a.sourcePort = pkt.destinationPort
 	a.destinationPort = pkt.sourcePort
 
+	if hostName := initChunk.hostNameAddress(); hostName != nil {
+		a.abortUnresolvableAddress(hostName)
+
+		return nil, nil
+	}
+
 	// 13.2 This is the last TSN received in sequence.  This value
 	// is set initially by taking the peer's initial TSN,
 	// received in the INIT or INIT ACK chunk, and
@@ -1954,17 +3461,41 @@ func (a *Association) handleInit(pkt *packet, initChunk *chunkInit) ([]*packet,
 
 	a.peerInterleaving = false
 	a.peerForwardTSN = false
+	a.peerReconfig = false
 	a.peerIForwardTSN = false
+	a.peerTimestamps = false
+	a.useECN = false
+	a.cookieLifeSpanIncrement = 0
+	a.peerAddresses = nil
+	a.peerAddressTypes = nil
 
 	for _, param := range initChunk.params {
 		switch val := param.(type) { // nolint:gocritic
+		case *paramIPAddress:
+			a.peerAddresses = append(a.peerAddresses, val.address)
+		case *paramSupportedAddressTypes:
+			// https://www.rfc-editor.org/rfc/rfc9260#section-5.1.2
+			// The INIT ACK must only list addresses of these types. It
+			// lists none, as the association is bound to its net.Conn.
+			a.peerAddressTypes = append(a.peerAddressTypes, val.addressTypes...)
+		case *paramCookiePreservative:
+			// https://www.rfc-editor.org/rfc/rfc9260#section-5.1.3
+			// The receiver MAY ignore the suggested increment for its own
+			// security reasons: it is capped so that the lifetime at most
+			// doubles.
+			increment := time.Duration(val.lifeSpanIncrement) * time.Millisecond
+			a.cookieLifeSpanIncrement = min(increment, a.cookieLifetime)
 		case *paramSupportedExtensions:
 			extensions := supportedExtensionsFromChunkTypes(val.ChunkTypes)
 			a.peerForwardTSN = a.peerForwardTSN || extensions.forwardTSN
+			a.peerReconfig = a.peerReconfig || extensions.reconfig
 			a.peerInterleaving = a.peerInterleaving || extensions.interleaving
 			a.peerIForwardTSN = a.peerIForwardTSN || extensions.iForwardTSN
+			a.peerTimestamps = a.peerTimestamps || extensions.timestamps
 		case *paramZeroChecksumAcceptable:
-			a.sendZeroChecksum = val.edmid == dtlsErrorDetectionMethod
+			a.sendZeroChecksum = val.edmid == a.zeroChecksumEDMID
+		case *paramECNCapable:
+			a.useECN = a.localECN
 		}
 	}
 
@@ -1994,25 +3525,38 @@ func (a *Association) handleInit(pkt *packet, initChunk *chunkInit) ([]*packet,
 	initAck.numOutboundStreams = a.myMaxNumOutboundStreams
 	initAck.numInboundStreams = a.myMaxNumInboundStreams
 	initAck.initiateTag = a.myVerificationTag
-	initAck.advertisedReceiverWindowCredit = a.maxReceiveBufferSize
+	initAck.advertisedReceiverWindowCredit = a.advertisedRWND
 
-	if a.myCookie == nil {
-		var err error
-		// NOTE: This generation process is not compliant with
-		// 5.1.3.  Generating State Cookie (https://www.rfc-editor.org/rfc/rfc4960#section-5.1.3)
-		if a.myCookie, err = newRandomStateCookie(); err != nil {
-			return nil, err
-		}
+	cookie, err := a.newStateCookie()
+	if err != nil {
+		return nil, err
 	}
+	a.myCookie = cookie
 
 	initAck.params = []param{a.myCookie}
 
 	if a.recvZeroChecksum {
-		initAck.params = append(initAck.params, &paramZeroChecksumAcceptable{edmid: dtlsErrorDetectionMethod})
+		initAck.params = append(initAck.params, &paramZeroChecksumAcceptable{edmid: a.zeroChecksumEDMID})
+	}
+	if a.localECN {
+		initAck.params = append(initAck.params, &paramECNCapable{})
 	}
 	a.log.Debugf("[%s] sendZeroChecksum=%t (on init)", a.name, a.sendZeroChecksum)
 
-	setSupportedExtensions(&initAck.chunkInitCommon, a.localInterleaving)
+	// https://www.rfc-editor.org/rfc/rfc9260#section-3.2.2
+	//   If the receiver of an INIT chunk detects unrecognized parameters
+	//   and has to report them according to Section 3.2.1, it MUST put
+	//   the "Unrecognized Parameter" parameter(s) in the INIT ACK chunk
+	//   sent in response to the INIT chunk.
+	for _, p := range initChunk.reportedUnrecognizedParams() {
+		unrecognized, err := p.marshal()
+		if err != nil {
+			return nil, err
+		}
+		initAck.params = append(initAck.params, &paramUnrecognized{unrecognizedParam: unrecognized})
+	}
+
+	setSupportedExtensions(&initAck.chunkInitCommon, a.localInterleaving, a.localTimestamps)
 
 	outbound.chunks = []chunk{initAck}
 
@@ -2033,8 +3577,8 @@ func (a *Association) handleInitAck(pkt *packet, initChunkAck *chunkInitAck) err
 		return nil
 	}
 
-	a.myMaxNumInboundStreams = min16(initChunkAck.numInboundStreams, a.myMaxNumInboundStreams)
-	a.myMaxNumOutboundStreams = min16(initChunkAck.numOutboundStreams, a.myMaxNumOutboundStreams)
+	a.myMaxNumInboundStreams = min16(initChunkAck.numOutboundStreams, a.myMaxNumInboundStreams)
+	a.myMaxNumOutboundStreams = min16(initChunkAck.numInboundStreams, a.myMaxNumOutboundStreams)
 	a.peerVerificationTag = initChunkAck.initiateTag
 	a.payloadQueue.init(initChunkAck.initialTSN - 1)
 	if a.sourcePort != pkt.destinationPort ||
@@ -2044,6 +3588,12 @@ func (a *Association) handleInitAck(pkt *packet, initChunkAck *chunkInitAck) err
 		return nil
 	}
 
+	if hostName := initChunkAck.hostNameAddress(); hostName != nil {
+		a.abortUnresolvableAddress(hostName)
+
+		return nil
+	}
+
 	a.setRWND(initChunkAck.advertisedReceiverWindowCredit)
 	a.log.Debugf("[%s] initial rwnd=%d", a.name, a.RWND())
 
@@ -2060,20 +3610,31 @@ func (a *Association) handleInitAck(pkt *packet, initChunkAck *chunkInitAck) err
 
 	a.peerInterleaving = false
 	a.peerForwardTSN = false
+	a.peerReconfig = false
 	a.peerIForwardTSN = false
+	a.peerTimestamps = false
+	a.useECN = false
+
+	a.peerAddresses = nil
 
 	var cookieParam *paramStateCookie
 	for _, param := range initChunkAck.params {
 		switch val := param.(type) {
+		case *paramIPAddress:
+			a.peerAddresses = append(a.peerAddresses, val.address)
 		case *paramStateCookie:
 			cookieParam = val
 		case *paramSupportedExtensions:
 			extensions := supportedExtensionsFromChunkTypes(val.ChunkTypes)
 			a.peerForwardTSN = a.peerForwardTSN || extensions.forwardTSN
+			a.peerReconfig = a.peerReconfig || extensions.reconfig
 			a.peerInterleaving = a.peerInterleaving || extensions.interleaving
 			a.peerIForwardTSN = a.peerIForwardTSN || extensions.iForwardTSN
+			a.peerTimestamps = a.peerTimestamps || extensions.timestamps
 		case *paramZeroChecksumAcceptable:
-			a.sendZeroChecksum = val.edmid == dtlsErrorDetectionMethod
+			a.sendZeroChecksum = val.edmid == a.zeroChecksumEDMID
+		case *paramECNCapable:
+			a.useECN = a.localECN
 		}
 	}
 
@@ -2099,7 +3660,27 @@ func (a *Association) handleInitAck(pkt *packet, initChunkAck *chunkInitAck) err
 	a.storedCookieEcho = &chunkCookieEcho{}
 	a.storedCookieEcho.cookie = cookieParam.cookie
 
-	err := a.sendCookieEcho()
+	// https://www.rfc-editor.org/rfc/rfc9260#section-3.2.2
+	//   If the receiver of an INIT ACK chunk detects unrecognized
+	//   parameters and has to report them according to Section 3.2.1, it
+	//   SHOULD bundle the ERROR chunk containing the "Unrecognized
+	//   Parameters" error cause with the COOKIE ECHO chunk sent in
+	//   response to the INIT ACK chunk.
+	var bundled []chunk
+	if reported := initChunkAck.reportedUnrecognizedParams(); len(reported) > 0 {
+		cause := &errorCauseUnrecognizedParameters{}
+		for _, p := range reported {
+			unrecognized, err := p.marshal()
+			if err != nil {
+				return err
+			}
+			cause.unrecognizedParams = append(cause.unrecognizedParams, unrecognized...)
+			cause.unrecognizedParams = padByte(cause.unrecognizedParams, getPadding(len(unrecognized)))
+		}
+		bundled = append(bundled, &chunkError{errorCauses: []errorCause{cause}})
+	}
+
+	err := a.sendCookieEcho(bundled...)
 	if err != nil {
 		a.log.Errorf("[%s] failed to send init: %s", a.name, err.Error())
 	}
@@ -2156,31 +3737,49 @@ func (a *Association) handleHeartbeatAck(c *chunkHeartbeatAck) {
 		return
 	}
 
-	// active RTT probe: if heartbeatInformation is exactly 8 bytes, treat it
-	// as a big-endian unix nano timestamp.
-	if len(info.heartbeatInformation) == 8 {
-		ns := binary.BigEndian.Uint64(info.heartbeatInformation)
-		if ns > math.MaxInt64 {
-			// Malformed or future-unsafe value; ignore this heartbeat-ack.
-			a.log.Warnf("[%s] HB RTT: timestamp overflows int64, ignoring", a.name)
+	// active RTT probe: heartbeatInformation is a big-endian unix nano
+	// timestamp followed by the nonce of an idle HEARTBEAT or a Ping().
+	if len(info.heartbeatInformation) != heartbeatInfoPingSize {
+		return
+	}
 
-			return
-		}
+	// https://www.rfc-editor.org/rfc/rfc9260#section-8.3
+	// The nonce is verified before the timestamp is trusted to measure the
+	// RTT. The error counter is cleared for any packet, see
+	// handleChunksEnd.
+	nonce := binary.BigEndian.Uint64(info.heartbeatInformation[heartbeatInfoTimestampSize:])
+	if ackCh, ok := a.pings[nonce]; ok {
+		delete(a.pings, nonce)
+		close(ackCh)
+	} else if nonce != 0 && nonce == a.heartbeatNonce {
+		a.heartbeatNonce = 0
+	} else {
+		a.log.Debugf("[%s] HeartbeatAck with unknown nonce", a.name)
 
-		sentNanos := int64(ns)
-		sent := time.Unix(0, sentNanos)
-		now := time.Now()
+		return
+	}
+
+	ns := binary.BigEndian.Uint64(info.heartbeatInformation)
+	if ns > math.MaxInt64 {
+		// Malformed or future-unsafe value; ignore this heartbeat-ack.
+		a.log.Warnf("[%s] HB RTT: timestamp overflows int64, ignoring", a.name)
+
+		return
+	}
 
-		if !sent.IsZero() && !now.Before(sent) {
-			rttMs := now.Sub(sent).Seconds() * 1000.0
-			srtt := a.rtoMgr.setNewRTT(rttMs)
-			a.srtt.Store(srtt)
+	sentNanos := int64(ns)
+	sent := time.Unix(0, sentNanos)
+	now := time.Now()
 
-			a.rack.rackMinRTTWnd.Push(now, now.Sub(sent))
+	if !sent.IsZero() && !now.Before(sent) {
+		rttMs := now.Sub(sent).Seconds() * 1000.0
+		srtt := a.rtoMgr.setNewRTT(rttMs)
+		a.srtt.Store(srtt)
 
-			a.log.Tracef("[%s] HB RTT: measured=%.3fms srtt=%.3fms rto=%.3fms",
-				a.name, rttMs, srtt, a.rtoMgr.getRTO())
-		}
+		a.rack.rackMinRTTWnd.Push(now, now.Sub(sent))
+
+		a.log.Tracef("[%s] HB RTT: measured=%.3fms srtt=%.3fms rto=%.3fms",
+			a.name, rttMs, srtt, a.rtoMgr.getRTO())
 	}
 }
 
@@ -2198,14 +3797,27 @@ func (a *Association) handleCookieEcho(cookieEcho *chunkCookieEcho) []*packet {
 	default:
 		return nil
 	case established:
-		if !bytes.Equal(a.myCookie.cookie, cookieEcho.cookie) {
+		if _, _, ok := a.validCookie(cookieEcho.cookie); !ok {
 			return nil
 		}
 	case closed, cookieWait, cookieEchoed:
-		if !bytes.Equal(a.myCookie.cookie, cookieEcho.cookie) {
+		created, increment, ok := a.validCookie(cookieEcho.cookie)
+		if !ok {
 			return nil
 		}
 
+		// https://www.rfc-editor.org/rfc/rfc9260#section-5.2.4
+		// The lifetime is only checked for a new association: the cookie of
+		// an existing one matches its Verification Tags.
+		if state == closed {
+			lifetime := a.cookieLifetime + increment
+			if staleness := time.Since(created) - lifetime; staleness > 0 {
+				a.log.Debugf("[%s] COOKIE-ECHO with a cookie stale by %v", a.name, staleness)
+
+				return a.staleCookieError(staleness)
+			}
+		}
+
 		// RFC wise, these do not seem to belong here, but removing them
 		// causes TestCookieEchoRetransmission to break
 		a.t1Init.stop()
@@ -2274,15 +3886,24 @@ func (a *Association) handleData(chunkPayload *chunkPayloadData) []*packet {
 		return nil
 	}
 
-	a.log.Tracef("[%s] DATA: tsn=%d immediateSack=%v len=%d",
-		a.name, chunkPayload.tsn, chunkPayload.immediateSack, len(chunkPayload.userData))
+	if a.onTrace != nil {
+		a.onTrace(newTraceEvent(TraceEventReceive, chunkPayload))
+	} else {
+		a.log.Tracef("[%s] DATA: tsn=%d immediateSack=%v len=%d",
+			a.name, chunkPayload.tsn, chunkPayload.immediateSack, len(chunkPayload.userData))
+	}
 	a.stats.incDATAs()
 
 	canPush := a.payloadQueue.canPush(chunkPayload.tsn)
+	duplicate := false
 	if canPush {
 		if !a.acceptPayloadData(chunkPayload) {
 			return nil
 		}
+	} else if a.payloadQueue.isDuplicate(chunkPayload.tsn) {
+		// push records it for the Duplicate TSNs of the next SACK.
+		a.payloadQueue.push(chunkPayload.tsn)
+		duplicate = true
 	}
 
 	// Upon the reception of a new DATA chunk, an endpoint shall examine the
@@ -2294,7 +3915,9 @@ func (a *Association) handleData(chunkPayload *chunkPayloadData) []*packet {
 	expectedTSN := a.peerLastTSN() + 1
 	gapDetected := sna32GT(chunkPayload.tsn, expectedTSN)
 
-	sackNow := chunkPayload.immediateSack || gapDetected
+	// RFC 9260 sec 6.2: a SACK is sent immediately on a duplicate DATA
+	// chunk, so that the peer learns about the spurious retransmission.
+	sackNow := chunkPayload.immediateSack || gapDetected || duplicate
 
 	return a.handlePeerLastTSNAndAcknowledgement(sackNow)
 }
@@ -2302,6 +3925,11 @@ func (a *Association) handleData(chunkPayload *chunkPayloadData) []*packet {
 // The caller should hold the lock.
 func (a *Association) acceptPayloadData(chunkPayload *chunkPayloadData) bool {
 	stream := a.getOrCreateStream(chunkPayload.streamIdentifier, true, PayloadTypeUnknown)
+	if stream == nil && a.streamLimitReached() {
+		a.refuseStreamData(chunkPayload)
+
+		return true
+	}
 	if stream == nil {
 		// silently discard the data. (sender will retry on T3-rtx timeout)
 		// see pion/sctp#30
@@ -2322,6 +3950,8 @@ func (a *Association) acceptPayloadData(chunkPayload *chunkPayloadData) bool {
 			"[%s] receive buffer full. dropping DATA with tsn=%d ssn=%d",
 			a.name, chunkPayload.tsn, chunkPayload.streamSequenceNumber,
 		)
+		a.stats.incReceiveBufferFullDrops()
+		a.reportReceiveBufferFull(chunkPayload.streamIdentifier)
 
 		return true
 	}
@@ -2342,10 +3972,51 @@ func (a *Association) pushPayloadDataToStream(stream *Stream, chunkPayload *chun
 
 		return false
 	}
+	a.stats.addGoodputBytesReceived(len(chunkPayload.userData))
+	a.autotuneReceiveBuffer(len(chunkPayload.userData))
+	if a.reassemblyTimeout > 0 && !(chunkPayload.beginningFragment && chunkPayload.endingFragment) {
+		a.startReassemblyTimer()
+	}
 
 	return true
 }
 
+// autotuneReceiveBuffer accounts n received bytes and, once per round trip,
+// grows the receive buffer to twice the bytes received during that round,
+// up to recvAutotuneMax. This mirrors TCP receive window autotuning: the
+// buffer never shrinks and only grows when the sender actually fills it.
+// The caller should hold the lock.
+func (a *Association) autotuneReceiveBuffer(n int) {
+	if a.maxReceiveBufferSize >= a.recvAutotuneMax {
+		return
+	}
+
+	now := time.Now()
+	if a.recvAutotuneStart.IsZero() {
+		a.recvAutotuneStart = now
+	}
+	a.recvAutotuneBytes += uint64(n) //nolint:gosec // G115
+
+	rtt := a.SRTT()
+	if rtt <= 0 {
+		rtt = recvAutotuneDefaultRTT
+	}
+	if now.Sub(a.recvAutotuneStart) < time.Duration(rtt*float64(time.Millisecond)) {
+		return
+	}
+
+	target := min(2*a.recvAutotuneBytes, uint64(a.recvAutotuneMax))
+	a.recvAutotuneStart = now
+	a.recvAutotuneBytes = 0
+	if target <= uint64(a.maxReceiveBufferSize) {
+		return
+	}
+
+	a.log.Debugf("[%s] receive buffer autotuned: %d -> %d", a.name, a.maxReceiveBufferSize, target)
+	a.maxReceiveBufferSize = uint32(target) //nolint:gosec // G115
+	a.payloadQueue.grow(getMaxTSNOffset(a.maxReceiveBufferSize, a.avgChunkSize))
+}
+
 // A common routine for handleData and handleForwardTSN routines
 // The caller should hold the lock.
 func (a *Association) handlePeerLastTSNAndAcknowledgement(sackImmediately bool) []*packet { //nolint:cyclop
@@ -2378,14 +4049,19 @@ func (a *Association) handlePeerLastTSNAndAcknowledgement(sackImmediately bool)
 	}
 
 	// RFC 4960 $6.7: SHOULD ack immediately when detecting a gap.
-	if sackImmediately || hasPacketLoss || a.ackMode == ackModeNoDelay {
+	if sackImmediately || hasPacketLoss || a.ackMode == ackModeNoDelay || a.sackPolicy == SackPolicyImmediate {
 		a.immediateAckTriggered = true
 
 		return reply
 	}
 
 	if a.ackMode == ackModeAlwaysDelay || (a.ackMode == ackModeNormal && a.ackState != ackStateImmediate) {
+		// Delay the SACK until sackFreq packets carrying DATA have arrived
+		// or the ack timer expires, whichever comes first.
 		if a.ackState == ackStateIdle {
+			a.delayedAckPackets = 0
+		}
+		if a.delayedAckPackets+1 < a.sackFreq {
 			a.delayedAckTriggered = true
 		} else {
 			a.immediateAckTriggered = true
@@ -2413,7 +4089,12 @@ func (a *Association) getMyReceiverWindowCredit() uint32 {
 	return a.maxReceiveBufferSize - bytesQueued
 }
 
-// OpenStream opens a stream.
+// OpenStream opens a stream. It returns ErrStreamAlreadyExists if a stream
+// with the same identifier is already registered, including one created by
+// data from the peer; use GetStream to get that stream. Once the association
+// is established, it
+// returns ErrStreamIDOutOfRange if the identifier is not below the number of
+// outbound streams negotiated with the peer.
 func (a *Association) OpenStream(
 	streamIdentifier uint16,
 	defaultPayloadType PayloadProtocolIdentifier,
@@ -2424,11 +4105,49 @@ func (a *Association) OpenStream(
 	switch a.getState() {
 	case shutdownAckSent, shutdownPending, shutdownReceived, shutdownSent, closed:
 		return nil, ErrAssociationClosed
+	case established:
+		if streamIdentifier >= a.myMaxNumOutboundStreams {
+			return nil, fmt.Errorf("%w: %d >= %d", ErrStreamIDOutOfRange, streamIdentifier, a.myMaxNumOutboundStreams)
+		}
+	}
+
+	if _, ok := a.streams[streamIdentifier]; ok {
+		return nil, fmt.Errorf("%w: %d", ErrStreamAlreadyExists, streamIdentifier)
 	}
 
 	return a.getOrCreateStream(streamIdentifier, false, defaultPayloadType), nil
 }
 
+// GetStream returns the stream registered with the given identifier, if any.
+// Unlike OpenStream it never creates a stream.
+func (a *Association) GetStream(streamIdentifier uint16) (*Stream, bool) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	s, ok := a.streams[streamIdentifier]
+
+	return s, ok
+}
+
+// Streams returns a snapshot of the streams currently registered on the
+// association, ordered by stream identifier. A stream whose outgoing side is
+// being reset reports StreamStateClosing from State. A stream is removed once
+// the peer resets it or the association is closed.
+func (a *Association) Streams() []*Stream {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	streams := make([]*Stream, 0, len(a.streams))
+	for _, s := range a.streams {
+		streams = append(streams, s)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].streamIdentifier < streams[j].streamIdentifier
+	})
+
+	return streams
+}
+
 // AcceptStream accepts a stream.
 func (a *Association) AcceptStream() (*Stream, error) {
 	s, ok := <-a.acceptCh
@@ -2442,20 +4161,31 @@ func (a *Association) AcceptStream() (*Stream, error) {
 // createStream creates a stream. The caller should hold the lock and check no stream exists for this id.
 func (a *Association) createStream(streamIdentifier uint16, accept bool) *Stream {
 	stream := &Stream{
-		association:      a,
-		streamIdentifier: streamIdentifier,
-		reassemblyQueue:  newReassemblyQueue(streamIdentifier),
-		log:              a.log,
-		name:             fmt.Sprintf("%d:%s", streamIdentifier, a.name),
-		writeDeadline:    deadline.New(),
+		association:       a,
+		streamIdentifier:  streamIdentifier,
+		reassemblyQueue:   newReassemblyQueue(streamIdentifier),
+		reassemblyTimeout: a.reassemblyTimeout,
+		log:               a.log,
+		name:              fmt.Sprintf("%d:%s", streamIdentifier, a.name),
+		writeDeadline:     deadline.New(),
+
+		bufferedAmountHigh: defaultBufferedAmountHighThreshold,
 	}
 
 	stream.readNotifier = sync.NewCond(&stream.lock)
 
 	if accept {
+		if a.streamLimitReached() {
+			a.log.Debugf("[%s] refused a new stream (streamIdentifier: %d, open streams: %d)",
+				a.name, streamIdentifier, len(a.streams))
+
+			return nil
+		}
+
 		select {
 		case a.acceptCh <- stream:
 			a.streams[streamIdentifier] = stream
+			a.streamStats.Store(streamIdentifier, &stream.stats)
 			a.log.Debugf("[%s] accepted a new stream (streamIdentifier: %d)",
 				a.name, streamIdentifier)
 		default:
@@ -2466,11 +4196,37 @@ func (a *Association) createStream(streamIdentifier uint16, accept bool) *Stream
 		}
 	} else {
 		a.streams[streamIdentifier] = stream
+		a.streamStats.Store(streamIdentifier, &stream.stats)
 	}
 
 	return stream
 }
 
+// streamLimitReached reports whether MaxStreams streams are open.
+// The caller should hold the lock.
+func (a *Association) streamLimitReached() bool {
+	return a.maxStreams != 0 && len(a.streams) >= int(a.maxStreams)
+}
+
+// refuseStreamData acknowledges and discards DATA of a stream refused
+// because of MaxStreams, and tells the peer that the stream identifier is
+// invalid (RFC 9260 section 6.8), once per message.
+// The caller should hold the lock.
+func (a *Association) refuseStreamData(chunkPayload *chunkPayloadData) {
+	a.payloadQueue.push(chunkPayload.tsn)
+	a.stats.incStreamLimitDrops()
+	if !chunkPayload.beginningFragment {
+		return
+	}
+
+	sid := make([]byte, 4)
+	binary.BigEndian.PutUint16(sid, chunkPayload.streamIdentifier)
+	a.controlQueue.push(a.createPacket([]chunk{&chunkError{
+		errorCauses: []errorCause{&errorCauseHeader{code: invalidStreamIdentifier, raw: sid}},
+	}}))
+	a.awakeWriteLoop()
+}
+
 // getOrCreateStream gets or creates a stream. The caller should hold the lock.
 func (a *Association) getOrCreateStream(
 	streamIdentifier uint16,
@@ -2505,6 +4261,9 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 	bytesAckedPerStream = map[uint16]int{}
 	now := time.Now() // capture the time for this SACK
 
+	// the newest original transmission acked, for InstantRTT
+	var rttSample *chunkPayloadData
+
 	// New ack point, so pop all ACKed packets from inflightQueue
 	// We add 1 because the "currentAckPoint" has already been popped from the inflight queue
 	// For the first SACK we take care of this by setting the ackpoint to cumAck - 1
@@ -2517,6 +4276,20 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 		// RACK: remove from xmit-time list since it's delivered
 		a.rackRemove(chunkPayload)
 
+		// Each TSN leaves the inflight queue exactly once, so counting here
+		// excludes retransmissions. Abandoned chunks may never have arrived.
+		if !chunkPayload.abandoned() {
+			a.stats.addGoodputBytesSent(len(chunkPayload.userData))
+		}
+
+		if chunkPayload.ackNotify != nil {
+			if chunkPayload.abandoned() {
+				a.notifyAck(chunkPayload, ErrMessageAbandoned)
+			} else {
+				a.notifyAck(chunkPayload, nil)
+			}
+		}
+
 		if !chunkPayload.acked { //nolint:nestif
 			// RFC 4960 sec 6.3.2.  Retransmission Timer Rules
 			//   R3)  Whenever a SACK is received that acknowledges the DATA chunk
@@ -2530,12 +4303,21 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 
 			nBytesAcked := len(chunkPayload.userData)
 
-			// Sum the number of bytes acknowledged per stream
+			// Sum the number of bytes acknowledged per stream. Reneged chunks
+			// were already reported when they were first gap-acked.
+			if chunkPayload.reneged {
+				nBytesAcked = 0
+			}
 			if amount, ok := bytesAckedPerStream[chunkPayload.streamIdentifier]; ok {
 				bytesAckedPerStream[chunkPayload.streamIdentifier] = amount + nBytesAcked
 			} else {
 				bytesAckedPerStream[chunkPayload.streamIdentifier] = nBytesAcked
 			}
+			a.recordInflightDwell(chunkPayload, now)
+
+			if a.onTrace != nil {
+				a.onTrace(newTraceEvent(TraceEventSacked, chunkPayload))
+			}
 
 			// RFC 4960 sec 6.3.1.  RTO Calculation
 			//   C4)  When data is in flight and when allowed by rule C5 below, a new
@@ -2546,7 +4328,10 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 			//        packets that were retransmitted (and thus for which it is
 			//        ambiguous whether the reply was for the first instance of the
 			//        chunk or for a later instance)
-			if sna32GTE(chunkPayload.tsn, a.minTSN2MeasureRTT) {
+			//
+			// With timestamps in use the RTT is measured from the echoed
+			// timestamp instead, see sampleTimestampRTT.
+			if !a.useTimestamps && sna32GTE(chunkPayload.tsn, a.minTSN2MeasureRTT) {
 				// Only original transmissions for classic RTT measurement (Karn's rule)
 				if chunkPayload.nSent == 1 {
 					a.minTSN2MeasureRTT = a.myNextTSN
@@ -2564,6 +4349,8 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 				}
 			}
 
+			rttSample = a.instantRTTSample(rttSample, chunkPayload)
+
 			// RFC 8985 (RACK) sec 5.2: RACK.segment is the most recently sent
 			// segment that has been delivered, including retransmissions.
 			if chunkPayload.since.After(newestDeliveredSendTime) {
@@ -2587,7 +4374,9 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 			tsn := selectiveAckChunk.cumulativeTSNAck + i
 			chunkPayload, ok := a.inflightQueue.get(tsn)
 			if !ok {
-				return nil, 0, time.Time{}, 0, false, fmt.Errorf("%w: %v", ErrTSNRequestNotExist, tsn)
+				a.log.Debugf("[%s] SACK: ignoring gap ack of unknown tsn=%d", a.name, tsn)
+
+				continue
 			}
 
 			// RACK: remove from xmit-time list since it's delivered
@@ -2595,6 +4384,9 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 
 			if !chunkPayload.acked { //nolint:nestif
 				nBytesAcked := a.inflightQueue.markAsAcked(tsn)
+				if chunkPayload.reneged {
+					nBytesAcked = 0
+				}
 
 				// Sum the number of bytes acknowledged per stream
 				if amount, ok := bytesAckedPerStream[chunkPayload.streamIdentifier]; ok {
@@ -2602,11 +4394,16 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 				} else {
 					bytesAckedPerStream[chunkPayload.streamIdentifier] = nBytesAcked
 				}
+				a.recordInflightDwell(chunkPayload, now)
 
-				a.log.Tracef("[%s] tsn=%d has been sacked", a.name, chunkPayload.tsn)
+				if a.onTrace != nil {
+					a.onTrace(newTraceEvent(TraceEventSacked, chunkPayload))
+				} else {
+					a.log.Tracef("[%s] tsn=%d has been sacked", a.name, chunkPayload.tsn)
+				}
 
 				// RTT / RTO and RACK updates
-				if sna32GTE(chunkPayload.tsn, a.minTSN2MeasureRTT) {
+				if !a.useTimestamps && sna32GTE(chunkPayload.tsn, a.minTSN2MeasureRTT) {
 					// Only original transmissions for classic RTT measurement
 					if chunkPayload.nSent == 1 {
 						a.minTSN2MeasureRTT = a.myNextTSN
@@ -2621,6 +4418,8 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 					}
 				}
 
+				rttSample = a.instantRTTSample(rttSample, chunkPayload)
+
 				if chunkPayload.since.After(newestDeliveredSendTime) {
 					newestDeliveredSendTime = chunkPayload.since
 					newestDeliveredOrigTSN = chunkPayload.tsn
@@ -2634,9 +4433,62 @@ func (a *Association) processSelectiveAck(selectiveAckChunk *chunkSelectiveAck)
 		}
 	}
 
+	if rttSample != nil {
+		a.updateInstantRTT(now.Sub(rttSample.since))
+	}
+
+	a.processRenegedChunks(selectiveAckChunk)
+
 	return bytesAckedPerStream, htna, newestDeliveredSendTime, newestDeliveredOrigTSN, deliveredFound, nil
 }
 
+// instantRTTSample returns the newer of sample and the newly acked chunk
+// to measure InstantRTT from. Retransmitted chunks are never used, as in
+// Karn's algorithm.
+func (a *Association) instantRTTSample(sample, acked *chunkPayloadData) *chunkPayloadData {
+	if !a.instantRTTEnabled || acked.nSent != 1 {
+		return sample
+	}
+	if sample == nil || acked.since.After(sample.since) {
+		return acked
+	}
+
+	return sample
+}
+
+// processRenegedChunks detects chunks that were gap-acked by an earlier SACK
+// but are no longer reported by this one, meaning the peer has dropped them
+// from its receive buffer, which RFC 9260 section 6.2 allows. Such chunks
+// are outstanding again, and are retransmitted by fast retransmit or on
+// T3-rtx expiry.
+//
+// An older SACK delivered out of order, with the same cumulative TSN ack,
+// also reports fewer TSNs. As the TSNs the peer has received only grow
+// unless it reneges, a SACK covering fewer TSNs than the last one is not
+// trusted: reneging is only inferred once the next SACK confirms it.
+//
+// The caller should hold the lock.
+func (a *Association) processRenegedChunks(selectiveAckChunk *chunkSelectiveAck) {
+	cumTSNAck := selectiveAckChunk.cumulativeTSNAck
+	coverage := cumTSNAck
+	for _, g := range selectiveAckChunk.gapAckBlocks {
+		coverage += uint32(g.end-g.start) + 1
+	}
+	lastCoverage := a.lastSackCoverage
+	a.lastSackCoverage = coverage
+	if sna32LT(coverage, lastCoverage) {
+		a.log.Tracef("[%s] SACK covers fewer TSNs than the last one, not checking for reneging", a.name)
+
+		return
+	}
+
+	reneged := a.inflightQueue.markAsReneged(cumTSNAck, selectiveAckChunk.gapAckBlocks)
+	for _, c := range reneged {
+		a.stats.incReneged()
+		a.log.Debugf("[%s] tsn=%d has been reneged by the peer", a.name, c.tsn)
+	}
+}
+
 // The caller should hold the lock.
 func (a *Association) onCumulativeTSNAckPointAdvanced(totalBytesAcked int) {
 	// RFC 4960, sec 6.3.2.  Retransmission Timer Rules
@@ -2649,7 +4501,7 @@ func (a *Association) onCumulativeTSNAckPointAdvanced(totalBytesAcked int) {
 		a.stopRackTimer()
 	} else {
 		a.log.Tracef("[%s] T3-rtx timer start (pt2)", a.name)
-		a.t3RTX.start(a.rtoMgr.getRTO())
+		a.t3RTX.start(a.getT3RTXTimeout())
 	}
 
 	// Update congestion control parameters
@@ -2667,7 +4519,12 @@ func (a *Association) onCumulativeTSNAckPointAdvanced(totalBytesAcked int) {
 		//      path MTU.
 		if !a.inFastRecovery &&
 			a.pendingQueue.size() > 0 {
-			a.setCWND(a.CWND() + min32(uint32(totalBytesAcked), a.CWND())) //nolint:gosec // G115
+			increase := min32(uint32(totalBytesAcked), a.CWND()) //nolint:gosec // G115
+			if a.byteCountingLimit != 0 {
+				// RFC 3465 appropriate byte counting
+				increase = min32(increase, a.byteCountingLimit*a.MTU())
+			}
+			a.setCWND(a.CWND() + increase)
 			// a.cwnd += min32(uint32(totalBytesAcked), a.MTU()) // SCTP way (slow)
 			a.log.Tracef("[%s] updated cwnd=%d ssthresh=%d acked=%d (SS)",
 				a.name, a.CWND(), a.ssthresh, totalBytesAcked)
@@ -2737,9 +4594,9 @@ func (a *Association) processFastRetransmission( //nolint:gocognit
 			if !ok {
 				return fmt.Errorf("%w: %v", ErrTSNRequestNotExist, tsn)
 			}
-			if !c.acked && !c.abandoned() && c.missIndicator < 3 {
+			if !c.acked && !c.abandoned() && c.missIndicator < a.fastRtxThreshold {
 				c.missIndicator++
-				if c.missIndicator == 3 {
+				if c.missIndicator == a.fastRtxThreshold {
 					if a.tlrActive {
 						a.tlrApplyAdditionalLossLocked(time.Now())
 					}
@@ -2750,8 +4607,11 @@ func (a *Association) processFastRetransmission( //nolint:gocognit
 						//     last sent, according to the formula described in Section 7.2.3.
 						a.inFastRecovery = true
 						a.fastRecoverExitPoint = htna
+						cwnd, ssthresh := a.CWND(), a.ssthresh
 						a.ssthresh = max32(a.CWND()/2, 4*a.MTU())
 						a.setCWND(a.ssthresh)
+						a.warnMinCwndOverridesLoss()
+						a.queueCongestionEvent(CongestionEventFastRecovery, cwnd, ssthresh)
 						a.partialBytesAcked = 0
 						a.willRetransmitFast = true
 
@@ -2770,6 +4630,59 @@ func (a *Association) processFastRetransmission( //nolint:gocognit
 	return nil
 }
 
+// handleECNE reacts to an ECN Echo from the peer. The congestion window is
+// reduced as for a loss, at most once per window of data, and a CWR chunk is
+// always returned so that the peer stops echoing.
+// The caller should hold the lock.
+func (a *Association) handleECNE(ecne *chunkECNE) []*packet {
+	if !a.useECN {
+		a.log.Debugf("[%s] ECNE received without ECN negotiated, ignoring", a.name)
+
+		return nil
+	}
+
+	// RFC 4960 Appendix A: the sender reduces its cwnd as if a packet was
+	// lost, but not more than once per round trip (window of data).
+	if !a.inFastRecovery && sna32GT(ecne.lowestTSN, a.ecnRecoveryPoint) {
+		cwnd, ssthresh := a.CWND(), a.ssthresh
+		a.ssthresh = max32(a.CWND()/2, 4*a.MTU())
+		a.setCWND(a.ssthresh)
+		a.warnMinCwndOverridesLoss()
+		a.queueCongestionEvent(CongestionEventECN, cwnd, ssthresh)
+		a.partialBytesAcked = 0
+		a.ecnRecoveryPoint = a.myNextTSN - 1
+
+		a.log.Tracef("[%s] updated cwnd=%d ssthresh=%d inflight=%d (ECN)",
+			a.name, a.CWND(), a.ssthresh, a.inflightQueue.getNumBytes())
+	}
+
+	return pack(a.createPacket([]chunk{&chunkCWR{lowestTSN: ecne.lowestTSN}}))
+}
+
+// validGapAckBlocks returns the gap ack blocks of the SACK that are well
+// formed, sorted, non-overlapping and only cover TSNs that were sent. The
+// others are dropped, so that a misbehaving peer can neither make the
+// association fail on unknown TSNs nor skew the congestion control with
+// blocks reporting the same TSNs twice.
+// The caller should hold the lock.
+func (a *Association) validGapAckBlocks(selectiveAckChunk *chunkSelectiveAck) []gapAckBlock {
+	var valid []gapAckBlock
+	var prevEnd uint16 // the cumulative TSN ack itself is offset 0
+	for _, g := range selectiveAckChunk.gapAckBlocks {
+		if g.start <= prevEnd || g.start > g.end ||
+			sna32GTE(selectiveAckChunk.cumulativeTSNAck+uint32(g.end), a.myNextTSN) {
+			a.log.Debugf("[%s] SACK: ignoring invalid gap ack block %d-%d (cumTSN=%d nextTSN=%d)",
+				a.name, g.start, g.end, selectiveAckChunk.cumulativeTSNAck, a.myNextTSN)
+
+			continue
+		}
+		valid = append(valid, g)
+		prevEnd = g.end
+	}
+
+	return valid
+}
+
 // The caller should hold the lock.
 //
 //nolint:cyclop
@@ -2802,6 +4715,17 @@ func (a *Association) handleSack(selectiveAckChunk *chunkSelectiveAck) error {
 		return nil
 	}
 
+	// The Duplicate TSNs (RFC 9260 section 3.3.4) show the peer received a
+	// DATA chunk more than once, such as after a spurious retransmission.
+	// They are only counted for diagnostics.
+	if len(selectiveAckChunk.duplicateTSN) > 0 {
+		a.log.Tracef("[%s] SACK reports duplicate TSNs %v", a.name, selectiveAckChunk.duplicateTSN)
+		a.stats.addDuplicateTSNs(len(selectiveAckChunk.duplicateTSN))
+	}
+
+	selectiveAckChunk.gapAckBlocks = a.validGapAckBlocks(selectiveAckChunk)
+	frtoOriginalsAcked := a.frtoOriginalsAcked(selectiveAckChunk)
+
 	// Process selective ack
 	bytesAckedPerStream, htna,
 		newestDeliveredSendTime, newestDeliveredOrigTSN,
@@ -2810,6 +4734,10 @@ func (a *Association) handleSack(selectiveAckChunk *chunkSelectiveAck) error {
 		return err
 	}
 
+	if deliveredFound {
+		a.sampleTimestampRTT()
+	}
+
 	var totalBytesAcked int
 	for _, nBytesAcked := range bytesAckedPerStream {
 		totalBytesAcked += nBytesAcked
@@ -2817,15 +4745,20 @@ func (a *Association) handleSack(selectiveAckChunk *chunkSelectiveAck) error {
 
 	cumTSNAckPointAdvanced := false
 	if sna32LT(a.cumulativeTSNAckPoint, selectiveAckChunk.cumulativeTSNAck) {
-		a.log.Tracef("[%s] SACK: cumTSN advanced: %d -> %d",
-			a.name,
-			a.cumulativeTSNAckPoint,
-			selectiveAckChunk.cumulativeTSNAck)
+		if a.onTrace != nil {
+			a.onTrace(TraceEvent{Kind: TraceEventCumulativeTSNAdvanced, TSN: selectiveAckChunk.cumulativeTSNAck})
+		} else {
+			a.log.Tracef("[%s] SACK: cumTSN advanced: %d -> %d",
+				a.name,
+				a.cumulativeTSNAckPoint,
+				selectiveAckChunk.cumulativeTSNAck)
+		}
 
 		a.cumulativeTSNAckPoint = selectiveAckChunk.cumulativeTSNAck
 		cumTSNAckPointAdvanced = true
 		a.onCumulativeTSNAckPointAdvanced(totalBytesAcked)
 	}
+	a.processFRTO(cumTSNAckPointAdvanced, frtoOriginalsAcked)
 
 	for si, nBytesAcked := range bytesAckedPerStream {
 		if s, ok := a.streams[si]; ok {
@@ -2850,6 +4783,8 @@ func (a *Association) handleSack(selectiveAckChunk *chunkSelectiveAck) error {
 		a.setRWND(selectiveAckChunk.advertisedReceiverWindowCredit - bytesOutstanding)
 	}
 
+	a.checkPeerStall(selectiveAckChunk.advertisedReceiverWindowCredit == 0 && !cumTSNAckPointAdvanced)
+
 	err = a.processFastRetransmission(
 		selectiveAckChunk.cumulativeTSNAck, selectiveAckChunk.gapAckBlocks, htna, cumTSNAckPointAdvanced,
 	)
@@ -2883,6 +4818,7 @@ func (a *Association) handleSack(selectiveAckChunk *chunkSelectiveAck) error {
 	}
 
 	a.postprocessSack(state, cumTSNAckPointAdvanced)
+	a.notifyFlushedLocked()
 
 	// RACK
 	a.onRackAfterSACK(deliveredFound, newestDeliveredSendTime, newestDeliveredOrigTSN, selectiveAckChunk)
@@ -2901,7 +4837,10 @@ func (a *Association) postprocessSack(state uint32, shouldAwakeWriteLoop bool) {
 	case a.inflightQueue.size() > 0:
 		// Start timer. (noop if already started)
 		a.log.Tracef("[%s] T3-rtx timer start (pt3)", a.name)
-		a.t3RTX.start(a.rtoMgr.getRTO())
+		a.t3RTX.start(a.getT3RTXTimeout())
+	case state == shutdownPending && a.pendingQueue.size() > 0:
+		// Send the remaining pending data before shutdown.
+		shouldAwakeWriteLoop = true
 	case state == shutdownPending:
 		// No more outstanding, send shutdown.
 		shouldAwakeWriteLoop = true
@@ -2966,15 +4905,93 @@ func (a *Association) handleShutdownComplete(_ *chunkShutdownComplete) error {
 	return nil
 }
 
-func (a *Association) handleAbort(c *chunkAbort) error {
+// handleUnknownChunk reports an unrecognized chunk to the peer in an ERROR
+// chunk when its type asks for it. The caller should hold the lock.
+func (a *Association) handleUnknownChunk(c *chunkUnknown) []*packet {
+	a.log.Debugf("[%s] unrecognized chunk: %s", a.name, c)
+	if !c.report() {
+		return nil
+	}
+
+	raw, err := c.marshal()
+	if err != nil {
+		return nil
+	}
+	// Keep the ERROR chunk within the MTU.
+	maxLen := int(a.MTU() - commonHeaderSize - chunkHeaderSize - errorCauseHeaderLength)
+	if len(raw) > maxLen {
+		raw = raw[:maxLen]
+	}
+
+	return pack(a.createPacket([]chunk{&chunkError{
+		errorCauses: []errorCause{&errorCauseUnrecognizedChunkType{unrecognizedChunk: raw}},
+	}}))
+}
+
+// The caller should hold the lock.
+func (a *Association) handleError(c *chunkError) {
 	var errStr strings.Builder
 	for _, e := range c.errorCauses {
 		fmt.Fprintf(&errStr, "(%s)", e)
 	}
+	a.log.Debugf("[%s] Error chunk, with following errors: %s", a.name, errStr.String())
+
+	for _, e := range c.errorCauses {
+		if stale, ok := e.(*errorCauseStaleCookie); ok {
+			a.handleStaleCookie(stale.staleness)
+		}
+	}
+
+	a.queueProtocolError(c.errorCauses)
+}
+
+// handleStaleCookie restarts the handshake with a new INIT when the peer
+// reports that our COOKIE ECHO carried a cookie expired for staleness
+// microseconds.
+// The caller should hold the lock.
+func (a *Association) handleStaleCookie(staleness uint32) {
+	// RFC 9260 sec 5.2.6
+	//   Upon the receipt of an ERROR chunk with a Stale Cookie error cause,
+	//   the endpoint MAY either start the association initialization
+	//   process over again or abort the association. An endpoint SHOULD
+	//   only consider the stale cookie in the COOKIE-ECHOED state.
+	if a.getState() != cookieEchoed {
+		return
+	}
+	if a.staleCookieCount >= a.t1Cookie.maxRetrans {
+		a.log.Warnf("[%s] stale cookie reported too many times, waiting for T1-cookie", a.name)
+
+		return
+	}
+	a.staleCookieCount++
+
+	a.log.Debugf("[%s] stale cookie, restarting handshake", a.name)
+	a.t1Cookie.stop()
+	a.storedCookieEcho = nil
+	a.storedInit = a.createInitChunk()
+	// RFC 9260 sec 5.2.6
+	//   When calculating the time extension, an implementation SHOULD use
+	//   the RTT information measured based on the previous COOKIE ECHO /
+	//   ERROR exchange, and SHOULD add no more than 1 second beyond the
+	//   measured RTT.
+	// There is no RTT measurement before the handshake, so the RTO stands
+	// in for it.
+	rtt := min(time.Duration(a.rtoMgr.getRTO())*time.Millisecond, time.Second)
+	increment := (time.Duration(staleness)*time.Microsecond + rtt).Milliseconds()
+	a.storedInit.params = append(a.storedInit.params, &paramCookiePreservative{
+		lifeSpanIncrement: uint32(min(increment, math.MaxUint32)), //nolint:gosec // G115
+	})
+	if err := a.sendInit(); err != nil {
+		a.log.Errorf("[%s] failed to send init: %s", a.name, err.Error())
+	}
+	a.setState(cookieWait)
+	a.t1Init.start(a.rtoMgr.getRTO())
+}
 
+func (a *Association) handleAbort(c *chunkAbort) error {
 	_ = a.close()
 
-	return fmt.Errorf("[%s] %w: %s", a.name, ErrChunk, errStr.String())
+	return fmt.Errorf("[%s] %w", a.name, newAbortError(c))
 }
 
 // createForwardTSN generates ForwardTSN chunk.
@@ -3216,7 +5233,7 @@ func (a *Association) handleIForwardTSN(chunkTSN *chunkIForwardTSN) []*packet {
 	return a.handlePeerLastTSNAndAcknowledgement(false)
 }
 
-func (a *Association) sendResetRequest(streamIdentifier uint16) error {
+func (a *Association) sendResetRequest(s *Stream) error {
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
@@ -3226,6 +5243,9 @@ func (a *Association) sendResetRequest(streamIdentifier uint16) error {
 			getAssociationStateString(state))
 	}
 
+	streamIdentifier := s.streamIdentifier
+	a.outgoingResets[streamIdentifier] = s
+
 	// Create DATA chunk which only contains valid stream identifier with
 	// nil userData and use it as a EOS from the stream.
 	c := &chunkPayloadData{
@@ -3248,7 +5268,13 @@ func (a *Association) handleReconfigParam(raw param) (*packet, error) {
 	switch par := raw.(type) {
 	case *paramOutgoingResetRequest:
 		a.log.Tracef("[%s] handleReconfigParam (OutgoingResetRequest)", a.name)
-		if a.peerLastTSN() < par.senderLastTSN && len(a.reconfigRequests) >= maxReconfigRequests {
+		// Drop the requests that have been pending for too long, so that they
+		// neither take room nor answer retransmissions.
+		a.expireReconfigRequests(time.Now())
+		rsn := par.reconfigRequestSequenceNumber
+		_, known := a.reconfigRequests[rsn]
+		enqueue := !known && a.peerLastTSN() < par.senderLastTSN
+		if enqueue && len(a.reconfigRequests) >= a.maxReconfigRequests {
 			// We have too many reconfig requests outstanding. Drop the request and let
 			// the peer retransmit. A well behaved peer should only have 1 outstanding
 			// reconfig request.
@@ -3261,7 +5287,10 @@ func (a *Association) handleReconfigParam(raw param) (*packet, error) {
 			// https://chromium.googlesource.com/external/webrtc/+/refs/heads/main/net/dcsctp/socket/stream_reset_handler.cc#271
 			return nil, fmt.Errorf("%w: %d", ErrTooManyReconfigRequests, len(a.reconfigRequests))
 		}
-		a.reconfigRequests[par.reconfigRequestSequenceNumber] = par
+		if !known {
+			a.reconfigRequestsSeen[rsn] = time.Now()
+		}
+		a.reconfigRequests[rsn] = par
 		resp := a.resetStreamsIfAny(par)
 		if resp != nil {
 			return resp, nil
@@ -3284,8 +5313,19 @@ func (a *Association) handleReconfigParam(raw param) (*packet, error) {
 
 			return nil, nil //nolint:nilnil
 		}
-		if par.result == reconfigResultSuccessPerformed {
+		var resetErr error
+		switch par.result {
+		case reconfigResultSuccessPerformed:
 			a.resetOutgoingStreamSequenceNumbers(par.reconfigResponseSequenceNumber)
+		case reconfigResultSuccessNOP:
+		default:
+			resetErr = fmt.Errorf("%w: %s", ErrStreamResetRejected, par.result)
+			a.log.Warnf("[%s] stream reset rsn=%d failed: %s",
+				a.name, par.reconfigResponseSequenceNumber, par.result)
+		}
+		a.completeOutgoingResets(par.reconfigResponseSequenceNumber, resetErr)
+		if resetErr != nil {
+			a.reopenOutgoingStreams(par.reconfigResponseSequenceNumber)
 		}
 		delete(a.reconfigs, par.reconfigResponseSequenceNumber)
 		if len(a.reconfigs) == 0 {
@@ -3298,6 +5338,19 @@ func (a *Association) handleReconfigParam(raw param) (*packet, error) {
 	}
 }
 
+// expireReconfigRequests drops the outstanding reconfig requests first
+// received more than Config.ReconfigRequestLifetime ago.
+// The caller should hold the lock.
+func (a *Association) expireReconfigRequests(now time.Time) {
+	for rsn := range a.reconfigRequests {
+		if now.Sub(a.reconfigRequestsSeen[rsn]) > a.reconfigRequestLifetime {
+			a.log.Debugf("[%s] dropping expired reconfig request rsn=%d", a.name, rsn)
+			delete(a.reconfigRequests, rsn)
+			delete(a.reconfigRequestsSeen, rsn)
+		}
+	}
+}
+
 // The caller should hold the lock.
 func (a *Association) resetOutgoingStreamSequenceNumbers(reconfigRequestSequenceNumber uint32) {
 	reconfig := a.reconfigs[reconfigRequestSequenceNumber]
@@ -3309,9 +5362,56 @@ func (a *Association) resetOutgoingStreamSequenceNumbers(reconfigRequestSequence
 		return
 	}
 	for _, id := range resetRequest.streamIdentifiers {
-		if s, ok := a.streams[id]; ok {
-			s.resetOutgoingStreamSequenceNumbers()
+		if s, ok := a.streams[id]; ok {
+			s.resetOutgoingStreamSequenceNumbers()
+		}
+	}
+}
+
+// completeOutgoingResets notifies the streams of the outgoing reset request
+// rsn that it completed, with err if it failed.
+// The caller should hold the lock.
+func (a *Association) completeOutgoingResets(rsn uint32, err error) {
+	reconfig := a.reconfigs[rsn]
+	if reconfig == nil {
+		return
+	}
+	resetRequest, ok := reconfig.paramA.(*paramOutgoingResetRequest)
+	if !ok {
+		return
+	}
+	for _, id := range resetRequest.streamIdentifiers {
+		if s, ok := a.outgoingResets[id]; ok {
+			delete(a.outgoingResets, id)
+			s.onOutgoingResetDone(err)
+		}
+	}
+}
+
+// reopenOutgoingStreams opens again the outgoing side of the streams of the
+// reset request rsn that the peer rejected, so that they can be written to
+// and closed again.
+// The caller should hold the lock.
+func (a *Association) reopenOutgoingStreams(rsn uint32) {
+	reconfig := a.reconfigs[rsn]
+	if reconfig == nil {
+		return
+	}
+	resetRequest, ok := reconfig.paramA.(*paramOutgoingResetRequest)
+	if !ok {
+		return
+	}
+	for _, id := range resetRequest.streamIdentifiers {
+		s, ok := a.streams[id]
+		if !ok {
+			continue
 		}
+		s.lock.Lock()
+		if s.state == StreamStateClosing {
+			s.state = StreamStateOpen
+			s.log.Debugf("[%s] state change: closing => open (reset rejected)", s.name)
+		}
+		s.lock.Unlock()
 	}
 }
 
@@ -3331,8 +5431,10 @@ func (a *Association) resetStreamsIfAny(resetRequest *paramOutgoingResetRequest)
 			a.lock.Lock()
 			a.log.Debugf("[%s] deleting stream %d", a.name, id)
 			delete(a.streams, s.streamIdentifier)
+			a.streamStats.Delete(s.streamIdentifier)
 		}
 		delete(a.reconfigRequests, resetRequest.reconfigRequestSequenceNumber)
+		delete(a.reconfigRequestsSeen, resetRequest.reconfigRequestSequenceNumber)
 	} else {
 		a.log.Debugf("[%s] resetStream(): senderLastTSN=%d > peerLastTSN=%d",
 			a.name, resetRequest.senderLastTSN, a.peerLastTSN())
@@ -3347,12 +5449,86 @@ func (a *Association) resetStreamsIfAny(resetRequest *paramOutgoingResetRequest)
 	}})
 }
 
+// recordInflightDwell records how long the chunk was in flight when first
+// acknowledged, see Config.EnableDwellTimeStats.
+func (a *Association) recordInflightDwell(chunkPayload *chunkPayloadData, now time.Time) {
+	if chunkPayload.firstSent.IsZero() {
+		return
+	}
+	a.stats.inflightDwell.add(now.Sub(chunkPayload.firstSent))
+	chunkPayload.firstSent = time.Time{}
+}
+
+// dropOldestMessages drops the oldest messages of s not sent yet until
+// excess bytes are dropped, then adds the messages just written and not sent
+// yet to the ones that may be dropped. It returns the lengths of the messages
+// dropped, see DropPolicyDropOldest.
+func (a *Association) dropOldestMessages(s *Stream, excess uint64, written [][]*chunkPayloadData) []int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var dropped []int
+	var nDropped uint64
+	i := 0
+	for ; i < len(s.droppable) && nDropped < excess; i++ {
+		msg := s.droppable[i]
+		msg[0]._dropped = true
+		length := 0
+		for _, c := range msg {
+			length += len(c.userData)
+		}
+		dropped = append(dropped, length)
+		nDropped += uint64(length) //nolint:gosec // G115, length is a length
+	}
+	s.droppable = s.droppable[i:]
+	for _, msg := range written {
+		if msg[0].nSent == 0 {
+			s.droppable = append(s.droppable, msg)
+		}
+	}
+
+	return dropped
+}
+
+// forgetDroppable removes the message beginning with chunkPayload from the
+// ones its stream may drop, once it leaves the pending queue.
+// The caller should hold the lock.
+func (a *Association) forgetDroppable(chunkPayload *chunkPayloadData) {
+	if !chunkPayload.unordered || !chunkPayload.beginningFragment {
+		return
+	}
+	s, ok := a.streams[chunkPayload.streamIdentifier]
+	if !ok {
+		return
+	}
+	for i, msg := range s.droppable {
+		if msg[0] == chunkPayload {
+			s.droppable = append(s.droppable[:i], s.droppable[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// popDroppedDataChunk removes a chunk dropped by the drop policy of its
+// stream from the pending queue. The caller should hold the lock.
+func (a *Association) popDroppedDataChunk(chunkPayload *chunkPayloadData) {
+	if err := a.pendingQueue.pop(chunkPayload); err != nil {
+		a.log.Errorf("[%s] failed to pop from pending queue: %s", a.name, err.Error())
+	}
+	if chunkPayload.ackNotify != nil {
+		a.notifyAck(chunkPayload, ErrMessageAbandoned)
+	}
+	a.notifyFlushedLocked()
+}
+
 // Move the chunk peeked with a.pendingQueue.peek() to the inflightQueue.
 // The caller should hold the lock.
 func (a *Association) movePendingDataChunkToInflightQueue(chunkPayload *chunkPayloadData) {
 	if err := a.pendingQueue.pop(chunkPayload); err != nil {
 		a.log.Errorf("[%s] failed to pop from pending queue: %s", a.name, err.Error())
 	}
+	a.forgetDroppable(chunkPayload)
 
 	if chunkPayload.endingFragment {
 		chunkPayload.setAllInflight()
@@ -3362,20 +5538,30 @@ func (a *Association) movePendingDataChunkToInflightQueue(chunkPayload *chunkPay
 	chunkPayload.tsn = a.generateNextTSN()
 	chunkPayload.since = time.Now()
 	chunkPayload.nSent = 1
+	if !chunkPayload.enqueued.IsZero() {
+		a.stats.pendingDwell.add(chunkPayload.since.Sub(chunkPayload.enqueued))
+		chunkPayload.enqueued = time.Time{}
+		chunkPayload.firstSent = chunkPayload.since
+	}
+	chunkPayload.rtoGeneration = a.rtoGeneration
 
 	a.checkPartialReliabilityStatus(chunkPayload)
 
-	a.log.Tracef(
-		"[%s] sending ppi=%d tsn=%d ssn=%d sent=%d len=%d (%v,%v)",
-		a.name,
-		chunkPayload.payloadType,
-		chunkPayload.tsn,
-		chunkPayload.streamSequenceNumber,
-		chunkPayload.nSent,
-		len(chunkPayload.userData),
-		chunkPayload.beginningFragment,
-		chunkPayload.endingFragment,
-	)
+	if a.onTrace != nil {
+		a.onTrace(newTraceEvent(TraceEventSend, chunkPayload))
+	} else {
+		a.log.Tracef(
+			"[%s] sending ppi=%d tsn=%d ssn=%d sent=%d len=%d (%v,%v)",
+			a.name,
+			chunkPayload.payloadType,
+			chunkPayload.tsn,
+			chunkPayload.streamSequenceNumber,
+			chunkPayload.nSent,
+			len(chunkPayload.userData),
+			chunkPayload.beginningFragment,
+			chunkPayload.endingFragment,
+		)
+	}
 
 	a.inflightQueue.pushNoCheck(chunkPayload)
 
@@ -3383,6 +5569,26 @@ func (a *Association) movePendingDataChunkToInflightQueue(chunkPayload *chunkPay
 	a.rackInsert(chunkPayload)
 }
 
+// exceedsMaxOutstandingBytes reports whether sending dataLen more bytes would
+// exceed Config.MaxOutstandingBytes. A chunk is always allowed when nothing is
+// outstanding so that a chunk larger than the cap cannot stall the association.
+// The caller should hold the lock.
+func (a *Association) exceedsMaxOutstandingBytes(dataLen uint32) bool {
+	inflight := uint32(a.inflightQueue.getNumBytes()) //nolint:gosec // G115
+
+	return a.maxOutstandingBytes > 0 && inflight > 0 && inflight+dataLen > a.maxOutstandingBytes
+}
+
+// holdForNagleLocked reports whether new DATA should be held back because
+// Nagle is enabled, earlier DATA is still outstanding and less than one full
+// packet of user data is queued.
+// The caller should hold the lock.
+func (a *Association) holdForNagleLocked() bool {
+	return a.nagle &&
+		a.inflightQueue.getNumBytes() > 0 &&
+		a.pendingQueue.getNumBytes() < int(a.MaxPayloadSize())
+}
+
 // popPendingDataChunksToSend pops chunks from the pending queues as many as
 // the cwnd and rwnd allows to send.
 // The caller should hold the lock.
@@ -3397,8 +5603,12 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 
 	// track current packet size for MTU bundling so budgeting is accurate.
 	bytesInPacket := 0
+	packets := uint32(0)
+	now := time.Now()
 
 	if a.pendingQueue.size() > 0 { //nolint:nestif
+		a.resetCwndAfterIdle(now)
+
 		// RFC 4960 sec 6.1.  Transmission of DATA Chunks
 		//   A) At any given time, the data sender MUST NOT transmit new data to
 		//      any destination transport address if its peer's rwnd indicates
@@ -3413,6 +5623,12 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 				break // no more pending data
 			}
 
+			if chunkPayload.dropped() {
+				a.popDroppedDataChunk(chunkPayload)
+
+				continue
+			}
+
 			dataLen := uint32(len(chunkPayload.userData)) //nolint:gosec // G115
 			if dataLen == 0 {
 				sisToReset = append(sisToReset, chunkPayload.streamIdentifier)
@@ -3424,6 +5640,10 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 				continue
 			}
 
+			if len(chunks) == 0 && a.holdForNagleLocked() {
+				break // wait for more data or for outstanding data to be acked
+			}
+
 			if uint32(a.inflightQueue.getNumBytes())+dataLen > a.CWND() { //nolint:gosec // G115
 				break // would exceeds cwnd
 			}
@@ -3432,11 +5652,19 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 				break // no more rwnd
 			}
 
+			if a.exceedsMaxOutstandingBytes(dataLen) {
+				break // would exceed the outstanding bytes cap
+			}
+
 			chunkBytes := chunkPayload.chunkSizeInPacket()
 
 			// ensure MTU bundling matches bundleDataChunksIntoPackets().
 			addBytes := chunkBytes
 			if bytesInPacket == 0 {
+				if a.maxBurst != 0 && packets >= a.maxBurst {
+					break // the rest is sent on the next wakeup
+				}
+
 				addBytes += int(commonHeaderSize)
 				if addBytes > int(a.MTU()) {
 					break
@@ -3448,6 +5676,7 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 				}
 
 				bytesInPacket = int(commonHeaderSize)
+				packets++
 			} else {
 				// if it doesn't fit, start a new packet and retry same chunk.
 				if bytesInPacket+chunkBytes > int(a.MTU()) {
@@ -3486,6 +5715,10 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 		}
 	}
 
+	if len(chunks) > 0 {
+		a.lastDataSent = now
+	}
+
 	if a.blockWrite && len(chunks) > 0 && a.pendingQueue.size() == 0 {
 		a.log.Tracef("[%s] all pending data have been sent, notify writable", a.name)
 		a.notifyBlockWritable()
@@ -3501,7 +5734,11 @@ func (a *Association) popPendingDataChunksToSend( //nolint:cyclop,gocognit
 func (a *Association) bundleDataChunksIntoPackets(chunks []*chunkPayloadData) []*packet {
 	packets := []*packet{}
 	chunksToSend := []chunk{}
-	bytesInPacket := int(commonHeaderSize)
+	packetOverhead := int(commonHeaderSize)
+	if a.useTimestamps {
+		packetOverhead += int(timestampChunkSize)
+	}
+	bytesInPacket := packetOverhead
 
 	for _, chunkPayload := range chunks {
 		// RFC 4960 sec 6.1.  Transmission of DATA Chunks
@@ -3513,7 +5750,7 @@ func (a *Association) bundleDataChunksIntoPackets(chunks []*chunkPayloadData) []
 		if bytesInPacket+chunkSizeInPacket > int(a.MTU()) {
 			packets = append(packets, a.createPacket(chunksToSend))
 			chunksToSend = []chunk{}
-			bytesInPacket = int(commonHeaderSize)
+			bytesInPacket = packetOverhead
 		}
 		chunksToSend = append(chunksToSend, chunkPayload)
 		bytesInPacket += chunkSizeInPacket
@@ -3526,20 +5763,46 @@ func (a *Association) bundleDataChunksIntoPackets(chunks []*chunkPayloadData) []
 	return packets
 }
 
-// sendPayloadData sends the data chunks.
-func (a *Association) sendPayloadData(ctx context.Context, chunks []*chunkPayloadData) error {
-	a.lock.Lock()
+// payloadDataStateError is the error of a write in a state other than
+// established: ErrShuttingDown once the shutdown sequence has started, by
+// either side, or ErrPayloadDataStateNotExist.
+func payloadDataStateError(state uint32) error {
+	switch state {
+	case shutdownPending, shutdownSent, shutdownReceived, shutdownAckSent:
+		return fmt.Errorf("%w: state=%s", ErrShuttingDown, getAssociationStateString(state))
+	default:
+		return fmt.Errorf("%w: state=%s", ErrPayloadDataStateNotExist, getAssociationStateString(state))
+	}
+}
+
+// sendPayloadData sends the data chunks. If nonBlocking is true, it returns
+// ErrWouldBlock instead of waiting for a pending blocking write. Waiting for
+// the lock or for a pending blocking write ends when ctx is done, and the
+// chunks are not sent if ctx is done once the lock is taken.
+func (a *Association) sendPayloadData(ctx context.Context, chunks []*chunkPayloadData, nonBlocking bool) error {
+	if err := lockContext(ctx, &a.lock); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		a.lock.Unlock()
+
+		return err
+	}
 
 	state := a.getState()
 	if state != established {
 		a.lock.Unlock()
 
-		return fmt.Errorf("%w: state=%s", ErrPayloadDataStateNotExist,
-			getAssociationStateString(state))
+		return payloadDataStateError(state)
 	}
 
 	if a.blockWrite {
 		for a.writePending {
+			if nonBlocking {
+				a.lock.Unlock()
+
+				return ErrWouldBlock
+			}
 			writeNotify := a.writeNotify
 			a.lock.Unlock()
 			select {
@@ -3553,15 +5816,23 @@ func (a *Association) sendPayloadData(ctx context.Context, chunks []*chunkPayloa
 			if state != established {
 				a.lock.Unlock()
 
-				return fmt.Errorf("%w: state=%s", ErrPayloadDataStateNotExist,
-					getAssociationStateString(state))
+				return payloadDataStateError(state)
 			}
 		}
 		a.writePending = true
 	}
 
+	var now time.Time
+	if a.dwellTimeStats {
+		now = time.Now()
+	}
+
 	// Push the chunks into the pending queue first.
 	for _, c := range chunks {
+		c.enqueued = now
+		if c.ackNotify != nil {
+			a.ackNotifies[c] = struct{}{}
+		}
 		a.pendingQueue.push(c)
 	}
 
@@ -3586,33 +5857,40 @@ func (a *Association) checkPartialReliabilityStatus(chunkPayload *chunkPayloadDa
 		return
 	}
 
-	// PR-SCTP
-	if stream, ok := a.streams[chunkPayload.streamIdentifier]; ok { //nolint:nestif
-		stream.lock.RLock()
-		if stream.reliabilityType == ReliabilityTypeRexmit {
-			if chunkPayload.nSent >= stream.reliabilityValue {
-				chunkPayload.setAbandoned(true)
-				a.rackRemove(chunkPayload)
+	// PR-SCTP, with the parameters of the stream when the message was
+	// written.
+	switch chunkPayload.reliabilityType {
+	case ReliabilityTypeRexmit:
+		if chunkPayload.nSent >= chunkPayload.reliabilityValue {
+			chunkPayload.setAbandoned(true)
+			a.rackRemove(chunkPayload)
+			if a.onTrace != nil {
+				a.onTrace(newTraceEvent(TraceEventAbandoned, chunkPayload))
+			} else {
 				a.log.Tracef(
 					"[%s] marked as abandoned: tsn=%d ppi=%d (remix: %d)",
 					a.name, chunkPayload.tsn, chunkPayload.payloadType, chunkPayload.nSent,
 				)
 			}
-		} else if stream.reliabilityType == ReliabilityTypeTimed {
-			elapsed := int64(time.Since(chunkPayload.since).Seconds() * 1000)
-			if elapsed >= int64(stream.reliabilityValue) {
-				chunkPayload.setAbandoned(true)
-				a.rackRemove(chunkPayload)
+		}
+	case ReliabilityTypeTimed:
+		elapsed := int64(time.Since(chunkPayload.since).Seconds() * 1000)
+		if elapsed >= int64(chunkPayload.reliabilityValue) {
+			chunkPayload.setAbandoned(true)
+			a.rackRemove(chunkPayload)
+			if a.onTrace != nil {
+				a.onTrace(newTraceEvent(TraceEventAbandoned, chunkPayload))
+			} else {
 				a.log.Tracef(
 					"[%s] marked as abandoned: tsn=%d ppi=%d (timed: %d)",
 					a.name, chunkPayload.tsn, chunkPayload.payloadType, elapsed,
 				)
 			}
 		}
-		stream.lock.RUnlock()
-	} else {
-		// Remote has reset its send side of the stream, we can still send data.
-		a.log.Tracef("[%s] stream %d not found, remote reset", a.name, chunkPayload.streamIdentifier)
+	}
+
+	if chunkPayload.abandoned() {
+		a.notifyFlushedLocked()
 	}
 }
 
@@ -3674,19 +5952,25 @@ func (a *Association) getDataPacketsToRetransmit(budgetScaled *int64, consumed *
 
 		chunkPayload.retransmit = false
 		bytesToSend += len(chunkPayload.userData)
+		a.incStreamRetransmissions(chunkPayload.streamIdentifier)
 
 		// Update for retransmission
 		chunkPayload.nSent++
 		chunkPayload.since = currRtxTimestamp
+		chunkPayload.rtoGeneration = a.rtoGeneration
 		a.rackRemove(chunkPayload)
 		a.rackInsert(chunkPayload)
 
 		a.checkPartialReliabilityStatus(chunkPayload)
 
-		a.log.Tracef(
-			"[%s] retransmitting tsn=%d ssn=%d sent=%d",
-			a.name, chunkPayload.tsn, chunkPayload.streamSequenceNumber, chunkPayload.nSent,
-		)
+		if a.onTrace != nil {
+			a.onTrace(newTraceEvent(TraceEventRetransmit, chunkPayload))
+		} else {
+			a.log.Tracef(
+				"[%s] retransmitting tsn=%d ssn=%d sent=%d",
+				a.name, chunkPayload.tsn, chunkPayload.streamSequenceNumber, chunkPayload.nSent,
+			)
+		}
 
 		chunks = append(chunks, chunkPayload)
 	}
@@ -3734,6 +6018,7 @@ func (a *Association) handleChunksStart() {
 
 	a.delayedAckTriggered = false
 	a.immediateAckTriggered = false
+	a.tsEcrValid = false
 }
 
 func (a *Association) handleChunksEnd() {
@@ -3742,13 +6027,19 @@ func (a *Association) handleChunksEnd() {
 
 	if a.immediateAckTriggered {
 		a.ackState = ackStateImmediate
+		a.delayedAckPackets = 0
 		a.ackTimer.stop()
 		a.awakeWriteLoop()
 	} else if a.delayedAckTriggered {
 		// Will send delayed ack in the next ack timeout
 		a.ackState = ackStateDelay
+		a.delayedAckPackets++
 		a.ackTimer.start()
 	}
+
+	// Any packet from the peer, including a HEARTBEAT ACK, shows the path is
+	// alive.
+	a.restartHeartbeatTimer()
 }
 
 func (a *Association) handleChunk(receivedPacket *packet, receivedChunk chunk) error { //nolint:cyclop
@@ -3787,11 +6078,7 @@ func (a *Association) handleChunk(receivedPacket *packet, receivedChunk chunk) e
 		err = a.handleAbort(receivedChunk)
 
 	case *chunkError:
-		var errStr strings.Builder
-		for _, e := range receivedChunk.errorCauses {
-			fmt.Fprintf(&errStr, "(%s)", e)
-		}
-		a.log.Debugf("[%s] Error chunk, with following errors: %s", a.name, errStr.String())
+		a.handleError(receivedChunk)
 
 	case *chunkHeartbeat:
 		packets = a.handleHeartbeat(receivedChunk)
@@ -3826,6 +6113,17 @@ func (a *Association) handleChunk(receivedPacket *packet, receivedChunk chunk) e
 	case *chunkShutdownComplete:
 		err = a.handleShutdownComplete(receivedChunk)
 
+	case *chunkECNE:
+		packets = a.handleECNE(receivedChunk)
+	case *chunkCWR:
+		a.log.Tracef("[%s] CWR received: lowestTSN=%d", a.name, receivedChunk.lowestTSN)
+
+	case *chunkTimestamp:
+		a.handleTimestamp(receivedPacket, receivedChunk)
+
+	case *chunkUnknown:
+		packets = a.handleUnknownChunk(receivedChunk)
+
 	default:
 		err = ErrChunkTypeUnhandled
 	}
@@ -3859,6 +6157,9 @@ func shouldAbortOnChunkValidationError(receivedChunk chunk) bool {
 }
 
 func (a *Association) onRetransmissionTimeout(id int, nRtos uint) { //nolint:cyclop
+	// deferred first so it runs after the lock is released.
+	defer a.flushCongestionEvents()
+
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
@@ -3896,6 +6197,10 @@ func (a *Association) onRetransmissionTimeout(id int, nRtos uint) { //nolint:cyc
 
 	if id == timerT3RTX { //nolint:nestif
 		a.stats.incT3Timeouts()
+		if !a.zeroRWNDSince.IsZero() {
+			// The zero window probe went unanswered: the window is still closed.
+			a.checkPeerStall(true)
+		}
 
 		// RFC 4960 sec 6.3.3
 		//  E1)  For the destination address for which the timer expires, adjust
@@ -3907,8 +6212,13 @@ func (a *Association) onRetransmissionTimeout(id int, nRtos uint) { //nolint:cyc
 		//      ssthresh = max(cwnd/2, 4*MTU)
 		//      cwnd = 1*MTU
 
+		cwnd, ssthresh := a.CWND(), a.ssthresh
 		a.ssthresh = max32(a.CWND()/2, 4*a.MTU())
 		a.setCWND(a.MTU())
+		a.warnMinCwndOverridesLoss()
+		a.queueCongestionEvent(CongestionEventRTO, cwnd, ssthresh)
+		a.rtoGeneration++
+		a.startFRTO(cwnd, ssthresh)
 		a.log.Tracef("[%s] updated cwnd=%d ssthresh=%d inflight=%d (RTO)",
 			a.name, a.CWND(), a.ssthresh, a.inflightQueue.getNumBytes())
 		// If not in Fast Recovery, enter Fast Recovery and mark the highest outstanding TSN as the Fast Recovery exit point.
@@ -3970,6 +6280,11 @@ func (a *Association) onRetransmissionTimeout(id int, nRtos uint) { //nolint:cyc
 		a.willRetransmitReconfig = true
 		a.awakeWriteLoop()
 	}
+
+	if id == timerHeartbeat && a.getState() == established {
+		a.log.Debugf("[%s] path idle, sending HEARTBEAT (nRtos=%d)", a.name, nRtos)
+		a.sendActiveHeartbeatLocked()
+	}
 }
 
 func (a *Association) onRetransmissionFailure(id int) {
@@ -3978,20 +6293,36 @@ func (a *Association) onRetransmissionFailure(id int) {
 
 	if id == timerT1Init {
 		a.log.Errorf("[%s] retransmission failure: T1-init", a.name)
-		a.completeHandshake(ErrHandshakeInitAck)
+		a.completeHandshake(&HandshakeError{Err: ErrHandshakeInitAck, Cause: ErrHandshakeTimeout})
 
 		return
 	}
 
 	if id == timerT1Cookie {
 		a.log.Errorf("[%s] retransmission failure: T1-cookie", a.name)
-		a.completeHandshake(ErrHandshakeCookieEcho)
+		a.completeHandshake(&HandshakeError{Err: ErrHandshakeCookieEcho, Cause: ErrHandshakeTimeout})
 
 		return
 	}
 
 	if id == timerT2Shutdown {
-		a.log.Errorf("[%s] retransmission failure: T2-shutdown", a.name)
+		// RFC 9260 sec 9.2: the peer is considered unreachable once
+		// SHUTDOWN or SHUTDOWN ACK retransmissions exceed
+		// Association.Max.Retrans.
+		a.log.Errorf("[%s] retransmission failure: T2-shutdown (peer unreachable)", a.name)
+		if a.closeErr == nil {
+			a.closeErr = ErrPeerUnreachable
+		}
+		if err := a.close(); err != nil {
+			a.log.Warnf("[%s] failed to close association: %v", a.name, err)
+		}
+
+		return
+	}
+
+	if id == timerReconfig {
+		a.log.Errorf("[%s] retransmission failure: reconfig", a.name)
+		a.abandonReconfigs()
 
 		return
 	}
@@ -4005,6 +6336,42 @@ func (a *Association) onRetransmissionFailure(id int) {
 
 		return
 	}
+
+	if id == timerHeartbeat {
+		// RFC 9260 sec 8.1: the peer is considered unreachable once the
+		// error counter exceeds Association.Max.Retrans.
+		a.log.Errorf("[%s] retransmission failure: heartbeat (peer unreachable)", a.name)
+		if a.closeErr == nil {
+			a.closeErr = ErrPeerUnreachable
+		}
+		if err := a.close(); err != nil {
+			a.log.Warnf("[%s] failed to close association: %v", a.name, err)
+		}
+	}
+}
+
+// abandonReconfigs gives up the outstanding stream reset requests after the
+// peer failed to respond. The streams being reset are closed and unblocked
+// with ErrStreamResetTimeout. The caller should hold the lock.
+func (a *Association) abandonReconfigs() {
+	for rsn, c := range a.reconfigs {
+		a.completeOutgoingResets(rsn, ErrStreamResetTimeout)
+		if req, ok := c.paramA.(*paramOutgoingResetRequest); ok {
+			for _, id := range req.streamIdentifiers {
+				s, ok := a.streams[id]
+				if !ok {
+					continue
+				}
+				a.log.Debugf("[%s] stream %d reset abandoned", a.name, id)
+				s.lock.Lock()
+				s.state = StreamStateClosed
+				s.lock.Unlock()
+				a.unregisterStream(s, ErrStreamResetTimeout)
+			}
+		}
+		delete(a.reconfigs, rsn)
+	}
+	a.willRetransmitReconfig = false
 }
 
 func (a *Association) onAckTimeout() {
@@ -4018,6 +6385,23 @@ func (a *Association) onAckTimeout() {
 	a.awakeWriteLoop()
 }
 
+// FlushAck sends the delayed SACK now instead of waiting for the ack timer,
+// for example when no reply to the received data is expected soon. It does
+// nothing if no SACK is pending or the association is not established.
+func (a *Association) FlushAck() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.getState() != established || a.ackState != ackStateDelay {
+		return
+	}
+
+	a.ackState = ackStateImmediate
+	a.delayedAckPackets = 0
+	a.ackTimer.stop()
+	a.awakeWriteLoop()
+}
+
 // BufferedAmount returns total amount (in bytes) of currently buffered user data.
 func (a *Association) BufferedAmount() int {
 	a.lock.RLock()
@@ -4026,11 +6410,137 @@ func (a *Association) BufferedAmount() int {
 	return a.pendingQueue.getNumBytes() + a.inflightQueue.getNumBytes()
 }
 
+// NegotiatedStreams returns the numbers of outbound and inbound streams
+// negotiated with the peer in the handshake (RFC 9260 section 5.1.1):
+// stream identifiers must be below them. Before the handshake completes,
+// they are the numbers advertised to the peer.
+func (a *Association) NegotiatedStreams() (outbound, inbound uint16) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.myMaxNumOutboundStreams, a.myMaxNumInboundStreams
+}
+
+// PeerAddresses returns the IPv4 and IPv6 addresses the peer listed in its
+// INIT or INIT ACK (RFC 9260 section 3.3.2.1). They are informational only:
+// the association is single-homed over its net.Conn and never sends to them.
+func (a *Association) PeerAddresses() []net.IP {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	addresses := make([]net.IP, len(a.peerAddresses))
+	for i, address := range a.peerAddresses {
+		addresses[i] = append(net.IP{}, address...)
+	}
+
+	return addresses
+}
+
+// PeerSupportedAddressTypes returns the parameter types (5 for IPv4, 6 for
+// IPv6, 11 for Host Name) of the Supported Address Types parameter of the
+// peer's INIT (RFC 9260 section 3.3.2.1), or nil if the peer did not send
+// one. Like PeerAddresses, it is informational only.
+func (a *Association) PeerSupportedAddressTypes() []uint16 {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if a.peerAddressTypes == nil {
+		return nil
+	}
+	types := make([]uint16, len(a.peerAddressTypes))
+	for i, t := range a.peerAddressTypes {
+		types[i] = uint16(t)
+	}
+
+	return types
+}
+
+// Flush blocks until all buffered user data, including data written while
+// it waits, has been acknowledged by the peer, so that BufferedAmount
+// reaches zero. Data abandoned by PR-SCTP counts as flushed.
+// Unlike Shutdown, it leaves the association open. It returns ctx.Err() if
+// ctx is done first, or ErrAssociationClosed if the association is closed.
+func (a *Association) Flush(ctx context.Context) error {
+	a.lock.Lock()
+	if a.unflushedBytesLocked() == 0 {
+		a.lock.Unlock()
+
+		return nil
+	}
+	if a.flushCh == nil {
+		a.flushCh = make(chan struct{})
+	}
+	flushCh := a.flushCh
+	a.lock.Unlock()
+
+	select {
+	case <-flushCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.readLoopCloseCh:
+		return ErrAssociationClosed
+	}
+}
+
+// unflushedBytesLocked returns the buffered bytes Flush waits for: those
+// of BufferedAmount, minus the abandoned chunks waiting for the peer to
+// acknowledge the FORWARD TSN. The caller should hold the lock.
+func (a *Association) unflushedBytesLocked() int {
+	nBytes := a.pendingQueue.getNumBytes() + a.inflightQueue.getNumBytes()
+	for i := 0; i < a.inflightQueue.size(); i++ {
+		if c := a.inflightQueue.chunks.At(i); !c.acked && c.abandoned() {
+			nBytes -= len(c.userData)
+		}
+	}
+
+	return nBytes
+}
+
+// notifyFlushedLocked wakes up the Flush callers once nothing is left to
+// flush. The caller should hold the lock.
+func (a *Association) notifyFlushedLocked() {
+	if a.flushCh == nil || a.unflushedBytesLocked() != 0 {
+		return
+	}
+
+	close(a.flushCh)
+	a.flushCh = nil
+}
+
+// PendingBytes returns the amount (in bytes) of user data queued but not
+// sent yet, such as data waiting for the congestion or receiver window.
+func (a *Association) PendingBytes() int {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.pendingQueue.getNumBytes()
+}
+
+// InflightBytes returns the amount (in bytes) of user data sent but not
+// acknowledged yet.
+func (a *Association) InflightBytes() int {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.inflightQueue.getNumBytes()
+}
+
 // MaxMessageSize returns the maximum message size you can send.
 func (a *Association) MaxMessageSize() uint32 {
 	return atomic.LoadUint32(&a.maxMessageSize)
 }
 
+// MaxPayloadSize returns the largest user data carried by one DATA chunk,
+// which is the MTU minus the packet and chunk headers. Messages larger than
+// this, up to MaxMessageSize, are split into several fragments, so sizing
+// messages to a multiple of it avoids sending a partly filled packet per
+// message. It is updated when the handshake negotiates I-DATA or
+// TIMESTAMP chunks, which take more room in each packet.
+func (a *Association) MaxPayloadSize() uint32 {
+	return atomic.LoadUint32(&a.maxPayloadSize)
+}
+
 // SetMaxMessageSize sets the maximum message size you can send.
 func (a *Association) SetMaxMessageSize(maxMsgSize uint32) {
 	atomic.StoreUint32(&a.maxMessageSize, maxMsgSize)
@@ -4103,6 +6613,57 @@ func (a *Association) stopPTOTimer() {
 	a.pokeTimerLoop()
 }
 
+// startReassemblyTimer arms the reassembly timer unless it is already armed.
+// It fires after the reassembly timeout, so an incomplete message is
+// discarded at most twice the timeout after its first fragment arrived.
+func (a *Association) startReassemblyTimer() {
+	a.timerMu.Lock()
+	defer a.timerMu.Unlock()
+
+	if a.reassemblyTimer == nil {
+		a.reassemblyTimer = time.AfterFunc(a.reassemblyTimeout, a.onReassemblyTimeout)
+	}
+}
+
+func (a *Association) stopReassemblyTimer() {
+	a.timerMu.Lock()
+	defer a.timerMu.Unlock()
+
+	if a.reassemblyTimer != nil {
+		a.reassemblyTimer.Stop()
+		a.reassemblyTimer = nil
+	}
+}
+
+// onReassemblyTimeout discards the incomplete messages that timed out on
+// all streams, so that they do not wait for DATA that may never come, and
+// rearms the timer while incomplete messages are left.
+func (a *Association) onReassemblyTimeout() {
+	a.timerMu.Lock()
+	a.reassemblyTimer = nil
+	a.timerMu.Unlock()
+
+	a.lock.Lock()
+	if a.getState() == closed {
+		a.lock.Unlock()
+
+		return
+	}
+	cutoff := time.Now().Add(-a.reassemblyTimeout)
+	incomplete := false
+	for _, s := range a.streams {
+		if s.discardIncomplete(cutoff) {
+			incomplete = true
+		}
+	}
+	if incomplete {
+		a.startReassemblyTimer()
+	}
+	a.lock.Unlock()
+
+	a.flushReassemblyMemoryChange()
+}
+
 // drainTimer safely stops a timer and drains its channel if needed.
 func drainTimer(t *time.Timer) {
 	if !t.Stop() {
@@ -4714,9 +7275,72 @@ func (a *Association) ActiveHeartbeat() {
 
 // caller must hold a.lock.
 func (a *Association) sendActiveHeartbeatLocked() {
+	a.heartbeatNonce = a.newHeartbeatNonce()
+	a.sendHeartbeatLocked(binary.BigEndian.AppendUint64(nil, a.heartbeatNonce))
+}
+
+// newHeartbeatNonce returns a random nonce not used by an outstanding
+// HEARTBEAT. The caller should hold the lock.
+func (a *Association) newHeartbeatNonce() uint64 {
+	for {
+		nonce := globalMathRandomGenerator.Uint64()
+		if _, exists := a.pings[nonce]; !exists && nonce != 0 && nonce != a.heartbeatNonce {
+			return nonce
+		}
+	}
+}
+
+// Ping sends a HEARTBEAT chunk to the peer and waits until the matching
+// HEARTBEAT ACK is received, returning the measured round-trip time. It can
+// be used as an application level keepalive or to sample the path RTT
+// independently of the data flow.
+//
+// ErrPingTimeout is returned if ctx is done before the HEARTBEAT ACK arrives.
+func (a *Association) Ping(ctx context.Context) (time.Duration, error) {
+	a.lock.Lock()
+	if a.getState() != established {
+		a.lock.Unlock()
+
+		return 0, ErrPingNonEstablished
+	}
+
+	nonce := a.newHeartbeatNonce()
+	ackCh := make(chan struct{})
+	a.pings[nonce] = ackCh
+
+	start := time.Now()
+	a.sendHeartbeatLocked(binary.BigEndian.AppendUint64(nil, nonce))
+	a.lock.Unlock()
+
+	select {
+	case <-ackCh:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		a.cancelPing(nonce)
+
+		return 0, fmt.Errorf("%w: %w", ErrPingTimeout, ctx.Err())
+	case <-a.readLoopCloseCh:
+		a.cancelPing(nonce)
+
+		return 0, ErrAssociationClosed
+	}
+}
+
+func (a *Association) cancelPing(nonce uint64) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	delete(a.pings, nonce)
+}
+
+// sendHeartbeatLocked queues a HEARTBEAT carrying the current time, followed
+// by the given nonce.
+// caller must hold a.lock.
+func (a *Association) sendHeartbeatLocked(nonce []byte) {
 	now := time.Now().UnixNano()
-	buf := make([]byte, 8)
+	buf := make([]byte, heartbeatInfoTimestampSize, heartbeatInfoTimestampSize+len(nonce))
 	binary.BigEndian.PutUint64(buf, uint64(now)) //nolint:gosec // time.now() will never be negative
+	buf = append(buf, nonce...)
 
 	info := &paramHeartbeatInfo{heartbeatInformation: buf}
 
@@ -4756,14 +7380,20 @@ func GenerateOutOfBandToken(opts ...ClientOption) ([]byte, error) {
 
 	init := &chunkInit{}
 	init.initialTSN = globalMathRandomGenerator.Uint32()
-	init.numOutboundStreams = math.MaxUint16
-	init.numInboundStreams = math.MaxUint16
+	init.numOutboundStreams = config.maxNumStreams()
+	init.numInboundStreams = config.maxNumStreams()
 	init.initiateTag = generateInitiateTag()
 	init.advertisedReceiverWindowCredit = config.MaxReceiveBufferSize
-	setSupportedExtensions(&init.chunkInitCommon, config.enableInterleaving)
+	if config.AdvertisedRWND != 0 {
+		init.advertisedReceiverWindowCredit = config.AdvertisedRWND
+	}
+	setSupportedExtensions(&init.chunkInitCommon, config.enableInterleaving, config.EnableTimestamps)
 
 	if config.EnableZeroChecksum {
-		init.params = append(init.params, &paramZeroChecksumAcceptable{edmid: dtlsErrorDetectionMethod})
+		init.params = append(init.params, &paramZeroChecksumAcceptable{edmid: config.ZeroChecksumEDMID})
+	}
+	if config.EnableECN {
+		init.params = append(init.params, &paramECNCapable{})
 	}
 	_, err := init.check()
 	if err != nil {
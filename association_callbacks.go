@@ -0,0 +1,372 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// receiveBufferFullReportInterval is the minimum time between two calls of
+// the receive buffer full callback.
+const receiveBufferFullReportInterval = time.Second
+
+// Bounds of the wait before a read is retried after a read error, doubled
+// on each consecutive error.
+const (
+	readErrorBackoffMin = time.Millisecond
+	readErrorBackoffMax = time.Second
+)
+
+// callbackSettings holds the optional application callbacks of an
+// association. It is referenced through a pointer so that Config stays
+// comparable.
+type callbackSettings struct {
+	onReassemblyMemoryChange func(delta int)
+	onReceiveBufferFull      func(streamID uint16)
+	onCongestionEvent        func(CongestionEvent)
+	onProtocolError          func(causes []ErrorCause)
+	onPeerStalled            func()
+	onTrace                  func(TraceEvent)
+	onReadError              func(error) bool
+}
+
+// callbackQueue runs application callbacks one at a time, in the order they
+// were pushed, on a goroutine of its own, so that a slow callback never
+// holds up packet processing. The goroutine exits once the queue is empty.
+type callbackQueue struct {
+	mu      sync.Mutex
+	fns     []func()
+	running bool
+}
+
+// push queues fn, starting the goroutine if it is not running.
+func (q *callbackQueue) push(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.fns = append(q.fns, fn)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+func (q *callbackQueue) run() {
+	for {
+		q.mu.Lock()
+		fns := q.fns
+		q.fns = nil
+		if len(fns) == 0 {
+			q.running = false
+			q.mu.Unlock()
+
+			return
+		}
+		q.mu.Unlock()
+
+		for _, fn := range fns {
+			fn()
+		}
+	}
+}
+
+func cloneCallbackSettings(s *callbackSettings) *callbackSettings {
+	if s == nil {
+		return nil
+	}
+
+	clone := *s
+
+	return &clone
+}
+
+func (c *Config) mutableCallbacks() *callbackSettings {
+	c.callbacks = cloneCallbackSettings(c.callbacks)
+	if c.callbacks == nil {
+		c.callbacks = &callbackSettings{}
+	}
+
+	return c.callbacks
+}
+
+// WithOnReassemblyMemoryChange sets a callback invoked with the change in
+// bytes held by the association's stream reassembly buffers: positive when
+// received data is buffered, negative when it is read or discarded. It lets
+// an external coordinator track memory across associations.
+// The callback is never called while association or stream locks are held,
+// but may be called from different goroutines. Data still buffered when a
+// stream is dropped without being read is not reported as released.
+// By default no callback is set.
+func WithOnReassemblyMemoryChange(fn func(delta int)) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onReassemblyMemoryChange = fn
+
+		return nil
+	})
+}
+
+// addReassemblyMemoryDelta records a change of reassembly buffer usage to be
+// reported by the next flushReassemblyMemoryChange call.
+func (a *Association) addReassemblyMemoryDelta(delta int) {
+	if delta == 0 || a == nil || a.onReassemblyMemoryChange == nil {
+		return
+	}
+
+	atomic.AddInt64(&a.reassemblyMemoryDelta, int64(delta))
+}
+
+// flushReassemblyMemoryChange reports the accumulated reassembly buffer
+// change, if any. The caller must not hold a.lock or any stream lock.
+func (a *Association) flushReassemblyMemoryChange() {
+	if a == nil || a.onReassemblyMemoryChange == nil {
+		return
+	}
+
+	if delta := atomic.SwapInt64(&a.reassemblyMemoryDelta, 0); delta != 0 {
+		a.onReassemblyMemoryChange(int(delta))
+	}
+}
+
+// WithOnReceiveBufferFull sets a callback invoked when DATA is dropped
+// because the receive buffer is full, with the identifier of the stream the
+// DATA was for. It is called at most once per second and runs in its own
+// goroutine, so it never blocks packet processing. Every drop is counted in
+// AssociationStats.ReceiveBufferFullDrops.
+// By default no callback is set.
+func WithOnReceiveBufferFull(fn func(streamID uint16)) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onReceiveBufferFull = fn
+
+		return nil
+	})
+}
+
+// reportReceiveBufferFull calls the receive buffer full callback unless it
+// was already called within receiveBufferFullReportInterval.
+// The caller should hold the lock.
+func (a *Association) reportReceiveBufferFull(streamID uint16) {
+	if a.onReceiveBufferFull == nil {
+		return
+	}
+
+	now := time.Now()
+	if !a.lastReceiveBufferFullCall.IsZero() &&
+		now.Sub(a.lastReceiveBufferFullCall) < receiveBufferFullReportInterval {
+		return
+	}
+	a.lastReceiveBufferFullCall = now
+
+	go a.onReceiveBufferFull(streamID)
+}
+
+// WithOnCongestionEvent sets a callback invoked each time the congestion
+// window is reduced by fast recovery, a T3-rtx timeout or an ECN echo.
+// Events are reported in order, one at a time, from a goroutine of their
+// own, so a slow callback delays later events but never packet processing.
+// By default no callback is set.
+func WithOnCongestionEvent(fn func(CongestionEvent)) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onCongestionEvent = fn
+
+		return nil
+	})
+}
+
+// queueCongestionEvent records a congestion window reduction, given the
+// values before it, to be reported by the next flushCongestionEvents call.
+// The caller should hold the lock.
+func (a *Association) queueCongestionEvent(typ CongestionEventType, cwndBefore, ssthreshBefore uint32) {
+	if a.onCongestionEvent == nil {
+		return
+	}
+
+	a.pendingCongestionEvents = append(a.pendingCongestionEvents, CongestionEvent{
+		Type:           typ,
+		CwndBefore:     cwndBefore,
+		CwndAfter:      a.CWND(),
+		SsthreshBefore: ssthreshBefore,
+		SsthreshAfter:  a.ssthresh,
+	})
+}
+
+// flushCongestionEvents hands the queued congestion events, if any, to the
+// goroutine reporting them. The caller must not hold a.lock.
+func (a *Association) flushCongestionEvents() {
+	if a.onCongestionEvent == nil {
+		return
+	}
+
+	a.lock.Lock()
+	events := a.pendingCongestionEvents
+	a.pendingCongestionEvents = nil
+	a.lock.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+	a.congestionEventQueue.push(func() {
+		for _, ev := range events {
+			a.onCongestionEvent(ev)
+		}
+	})
+}
+
+// WithOnProtocolError sets a callback invoked with the error causes of each
+// ERROR chunk received from the peer, such as "Unrecognized Chunk Type" or
+// "Stale Cookie Error". The association handles the causes it can act on
+// by itself: a stale cookie during the handshake restarts it with a new
+// INIT. Errors are reported in order, one at a time, from a goroutine of
+// their own, so a slow callback delays later errors but never packet
+// processing.
+// By default no callback is set.
+func WithOnProtocolError(fn func(causes []ErrorCause)) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onProtocolError = fn
+
+		return nil
+	})
+}
+
+// queueProtocolError records the causes of a received ERROR chunk to be
+// reported by the next flushProtocolErrors call.
+// The caller should hold the lock.
+func (a *Association) queueProtocolError(causes []errorCause) {
+	if a.onProtocolError == nil || len(causes) == 0 {
+		return
+	}
+
+	reported := make([]ErrorCause, 0, len(causes))
+	for _, cause := range causes {
+		reported = append(reported, newErrorCause(cause))
+	}
+	a.pendingProtocolErrors = append(a.pendingProtocolErrors, reported)
+}
+
+// flushProtocolErrors hands the queued ERROR chunk causes, if any, to the
+// goroutine reporting them. The caller must not hold a.lock.
+func (a *Association) flushProtocolErrors() {
+	if a.onProtocolError == nil {
+		return
+	}
+
+	a.lock.Lock()
+	pending := a.pendingProtocolErrors
+	a.pendingProtocolErrors = nil
+	a.lock.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	a.protocolErrorQueue.push(func() {
+		for _, causes := range pending {
+			a.onProtocolError(causes)
+		}
+	})
+}
+
+// WithOnPeerStalled sets a callback invoked when the peer has advertised a
+// zero receiver window without accepting any data for the PeerStallTimeout
+// set by WithPeerStallTimeout, which tells a wedged receiver apart from
+// normal backpressure. The association is kept: the application decides
+// whether to tear it down. The callback is called once per zero window
+// episode, in its own goroutine. An episode ends when the peer advertises
+// a non-zero window or acknowledges new data.
+// By default no callback is set.
+func WithOnPeerStalled(fn func()) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onPeerStalled = fn
+
+		return nil
+	})
+}
+
+// checkPeerStall tracks how long the peer's receiver window has stayed
+// closed and reports a stall once it exceeds peerStallTimeout.
+// The caller should hold the lock.
+func (a *Association) checkPeerStall(zeroWindow bool) {
+	if a.peerStallTimeout == 0 {
+		return
+	}
+	if !zeroWindow {
+		a.zeroRWNDSince = time.Time{}
+		a.peerStallReported = false
+
+		return
+	}
+
+	now := time.Now()
+	if a.zeroRWNDSince.IsZero() {
+		a.zeroRWNDSince = now
+
+		return
+	}
+	if a.peerStallReported || now.Sub(a.zeroRWNDSince) < a.peerStallTimeout {
+		return
+	}
+
+	a.peerStallReported = true
+	a.log.Warnf("[%s] peer receiver window closed for %v", a.name, now.Sub(a.zeroRWNDSince))
+	if a.onPeerStalled != nil {
+		go a.onPeerStalled()
+	}
+}
+
+// WithOnTrace sets a callback invoked for each DATA chunk sent,
+// retransmitted, abandoned, acknowledged or received, in place of the
+// corresponding trace level log lines. Unlike the log lines, events are not
+// formatted, so they are cheap enough to be consumed on busy associations.
+// The callback is called synchronously with the association lock held: it
+// must return quickly and must not call back into the association.
+// By default no callback is set and these events are logged at trace level.
+func WithOnTrace(fn func(TraceEvent)) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onTrace = fn
+
+		return nil
+	})
+}
+
+// WithOnReadError sets a callback invoked with each error returned by the
+// transport's read, such as a transient error of a flaky UDP stack. It
+// returns whether to keep the association and read again: consecutive
+// retries wait from 1ms, doubling up to 1s, so that a persistent error does
+// not spin. Once the association is closing, reads are not retried
+// whatever the callback returns.
+// By default no callback is set and any read error closes the association.
+func WithOnReadError(fn func(err error) bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.mutableCallbacks().onReadError = fn
+
+		return nil
+	})
+}
+
+// retryRead reports whether the read loop should read again after err,
+// waiting for the backoff first. It is called from the read loop only.
+func (a *Association) retryRead(err error, backoff *time.Duration) bool {
+	if a.onReadError == nil {
+		return false
+	}
+	select {
+	case <-a.closeWriteLoopCh:
+		return false
+	default:
+	}
+	if !a.onReadError(err) {
+		return false
+	}
+
+	*backoff = min(max(2**backoff, readErrorBackoffMin), readErrorBackoffMax)
+	a.log.Debugf("[%s] retrying read in %v after error: %v", a.name, *backoff, err)
+	timer := time.NewTimer(*backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-a.closeWriteLoopCh:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"errors"
+	"strings"
+)
+
+// AbortError is the error reported when the peer sends an ABORT chunk.
+// It matches ErrChunk with errors.Is.
+type AbortError struct {
+	// Causes holds the error causes carried by the ABORT chunk.
+	Causes []ErrorCause
+
+	// Reason is the Upper Layer Abort Reason of a User-Initiated Abort
+	// error cause, or empty if the ABORT did not carry one.
+	Reason string
+}
+
+func newAbortError(c *chunkAbort) *AbortError {
+	abortErr := &AbortError{Causes: newErrorCauses(c.errorCauses)}
+	for _, cause := range c.errorCauses {
+		if userAbort, ok := cause.(*errorCauseUserInitiatedAbort); ok && abortErr.Reason == "" {
+			abortErr.Reason = string(userAbort.upperLayerAbortReason)
+		}
+	}
+
+	return abortErr
+}
+
+func (e *AbortError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrChunk.Error())
+	b.WriteString(": ")
+	for _, cause := range e.Causes {
+		b.WriteString("(")
+		b.WriteString(cause.detail())
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// Unwrap returns ErrChunk.
+func (e *AbortError) Unwrap() error {
+	return ErrChunk
+}
+
+// HandshakeError is returned by Client and Server when the association could
+// not be established.
+type HandshakeError struct {
+	// Err is the handshake failure: ErrHandshakeInitAck,
+	// ErrHandshakeCookieEcho or ErrAssociationClosedBeforeConn.
+	Err error
+
+	// Cause is the specific reason for the failure: ErrHandshakeTimeout
+	// when INIT or COOKIE ECHO retransmissions were exhausted, or the error
+	// that ended the association, such as an *AbortError for a received
+	// ABORT or a transport read error. It may be nil.
+	Cause error
+}
+
+func (e *HandshakeError) Error() string {
+	if e.Cause == nil {
+		return e.Err.Error()
+	}
+
+	return e.Err.Error() + ": " + e.Cause.Error()
+}
+
+// Unwrap returns Err and Cause so both can be matched with errors.Is and
+// errors.As.
+func (e *HandshakeError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Err, e.Cause}
+}
+
+// handshakeError wraps err, a handshake failure, together with the error
+// that closed the association, if any.
+func (a *Association) handshakeError(err error) *HandshakeError {
+	var handshakeErr *HandshakeError
+	if errors.As(err, &handshakeErr) {
+		return handshakeErr
+	}
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return &HandshakeError{Err: err, Cause: a.closeErr}
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// frtoPhase is the step of the F-RTO spurious timeout detection (RFC 5682)
+// the association is in.
+type frtoPhase uint8
+
+const (
+	// frtoIdle: no T3-rtx timeout is being checked.
+	frtoIdle frtoPhase = iota
+	// frtoFirstSack: waiting for the first SACK after the timeout.
+	frtoFirstSack
+	// frtoSecondSack: waiting for the second SACK after the timeout.
+	frtoSecondSack
+)
+
+// frtoState is the state of the F-RTO detection of the last T3-rtx timeout.
+type frtoState struct {
+	phase    frtoPhase
+	recover  uint32 // highest TSN sent when the timer expired
+	cwnd     uint32 // cwnd before the timeout
+	ssthresh uint32 // ssthresh before the timeout
+}
+
+// startFRTO starts checking whether the T3-rtx timeout that just expired
+// was spurious, given the cwnd and ssthresh before it. A timeout during
+// the check falls back to the conventional recovery.
+// The caller should hold the lock.
+func (a *Association) startFRTO(cwnd, ssthresh uint32) {
+	if !a.frtoEnabled {
+		return
+	}
+	if a.frto.phase != frtoIdle {
+		a.log.Debugf("[%s] F-RTO: timeout during detection", a.name)
+		a.frto.phase = frtoIdle
+
+		return
+	}
+
+	a.frto = frtoState{
+		phase:    frtoFirstSack,
+		recover:  a.myNextTSN - 1,
+		cwnd:     cwnd,
+		ssthresh: ssthresh,
+	}
+}
+
+// frtoOriginalsAcked reports whether the SACK newly acknowledges a DATA
+// chunk that was last sent before the latest T3-rtx timeout, that is which
+// was not lost but delayed. The caller should hold the lock.
+func (a *Association) frtoOriginalsAcked(sack *chunkSelectiveAck) bool {
+	if a.frto.phase == frtoIdle {
+		return false
+	}
+
+	// The SACK is not validated yet: the walks stop at the first TSN that
+	// is not inflight, so that a bogus cumulative TSN ack cannot make them
+	// run for billions of TSNs.
+	original := func(tsn uint32) (isOriginal, inflight bool) {
+		c, ok := a.inflightQueue.get(tsn)
+
+		return ok && !c.acked && c.rtoGeneration != a.rtoGeneration, ok
+	}
+	for tsn := a.cumulativeTSNAckPoint + 1; sna32LTE(tsn, sack.cumulativeTSNAck); tsn++ {
+		isOriginal, inflight := original(tsn)
+		if isOriginal {
+			return true
+		}
+		if !inflight {
+			return false
+		}
+	}
+	for _, g := range sack.gapAckBlocks {
+		for i := uint32(g.start); i <= uint32(g.end); i++ {
+			isOriginal, inflight := original(sack.cumulativeTSNAck + i)
+			if isOriginal {
+				return true
+			}
+			if !inflight {
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// processFRTO runs the F-RTO steps on a SACK received after a T3-rtx
+// timeout. RFC 5682 section 2.1, adapted to SCTP:
+//   - The first SACK must advance the cumulative TSN ack point, but not
+//     acknowledge everything sent before the timeout, which would not tell
+//     the retransmissions and the original transmissions apart.
+//   - The second SACK must advance the cumulative TSN ack point and
+//     acknowledge DATA that was not retransmitted after the timeout. The
+//     timeout is then spurious: cwnd and ssthresh are restored, and the
+//     DATA not retransmitted yet is not retransmitted.
+//
+// Otherwise the conventional recovery goes on.
+// The caller should hold the lock.
+func (a *Association) processFRTO(cumTSNAckPointAdvanced, originalsAcked bool) {
+	switch a.frto.phase {
+	case frtoIdle:
+	case frtoFirstSack:
+		if !cumTSNAckPointAdvanced || sna32GTE(a.cumulativeTSNAckPoint, a.frto.recover) {
+			a.frto.phase = frtoIdle
+
+			return
+		}
+		a.frto.phase = frtoSecondSack
+	case frtoSecondSack:
+		a.frto.phase = frtoIdle
+		if !cumTSNAckPointAdvanced || !originalsAcked {
+			return
+		}
+
+		a.stats.incSpuriousRTOs()
+		a.log.Debugf("[%s] F-RTO: spurious timeout, restoring cwnd=%d ssthresh=%d",
+			a.name, a.frto.cwnd, a.frto.ssthresh)
+		a.setCWND(max32(a.frto.cwnd, a.CWND()))
+		a.ssthresh = a.frto.ssthresh
+		a.inflightQueue.unmarkRetransmitBefore(a.rtoGeneration)
+	}
+}
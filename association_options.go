@@ -5,6 +5,7 @@ package sctp
 
 import (
 	"net"
+	"time"
 
 	"github.com/pion/logging"
 )
@@ -84,6 +85,143 @@ func WithEnableZeroChecksum(b bool) AssociationOption {
 	})
 }
 
+// WithZeroChecksumEDMID sets the RFC 9653 Error Detection Method Identifier
+// used for zero checksum negotiation for the association.
+// By default this is ZeroChecksumEDMIDDTLS.
+func WithZeroChecksumEDMID(edmid uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if edmid == zeroChecksumEDMIDReserved {
+			return errInvalidZeroChecksumEDMID
+		}
+		c.ZeroChecksumEDMID = edmid
+
+		return nil
+	})
+}
+
+// WithEnableECN sets whether the association should negotiate explicit congestion notification.
+// By default this is false.
+func WithEnableECN(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.EnableECN = b
+
+		return nil
+	})
+}
+
+// WithTransport sets the transport carrying the association's packets,
+// replacing the net.Conn.
+// By default the net.Conn set by WithNetConn is used.
+func WithTransport(transport Transport) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.Transport = transport
+
+		return nil
+	})
+}
+
+// WithClock sets the clock scheduling the association's retransmission and
+// delayed ack timers.
+// By default the system clock is used.
+func WithClock(clock Clock) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.Clock = clock
+
+		return nil
+	})
+}
+
+// WithCookieGenerator sets the generator of the State Cookie sent in the
+// INIT ACK, which also validates the cookie echoed back by the peer.
+// By default a random cookie is used.
+func WithCookieGenerator(generator CookieGenerator) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.CookieGenerator = generator
+
+		return nil
+	})
+}
+
+// WithCookieLifetime sets how long the State Cookie of an INIT ACK is valid.
+// By default this is 60 seconds.
+func WithCookieLifetime(lifetime time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if lifetime <= 0 {
+			return errInvalidCookieLifetime
+		}
+		c.CookieLifetime = lifetime
+
+		return nil
+	})
+}
+
+// WithEnableTimestamps sets whether the association should negotiate the TIMESTAMP
+// chunk used to measure RTT from echoed timestamps.
+// By default this is false.
+func WithEnableTimestamps(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.EnableTimestamps = b
+
+		return nil
+	})
+}
+
+// WithLenientChunkParsing sets whether the well-formed chunks of a received
+// packet are processed when another chunk of the packet is malformed.
+// By default this is false and such packets are dropped.
+func WithLenientChunkParsing(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.LenientChunkParsing = b
+
+		return nil
+	})
+}
+
+// WithIgnoreInboundChecksum sets whether received packets with a checksum
+// mismatch are processed instead of dropped. UNSAFE: this is a diagnostic
+// aid for middleboxes that mangle checksums, see Config.IgnoreInboundChecksum.
+// By default this is false.
+func WithIgnoreInboundChecksum(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.IgnoreInboundChecksum = b
+
+		return nil
+	})
+}
+
+// WithEnableDwellTimeStats sets whether the time DATA chunks wait in the
+// pending queue and in flight is recorded in the association stats.
+// By default this is false.
+func WithEnableDwellTimeStats(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.EnableDwellTimeStats = b
+
+		return nil
+	})
+}
+
+// WithEnableInstantRTT sets whether the RTT is sampled on every SACK for
+// Association.InstantRTT, without affecting the RTO.
+// By default this is false.
+func WithEnableInstantRTT(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.EnableInstantRTT = b
+
+		return nil
+	})
+}
+
+// WithVerificationTagCheck sets whether received packets with an unexpected
+// verification tag are discarded, see Config.DisableVerificationTagCheck.
+// By default this is true.
+func WithVerificationTagCheck(enabled bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.DisableVerificationTagCheck = !enabled
+
+		return nil
+	})
+}
+
 // WithEnableInterleaving sets whether the association should negotiate message interleaving.
 // By default this is true.
 func WithEnableInterleaving(b bool) AssociationOption {
@@ -108,6 +246,20 @@ func WithMTU(size uint32) AssociationOption {
 	})
 }
 
+// WithReceiveMTU sets the size of the buffer inbound packets are read into.
+// Larger packets are dropped. It must be at least 1200.
+// By default this is 8192.
+func WithReceiveMTU(size uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if size < minReceiveMTU {
+			return errReceiveMTUTooSmall
+		}
+		c.ReceiveMTU = size
+
+		return nil
+	})
+}
+
 // Congestion control options //
 
 // WithMaxReceiveBufferSize sets the maximum receive buffer size for the association.
@@ -123,6 +275,49 @@ func WithMaxReceiveBufferSize(size uint32) AssociationOption {
 	})
 }
 
+// WithAvgChunkSizeHint sets the expected average size of received DATA
+// chunks, which bounds how many TSNs beyond the cumulative TSN are queued.
+// Lower it for traffic of small messages. By default this is 500 bytes.
+func WithAvgChunkSizeHint(size uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if size == 0 {
+			return errZeroAvgChunkSizeHint
+		}
+		c.AvgChunkSizeHint = size
+
+		return nil
+	})
+}
+
+// WithAdvertisedRWND sets the receiver window advertised in INIT and INIT ACK
+// for the association. It must not exceed the maximum receive buffer size.
+// By default this is the maximum receive buffer size.
+func WithAdvertisedRWND(rwnd uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if rwnd == 0 {
+			return errZeroAdvertisedRWND
+		}
+		c.AdvertisedRWND = rwnd
+
+		return nil
+	})
+}
+
+// WithReceiveBufferAutotune enables receive buffer autotuning for the
+// association: the receive buffer starts at the MaxReceiveBufferSize and grows
+// with the measured throughput up to maxSize.
+// By default autotuning is disabled.
+func WithReceiveBufferAutotune(maxSize uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxSize == 0 {
+			return errZeroReceiveBufferAutotuneMax
+		}
+		c.ReceiveBufferAutotuneMax = maxSize
+
+		return nil
+	})
+}
+
 // WithMaxMessageSize sets the maximum message size for the association.
 // By default this is 65536.
 func WithMaxMessageSize(size uint32) AssociationOption {
@@ -148,7 +343,219 @@ func WithRTOMax(rtoMax float64) AssociationOption {
 	})
 }
 
+// WithMinT3RTX sets the minimum T3-rtx timeout in ms for the association.
+// The T3-rtx timer never fires before this floor even if the computed RTO is
+// lower. By default no floor is applied.
+func WithMinT3RTX(minT3RTX float64) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if minT3RTX < 0 {
+			return errInvalidMinT3RTX
+		}
+		c.MinT3RTX = minT3RTX
+
+		return nil
+	})
+}
+
+// WithRTOJitter randomizes each retransmission timeout by up to +/- jitter
+// times the RTO, e.g. 0.1 for +/-10%, so that associations sharing a path do
+// not retransmit in lock step. The timeout never drops below RTO.Min.
+// By default no jitter is applied.
+func WithRTOJitter(jitter float64) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if jitter < 0 || jitter >= 1 {
+			return errInvalidRTOJitter
+		}
+		c.RTOJitter = jitter
+
+		return nil
+	})
+}
+
+// WithPeerStallTimeout sets how long the peer may advertise a zero receiver
+// window without accepting any data before the callback set by
+// WithOnPeerStalled is called.
+// By default this is 0, which disables the check.
+func WithPeerStallTimeout(timeout time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if timeout < 0 {
+			return errInvalidPeerStallTimeout
+		}
+		c.PeerStallTimeout = timeout
+
+		return nil
+	})
+}
+
+// WithReassemblyTimeout sets how long a partially received message may wait
+// for its remaining fragments before it is discarded, see
+// Config.ReassemblyTimeout. This deviates from reliable delivery, so it
+// should only be used to protect against misbehaving peers.
+// By default this is 0, which keeps incomplete messages until completed.
+func WithReassemblyTimeout(timeout time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if timeout < 0 {
+			return errInvalidReassemblyTimeout
+		}
+		c.ReassemblyTimeout = timeout
+
+		return nil
+	})
+}
+
+// WithHeartbeatInterval sets the idle heartbeat interval for the association.
+// By default this is 0, which disables idle heartbeats. A non-zero interval
+// must be at least one millisecond.
+func WithHeartbeatInterval(interval time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if interval < 0 {
+			return errInvalidHeartbeatInterval
+		}
+		if interval > 0 && interval < time.Millisecond {
+			return errHeartbeatIntervalTooSmall
+		}
+		c.HeartbeatInterval = interval
+
+		return nil
+	})
+}
+
+// WithHeartbeatMaxRetrans sets how many consecutive idle heartbeats may go
+// unacknowledged before the association is closed.
+// By default this is 5.
+func WithHeartbeatMaxRetrans(maxRetrans uint) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxRetrans == 0 {
+			return errZeroHeartbeatMaxRetrans
+		}
+		c.HeartbeatMaxRetrans = maxRetrans
+
+		return nil
+	})
+}
+
+// WithMaxInitRetransmits sets how many times INIT or COOKIE ECHO is
+// retransmitted before the handshake fails.
+// By default this is 8.
+func WithMaxInitRetransmits(maxRetrans uint) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxRetrans == 0 {
+			return errZeroMaxInitRetransmits
+		}
+		c.MaxInitRetransmits = maxRetrans
+
+		return nil
+	})
+}
+
+// WithMaxShutdownRetrans sets how many times SHUTDOWN or SHUTDOWN ACK is
+// retransmitted before the association is closed.
+// By default this is 10.
+func WithMaxShutdownRetrans(maxRetrans uint) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxRetrans == 0 {
+			return errZeroMaxShutdownRetrans
+		}
+		c.MaxShutdownRetrans = maxRetrans
+
+		return nil
+	})
+}
+
+// WithMaxReconfigRetrans sets how many times a RECONFIG stream reset
+// request is retransmitted before it is given up and the streams being reset
+// are closed with ErrStreamResetTimeout.
+// By default this is 0, which retransmits until the peer responds.
+func WithMaxReconfigRetrans(maxRetrans uint) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.MaxReconfigRetrans = maxRetrans
+
+		return nil
+	})
+}
+
+// WithReconfigRequestLifetime sets how long an incoming stream reset request
+// is kept, counted from when it was first received.
+// By default this is 30 seconds.
+func WithReconfigRequestLifetime(lifetime time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if lifetime <= 0 {
+			return errInvalidReconfigRequestLifetime
+		}
+		c.ReconfigRequestLifetime = lifetime
+
+		return nil
+	})
+}
+
+// WithMaxReconfigRequests sets how many incoming stream reset requests
+// are kept while waiting for the data sent before them.
+// By default this is 1000.
+func WithMaxReconfigRequests(maxRequests uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxRequests == 0 {
+			return errZeroMaxReconfigRequests
+		}
+		c.MaxReconfigRequests = maxRequests
+
+		return nil
+	})
+}
+
+// WithMaxStreams caps the number of streams open at the same time, see
+// Config.MaxStreams.
+// By default this is 0, which means no limit.
+func WithMaxStreams(maxStreams uint16) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.MaxStreams = maxStreams
+
+		return nil
+	})
+}
+
+// WithProfile sets the latency/throughput profile for the association.
+// By default this is ProfileDefault.
+func WithProfile(profile Profile) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if profile > ProfileThroughput {
+			return errInvalidProfile
+		}
+		c.Profile = profile
+
+		return nil
+	})
+}
+
+// WithSackPolicy sets when SACKs for received DATA are sent.
+// By default this is SackPolicyDelayed.
+func WithSackPolicy(policy SackPolicy) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if policy > SackPolicyBundle {
+			return errInvalidSackPolicy
+		}
+		c.SackPolicy = policy
+
+		return nil
+	})
+}
+
+// WithSackFrequency sets how many packets carrying DATA are received before
+// a delayed SACK is sent without waiting for the delayed-ack timer.
+// By default this is set by the Profile, 2 for ProfileDefault.
+func WithSackFrequency(n int) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if n < 1 {
+			return errInvalidSackFrequency
+		}
+		c.SackFrequency = n
+
+		return nil
+	})
+}
+
 // WithMinCwnd sets the minimum congestion window for the association.
+// The floor also applies after loss, so a value at or above ssthresh
+// disables the congestion window reduction of fast recovery.
 func WithMinCwnd(minCwnd uint32) AssociationOption {
 	return sharedOption(func(c *Config) error {
 		c.MinCwnd = minCwnd
@@ -157,6 +564,34 @@ func WithMinCwnd(minCwnd uint32) AssociationOption {
 	})
 }
 
+// WithInitialCwnd sets the initial congestion window, for example to
+// 10*MTU. It must not be below the minimum congestion window.
+// By default this is min(4*MTU, max(2*MTU, 4380)).
+func WithInitialCwnd(initialCwnd uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if initialCwnd == 0 {
+			return errZeroInitialCwnd
+		}
+		c.InitialCwnd = initialCwnd
+
+		return nil
+	})
+}
+
+// WithMaxCwnd caps the growth of the congestion window. It must not be
+// below the minimum or initial congestion window.
+// By default the congestion window is not capped.
+func WithMaxCwnd(maxCwnd uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if maxCwnd == 0 {
+			return errZeroMaxCwnd
+		}
+		c.MaxCwnd = maxCwnd
+
+		return nil
+	})
+}
+
 // WithFastRtxWnd sets the fast retransmission window for the association.
 func WithFastRtxWnd(fastRtxWnd uint32) AssociationOption {
 	return sharedOption(func(c *Config) error {
@@ -175,6 +610,114 @@ func WithCwndCAStep(cwndCAStep uint32) AssociationOption {
 	})
 }
 
+// WithByteCountingLimit enables appropriate byte counting (RFC 3465) in
+// slow start, growing cwnd by at most limit MTUs per SACK.
+// By default this is 0 and cwnd grows by up to the acknowledged bytes.
+func WithByteCountingLimit(limit uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.ByteCountingLimit = limit
+
+		return nil
+	})
+}
+
+// WithFinalSack sets whether Close first sends the SACK being delayed, if
+// any, so that the peer does not retransmit data that was received. Close
+// waits at most 50ms for it.
+// By default this is enabled.
+func WithFinalSack(enabled bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.DisableFinalSack = !enabled
+
+		return nil
+	})
+}
+
+// WithIdleCwndReset enables or disables the reset of the congestion window
+// to its initial value after an idle period (RFC 9260 section 7.2.1).
+// By default this is enabled.
+func WithIdleCwndReset(enabled bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.DisableIdleCwndReset = !enabled
+
+		return nil
+	})
+}
+
+// WithEnableFRTO sets whether spurious T3-rtx timeouts are detected in the
+// manner of F-RTO (RFC 5682), restoring the congestion window after them.
+// By default this is false.
+func WithEnableFRTO(b bool) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.EnableFRTO = b
+
+		return nil
+	})
+}
+
+// WithIdleCwndResetTimeout sets how long no new DATA must be sent before the
+// congestion window is reset to its initial value.
+// By default this is 0, which uses the current RTO.
+func WithIdleCwndResetTimeout(timeout time.Duration) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if timeout < 0 {
+			return errInvalidIdleCwndResetTimeout
+		}
+		c.IdleCwndResetTimeout = timeout
+
+		return nil
+	})
+}
+
+// WithFastRetransmitThreshold sets how many SACKs must report a DATA chunk
+// missing before it is fast retransmitted.
+// By default this is 3.
+func WithFastRetransmitThreshold(threshold uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if threshold == 0 {
+			return errZeroFastRetransmitThreshold
+		}
+		c.FastRetransmitThreshold = threshold
+
+		return nil
+	})
+}
+
+// WithMaxOutstandingBytes caps the bytes of DATA in flight for the association,
+// independent of the congestion and receiver windows.
+// By default this is 0 (unlimited).
+func WithMaxOutstandingBytes(maxOutstandingBytes uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.MaxOutstandingBytes = maxOutstandingBytes
+
+		return nil
+	})
+}
+
+// WithMaxBurst caps the number of packets of new DATA sent at once.
+// By default this is 0 (unlimited).
+func WithMaxBurst(maxBurst uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		c.MaxBurst = maxBurst
+
+		return nil
+	})
+}
+
+// WithWriteBatchSize caps the number of packets the write loop writes
+// before checking whether the association is closing. It cannot be zero.
+// By default this is 64.
+func WithWriteBatchSize(size uint32) AssociationOption {
+	return sharedOption(func(c *Config) error {
+		if size == 0 {
+			return errZeroWriteBatchSize
+		}
+		c.WriteBatchSize = size
+
+		return nil
+	})
+}
+
 // WithSNAP enables SNAP, https://datatracker.ietf.org/doc/draft-hancke-tsvwg-snap/.
 func WithSNAP(localSctpInit []byte, remoteSctpInit []byte) AssociationOption {
 	return sharedOption(func(c *Config) error {
@@ -53,6 +53,17 @@ func TestAssociationOptions_Interleaving(t *testing.T) {
 	assert.True(t, disabledCfg.enableInterleavingSet)
 }
 
+func TestAssociationOptions_TransportAndClock(t *testing.T) {
+	transport := newChanTransport()
+	clock := &fakeClock{}
+
+	cfg, err := buildClientConfig(WithTransport(transport), WithClock(clock))
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.NetConn)
+	assert.Equal(t, transport, cfg.Transport)
+	assert.Equal(t, clock, cfg.Clock)
+}
+
 func TestAssociationOptions_Validation(t *testing.T) {
 	t.Run("nil logger factory", func(t *testing.T) {
 		var cfg Config
@@ -78,6 +89,12 @@ func TestAssociationOptions_Validation(t *testing.T) {
 		assert.ErrorIs(t, err, errZeroMaxReceiveBufferOption)
 	})
 
+	t.Run("avg chunk size hint zero", func(t *testing.T) {
+		var cfg Config
+		err := WithAvgChunkSizeHint(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroAvgChunkSizeHint)
+	})
+
 	t.Run("max msg size zero", func(t *testing.T) {
 		var cfg Config
 		err := WithMaxMessageSize(0).applyServer(&cfg)
@@ -90,6 +107,130 @@ func TestAssociationOptions_Validation(t *testing.T) {
 		assert.ErrorIs(t, err, errInvalidRTOMax)
 	})
 
+	t.Run("min t3-rtx < 0", func(t *testing.T) {
+		var cfg Config
+		err := WithMinT3RTX(-1).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidMinT3RTX)
+	})
+
+	t.Run("rto jitter out of range", func(t *testing.T) {
+		var cfg Config
+		assert.ErrorIs(t, WithRTOJitter(-0.1).applyServer(&cfg), errInvalidRTOJitter)
+		assert.ErrorIs(t, WithRTOJitter(1).applyServer(&cfg), errInvalidRTOJitter)
+	})
+
+	t.Run("peer stall timeout < 0", func(t *testing.T) {
+		var cfg Config
+		err := WithPeerStallTimeout(-time.Second).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidPeerStallTimeout)
+	})
+
+	t.Run("reassembly timeout < 0", func(t *testing.T) {
+		var cfg Config
+		err := WithReassemblyTimeout(-time.Second).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidReassemblyTimeout)
+	})
+
+	t.Run("heartbeat interval < 0", func(t *testing.T) {
+		var cfg Config
+		err := WithHeartbeatInterval(-time.Second).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidHeartbeatInterval)
+	})
+
+	t.Run("heartbeat interval < 1ms", func(t *testing.T) {
+		var cfg Config
+		err := WithHeartbeatInterval(time.Microsecond).applyServer(&cfg)
+		assert.ErrorIs(t, err, errHeartbeatIntervalTooSmall)
+	})
+
+	t.Run("heartbeat max retrans zero", func(t *testing.T) {
+		var cfg Config
+		err := WithHeartbeatMaxRetrans(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroHeartbeatMaxRetrans)
+	})
+
+	t.Run("advertised rwnd zero", func(t *testing.T) {
+		var cfg Config
+		err := WithAdvertisedRWND(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroAdvertisedRWND)
+	})
+
+	t.Run("advertised rwnd above receive buffer", func(t *testing.T) {
+		_, err := buildServerConfig(WithNetConn(&dumbConn{}), WithMaxReceiveBufferSize(1000), WithAdvertisedRWND(1001))
+		assert.ErrorIs(t, err, errAdvertisedRWNDTooLarge)
+
+		_, err = buildClientConfig(WithNetConn(&dumbConn{}), WithMaxReceiveBufferSize(1000), WithAdvertisedRWND(1000))
+		assert.NoError(t, err)
+	})
+
+	t.Run("receive buffer autotune zero", func(t *testing.T) {
+		var cfg Config
+		err := WithReceiveBufferAutotune(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroReceiveBufferAutotuneMax)
+	})
+
+	t.Run("max init retransmits zero", func(t *testing.T) {
+		var cfg Config
+		err := WithMaxInitRetransmits(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroMaxInitRetransmits)
+	})
+
+	t.Run("max shutdown retrans zero", func(t *testing.T) {
+		var cfg Config
+		err := WithMaxShutdownRetrans(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroMaxShutdownRetrans)
+	})
+
+	t.Run("max reconfig requests zero", func(t *testing.T) {
+		var cfg Config
+		err := WithMaxReconfigRequests(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroMaxReconfigRequests)
+	})
+
+	t.Run("reconfig request lifetime zero", func(t *testing.T) {
+		var cfg Config
+		err := WithReconfigRequestLifetime(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidReconfigRequestLifetime)
+	})
+
+	t.Run("fast retransmit threshold zero", func(t *testing.T) {
+		var cfg Config
+		err := WithFastRetransmitThreshold(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroFastRetransmitThreshold)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		var cfg Config
+		err := WithProfile(ProfileThroughput + 1).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidProfile)
+	})
+
+	t.Run("unknown sack policy", func(t *testing.T) {
+		var cfg Config
+		err := WithSackPolicy(SackPolicyBundle + 1).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidSackPolicy)
+
+		bad := Config{SackPolicy: SackPolicyBundle + 1}
+		assert.ErrorIs(t, bad.applyServer(&cfg), errInvalidSackPolicy)
+		assert.ErrorIs(t, bad.applyClient(&cfg), errInvalidSackPolicy)
+	})
+
+	t.Run("sack frequency zero", func(t *testing.T) {
+		var cfg Config
+		err := WithSackFrequency(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidSackFrequency)
+
+		bad := Config{SackFrequency: -1}
+		assert.ErrorIs(t, bad.applyServer(&cfg), errInvalidSackFrequency)
+		assert.ErrorIs(t, bad.applyClient(&cfg), errInvalidSackFrequency)
+	})
+
+	t.Run("reserved zero checksum edmid", func(t *testing.T) {
+		var cfg Config
+		err := WithZeroChecksumEDMID(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidZeroChecksumEDMID)
+	})
+
 	t.Run("snap nil arguments", func(t *testing.T) {
 		var cfg Config
 		err := WithSNAP(nil, nil).applyServer(&cfg)
@@ -111,6 +252,72 @@ func TestAssociationOptions_Validation(t *testing.T) {
 		).applyServer(&cfg)
 		assert.ErrorIs(t, err, errInvalidStreamSchedulerWeight)
 	})
+
+	t.Run("initial cwnd zero", func(t *testing.T) {
+		var cfg Config
+		err := WithInitialCwnd(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroInitialCwnd)
+	})
+
+	t.Run("max cwnd zero", func(t *testing.T) {
+		var cfg Config
+		err := WithMaxCwnd(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroMaxCwnd)
+	})
+
+	t.Run("idle cwnd reset timeout < 0", func(t *testing.T) {
+		var cfg Config
+		err := WithIdleCwndResetTimeout(-time.Second).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidIdleCwndResetTimeout)
+	})
+
+	t.Run("write batch size zero", func(t *testing.T) {
+		var cfg Config
+		err := WithWriteBatchSize(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errZeroWriteBatchSize)
+
+		cfg2, err := buildClientConfig(WithNetConn(&dumbConn{}), WithWriteBatchSize(8))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(8), cfg2.WriteBatchSize)
+	})
+
+	t.Run("receive mtu too small", func(t *testing.T) {
+		var cfg Config
+		err := WithReceiveMTU(1199).applyServer(&cfg)
+		assert.ErrorIs(t, err, errReceiveMTUTooSmall)
+
+		_, err = buildClientConfig(WithNetConn(&dumbConn{}), Config{ReceiveMTU: 1000})
+		assert.ErrorIs(t, err, errReceiveMTUTooSmall)
+
+		cfg2, err := buildServerConfig(WithNetConn(&dumbConn{}), WithReceiveMTU(1200))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(1200), cfg2.ReceiveMTU)
+	})
+
+	t.Run("cookie lifetime <= 0", func(t *testing.T) {
+		var cfg Config
+		err := WithCookieLifetime(0).applyServer(&cfg)
+		assert.ErrorIs(t, err, errInvalidCookieLifetime)
+	})
+
+	t.Run("cwnd limits", func(t *testing.T) {
+		conn := &dumbConn{}
+
+		_, err := buildClientConfig(WithNetConn(conn), WithMinCwnd(5000), WithInitialCwnd(4000))
+		assert.ErrorIs(t, err, errInitialCwndBelowMinCwnd)
+
+		_, err = buildServerConfig(WithNetConn(conn), WithMinCwnd(5000), WithMaxCwnd(4000))
+		assert.ErrorIs(t, err, errMaxCwndTooSmall)
+
+		_, err = buildClientConfig(WithNetConn(conn), WithInitialCwnd(20000), WithMaxCwnd(10000))
+		assert.ErrorIs(t, err, errMaxCwndTooSmall)
+
+		cfg, err := buildServerConfig(WithNetConn(conn), WithMinCwnd(5000), WithInitialCwnd(12000),
+			WithMaxCwnd(12000))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(12000), cfg.InitialCwnd)
+		assert.Equal(t, uint32(12000), cfg.MaxCwnd)
+	})
 }
 
 func TestClientWithOptions_ValidatesOptionValues(t *testing.T) {
@@ -163,14 +370,45 @@ func TestAssociationOptions_ClientAndServer(t *testing.T) {
 
 		WithMTU(1200),
 		WithMaxReceiveBufferSize(7777),
+		WithAvgChunkSizeHint(20),
 		WithMaxMessageSize(30000),
 		WithRTOMax(1000),
 		WithMinCwnd(5000),
+		WithInitialCwnd(12000),
+		WithMaxCwnd(50000),
 		WithFastRtxWnd(6000),
 		WithCwndCAStep(7000),
+		WithByteCountingLimit(2),
+		WithIdleCwndReset(false),
+		WithFinalSack(false),
+		WithIdleCwndResetTimeout(time.Second),
+		WithEnableFRTO(true),
+		WithFastRetransmitThreshold(2),
+		WithMaxOutstandingBytes(8000),
+		WithMaxBurst(4),
+		WithMinT3RTX(300),
+		WithRTOJitter(0.2),
+		WithPeerStallTimeout(time.Minute),
+		WithReassemblyTimeout(time.Minute),
+		WithOnPeerStalled(func() {}),
+		WithHeartbeatInterval(time.Minute),
+		WithHeartbeatMaxRetrans(3),
+		WithMaxInitRetransmits(3),
+		WithMaxShutdownRetrans(4),
+		WithMaxReconfigRetrans(6),
+		WithMaxReconfigRequests(50),
+		WithReconfigRequestLifetime(time.Minute),
+		WithMaxStreams(100),
+		WithSackPolicy(SackPolicyBundle),
+		WithSackFrequency(8),
 		WithBlockWrite(true),
 		WithEnableZeroChecksum(true),
 		WithEnableInterleaving(false),
+		WithLenientChunkParsing(true),
+		WithIgnoreInboundChecksum(true),
+		WithEnableDwellTimeStats(true),
+		WithEnableInstantRTT(true),
+		WithVerificationTagCheck(false),
 	)
 	assert.NoError(t, err)
 	defer func() {
@@ -188,13 +426,48 @@ func TestAssociationOptions_ClientAndServer(t *testing.T) {
 
 	assert.Equal(t, uint32(7777), aClient.maxReceiveBufferSize)
 	assert.Equal(t, uint32(7777), aServer.maxReceiveBufferSize)
+	assert.Equal(t, uint32(20), aClient.avgChunkSize)
+	assert.Equal(t, uint32(20), aServer.avgChunkSize)
 
 	assert.Equal(t, uint32(30000), aClient.MaxMessageSize())
 	assert.Equal(t, uint32(30000), aServer.MaxMessageSize())
 
 	assert.Equal(t, uint32(5000), aClient.minCwnd)
+	assert.Equal(t, uint32(50000), aClient.maxCwnd)
+	assert.LessOrEqual(t, aClient.CWND(), uint32(50000))
 	assert.Equal(t, uint32(6000), aClient.fastRtxWnd)
 	assert.Equal(t, uint32(7000), aClient.cwndCAStep)
+	assert.Equal(t, uint32(2), aClient.byteCountingLimit)
+	assert.False(t, aClient.idleCwndReset)
+	assert.False(t, aServer.finalSack)
+	assert.Equal(t, time.Second, aClient.idleCwndResetTimeout)
+	assert.True(t, aClient.frtoEnabled)
+	assert.True(t, aServer.frtoEnabled)
+	assert.Equal(t, uint32(2), aClient.fastRtxThreshold)
+	assert.Equal(t, uint32(8000), aClient.maxOutstandingBytes)
+	assert.Equal(t, uint32(4), aClient.maxBurst)
+	assert.Equal(t, float64(300), aClient.minT3RTX)
+	assert.Equal(t, 0.2, aClient.t3RTX.jitter)
+	assert.Equal(t, 0.2, aServer.t1Cookie.jitter)
+	assert.Zero(t, aClient.tHeartbeat.jitter)
+	assert.Equal(t, time.Minute, aClient.peerStallTimeout)
+	assert.Equal(t, time.Minute, aServer.reassemblyTimeout)
+	assert.NotNil(t, aServer.onPeerStalled)
+	assert.Equal(t, float64(60000), aClient.heartbeatInterval)
+	assert.Equal(t, uint(3), aClient.tHeartbeat.maxRetrans)
+	assert.True(t, aClient.tHeartbeat.isRunning())
+	assert.Equal(t, uint(3), aClient.t1Init.maxRetrans)
+	assert.Equal(t, uint(3), aServer.t1Cookie.maxRetrans)
+	assert.Equal(t, uint(4), aClient.t2Shutdown.maxRetrans)
+	assert.Equal(t, uint(6), aClient.tReconfig.maxRetrans)
+	assert.Equal(t, 50, aClient.maxReconfigRequests)
+	assert.Equal(t, time.Minute, aClient.reconfigRequestLifetime)
+	assert.Equal(t, uint16(100), aClient.maxStreams)
+	assert.Equal(t, uint16(100), aServer.myMaxNumInboundStreams, "negotiated down to the cap")
+	assert.Equal(t, SackPolicyBundle, aClient.sackPolicy)
+	assert.Equal(t, SackPolicyBundle, aServer.sackPolicy)
+	assert.Equal(t, 8, aClient.sackFreq)
+	assert.Equal(t, 8, aServer.sackFreq)
 
 	assert.True(t, aClient.blockWrite)
 	assert.True(t, aServer.blockWrite)
@@ -202,6 +475,18 @@ func TestAssociationOptions_ClientAndServer(t *testing.T) {
 	assert.True(t, aClient.recvZeroChecksum)
 	assert.True(t, aServer.recvZeroChecksum)
 
+	assert.True(t, aClient.lenientChunkParsing)
+	assert.True(t, aServer.lenientChunkParsing)
+
+	assert.True(t, aClient.ignoreInboundChecksum)
+	assert.True(t, aServer.ignoreInboundChecksum)
+	assert.True(t, aClient.dwellTimeStats)
+	assert.True(t, aServer.dwellTimeStats)
+	assert.True(t, aClient.instantRTTEnabled)
+	assert.True(t, aServer.instantRTTEnabled)
+	assert.False(t, aClient.verificationTagCheck)
+	assert.False(t, aServer.verificationTagCheck)
+
 	assert.Equal(t, uint32(1200)-(commonHeaderSize+dataChunkHeaderSize), aClient.maxPayloadSize)
 	assert.Equal(t, uint32(1200)-(commonHeaderSize+dataChunkHeaderSize), aServer.maxPayloadSize)
 
@@ -210,3 +495,63 @@ func TestAssociationOptions_ClientAndServer(t *testing.T) {
 
 	time.Sleep(10 * time.Millisecond)
 }
+
+func TestAssociationOptions_AdvertisedRWND(t *testing.T) {
+	aClient, aServer, err := association(t, udpPiper, WithAdvertisedRWND(5000))
+	assert.NoError(t, err)
+	defer func() {
+		_ = aClient.Close()
+		_ = aServer.Close()
+	}()
+
+	assert.Equal(t, uint32(5000), aClient.RWND(), "INIT ACK should advertise the configured rwnd")
+	assert.Equal(t, uint32(5000), aServer.RWND(), "INIT should advertise the configured rwnd")
+	assert.Equal(t, uint32(initialRecvBufSize), aServer.maxReceiveBufferSize, "actual buffer should be unchanged")
+}
+
+func TestAssociationOptions_CookieGenerator(t *testing.T) {
+	gen := &hmacCookieGenerator{key: []byte("secret")}
+
+	aClient, aServer, err := association(t, udpPiper, WithCookieGenerator(gen), WithCookieLifetime(time.Minute))
+	assert.NoError(t, err)
+	defer func() {
+		_ = aClient.Close()
+		_ = aServer.Close()
+	}()
+
+	assert.Equal(t, gen, aServer.cookieGenerator)
+	assert.Equal(t, time.Minute, aServer.cookieLifetime)
+	assert.Positive(t, gen.generated.Load(), "INIT ACK should carry a generated cookie")
+	assert.Positive(t, gen.validated.Load(), "COOKIE ECHO should be validated by the generator")
+}
+
+func TestAssociationOptions_Profile(t *testing.T) {
+	for _, tc := range []struct {
+		profile  Profile
+		ackDelay time.Duration
+		sackFreq int
+		nagle    bool
+	}{
+		{ProfileDefault, 200 * time.Millisecond, 2, false},
+		{ProfileLatency, 0, 1, false},
+		{ProfileThroughput, 200 * time.Millisecond, 4, true},
+	} {
+		t.Run(tc.profile.String(), func(t *testing.T) {
+			ca, cb := net.Pipe()
+			defer func() {
+				_ = cb.Close()
+			}()
+
+			cfg, err := buildServerConfig(WithNetConn(ca), WithProfile(tc.profile))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.profile, cfg.Profile)
+
+			assoc := createAssociationFromConfigWithTsn(cfg, 1)
+			defer assoc.close() //nolint:errcheck
+
+			assert.Equal(t, tc.ackDelay, assoc.ackTimer.interval)
+			assert.Equal(t, tc.sackFreq, assoc.sackFreq)
+			assert.Equal(t, tc.nagle, assoc.nagle)
+		})
+	}
+}
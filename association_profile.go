@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"time"
+)
+
+// Profile selects a set of acknowledgement and sending defaults that trade
+// latency against throughput.
+//
+// The settings applied by each profile are:
+//
+//	Profile            delayed-ack timeout  SACK frequency   Nagle
+//	ProfileDefault     200 ms               every 2 packets  off
+//	ProfileLatency     none                 every packet     off
+//	ProfileThroughput  200 ms               every 4 packets  on
+//
+// SACK frequency is the number of packets carrying DATA that are received
+// before a SACK is sent without waiting for the delayed-ack timeout. With
+// Nagle enabled, new DATA is held back while earlier DATA is outstanding
+// until at least one full packet of user data is queued.
+//
+// This package does not pace transmissions, so no profile changes how
+// packets allowed by the congestion window are spread over time.
+type Profile uint8
+
+const (
+	// ProfileDefault keeps the RFC 4960 recommended behavior.
+	ProfileDefault Profile = iota
+	// ProfileLatency minimizes delays by acknowledging every packet immediately.
+	ProfileLatency
+	// ProfileThroughput maximizes coalescing of SACKs and small messages.
+	ProfileThroughput
+)
+
+func (p Profile) String() string {
+	switch p {
+	case ProfileDefault:
+		return "Default"
+	case ProfileLatency:
+		return "Latency"
+	case ProfileThroughput:
+		return "Throughput"
+	default:
+		return "Unknown"
+	}
+}
+
+// profileSettings holds the underlying parameters configured by a Profile.
+type profileSettings struct {
+	// timeout of the delayed-ack timer; 0 when acks are never delayed
+	ackDelay time.Duration
+
+	// number of packets carrying DATA received before a SACK is sent immediately
+	sackFreq int
+
+	// hold back small writes while data is outstanding
+	nagle bool
+}
+
+func (p Profile) settings() profileSettings {
+	switch p {
+	case ProfileLatency:
+		return profileSettings{
+			ackDelay: 0,
+			sackFreq: 1,
+		}
+	case ProfileThroughput:
+		return profileSettings{
+			ackDelay: ackInterval,
+			sackFreq: 4,
+			nagle:    true,
+		}
+	default:
+		return profileSettings{
+			ackDelay: ackInterval,
+			sackFreq: 2,
+		}
+	}
+}
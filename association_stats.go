@@ -5,8 +5,48 @@ package sctp
 
 import (
 	"sync/atomic"
+	"time"
 )
 
+// AssociationStats is a snapshot of the counters of an association.
+type AssociationStats struct {
+	PacketsReceived uint64
+	PacketsSent     uint64
+	DATAs           uint64
+	SACKsReceived   uint64
+	SACKsSent       uint64
+	T3Timeouts      uint64
+	AckTimeouts     uint64
+	FastRetrans     uint64
+	Reneged         uint64
+	// ReceiveBufferFullDrops counts DATA chunks dropped because the receive
+	// buffer (MaxReceiveBufferSize) was full.
+	ReceiveBufferFullDrops uint64
+	// StreamLimitDrops counts DATA chunks dropped because they would have
+	// opened a stream beyond MaxStreams.
+	StreamLimitDrops uint64
+	// DuplicateTSNs counts the TSNs the peer reported in its SACKs as
+	// received more than once, a sign of spurious retransmissions.
+	DuplicateTSNs uint64
+	// SpuriousRTOs counts the T3-rtx timeouts detected as spurious, see
+	// Config.EnableFRTO.
+	SpuriousRTOs uint64
+	// GoodputBytesSent counts the user bytes cumulatively acknowledged by
+	// the peer, and GoodputBytesReceived the user bytes of the unique DATA
+	// chunks delivered to streams. Unlike the wire byte counters, they do
+	// not include headers or retransmissions.
+	GoodputBytesSent     uint64
+	GoodputBytesReceived uint64
+	// PendingDwell aggregates how long DATA chunks waited in the pending
+	// queue before being sent for the first time, and InflightDwell how
+	// long they then waited to be acknowledged. They are only recorded with
+	// Config.EnableDwellTimeStats.
+	PendingDwell  DwellTimeStats
+	InflightDwell DwellTimeStats
+	// Streams holds the counters of each open stream, by stream identifier.
+	Streams map[uint16]StreamStats
+}
+
 type associationStats struct {
 	nPacketsReceived uint64
 	nPacketsSent     uint64
@@ -16,6 +56,15 @@ type associationStats struct {
 	nT3Timeouts      uint64
 	nAckTimeouts     uint64
 	nFastRetrans     uint64
+	nReneged         uint64
+	nRecvBufFull     uint64
+	nStreamLimit     uint64
+	nDuplicateTSNs   uint64
+	nSpuriousRTOs    uint64
+	nGoodputSent     uint64
+	nGoodputReceived uint64
+	pendingDwell     dwellTimeStats
+	inflightDwell    dwellTimeStats
 }
 
 func (s *associationStats) incPacketsReceived() {
@@ -82,6 +131,84 @@ func (s *associationStats) getNumFastRetrans() uint64 {
 	return atomic.LoadUint64(&s.nFastRetrans)
 }
 
+func (s *associationStats) incReneged() {
+	atomic.AddUint64(&s.nReneged, 1)
+}
+
+func (s *associationStats) getNumReneged() uint64 {
+	return atomic.LoadUint64(&s.nReneged)
+}
+
+func (s *associationStats) incReceiveBufferFullDrops() {
+	atomic.AddUint64(&s.nRecvBufFull, 1)
+}
+
+func (s *associationStats) getNumReceiveBufferFullDrops() uint64 {
+	return atomic.LoadUint64(&s.nRecvBufFull)
+}
+
+func (s *associationStats) incStreamLimitDrops() {
+	atomic.AddUint64(&s.nStreamLimit, 1)
+}
+
+func (s *associationStats) getNumStreamLimitDrops() uint64 {
+	return atomic.LoadUint64(&s.nStreamLimit)
+}
+
+func (s *associationStats) addDuplicateTSNs(n int) {
+	atomic.AddUint64(&s.nDuplicateTSNs, uint64(n)) //nolint:gosec // G115, n is a length
+}
+
+func (s *associationStats) getNumDuplicateTSNs() uint64 {
+	return atomic.LoadUint64(&s.nDuplicateTSNs)
+}
+
+func (s *associationStats) incSpuriousRTOs() {
+	atomic.AddUint64(&s.nSpuriousRTOs, 1)
+}
+
+func (s *associationStats) getNumSpuriousRTOs() uint64 {
+	return atomic.LoadUint64(&s.nSpuriousRTOs)
+}
+
+func (s *associationStats) addGoodputBytesSent(n int) {
+	atomic.AddUint64(&s.nGoodputSent, uint64(n)) //nolint:gosec // G115, n is a length
+}
+
+func (s *associationStats) getGoodputBytesSent() uint64 {
+	return atomic.LoadUint64(&s.nGoodputSent)
+}
+
+func (s *associationStats) addGoodputBytesReceived(n int) {
+	atomic.AddUint64(&s.nGoodputReceived, uint64(n)) //nolint:gosec // G115, n is a length
+}
+
+func (s *associationStats) getGoodputBytesReceived() uint64 {
+	return atomic.LoadUint64(&s.nGoodputReceived)
+}
+
+func (s *associationStats) snapshot() AssociationStats {
+	return AssociationStats{
+		PacketsReceived:        s.getNumPacketsReceived(),
+		PacketsSent:            s.getNumPacketsSent(),
+		DATAs:                  s.getNumDATAs(),
+		SACKsReceived:          s.getNumSACKsReceived(),
+		SACKsSent:              s.getNumSACKsSent(),
+		T3Timeouts:             s.getNumT3Timeouts(),
+		AckTimeouts:            s.getNumAckTimeouts(),
+		FastRetrans:            s.getNumFastRetrans(),
+		Reneged:                s.getNumReneged(),
+		ReceiveBufferFullDrops: s.getNumReceiveBufferFullDrops(),
+		StreamLimitDrops:       s.getNumStreamLimitDrops(),
+		DuplicateTSNs:          s.getNumDuplicateTSNs(),
+		SpuriousRTOs:           s.getNumSpuriousRTOs(),
+		GoodputBytesSent:       s.getGoodputBytesSent(),
+		GoodputBytesReceived:   s.getGoodputBytesReceived(),
+		PendingDwell:           s.pendingDwell.snapshot(),
+		InflightDwell:          s.inflightDwell.snapshot(),
+	}
+}
+
 func (s *associationStats) reset() {
 	atomic.StoreUint64(&s.nPacketsReceived, 0)
 	atomic.StoreUint64(&s.nPacketsSent, 0)
@@ -91,4 +218,157 @@ func (s *associationStats) reset() {
 	atomic.StoreUint64(&s.nT3Timeouts, 0)
 	atomic.StoreUint64(&s.nAckTimeouts, 0)
 	atomic.StoreUint64(&s.nFastRetrans, 0)
+	atomic.StoreUint64(&s.nReneged, 0)
+	atomic.StoreUint64(&s.nRecvBufFull, 0)
+	atomic.StoreUint64(&s.nStreamLimit, 0)
+	atomic.StoreUint64(&s.nDuplicateTSNs, 0)
+	atomic.StoreUint64(&s.nSpuriousRTOs, 0)
+	atomic.StoreUint64(&s.nGoodputSent, 0)
+	atomic.StoreUint64(&s.nGoodputReceived, 0)
+	s.pendingDwell.reset()
+	s.inflightDwell.reset()
+}
+
+// DwellTimeStats is a snapshot of the time DATA chunks spent in a queue.
+type DwellTimeStats struct {
+	// Count is the number of DATA chunks measured.
+	Count uint64
+	Min   time.Duration
+	Avg   time.Duration
+	Max   time.Duration
+}
+
+type dwellTimeStats struct {
+	count uint64
+	total uint64 // nanoseconds
+	min   uint64 // nanoseconds plus one, zero when count is zero
+	max   uint64 // nanoseconds
+}
+
+func (s *dwellTimeStats) add(d time.Duration) {
+	nanos := uint64(max(d, 0)) //nolint:gosec // G115, d is not negative
+	atomic.AddUint64(&s.count, 1)
+	atomic.AddUint64(&s.total, nanos)
+	for {
+		curr := atomic.LoadUint64(&s.min)
+		if (curr != 0 && curr <= nanos+1) || atomic.CompareAndSwapUint64(&s.min, curr, nanos+1) {
+			break
+		}
+	}
+	for {
+		curr := atomic.LoadUint64(&s.max)
+		if curr >= nanos || atomic.CompareAndSwapUint64(&s.max, curr, nanos) {
+			break
+		}
+	}
+}
+
+func (s *dwellTimeStats) snapshot() DwellTimeStats {
+	stats := DwellTimeStats{Count: atomic.LoadUint64(&s.count)}
+	if stats.Count == 0 {
+		return stats
+	}
+	//nolint:gosec // G115, durations recorded fit in an int64
+	stats.Avg = time.Duration(atomic.LoadUint64(&s.total) / stats.Count)
+	if minNanos := atomic.LoadUint64(&s.min); minNanos != 0 {
+		stats.Min = time.Duration(minNanos - 1) //nolint:gosec // G115
+	}
+	stats.Max = time.Duration(atomic.LoadUint64(&s.max)) //nolint:gosec // G115
+
+	return stats
+}
+
+func (s *dwellTimeStats) reset() {
+	atomic.StoreUint64(&s.count, 0)
+	atomic.StoreUint64(&s.total, 0)
+	atomic.StoreUint64(&s.min, 0)
+	atomic.StoreUint64(&s.max, 0)
+}
+
+// StreamStats is a snapshot of the counters of a stream.
+type StreamStats struct {
+	// MessagesWritten and BytesWritten count the messages successfully
+	// queued by Write and WriteSCTP.
+	MessagesWritten uint64
+	BytesWritten    uint64
+	// DATAsReceived and BytesReceived count the DATA chunks received for
+	// the stream, and their user data.
+	DATAsReceived uint64
+	BytesReceived uint64
+	// MessagesRead and BytesRead count the messages returned by Read and
+	// ReadSCTP.
+	MessagesRead uint64
+	BytesRead    uint64
+	// Retransmissions counts the DATA chunks of the stream retransmitted
+	// after a T3-rtx timeout or by fast retransmit.
+	Retransmissions uint64
+	// MessagesDropped and BytesDropped count the messages dropped before
+	// being sent, see Stream.SetDropPolicy.
+	MessagesDropped uint64
+	BytesDropped    uint64
+	// ReassemblyTimeouts and ReassemblyTimeoutBytes count the incomplete
+	// received messages discarded after Config.ReassemblyTimeout.
+	ReassemblyTimeouts     uint64
+	ReassemblyTimeoutBytes uint64
+}
+
+type streamStats struct {
+	nMessagesWritten uint64
+	nBytesWritten    uint64
+	nDATAsReceived   uint64
+	nBytesReceived   uint64
+	nMessagesRead    uint64
+	nBytesRead       uint64
+	nRetransmissions uint64
+	nMessagesDropped uint64
+	nBytesDropped    uint64
+
+	nReassemblyTimeouts     uint64
+	nReassemblyTimeoutBytes uint64
+}
+
+func (s *streamStats) addWritten(bytes int) {
+	atomic.AddUint64(&s.nMessagesWritten, 1)
+	atomic.AddUint64(&s.nBytesWritten, uint64(bytes)) //nolint:gosec // G115, bytes is a length
+}
+
+func (s *streamStats) addReceived(bytes int) {
+	atomic.AddUint64(&s.nDATAsReceived, 1)
+	atomic.AddUint64(&s.nBytesReceived, uint64(bytes)) //nolint:gosec // G115, bytes is a length
+}
+
+func (s *streamStats) addRead(bytes int) {
+	atomic.AddUint64(&s.nMessagesRead, 1)
+	atomic.AddUint64(&s.nBytesRead, uint64(bytes)) //nolint:gosec // G115, bytes is a length
+}
+
+func (s *streamStats) addDropped(bytes int) {
+	atomic.AddUint64(&s.nMessagesDropped, 1)
+	atomic.AddUint64(&s.nBytesDropped, uint64(bytes)) //nolint:gosec // G115, bytes is a length
+}
+
+func (s *streamStats) addReassemblyTimeouts(messages, bytes int) {
+	atomic.AddUint64(&s.nReassemblyTimeouts, uint64(messages))  //nolint:gosec // G115, messages is a count
+	atomic.AddUint64(&s.nReassemblyTimeoutBytes, uint64(bytes)) //nolint:gosec // G115, bytes is a length
+}
+
+func (s *streamStats) incRetransmissions() {
+	atomic.AddUint64(&s.nRetransmissions, 1)
+}
+
+func (s *streamStats) snapshot() StreamStats {
+	return StreamStats{
+		MessagesWritten: atomic.LoadUint64(&s.nMessagesWritten),
+		BytesWritten:    atomic.LoadUint64(&s.nBytesWritten),
+		DATAsReceived:   atomic.LoadUint64(&s.nDATAsReceived),
+		BytesReceived:   atomic.LoadUint64(&s.nBytesReceived),
+		MessagesRead:    atomic.LoadUint64(&s.nMessagesRead),
+		BytesRead:       atomic.LoadUint64(&s.nBytesRead),
+		Retransmissions: atomic.LoadUint64(&s.nRetransmissions),
+		MessagesDropped: atomic.LoadUint64(&s.nMessagesDropped),
+		BytesDropped:    atomic.LoadUint64(&s.nBytesDropped),
+
+		ReassemblyTimeouts:     atomic.LoadUint64(&s.nReassemblyTimeouts),
+		ReassemblyTimeoutBytes: atomic.LoadUint64(&s.nReassemblyTimeoutBytes),
+	}
 }
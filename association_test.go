@@ -6,8 +6,11 @@
 package sctp
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
 	cryptoRand "crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -25,6 +28,7 @@ import (
 	"time"
 
 	"github.com/pion/logging"
+	"github.com/pion/sctp/sctptest"
 	"github.com/pion/transport/v4/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -826,6 +830,15 @@ func TestAssociationInterleavingNegotiationPayloadChunkType(t *testing.T) {
 			require.True(t, ok)
 			require.Equal(t, metadata0, metadata1)
 
+			if tt.useInterleaving {
+				require.Equal(t,
+					[]Extension{ExtensionReconfig, ExtensionIData, ExtensionIForwardTSN},
+					a0.NegotiatedExtensions())
+			} else {
+				require.Equal(t, []Extension{ExtensionReconfig, ExtensionForwardTSN}, a0.NegotiatedExtensions())
+			}
+			require.Equal(t, a0.NegotiatedExtensions(), a1.NegotiatedExtensions())
+
 			s0, s1, err := establishSessionPair(br, a0, a1, si)
 			require.NoError(t, err, "failed to establish session pair")
 
@@ -855,6 +868,23 @@ func TestAssociationMetadataNotReadyBeforeHandshake(t *testing.T) {
 	metadata, ok := assoc.Metadata()
 	require.False(t, ok)
 	require.Equal(t, AssociationMetadata{}, metadata)
+	require.Nil(t, assoc.NegotiatedExtensions())
+}
+
+func TestAssociationPeerReconfigResetByInit(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+	// negotiated with the peer before it restarted without RECONFIG support
+	assoc.peerReconfig = true
+
+	init := &chunkInit{}
+	init.initialTSN = 1234
+	init.numOutboundStreams = 1
+	init.numInboundStreams = 1
+	init.initiateTag = 5678
+	init.advertisedReceiverWindowCredit = 512 * 1024
+	_, err := assoc.handleInit(&packet{sourcePort: 5001, destinationPort: 5002}, init)
+	require.NoError(t, err)
+	assert.False(t, assoc.peerReconfig)
 }
 
 func TestAssociationMetadataJSON(t *testing.T) {
@@ -943,13 +973,14 @@ func TestAssociationInterleavingFinalizedOnEstablishedTransition(t *testing.T) {
 	t.Run("cookie echo", func(t *testing.T) {
 		assoc := createTestAssociation(t, Config{})
 		assoc.handshakeCompletedCh = make(chan error, 1)
-		assoc.myCookie = &paramStateCookie{cookie: []byte("cookie")}
+		cookie := stampTestCookie([]byte("cookie"), time.Now())
+		assoc.myCookie = &paramStateCookie{cookie: cookie}
 		assoc.localInterleaving = true
 		assoc.peerInterleaving = true
 		assoc.peerIForwardTSN = true
 		assoc.setState(cookieEchoed)
 
-		packets := assoc.handleCookieEcho(&chunkCookieEcho{cookie: []byte("cookie")})
+		packets := assoc.handleCookieEcho(&chunkCookieEcho{cookie: cookie})
 
 		require.NotEmpty(t, packets)
 		require.Equal(t, established, assoc.getState())
@@ -2662,6 +2693,50 @@ func TestHandleDataAckTriggering(t *testing.T) {
 		assert.False(t, delayed, "gap should not trigger delayed ack")
 		assert.True(t, immediate, "gap should trigger immediate ack")
 	})
+
+	t.Run("FlushAck sends the delayed ack", func(t *testing.T) {
+		assoc := newAssoc()
+		defer assoc.ackTimer.stop()
+
+		assoc.FlushAck()
+		assert.Equal(t, ackStateIdle, assoc.ackState, "FlushAck should do nothing without a pending ack")
+
+		pd := &chunkPayloadData{
+			beginningFragment:    true,
+			endingFragment:       true,
+			tsn:                  assoc.peerLastTSN() + 1,
+			streamIdentifier:     1,
+			streamSequenceNumber: 1,
+			userData:             []byte("flush"),
+		}
+
+		assoc.handleChunksStart()
+		assoc.handleData(pd)
+		assoc.handleChunksEnd()
+		require.Equal(t, ackStateDelay, assoc.ackState)
+
+		assoc.FlushAck()
+
+		assoc.lock.Lock()
+		ackState := assoc.ackState
+		timerRunning := assoc.ackTimer.isRunning()
+		packets := assoc.gatherOutboundSackPackets(nil)
+		assoc.lock.Unlock()
+
+		assert.Equal(t, ackStateImmediate, ackState, "FlushAck should request the ack now")
+		assert.False(t, timerRunning, "ack timer should be stopped")
+		assert.Len(t, packets, 1, "SACK should be sent")
+	})
+
+	t.Run("FlushAck requires an established association", func(t *testing.T) {
+		assoc := newAssoc()
+		defer assoc.ackTimer.stop()
+
+		assoc.ackState = ackStateDelay
+		assoc.setState(shutdownSent)
+		assoc.FlushAck()
+		assert.Equal(t, ackStateDelay, assoc.ackState)
+	})
 }
 
 func TestAssocT1InitTimer(t *testing.T) { //nolint:cyclop
@@ -3016,6 +3091,47 @@ func TestAssocT3RtxTimer(t *testing.T) {
 
 		closeAssociationPair(br, a0, a1)
 	})
+
+	// Deliver every packet late with a jittery delay that exceeds the RTO
+	// but stays below the T3-rtx floor; no retransmission should fire.
+	t.Run("Jitter below floor", func(t *testing.T) {
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		const si uint16 = 6
+		const msg = "ABC"
+		br := test.NewBridge()
+
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		assert.NoError(t, err, "failed to create associations")
+
+		s0, s1, err := establishSessionPair(br, a0, a1, si)
+		assert.NoError(t, err, "failed to establish session pair")
+
+		// lock RTO value at 5 [msec], floor the T3-rtx at 500 [msec]
+		a0.lock.Lock()
+		a0.rtoMgr.setRTO(5.0, true)
+		a0.minT3RTX = 500
+		a0.lock.Unlock()
+		a0.stats.reset()
+
+		buf := make([]byte, 32)
+		for i := range 5 {
+			_, err = s0.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
+			assert.NoError(t, err, "WriteSCTP failed")
+
+			time.Sleep(time.Duration(20+10*(i%3)) * time.Millisecond)
+			flushBuffers(br, a0, a1)
+
+			n, _, err := s1.ReadSCTP(buf)
+			assert.NoError(t, err, "ReadSCTP failed")
+			assert.Equal(t, msg, string(buf[:n]), "unexpected received data")
+		}
+
+		assert.Equal(t, uint64(0), a0.stats.getNumT3Timeouts(), "should be no spurious retransmission")
+
+		closeAssociationPair(br, a0, a1)
+	})
 }
 
 func TestAssocCongestionControl(t *testing.T) { //nolint:cyclop,maintidx
@@ -3533,6 +3649,60 @@ func TestAssocReset(t *testing.T) { //nolint:cyclop
 
 		closeAssociationPair(br, a0, a1)
 	})
+
+	t.Run("Unread data is readable after reset", func(t *testing.T) {
+		checkGoroutineLeaks(t)
+
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		const si uint16 = 1
+		msgs := []string{"ABC", "DEF", "GHI"}
+		br := test.NewBridge()
+
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		assert.NoError(t, err, "failed to create associations")
+
+		s0, s1, err := establishSessionPair(br, a0, a1, si)
+		assert.NoError(t, err, "failed to establish session pair")
+
+		for _, msg := range msgs {
+			_, err = s0.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
+			assert.NoError(t, err)
+		}
+
+		var resets atomic.Int32
+		s1.OnReset(func() { resets.Add(1) })
+
+		err = s0.Close() // send reset
+		assert.NoError(t, err)
+
+		// Deliver the data and the reset without reading from s1.
+		for {
+			br.Process()
+
+			a1.lock.RLock()
+			_, ok := a1.streams[si]
+			a1.lock.RUnlock()
+			if !ok {
+				break
+			}
+		}
+
+		buf := make([]byte, 32)
+		for _, msg := range msgs {
+			n, ppi, err := s1.ReadSCTP(buf)
+			assert.NoError(t, err, "buffered data should be readable after reset")
+			assert.Equal(t, PayloadTypeWebRTCBinary, ppi, "unexpected ppi")
+			assert.Equal(t, msg, string(buf[:n]), "unexpected received data")
+		}
+
+		_, _, err = s1.ReadSCTP(buf)
+		assert.Equal(t, io.EOF, err, "should end with EOF once drained")
+		assert.Equal(t, int32(1), resets.Load(), "OnReset should fire once")
+
+		closeAssociationPair(br, a0, a1)
+	})
 }
 
 func TestAssocResetResetsInterleavingCounters(t *testing.T) {
@@ -3841,7 +4011,7 @@ func TestAssocHandleInit(t *testing.T) {
 		init.numInboundStreams = 1002
 		init.initiateTag = 5678
 		init.advertisedReceiverWindowCredit = 512 * 1024
-		setSupportedExtensions(&init.chunkInitCommon, false)
+		setSupportedExtensions(&init.chunkInitCommon, false, false)
 
 		_, err := assoc.handleInit(pkt, init)
 		if expectErr {
@@ -3851,8 +4021,8 @@ func TestAssocHandleInit(t *testing.T) {
 		}
 		assert.NoError(t, err, "should succeed")
 		assert.Equal(t, init.initialTSN-1, assoc.peerLastTSN(), "should match")
-		assert.Equal(t, uint16(1001), assoc.myMaxNumOutboundStreams, "should match")
-		assert.Equal(t, uint16(1002), assoc.myMaxNumInboundStreams, "should match")
+		assert.Equal(t, uint16(1002), assoc.myMaxNumOutboundStreams, "should match the peer's inbound streams")
+		assert.Equal(t, uint16(1001), assoc.myMaxNumInboundStreams, "should match the peer's outbound streams")
 		assert.Equal(t, uint32(5678), assoc.peerVerificationTag, "should match")
 		assert.Equal(t, pkt.sourcePort, assoc.destinationPort, "should match")
 		assert.Equal(t, pkt.destinationPort, assoc.sourcePort, "should match")
@@ -3884,6 +4054,419 @@ func TestAssocHandleInit(t *testing.T) {
 	})
 }
 
+func TestAssociationECN(t *testing.T) {
+	t.Run("negotiated", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper, WithEnableECN(true))
+		require.NoError(t, err)
+		defer func() {
+			_ = aClient.Close()
+			_ = aServer.Close()
+		}()
+
+		aServer.lock.RLock()
+		assert.True(t, aServer.useECN, "server should use ECN")
+		aServer.lock.RUnlock()
+
+		aClient.lock.Lock()
+		defer aClient.lock.Unlock()
+		assert.True(t, aClient.useECN, "client should use ECN")
+
+		mtu := aClient.MTU()
+		aClient.setCWND(20 * mtu)
+		// pretend a window of data has been sent and the peer saw a CE mark
+		ecne := &chunkECNE{lowestTSN: aClient.myNextTSN + 1}
+		aClient.myNextTSN += 10
+
+		packets := aClient.handleECNE(ecne)
+		assert.Equal(t, 10*mtu, aClient.CWND(), "cwnd should be halved")
+		assert.Equal(t, 10*mtu, aClient.ssthresh, "ssthresh should be halved")
+		require.Len(t, packets, 1)
+		require.Len(t, packets[0].chunks, 1)
+		cwr, ok := packets[0].chunks[0].(*chunkCWR)
+		require.True(t, ok, "should respond with CWR")
+		assert.Equal(t, ecne.lowestTSN, cwr.lowestTSN)
+
+		// A repeated echo within the same window must not reduce cwnd again.
+		packets = aClient.handleECNE(ecne)
+		assert.Equal(t, 10*mtu, aClient.CWND(), "cwnd should be reduced once per window")
+		assert.Len(t, packets, 1, "should still respond with CWR")
+	})
+
+	handleInitTest := func(t *testing.T, localECN, peerECN bool) *Association {
+		t.Helper()
+
+		assoc := createTestAssociation(t, Config{EnableECN: localECN})
+		init := &chunkInit{}
+		init.initialTSN = 1234
+		init.numOutboundStreams = 1
+		init.numInboundStreams = 1
+		init.initiateTag = 5678
+		init.advertisedReceiverWindowCredit = 512 * 1024
+		if peerECN {
+			init.params = append(init.params, &paramECNCapable{})
+		}
+
+		packets, err := assoc.handleInit(&packet{sourcePort: 5001, destinationPort: 5002}, init)
+		require.NoError(t, err)
+		require.Len(t, packets, 1)
+		initAck, ok := packets[0].chunks[0].(*chunkInitAck)
+		require.True(t, ok)
+		assert.Equal(t, localECN, hasECNCapable(initAck.params), "INIT ACK ECN capable param")
+
+		return assoc
+	}
+
+	t.Run("peer not capable", func(t *testing.T) {
+		assoc := handleInitTest(t, true, false)
+		assert.False(t, assoc.useECN)
+
+		cwnd := assoc.CWND()
+		assert.Nil(t, assoc.handleECNE(&chunkECNE{lowestTSN: assoc.myNextTSN}))
+		assert.Equal(t, cwnd, assoc.CWND(), "cwnd should not change")
+	})
+
+	t.Run("local not capable", func(t *testing.T) {
+		assoc := handleInitTest(t, false, true)
+		assert.False(t, assoc.useECN)
+	})
+}
+
+func TestAssociationTimestamps(t *testing.T) {
+	t.Run("negotiated", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper, WithEnableTimestamps(true))
+		require.NoError(t, err)
+		defer func() {
+			_ = aClient.Close()
+			_ = aServer.Close()
+		}()
+
+		aServer.lock.RLock()
+		assert.True(t, aServer.useTimestamps, "server should use timestamps")
+		aServer.lock.RUnlock()
+
+		aClient.lock.RLock()
+		assert.True(t, aClient.useTimestamps, "client should use timestamps")
+		dataHeaderSize := dataChunkHeaderSize
+		if aClient.useInterleaving {
+			dataHeaderSize = iDataChunkHeaderSize
+		}
+		assert.Equal(t, aClient.MTU()-(commonHeaderSize+dataHeaderSize+timestampChunkSize),
+			aClient.maxPayloadSize, "DATA payload should leave room for the TIMESTAMP chunk")
+		aClient.lock.RUnlock()
+
+		stream, err := aClient.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		_, err = stream.Write(make([]byte, 3000))
+		require.NoError(t, err)
+
+		accepted, err := aServer.AcceptStream()
+		require.NoError(t, err)
+		buf := make([]byte, 4000)
+		n, err := accepted.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, 3000, n)
+
+		// Karn's measurement is disabled, so the RTT can only come from an
+		// echoed timestamp.
+		assert.Eventually(t, func() bool {
+			return aClient.SRTT() > 0
+		}, 2*time.Second, 10*time.Millisecond, "client should sample the RTT from the SACK")
+	})
+
+	t.Run("echo", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{EnableTimestamps: true})
+		assoc.peerTimestamps = true
+		require.NoError(t, assoc.updateInterleavingState())
+		require.True(t, assoc.useTimestamps)
+
+		assoc.handleTimestamp(
+			&packet{chunks: []chunk{&chunkPayloadData{}}},
+			&chunkTimestamp{tsVal: 42},
+		)
+		// only the first DATA packet since the last SACK is echoed
+		assoc.handleTimestamp(
+			&packet{chunks: []chunk{&chunkPayloadData{}}},
+			&chunkTimestamp{tsVal: 43},
+		)
+
+		p := assoc.addTimestampChunk(assoc.createPacket([]chunk{&chunkSelectiveAck{}}))
+		require.Len(t, p.chunks, 2)
+		ts, ok := p.chunks[0].(*chunkTimestamp)
+		require.True(t, ok, "TIMESTAMP should be bundled first")
+		assert.True(t, ts.echoActive)
+		assert.Equal(t, uint32(42), ts.tsEcr)
+		assert.False(t, assoc.tsRecentValid)
+
+		p = assoc.addTimestampChunk(assoc.createPacket([]chunk{&chunkShutdown{}}))
+		assert.Len(t, p.chunks, 1, "TIMESTAMP only goes with DATA or SACK")
+
+		// a SACK echoing a timestamp sent 50 ms ago
+		assoc.handleTimestamp(
+			&packet{chunks: []chunk{&chunkSelectiveAck{}}},
+			&chunkTimestamp{tsEcr: assoc.timestampNow() - 50000, echoActive: true},
+		)
+		assoc.sampleTimestampRTT()
+		assert.InDelta(t, 50.0, assoc.SRTT(), 10.0)
+	})
+
+	t.Run("full SACK", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{EnableTimestamps: true})
+		assoc.peerTimestamps = true
+		require.NoError(t, assoc.updateInterleavingState())
+		assoc.handleTimestamp(
+			&packet{chunks: []chunk{&chunkPayloadData{}}},
+			&chunkTimestamp{tsVal: 42},
+		)
+
+		// duplicate TSNs filling the MTU
+		n := (assoc.MTU() - commonHeaderSize - chunkHeaderSize - selectiveAckHeaderSize) / 4
+		sack := &chunkSelectiveAck{duplicateTSN: make([]uint32, n)}
+		p := assoc.addTimestampChunk(assoc.createPacket([]chunk{sack}))
+		require.Len(t, p.chunks, 1, "TIMESTAMP should not push the SACK over the MTU")
+		raw, err := assoc.marshalPacket(p)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(raw), int(assoc.MTU()))
+		assert.True(t, assoc.tsRecentValid, "the echo should wait for the next SACK")
+
+		p = assoc.addTimestampChunk(assoc.createPacket([]chunk{&chunkSelectiveAck{}}))
+		require.Len(t, p.chunks, 2)
+		ts, ok := p.chunks[0].(*chunkTimestamp)
+		require.True(t, ok)
+		assert.Equal(t, uint32(42), ts.tsEcr)
+	})
+
+	t.Run("peer not capable", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{EnableTimestamps: true})
+		init := &chunkInit{}
+		init.initialTSN = 1234
+		init.numOutboundStreams = 1
+		init.numInboundStreams = 1
+		init.initiateTag = 5678
+		init.advertisedReceiverWindowCredit = 512 * 1024
+		setSupportedExtensions(&init.chunkInitCommon, false, false)
+
+		packets, err := assoc.handleInit(&packet{sourcePort: 5001, destinationPort: 5002}, init)
+		require.NoError(t, err)
+		require.Len(t, packets, 1)
+		initAck, ok := packets[0].chunks[0].(*chunkInitAck)
+		require.True(t, ok)
+		assert.True(t, getSupportedExtensions(initAck.params).timestamps, "INIT ACK should list TIMESTAMP")
+		assert.False(t, assoc.useTimestamps)
+		assert.Equal(t, assoc.MTU()-(commonHeaderSize+dataChunkHeaderSize), assoc.maxPayloadSize)
+	})
+}
+
+func TestAssociationPing(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+		defer func() {
+			_ = aClient.Close()
+			_ = aServer.Close()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		rtt, err := aClient.Ping(ctx)
+		assert.NoError(t, err)
+		assert.Greater(t, rtt, time.Duration(0))
+
+		rtt, err = aServer.Ping(ctx)
+		assert.NoError(t, err)
+		assert.Greater(t, rtt, time.Duration(0))
+
+		aClient.lock.RLock()
+		assert.Empty(t, aClient.pings, "ping should be removed once acked")
+		aClient.lock.RUnlock()
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		br := test.NewBridge()
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		require.NoError(t, err)
+
+		// the bridge is not ticked, so the HEARTBEAT is never delivered.
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = a0.Ping(ctx)
+		assert.ErrorIs(t, err, ErrPingTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		a0.lock.Lock()
+		assert.Empty(t, a0.pings, "timed out ping should be removed")
+
+		// an ack carrying an unknown nonce is ignored
+		info := binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano())) //nolint:gosec
+		info = binary.BigEndian.AppendUint64(info, 42)
+		a0.handleHeartbeatAck(&chunkHeartbeatAck{params: []param{&paramHeartbeatInfo{heartbeatInformation: info}}})
+		assert.Empty(t, a0.pings)
+		a0.lock.Unlock()
+
+		closeAssociationPair(br, a0, a1)
+	})
+
+	t.Run("not established", func(t *testing.T) {
+		a := createTestAssociation(t, Config{})
+		_, err := a.Ping(context.Background())
+		assert.ErrorIs(t, err, ErrPingNonEstablished)
+	})
+}
+
+func TestAssociationHeartbeatAck(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	ack := func(info []byte) {
+		assoc.handleHeartbeatAck(&chunkHeartbeatAck{params: []param{&paramHeartbeatInfo{heartbeatInformation: info}}})
+	}
+
+	assoc.ActiveHeartbeat()
+	packets := assoc.controlQueue.popAll()
+	require.Len(t, packets, 1)
+	hb, ok := packets[0].chunks[0].(*chunkHeartbeat)
+	require.True(t, ok)
+	info, ok := hb.params[0].(*paramHeartbeatInfo)
+	require.True(t, ok)
+	require.Len(t, info.heartbeatInformation, heartbeatInfoPingSize)
+	nonce := binary.BigEndian.Uint64(info.heartbeatInformation[heartbeatInfoTimestampSize:])
+	assert.Equal(t, assoc.heartbeatNonce, nonce)
+
+	// echo the HEARTBEAT as if it was sent 50ms ago
+	sent := time.Now().Add(-50 * time.Millisecond)
+	echoed := binary.BigEndian.AppendUint64(nil, uint64(sent.UnixNano())) //nolint:gosec
+	echoed = binary.BigEndian.AppendUint64(echoed, nonce)
+
+	// acks with an unknown nonce, or without a nonce, are ignored
+	srtt := assoc.SRTT()
+	ack(binary.BigEndian.AppendUint64(echoed[:heartbeatInfoTimestampSize:heartbeatInfoTimestampSize], nonce+1))
+	ack(echoed[:heartbeatInfoTimestampSize])
+	assert.Equal(t, srtt, assoc.SRTT())
+	assert.Equal(t, nonce, assoc.heartbeatNonce)
+
+	ack(echoed)
+	assert.Less(t, assoc.SRTT(), srtt, "the 50ms sample lowers the SRTT")
+	assert.Zero(t, assoc.heartbeatNonce)
+
+	// a replayed ack is ignored
+	srtt = assoc.SRTT()
+	echoed = binary.BigEndian.AppendUint64(nil, uint64(time.Now().Add(-time.Second).UnixNano())) //nolint:gosec
+	ack(binary.BigEndian.AppendUint64(echoed, nonce))
+	assert.Equal(t, srtt, assoc.SRTT())
+}
+
+func TestAssociationIdleHeartbeat(t *testing.T) {
+	t.Run("acked heartbeats keep association up", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper, WithHeartbeatInterval(30*time.Millisecond))
+		require.NoError(t, err)
+		defer func() {
+			_ = aClient.Close()
+			_ = aServer.Close()
+		}()
+
+		before := aClient.stats.getNumPacketsReceived()
+		time.Sleep(300 * time.Millisecond)
+
+		assert.Greater(t, aClient.stats.getNumPacketsReceived(), before+2, "idle association should exchange heartbeats")
+		assert.Equal(t, established, aClient.getState())
+		assert.Equal(t, established, aServer.getState())
+	})
+
+	t.Run("unreachable peer closes association", func(t *testing.T) {
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		br := test.NewBridge()
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		require.NoError(t, err)
+
+		// the bridge is not ticked any more, so no HEARTBEAT is ever acked.
+		sent := a0.stats.getNumPacketsSent()
+		a0.lock.Lock()
+		a0.heartbeatInterval = 20
+		a0.tHeartbeat = newRTXTimer(timerHeartbeat, a0, 3, a0.heartbeatInterval)
+		a0.restartHeartbeatTimer()
+		a0.lock.Unlock()
+
+		assert.Eventually(t, func() bool {
+			return a0.getState() == closed
+		}, 2*time.Second, 10*time.Millisecond, "association should close after unacked heartbeats")
+		assert.Equal(t, sent+3, a0.stats.getNumPacketsSent(), "one HEARTBEAT per timeout before giving up")
+		assert.ErrorIs(t, a0.CloseError(), ErrPeerUnreachable)
+
+		closeAssociationPair(br, a0, a1)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		a := createTestAssociation(t, Config{})
+		a.lock.Lock()
+		a.setState(established)
+		a.restartHeartbeatTimer()
+		a.lock.Unlock()
+		assert.False(t, a.tHeartbeat.isRunning())
+	})
+
+	t.Run("sub-millisecond interval rounds up", func(t *testing.T) {
+		a := createTestAssociation(t, Config{HeartbeatInterval: time.Microsecond})
+		assert.Equal(t, float64(1), a.heartbeatInterval)
+	})
+}
+
+func TestAssociationCloseError(t *testing.T) {
+	t.Run("peer abort", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+		assert.NoError(t, aClient.CloseError())
+
+		aServer.Abort("bye")
+
+		select {
+		case <-aClient.readLoopCloseCh:
+		case <-time.After(time.Second):
+			assert.Fail(t, "client should close on ABORT")
+		}
+
+		closeErr := aClient.CloseError()
+		assert.ErrorIs(t, closeErr, ErrChunk)
+		var abortErr *AbortError
+		require.ErrorAs(t, closeErr, &abortErr)
+		assert.Equal(t, "bye", abortErr.Reason)
+
+		assert.NoError(t, aServer.CloseError(), "local abort is not a failure")
+		_ = aClient.Close()
+	})
+
+	t.Run("local close", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+
+		assert.NoError(t, aClient.Close())
+		assert.NoError(t, aClient.CloseError())
+		_ = aServer.Close()
+	})
+
+	t.Run("graceful shutdown", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, aClient.Shutdown(ctx))
+
+		select {
+		case <-aServer.readLoopCloseCh:
+		case <-time.After(time.Second):
+			assert.Fail(t, "server should close after SHUTDOWN")
+		}
+		<-aClient.readLoopCloseCh
+
+		assert.NoError(t, aClient.CloseError())
+		assert.NoError(t, aServer.CloseError())
+	})
+}
+
 func TestAssocMaxMessageSize(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
 		loggerFactory := logging.NewDefaultLoggerFactory()
@@ -4401,7 +4984,7 @@ func TestAssociationReceiveWindow(t *testing.T) {
 
 	done := make(chan bool)
 	go func() {
-		chunks, _ := s1.packetize(make([]byte, 1000), PayloadTypeWebRTCBinary)
+		chunks, _ := s1.packetize(make([]byte, 1000), WriteOptions{PayloadType: PayloadTypeWebRTCBinary})
 		chunks = chunks[:1]
 		chunk := chunks[0]
 		// Fake the TSN and enqueue 1 chunk with a very high tsn in the payload queue
@@ -4520,6 +5103,7 @@ func TestAssociationFastRtxWnd(t *testing.T) {
 	for i := 11; i < 14; i++ {
 		ack.gapAckBlocks[0].end = uint16(i) //nolint:gosec // G115
 		pkt := a1.createPacket([]chunk{&ack})
+		pkt.verificationTag = a1.myVerificationTag // as sent by the peer
 		pktBuf, err1 := pkt.marshal(true)
 		require.NoError(t, err1)
 		dbConn1.inboundHandler(pktBuf)
@@ -4551,6 +5135,7 @@ func TestAssociationFastRtxWnd(t *testing.T) {
 	//nolint:gosec // G115
 	ack.gapAckBlocks = append(ack.gapAckBlocks, gapAckBlock{start: uint16(end), end: uint16(end)})
 	pkt := a1.createPacket([]chunk{&ack})
+	pkt.verificationTag = a1.myVerificationTag
 	pktBuf, err := pkt.marshal(true)
 	require.NoError(t, err)
 	dbConn1.inboundHandler(pktBuf)
@@ -4562,6 +5147,14 @@ func TestAssociationFastRtxWnd(t *testing.T) {
 	}, 5*time.Second, 10*time.Millisecond)
 }
 
+func TestGetMaxTSNOffset(t *testing.T) {
+	assert.Equal(t, uint32(minTSNOffset), getMaxTSNOffset(100*1024, defaultAvgChunkSize))
+	assert.Equal(t, uint32(8000), getMaxTSNOffset(1000*1000, defaultAvgChunkSize))
+	// Small chunks allow more TSNs for the same buffer.
+	assert.Equal(t, uint32(20480), getMaxTSNOffset(100*1024, 20))
+	assert.Equal(t, uint32(maxTSNOffset), getMaxTSNOffset(1024*1024, 20))
+}
+
 func TestAssociationMaxTSNOffset(t *testing.T) {
 	udp1, udp2 := createUDPConnPair()
 	// a1 is the association used for sending data
@@ -4582,7 +5175,7 @@ func TestAssociationMaxTSNOffset(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint16(1), s2.streamIdentifier)
 
-	chunks, _ := s1.packetize(make([]byte, 1000), PayloadTypeWebRTCBinary)
+	chunks, _ := s1.packetize(make([]byte, 1000), WriteOptions{PayloadType: PayloadTypeWebRTCBinary})
 	chunks = chunks[:1]
 	sendChunk := func(tsn uint32) {
 		chunk := chunks[0]
@@ -4648,31 +5241,85 @@ func TestAssociation_Shutdown(t *testing.T) {
 	}
 }
 
-func TestAssociation_ShutdownDuringWrite(t *testing.T) {
+func TestAssociation_ShutdownDrainsPendingData(t *testing.T) {
 	checkGoroutineLeaks(t)
 
 	a1, a2, err := createAssocs()
 	require.NoError(t, err)
 
-	s11, err := a1.OpenStream(1, PayloadTypeWebRTCString)
+	s11, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
 	require.NoError(t, err)
 
-	s21, err := a2.OpenStream(1, PayloadTypeWebRTCString)
+	s21, err := a2.OpenStream(1, PayloadTypeWebRTCBinary)
 	require.NoError(t, err)
 
-	writingDone := make(chan struct{})
+	const msgSize = 1000
+	const numMsgs = 50
+	require.Greater(t, msgSize*numMsgs, int(a1.CWND()), "data should not fit in one cwnd")
 
+	received := make(chan int, 1)
 	go func() {
-		defer close(writingDone)
+		total := 0
+		buf := make([]byte, msgSize)
+		for total < msgSize*numMsgs {
+			n, readErr := s21.Read(buf)
+			if readErr != nil {
+				break
+			}
+			total += n
+		}
+		received <- total
+	}()
 
-		var i byte
+	for i := range numMsgs {
+		msg := bytes.Repeat([]byte{byte(i)}, msgSize)
+		_, err = s11.Write(msg)
+		require.NoError(t, err)
+	}
 
-		for {
-			i++
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, a1.Shutdown(ctx))
 
-			if i%100 == 0 {
-				time.Sleep(20 * time.Millisecond)
-			}
+	select {
+	case total := <-received:
+		assert.Equal(t, msgSize*numMsgs, total, "all written data should be delivered")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timed out waiting for data")
+	}
+
+	select {
+	case <-a2.readLoopCloseCh:
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "timed out waiting for a2 read loop to close")
+	}
+}
+
+func TestAssociation_ShutdownDuringWrite(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	a1, a2, err := createAssocs()
+	require.NoError(t, err)
+
+	s11, err := a1.OpenStream(1, PayloadTypeWebRTCString)
+	require.NoError(t, err)
+
+	s21, err := a2.OpenStream(1, PayloadTypeWebRTCString)
+	require.NoError(t, err)
+
+	writingDone := make(chan struct{})
+
+	go func() {
+		defer close(writingDone)
+
+		var i byte
+
+		for {
+			i++
+
+			if i%100 == 0 {
+				time.Sleep(20 * time.Millisecond)
+			}
 
 			_, writeErr := s21.Write([]byte{i})
 			if writeErr != nil {
@@ -4716,6 +5363,311 @@ func TestAssociation_ShutdownDuringWrite(t *testing.T) {
 	}
 }
 
+// writeFailConn fails writes once broken while reads keep blocking, like a
+// transport that died without the read side noticing.
+type writeFailConn struct {
+	net.Conn
+	broken atomic.Bool
+}
+
+func (c *writeFailConn) Write(p []byte) (int, error) {
+	if c.broken.Load() {
+		return 0, net.ErrClosed
+	}
+
+	return c.Conn.Write(p)
+}
+
+func TestAssociation_ShutdownClosedConn(t *testing.T) {
+	t.Run("conn closed", func(t *testing.T) {
+		a1, a2, err := createAssocs()
+		require.NoError(t, err)
+		defer func() {
+			_ = a2.Close()
+		}()
+
+		require.NoError(t, a1.netConn.Close())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		err = a1.Shutdown(ctx)
+		assert.ErrorIs(t, err, ErrShutdownTransportClosed)
+		assert.Less(t, time.Since(start), time.Second, "shutdown should not wait for ctx")
+
+		_ = a1.Close()
+	})
+
+	t.Run("writes failing", func(t *testing.T) {
+		var failing *writeFailConn
+		aClient, aServer, err := association(t, func(t *testing.T) (net.Conn, net.Conn) {
+			t.Helper()
+
+			ca, cb := udpPiper(t)
+			failing = &writeFailConn{Conn: ca}
+
+			return failing, cb
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = aServer.Close()
+		}()
+
+		failing.broken.Store(true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		err = aClient.Shutdown(ctx)
+		assert.ErrorIs(t, err, ErrShutdownTransportClosed)
+		assert.ErrorIs(t, err, net.ErrClosed)
+		assert.Less(t, time.Since(start), time.Second, "shutdown should not wait for ctx")
+
+		select {
+		case <-aClient.readLoopCloseCh:
+		case <-time.After(time.Second):
+			assert.Fail(t, "read loop should exit after the write failure")
+		}
+	})
+}
+
+func TestAssociation_ShutdownUnresponsivePeer(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	br := test.NewBridge()
+	a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+	require.NoError(t, err)
+
+	// the bridge is not ticked any more, so the SHUTDOWN is never acked.
+	sent := a0.stats.getNumPacketsSent()
+	a0.lock.Lock()
+	a0.t2Shutdown = newRTXTimer(timerT2Shutdown, a0, 3, 20)
+	a0.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = a0.Shutdown(ctx)
+	assert.ErrorIs(t, err, ErrPeerUnreachable)
+	assert.Equal(t, closed, a0.getState())
+	assert.Equal(t, sent+4, a0.stats.getNumPacketsSent(), "SHUTDOWN and one retransmission per timeout")
+
+	closeAssociationPair(br, a0, a1)
+}
+
+// dropWriteConn silently discards writes once dropping is set.
+type dropWriteConn struct {
+	net.Conn
+	dropping atomic.Bool
+}
+
+func (c *dropWriteConn) Write(p []byte) (int, error) {
+	if c.dropping.Load() {
+		return len(p), nil
+	}
+
+	return c.Conn.Write(p)
+}
+
+func TestAssociation_MaxInitRetransmits(t *testing.T) {
+	transport := newChanTransport()
+	clock := &fakeClock{}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ClientWithOptions(
+			WithTransport(transport),
+			WithClock(clock),
+			WithLoggerFactory(logging.NewDefaultLoggerFactory()),
+			WithMaxInitRetransmits(2),
+		)
+		done <- err
+	}()
+
+	// the INIT and two retransmissions
+	for i := range 3 {
+		select {
+		case <-transport.out:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "INIT not sent", "attempt %d", i)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrHandshakeInitAck)
+		assert.ErrorIs(t, err, ErrHandshakeTimeout)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "handshake did not time out")
+	}
+	assert.Empty(t, transport.out, "no INIT after the last retransmission")
+}
+
+func TestAssociation_MaxShutdownRetrans(t *testing.T) {
+	var dropping *dropWriteConn
+	aClient, aServer, err := association(t, func(t *testing.T) (net.Conn, net.Conn) {
+		t.Helper()
+
+		ca, cb := udpPiper(t)
+		dropping = &dropWriteConn{Conn: ca}
+
+		return dropping, cb
+	}, WithMaxShutdownRetrans(2), WithRTOMax(20))
+	require.NoError(t, err)
+	defer func() {
+		_ = aServer.Close()
+	}()
+
+	dropping.dropping.Store(true)
+	sent := aClient.stats.getNumPacketsSent()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = aClient.Shutdown(ctx)
+	assert.ErrorIs(t, err, ErrPeerUnreachable)
+	assert.Equal(t, closed, aClient.getState())
+	assert.Equal(t, sent+3, aClient.stats.getNumPacketsSent(), "SHUTDOWN and two retransmissions")
+
+	_ = aClient.Close()
+}
+
+func TestAssociation_CloseWithTimeout(t *testing.T) {
+	t.Run("graceful", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+
+		assert.NoError(t, aClient.CloseWithTimeout(time.Second))
+		assert.Equal(t, closed, aClient.getState())
+
+		select {
+		case <-aServer.readLoopCloseCh:
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for server read loop to close")
+		}
+	})
+
+	t.Run("not established", func(t *testing.T) {
+		aClient, aServer, err := association(t, udpPiper)
+		require.NoError(t, err)
+		defer func() {
+			_ = aServer.Close()
+		}()
+
+		aClient.setState(shutdownReceived)
+		assert.NoError(t, aClient.CloseWithTimeout(time.Second))
+		assert.Equal(t, closed, aClient.getState())
+	})
+}
+
+func TestAssociation_HandshakeError(t *testing.T) {
+	t.Run("peer abort", func(t *testing.T) {
+		c1, c2 := net.Pipe()
+		defer func() {
+			_ = c2.Close()
+		}()
+
+		go func() {
+			buf := make([]byte, receiveMTU)
+			n, err := c2.Read(buf)
+			if err != nil {
+				return
+			}
+			initPkt := &packet{}
+			if err = initPkt.unmarshal(true, buf[:n]); err != nil {
+				return
+			}
+			initChunk, ok := initPkt.chunks[0].(*chunkInit)
+			if !ok {
+				return
+			}
+			raw, err := (&packet{
+				sourcePort:      initPkt.destinationPort,
+				destinationPort: initPkt.sourcePort,
+				verificationTag: initChunk.initiateTag,
+				chunks: []chunk{&chunkAbort{errorCauses: []errorCause{
+					&errorCauseUserInitiatedAbort{upperLayerAbortReason: []byte("go away")},
+				}}},
+			}).marshal(true)
+			if err != nil {
+				return
+			}
+			_, _ = c2.Write(raw)
+		}()
+
+		_, err := ClientWithOptions(WithNetConn(c1), WithLoggerFactory(logging.NewDefaultLoggerFactory()))
+		assert.ErrorIs(t, err, ErrAssociationClosedBeforeConn)
+		assert.ErrorIs(t, err, ErrChunk)
+
+		var handshakeErr *HandshakeError
+		require.ErrorAs(t, err, &handshakeErr)
+		assert.Equal(t, ErrAssociationClosedBeforeConn, handshakeErr.Err)
+
+		var abortErr *AbortError
+		require.ErrorAs(t, err, &abortErr)
+		assert.Equal(t, "go away", abortErr.Reason)
+		assert.Len(t, abortErr.Causes, 1)
+	})
+
+	t.Run("init timeout", func(t *testing.T) {
+		c1, c2 := net.Pipe()
+		defer func() {
+			_ = c2.Close()
+		}()
+
+		// the peer swallows every INIT without answering.
+		go func() {
+			buf := make([]byte, receiveMTU)
+			for {
+				if _, err := c2.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+
+		a, err := ClientWithOptions(
+			WithNetConn(c1),
+			WithLoggerFactory(logging.NewDefaultLoggerFactory()),
+			WithRTOMax(10),
+		)
+		assert.Nil(t, a)
+		assert.ErrorIs(t, err, ErrHandshakeInitAck)
+		assert.ErrorIs(t, err, ErrHandshakeTimeout)
+		assert.NotErrorIs(t, err, ErrAssociationClosedBeforeConn)
+
+		var handshakeErr *HandshakeError
+		require.ErrorAs(t, err, &handshakeErr)
+		assert.Equal(t, ErrHandshakeTimeout, handshakeErr.Cause)
+
+		// the conn belongs to the caller and stays open
+		_, err = c1.Write([]byte{1})
+		assert.NoError(t, err)
+		assert.NoError(t, c1.Close())
+	})
+
+	t.Run("transport closed", func(t *testing.T) {
+		c1, c2 := net.Pipe()
+
+		go func() {
+			buf := make([]byte, receiveMTU)
+			_, _ = c2.Read(buf)
+			_ = c2.Close()
+		}()
+
+		_, err := ClientWithOptions(WithNetConn(c1), WithLoggerFactory(logging.NewDefaultLoggerFactory()))
+		assert.ErrorIs(t, err, ErrAssociationClosedBeforeConn)
+		assert.ErrorIs(t, err, io.EOF)
+
+		var handshakeErr *HandshakeError
+		require.ErrorAs(t, err, &handshakeErr)
+		assert.ErrorIs(t, handshakeErr.Cause, io.EOF)
+	})
+}
+
 func TestAssociation_HandlePacketInCookieWaitState(t *testing.T) {
 	loggerFactory := logging.NewDefaultLoggerFactory()
 
@@ -4896,6 +5848,90 @@ func TestAssociation_Abort(t *testing.T) {
 	assert.Error(t, err, "User Initiated Abort: 1234", "expected abort reason")
 }
 
+func TestAssociation_AbortWithCause(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	a1, a2, err := createAssocs()
+	require.NoError(t, err)
+
+	a1.AbortWithCause(NewProtocolViolationCause("bad peer"))
+
+	for _, a := range []*Association{a1, a2} {
+		select {
+		case <-a.Closed():
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for the association to close")
+		}
+	}
+
+	var abortErr *AbortError
+	require.ErrorAs(t, a2.CloseError(), &abortErr)
+	require.Len(t, abortErr.Causes, 1)
+	assert.Equal(t, uint16(protocolViolation), abortErr.Causes[0].Code)
+	assert.Equal(t, "Protocol Violation", abortErr.Causes[0].Name)
+	assert.Equal(t, []byte("bad peer"), abortErr.Causes[0].Info)
+	assert.Contains(t, abortErr.Error(), "(Protocol Violation: bad peer)")
+	assert.Empty(t, abortErr.Reason)
+	_ = a1.Close()
+	_ = a2.Close()
+}
+
+func TestAssociation_Closed(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	a1, a2, err := createAssocs()
+	require.NoError(t, err)
+
+	select {
+	case <-a2.Closed():
+		assert.Fail(t, "association should be open")
+	default:
+	}
+
+	a1.Abort("bye")
+
+	for _, a := range []*Association{a1, a2} {
+		select {
+		case <-a.Closed():
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for the association to close")
+		}
+	}
+	var abortErr *AbortError
+	assert.ErrorAs(t, a2.CloseError(), &abortErr)
+
+	// Close afterwards is fine and the channel stays closed.
+	assert.NoError(t, a2.Close())
+	<-a2.Closed()
+	_ = a1.Close()
+}
+
+// TestAssociation_ServerWithContext tests that the server is closed when the
+// context expires before the peer sends INIT.
+func TestAssociation_ServerWithContext(t *testing.T) {
+	lim := test.TimeOut(time.Second * 5)
+	defer lim.Stop()
+
+	checkGoroutineLeaks(t)
+
+	udp1, udp2 := createUDPConnPair()
+	defer func() {
+		_ = udp2.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	a, err := ServerWithContext(ctx, Config{
+		NetConn:       udp1,
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+	})
+	assert.Nil(t, a)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "server should give up when ctx expires")
+}
+
 // TestAssociation_createClientWithContext tests that the client is closed when the context is canceled.
 func TestAssociation_createClientWithContext(t *testing.T) {
 	// Limit runtime in case of deadlocks
@@ -5044,6 +6080,64 @@ func TestAssociation_ZeroChecksum(t *testing.T) {
 	}
 }
 
+func TestAssociation_ZeroChecksumEDMID(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	for _, testCase := range []struct {
+		name                     string
+		clientEDMID, serverEDMID uint32
+		expectChecksumEnabled    bool
+	}{
+		{"default", 0, 0, true},
+		{"custom matching", 2, 2, true},
+		{"mismatch", ZeroChecksumEDMIDDTLS, 2, false},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			a1chan, a2chan := make(chan *Association), make(chan *Association)
+
+			udp1, udp2 := createUDPConnPair()
+
+			go func() {
+				a1, err := Client(Config{
+					NetConn:            udp1,
+					LoggerFactory:      logging.NewDefaultLoggerFactory(),
+					EnableZeroChecksum: true,
+					ZeroChecksumEDMID:  testCase.clientEDMID,
+				})
+				assert.NoError(t, err)
+				a1chan <- a1
+			}()
+
+			go func() {
+				a2, err := Server(Config{
+					NetConn:            udp2,
+					LoggerFactory:      logging.NewDefaultLoggerFactory(),
+					EnableZeroChecksum: true,
+					ZeroChecksumEDMID:  testCase.serverEDMID,
+				})
+				assert.NoError(t, err)
+				a2chan <- a2
+			}()
+
+			a1, a2 := <-a1chan, <-a2chan
+
+			metadata1, ok := a1.Metadata()
+			require.True(t, ok)
+			assert.Equal(t, testCase.expectChecksumEnabled, metadata1.ZeroChecksumSendingEnabled)
+
+			metadata2, ok := a2.Metadata()
+			require.True(t, ok)
+			assert.Equal(t, testCase.expectChecksumEnabled, metadata2.ZeroChecksumSendingEnabled)
+
+			require.NoError(t, a1.Close())
+			require.NoError(t, a2.Close())
+		})
+	}
+}
+
 func TestDataChunkBundlingIntoPacket(t *testing.T) {
 	a := &Association{mtu: initialMTU}
 	chunks := make([]*chunkPayloadData, 300)
@@ -5108,7 +6202,7 @@ func TestAssociation_ReconfigRequestsLimited(t *testing.T) {
 	a1.lock.RLock()
 	tsn := a1.myNextTSN
 	a1.lock.RUnlock()
-	for i := range maxReconfigRequests + 100 {
+	for i := range defaultMaxReconfigRequests + 100 {
 		c := &chunkReconfig{
 			paramA: &paramOutgoingResetRequest{
 				reconfigRequestSequenceNumber: 10 + uint32(i),      //nolint:gosec // G115
@@ -5128,31 +6222,96 @@ func TestAssociation_ReconfigRequestsLimited(t *testing.T) {
 	// Let a2 process the requests
 	time.Sleep(2 * time.Second)
 	a2.lock.RLock()
-	require.LessOrEqual(t, len(a2.reconfigRequests), maxReconfigRequests)
+	require.LessOrEqual(t, len(a2.reconfigRequests), defaultMaxReconfigRequests)
 	a2.lock.RUnlock()
 
 	require.NoError(t, a1.Close())
 	require.NoError(t, a2.Close())
 }
 
-func TestAssociation_OpenStreamAfterClose(t *testing.T) {
-	checkGoroutineLeaks(t)
+func TestAssociation_ReconfigRequestsExpire(t *testing.T) {
+	const maxRequests = 10
+	const lifetime = time.Minute
+	assoc := createTestAssociation(t, Config{MaxReconfigRequests: maxRequests, ReconfigRequestLifetime: lifetime})
+	assoc.payloadQueue.init(100)
 
-	a1, a2, err := createAssocs()
-	require.NoError(t, err)
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
 
-	require.NoError(t, a1.Close())
-	require.NoError(t, a2.Close())
+	request := func(rsn uint32) error {
+		_, err := assoc.handleReconfigParam(&paramOutgoingResetRequest{
+			reconfigRequestSequenceNumber: rsn,
+			senderLastTSN:                 assoc.peerLastTSN() + 10, // never completes
+			streamIdentifiers:             []uint16{uint16(rsn)},    //nolint:gosec // G115
+		})
 
-	_, err = a1.OpenStream(1, PayloadTypeWebRTCString)
-	require.ErrorIs(t, err, ErrAssociationClosed)
+		return err
+	}
 
-	_, err = a2.OpenStream(1, PayloadTypeWebRTCString)
-	require.ErrorIs(t, err, ErrAssociationClosed)
-}
+	// Flood with requests that never complete.
+	for i := range uint32(3 * maxRequests) {
+		err := request(i)
+		if i < maxRequests {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, ErrTooManyReconfigRequests)
+		}
+	}
+	assert.Len(t, assoc.reconfigRequests, maxRequests)
+	assert.Len(t, assoc.reconfigRequestsSeen, maxRequests)
 
-// https://github.com/pion/sctp/pull/350
-// may need to run with a high test count to reproduce if there
+	// A retransmission keeps the time the request was first seen.
+	firstSeen := assoc.reconfigRequestsSeen[0]
+	require.NoError(t, request(0))
+	assert.Equal(t, firstSeen, assoc.reconfigRequestsSeen[0])
+
+	// Age half of the requests past their lifetime.
+	for rsn := range uint32(maxRequests / 2) {
+		assoc.reconfigRequestsSeen[rsn] = time.Now().Add(-lifetime - time.Second)
+	}
+
+	for i := range uint32(maxRequests) {
+		err := request(1000 + i)
+		if i < maxRequests/2 {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, ErrTooManyReconfigRequests)
+		}
+	}
+	assert.Len(t, assoc.reconfigRequests, maxRequests)
+	assert.Len(t, assoc.reconfigRequestsSeen, maxRequests)
+	for rsn := range uint32(maxRequests / 2) {
+		assert.NotContains(t, assoc.reconfigRequests, rsn, "expired request should be evicted")
+	}
+
+	// Expired requests are dropped on any request, not only when full.
+	delete(assoc.reconfigRequests, 1000)
+	delete(assoc.reconfigRequestsSeen, 1000)
+	assoc.reconfigRequestsSeen[1001] = time.Now().Add(-lifetime - time.Second)
+	require.NoError(t, request(1002))
+	assert.NotContains(t, assoc.reconfigRequests, uint32(1001), "expired request should be evicted")
+	assert.NotContains(t, assoc.reconfigRequestsSeen, uint32(1001))
+	assert.Len(t, assoc.reconfigRequests, maxRequests-2)
+}
+
+func TestAssociation_OpenStreamAfterClose(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	a1, a2, err := createAssocs()
+	require.NoError(t, err)
+
+	require.NoError(t, a1.Close())
+	require.NoError(t, a2.Close())
+
+	_, err = a1.OpenStream(1, PayloadTypeWebRTCString)
+	require.ErrorIs(t, err, ErrAssociationClosed)
+
+	_, err = a2.OpenStream(1, PayloadTypeWebRTCString)
+	require.ErrorIs(t, err, ErrAssociationClosed)
+}
+
+// https://github.com/pion/sctp/pull/350
+// may need to run with a high test count to reproduce if there
 // is ever a regression.
 func TestAssociation_OpenStreamAfterInternalClose(t *testing.T) {
 	checkGoroutineLeaks(t)
@@ -5176,6 +6335,129 @@ func TestAssociation_OpenStreamAfterInternalClose(t *testing.T) {
 	require.Equal(t, 0, len(a2.streams))
 }
 
+func TestAssociation_Flush(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	conn1, conn2 := createUDPConnPair()
+	a1, a2, err := createAssociationPairWithConfig(conn1, conn2, Config{MaxReceiveBufferSize: 4000})
+	require.NoError(t, err)
+
+	defer noErrorClose(t, a2.Close)
+	defer noErrorClose(t, a1.Close)
+
+	// Nothing buffered yet.
+	require.NoError(t, a1.Flush(context.Background()))
+
+	s1, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	_, err = s1.WriteSCTP([]byte("hello"), PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	s2, err := a2.AcceptStream()
+	require.NoError(t, err)
+
+	buf := make([]byte, 4000)
+	_, err = s2.Read(buf)
+	require.NoError(t, err)
+
+	// More than the receiver buffers: the flush stalls until it reads.
+	for i := 0; i < 4; i++ {
+		_, err = s1.WriteSCTP(make([]byte, 3000), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, a1.Flush(ctx), context.DeadlineExceeded)
+	require.NotZero(t, a1.BufferedAmount())
+
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		for i := 0; i < 4; i++ {
+			time.Sleep(50 * time.Millisecond) // slow receiver
+			_, rerr := s2.Read(buf)
+			assert.NoError(t, rerr)
+		}
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, a1.Flush(ctx))
+	assert.Zero(t, a1.BufferedAmount())
+	<-readDone
+}
+
+func TestAssociation_FlushAbandoned(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	abandoned := mkChunk(100, time.Now())
+	abandoned.setAllInflight()
+	abandoned.setAbandoned(true)
+	assoc.inflightQueue.pushNoCheck(abandoned)
+	assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
+
+	flushed := make(chan error, 1)
+	go func() { flushed <- assoc.Flush(context.Background()) }()
+
+	select {
+	case err := <-flushed:
+		require.Fail(t, "flushed with data in flight", "err=%v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Acking the only chunk not abandoned completes the flush.
+	assoc.lock.Lock()
+	err := assoc.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               99,
+		advertisedReceiverWindowCredit: 64 * 1024,
+		gapAckBlocks:                   []gapAckBlock{{start: 2, end: 2}},
+	})
+	assoc.lock.Unlock()
+	require.NoError(t, err)
+
+	select {
+	case err := <-flushed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "flush not completed")
+	}
+}
+
+func TestAssociation_Goodput(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	conn1, conn2 := createUDPConnPair()
+	a1, a2, err := createAssociationPairWithConfig(conn1, conn2, Config{})
+	require.NoError(t, err)
+
+	defer noErrorClose(t, a2.Close)
+	defer noErrorClose(t, a1.Close)
+
+	s1, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s1.WriteSCTP(make([]byte, 1000), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+	}
+	s2, err := a2.AcceptStream()
+	require.NoError(t, err)
+
+	buf := make([]byte, 2000)
+	for i := 0; i < 3; i++ {
+		_, err = s2.Read(buf)
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, a1.Flush(ctx))
+
+	assert.Equal(t, uint64(3000), a1.GoodputBytesSent())
+	assert.Equal(t, uint64(3000), a2.GoodputBytesReceived())
+	assert.Greater(t, a1.BytesSent(), a1.GoodputBytesSent(), "wire bytes include headers")
+	assert.Equal(t, uint64(3000), a1.Stats().GoodputBytesSent)
+	assert.Equal(t, uint64(3000), a2.Stats().GoodputBytesReceived)
+}
+
 func TestAssociation_BlockWrite(t *testing.T) {
 	checkGoroutineLeaks(t)
 
@@ -5358,11 +6640,12 @@ func newRackTestAssoc(t *testing.T) *Association {
 	assoc.initialTSN = 100
 	assoc.myNextTSN = 102 // we'll populate TSN=100,101 manually below
 	assoc.cumulativeTSNAckPoint = 99
+	assoc.lastSackCoverage = 99
 	assoc.advancedPeerTSNAckPoint = 99
 
 	// fresh queues
 	assoc.inflightQueue = newPayloadQueue()
-	assoc.payloadQueue = newReceivePayloadQueue(getMaxTSNOffset(assoc.maxReceiveBufferSize))
+	assoc.payloadQueue = newReceivePayloadQueue(getMaxTSNOffset(assoc.maxReceiveBufferSize, assoc.avgChunkSize))
 
 	// RACK defaults for tests
 	assoc.rackReorderingSeen = false
@@ -5387,6 +6670,56 @@ func mkChunk(tsn uint32, since time.Time) *chunkPayloadData {
 	}
 }
 
+func TestAssociation_PeerStall(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.peerStallTimeout = 50 * time.Millisecond
+	stalled := make(chan struct{}, 2)
+	assoc.onPeerStalled = func() { stalled <- struct{}{} }
+	assoc.setCWND(64 * 1024)
+	assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now())) // zero window probe
+
+	sack := func(rwnd uint32) {
+		t.Helper()
+
+		assoc.lock.Lock()
+		err := assoc.handleSack(&chunkSelectiveAck{
+			cumulativeTSNAck:               99,
+			advertisedReceiverWindowCredit: rwnd,
+		})
+		assoc.lock.Unlock()
+		require.NoError(t, err)
+	}
+	expectStalled := func(want bool) {
+		t.Helper()
+
+		select {
+		case <-stalled:
+			assert.True(t, want, "unexpected peer stall")
+		case <-time.After(20 * time.Millisecond):
+			assert.False(t, want, "peer stall not reported")
+		}
+	}
+
+	sack(0)
+	expectStalled(false)
+
+	time.Sleep(60 * time.Millisecond)
+	sack(0)
+	expectStalled(true)
+
+	// Reported once per zero window episode.
+	sack(0)
+	expectStalled(false)
+
+	// An open window ends the episode, so the timeout starts over.
+	sack(1000)
+	sack(0)
+	expectStalled(false)
+	time.Sleep(60 * time.Millisecond)
+	sack(0)
+	expectStalled(true)
+}
+
 func TestRACK_MarkLossOnACK(t *testing.T) {
 	assoc := newRackTestAssoc(t)
 
@@ -5630,900 +6963,4092 @@ func TestProcessSelectiveAck_GapBlockUint16Max(t *testing.T) {
 	assert.True(t, got.acked, "chunk should be marked as acked after SACK gap-block processing")
 }
 
-func TestRTOClearsFastRecovery(t *testing.T) {
-	assoc := newRackTestAssoc(t)
+func TestAssociation_InvalidGapAckBlocks(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		block gapAckBlock
+	}{
+		{"start after end", gapAckBlock{start: 3, end: 2}},
+		{"zero start", gapAckBlock{start: 0, end: 2}},
+		{"beyond highest sent TSN", gapAckBlock{start: 3, end: 10}},
+		{"far beyond highest sent TSN", gapAckBlock{start: 1000, end: 65535}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assoc := newRackTestAssoc(t)
+			assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now()))
+			assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
+
+			assoc.lock.Lock()
+			err := assoc.handleSack(&chunkSelectiveAck{
+				cumulativeTSNAck:               99,
+				advertisedReceiverWindowCredit: 64 * 1024,
+				gapAckBlocks:                   []gapAckBlock{tc.block, {start: 2, end: 2}},
+			})
+			assoc.lock.Unlock()
+			require.NoError(t, err, "an invalid gap ack block should not fail the association")
+			assert.Equal(t, established, assoc.getState())
 
-	assoc.inFastRecovery = true
-	assoc.willRetransmitFast = true
-	assoc.fastRecoverExitPoint = assoc.cumulativeTSNAckPoint + 10
-	assoc.partialBytesAcked = 1234
-	assoc.setCWND(6 * assoc.MTU())
+			first, ok := assoc.inflightQueue.get(100)
+			require.True(t, ok)
+			assert.False(t, first.acked, "the invalid block should be ignored")
+			second, ok := assoc.inflightQueue.get(101)
+			require.True(t, ok)
+			assert.True(t, second.acked, "valid blocks of the same SACK should still be processed")
+		})
+	}
+}
 
-	assoc.onRetransmissionTimeout(timerT3RTX, 1)
+func TestAssociation_OverlappingGapAckBlocks(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		blocks []gapAckBlock
+		acked  []uint32
+	}{
+		{"sorted", []gapAckBlock{{start: 2, end: 2}, {start: 4, end: 4}}, []uint32{101, 103}},
+		{"overlapping", []gapAckBlock{{start: 2, end: 3}, {start: 3, end: 4}}, []uint32{101, 102}},
+		{"duplicate", []gapAckBlock{{start: 2, end: 2}, {start: 2, end: 2}}, []uint32{101}},
+		{"not sorted", []gapAckBlock{{start: 4, end: 4}, {start: 2, end: 2}}, []uint32{103}},
+		{"nested", []gapAckBlock{{start: 2, end: 4}, {start: 3, end: 3}}, []uint32{101, 102, 103}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assoc := newRackTestAssoc(t)
+			assoc.myNextTSN = 105
+			for tsn := uint32(100); tsn < 105; tsn++ {
+				assoc.inflightQueue.pushNoCheck(mkChunk(tsn, time.Now()))
+			}
 
-	assert.False(t, assoc.inFastRecovery, "RTO should exit fast recovery")
-	assert.False(t, assoc.willRetransmitFast, "RTO should clear pending fast retransmit")
-	assert.Equal(t, uint32(0), assoc.partialBytesAcked, "RTO should reset partial bytes acked")
-	assert.Equal(t, assoc.MTU(), assoc.CWND(), "RTO should reset cwnd to MTU")
-	assert.Equal(t, uint32(0), assoc.fastRecoverExitPoint, "RTO should clear fast recovery exit point")
-}
+			sack := &chunkSelectiveAck{
+				cumulativeTSNAck:               99,
+				advertisedReceiverWindowCredit: 64 * 1024,
+				gapAckBlocks:                   tc.blocks,
+			}
+			assoc.lock.Lock()
+			sack.gapAckBlocks = assoc.validGapAckBlocks(sack)
+			bytesAckedPerStream, _, _, _, _, err := assoc.processSelectiveAck(sack) //nolint:dogsled
+			assoc.lock.Unlock()
+			require.NoError(t, err)
 
-func newTLRAssociationForTest(t *testing.T) (*Association, net.Conn) {
-	t.Helper()
+			var acked []uint32
+			for tsn := uint32(100); tsn < 105; tsn++ {
+				c, ok := assoc.inflightQueue.get(tsn)
+				require.True(t, ok)
+				if c.acked {
+					acked = append(acked, tsn)
+				}
+			}
+			assert.Equal(t, tc.acked, acked)
 
-	c1, c2 := net.Pipe()
+			var total int
+			for _, n := range bytesAckedPerStream {
+				total += n
+			}
+			assert.Equal(t, len(tc.acked), total, "each one-byte chunk should be counted once")
+		})
+	}
+}
 
-	a := createTestAssociation(t, Config{
-		Name:          "tlr-test",
-		NetConn:       c1,
-		MTU:           1200,
+func TestAssociationCongestionEvents(t *testing.T) {
+	events := make(chan CongestionEvent, 4)
+	assoc, err := createServerAssociation(Config{
+		NetConn:       &dumbConn{},
 		LoggerFactory: logging.NewDefaultLoggerFactory(),
-		RTOMax:        1000,
-	})
+	}, WithOnCongestionEvent(func(ev CongestionEvent) {
+		events <- ev
+	}))
+	require.NoError(t, err)
 
-	return a, c2
-}
+	assoc.setState(established)
+	assoc.cumulativeTSNAckPoint = 99
+	assoc.advancedPeerTSNAckPoint = 99
+	assoc.myNextTSN = 102
+	assoc.setCWND(64 * 1024)
+	assoc.setRWND(64 * 1024)
+	assoc.ssthresh = 128 * 1024
 
-func shutdownTLRAssociationForTest(a *Association, peer net.Conn) {
-	if peer != nil {
-		_ = peer.Close()
-	}
+	first := mkChunk(100, time.Now())
+	first.missIndicator = 2
+	assoc.inflightQueue.pushNoCheck(first)
+	assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
 
-	if a == nil {
-		return
-	}
+	assoc.lock.Lock()
+	err = assoc.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               99,
+		advertisedReceiverWindowCredit: 64 * 1024,
+		gapAckBlocks:                   []gapAckBlock{{start: 2, end: 2}},
+	})
+	assoc.lock.Unlock()
+	require.NoError(t, err)
+	assert.Empty(t, events, "events should only be reported once flushed")
 
-	a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
-	a.closeAllTimers()
+	assoc.flushCongestionEvents()
+	assert.Equal(t, CongestionEvent{
+		Type:           CongestionEventFastRecovery,
+		CwndBefore:     64 * 1024,
+		CwndAfter:      32 * 1024,
+		SsthreshBefore: 128 * 1024,
+		SsthreshAfter:  32 * 1024,
+	}, <-events)
 
-	_ = a.netConn.Close()
+	assoc.onRetransmissionTimeout(timerT3RTX, 1)
+	ev := <-events
+	assert.Equal(t, CongestionEventRTO, ev.Type)
+	assert.Equal(t, uint32(32*1024), ev.CwndBefore)
+	assert.Equal(t, assoc.MTU(), ev.CwndAfter)
+	assert.Equal(t, "RTO", ev.Type.String())
 }
 
-func pushPendingFullPacketChunks(t *testing.T, a *Association, n int) {
-	t.Helper()
-
-	userLen := int(a.MTU()) - int(commonHeaderSize+dataChunkHeaderSize)
-	assert.True(t, userLen > 0)
+func TestAssociationCongestionEventsBlockingCallback(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
 
-	for range n {
-		a.pendingQueue.push(&chunkPayloadData{
-			streamIdentifier:  0,
-			beginningFragment: true,
-			endingFragment:    true,
-			userData:          make([]byte, userLen),
-		})
-	}
-}
+	release := make(chan struct{})
+	types := make(chan CongestionEventType, 2)
+	assoc, err := createServerAssociation(Config{
+		NetConn:       &dumbConn{},
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+	}, WithOnCongestionEvent(func(ev CongestionEvent) {
+		<-release
+		types <- ev.Type
+	}))
+	require.NoError(t, err)
 
-func pushInflightRetransmitFullPacketChunks(t *testing.T, a *Association, startTSN uint32, n int) {
-	t.Helper()
+	assoc.setState(established)
+	assoc.setCWND(64 * 1024)
+	assoc.ssthresh = 128 * 1024
 
-	userLen := int(a.MTU()) - int(commonHeaderSize+dataChunkHeaderSize)
-	assert.True(t, userLen > 0)
+	// Neither call waits for the blocked callback.
+	assoc.onRetransmissionTimeout(timerT3RTX, 1)
+	assoc.lock.Lock()
+	assoc.queueCongestionEvent(CongestionEventECN, assoc.CWND(), assoc.ssthresh)
+	assoc.lock.Unlock()
+	assoc.flushCongestionEvents()
+	assert.Empty(t, types)
 
-	for i := range n {
-		tsn := startTSN + uint32(i) //nolint:gosec
-		a.inflightQueue.pushNoCheck(&chunkPayloadData{
-			tsn:              tsn,
-			streamIdentifier: 0,
-			userData:         make([]byte, userLen),
-			nSent:            1,
-			retransmit:       true,
-		})
-	}
+	close(release)
+	assert.Equal(t, CongestionEventRTO, <-types)
+	assert.Equal(t, CongestionEventECN, <-types)
 }
 
-func TestTLR_AllowSend_BudgetGatingAndFirstSendAlwaysAllowed(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestAssociationProtocolError(t *testing.T) {
+	reported := make(chan []ErrorCause, 1)
+	assoc, err := createServerAssociation(Config{
+		NetConn:       &dumbConn{},
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+	}, WithOnProtocolError(func(causes []ErrorCause) {
+		reported <- causes
+	}))
+	require.NoError(t, err)
 
 	assoc.lock.Lock()
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = true
-	assoc.tlrStartTime = time.Now()
-	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
+	assoc.setState(cookieEchoed)
+	assoc.storedCookieEcho = &chunkCookieEcho{cookie: []byte{1, 2, 3, 4}}
+	assoc.t1Cookie.start(assoc.rtoMgr.getRTO())
 	assoc.lock.Unlock()
 
-	t.Run("GatesAfterBudgetExhausted_FirstRTT_AllowsExactly4MTUsWorth", func(t *testing.T) {
-		assoc.lock.Lock()
-		defer assoc.lock.Unlock()
+	p := &packet{
+		sourcePort:      defaultSCTPSrcDstPort,
+		destinationPort: defaultSCTPSrcDstPort,
+		verificationTag: assoc.myVerificationTag,
+		chunks: []chunk{&chunkError{errorCauses: []errorCause{
+			&errorCauseUnrecognizedChunkType{unrecognizedChunk: []byte{0xb1, 0x00, 0x00, 0x04}},
+			&errorCauseStaleCookie{staleness: 1500},
+		}}},
+	}
+	raw, err := p.marshal(true)
+	require.NoError(t, err)
+	require.NoError(t, assoc.handleInbound(raw))
 
-		budget := assoc.tlrCurrentBurstBudgetScaledLocked()
-		consumed := false
+	assert.Equal(t, []ErrorCause{
+		{
+			Code: uint16(unrecognizedChunkType),
+			Name: "Unrecognized Chunk Type",
+			Info: []byte{0xb1, 0x00, 0x00, 0x04},
+		},
+		{
+			Code: uint16(staleCookieError),
+			Name: "Stale Cookie Error",
+			Info: []byte{0x00, 0x00, 0x05, 0xdc},
+		},
+	}, <-reported)
 
-		allowed := 0
-		mtu := int(assoc.MTU())
-		for assoc.tlrAllowSendLocked(&budget, &consumed, mtu) {
-			allowed++
+	// The stale cookie restarts the handshake with a new INIT.
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+	assert.Equal(t, cookieWait, assoc.getState())
+	assert.False(t, assoc.t1Cookie.isRunning())
+	assert.True(t, assoc.t1Init.isRunning())
+	assert.Nil(t, assoc.storedCookieEcho)
+	require.NotNil(t, assoc.storedInit)
+	sent := assoc.controlQueue.popAll()
+	require.Len(t, sent, 1)
+	assert.Equal(t, []chunk{assoc.storedInit}, sent[0].chunks)
+	assoc.t1Init.stop()
+
+	// asking for the cookie lifetime to be extended by the staleness plus
+	// at most one second
+	var preservative *paramCookiePreservative
+	for _, p := range assoc.storedInit.params {
+		if c, ok := p.(*paramCookiePreservative); ok {
+			preservative = c
 		}
+	}
+	require.NotNil(t, preservative)
+	assert.GreaterOrEqual(t, preservative.lifeSpanIncrement, uint32(1))
+	assert.LessOrEqual(t, preservative.lifeSpanIncrement, uint32(1002))
+}
 
-		assert.Equal(t, 4, allowed)
-	})
+func TestAssociationProtocolErrorBlockingCallback(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
 
-	t.Run("FirstSendAllowedEvenIfBudgetTooSmall", func(t *testing.T) {
-		assoc.lock.Lock()
-		defer assoc.lock.Unlock()
-
-		budget := int64(1000)
-		consumed := false
+	release := make(chan struct{})
+	reported := make(chan []byte, 2)
+	assoc, err := createServerAssociation(Config{
+		NetConn:       &dumbConn{},
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+	}, WithOnProtocolError(func(causes []ErrorCause) {
+		<-release
+		reported <- causes[0].Info
+	}))
+	require.NoError(t, err)
+	assoc.setState(established)
 
-		ok1 := assoc.tlrAllowSendLocked(&budget, &consumed, int(assoc.MTU()))
-		ok2 := assoc.tlrAllowSendLocked(&budget, &consumed, int(assoc.MTU()))
+	// Neither packet waits for the blocked callback.
+	for _, typ := range []byte{0xb1, 0xb2} {
+		p := &packet{
+			sourcePort:      defaultSCTPSrcDstPort,
+			destinationPort: defaultSCTPSrcDstPort,
+			verificationTag: assoc.myVerificationTag,
+			chunks: []chunk{&chunkError{errorCauses: []errorCause{
+				&errorCauseUnrecognizedChunkType{unrecognizedChunk: []byte{typ, 0x00, 0x00, 0x04}},
+			}}},
+		}
+		raw, err := p.marshal(true)
+		require.NoError(t, err)
+		require.NoError(t, assoc.handleInbound(raw))
+	}
+	assert.Empty(t, reported)
 
-		assert.True(t, ok1)
-		assert.False(t, ok2)
-		assert.True(t, consumed)
-		assert.Equal(t, int64(0), budget)
-	})
+	close(release)
+	assert.Equal(t, []byte{0xb1, 0x00, 0x00, 0x04}, <-reported)
+	assert.Equal(t, []byte{0xb2, 0x00, 0x00, 0x04}, <-reported)
 }
 
-func TestTLR_Begin_SetsEndTSNToHighestOutstanding(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestAssociation_UnknownChunk(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		typ    chunkType
+		skip   bool
+		report bool
+	}{
+		{"stop", 0x0f, false, false},
+		{"stop and report", 0x4f, false, true},
+		{"skip", 0x8f, true, false},
+		{"skip and report", 0xcf, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assoc := createTestAssociation(t, Config{})
+			assoc.setState(established)
+			assoc.sourcePort = defaultSCTPSrcDstPort
+			assoc.destinationPort = defaultSCTPSrcDstPort
+
+			unknown := &chunkUnknown{chunkHeader{typ: tc.typ, raw: []byte{1, 2, 3, 4}}}
+			p := &packet{
+				sourcePort:      defaultSCTPSrcDstPort,
+				destinationPort: defaultSCTPSrcDstPort,
+				verificationTag: assoc.myVerificationTag,
+				chunks: []chunk{
+					unknown,
+					&chunkHeartbeat{params: []param{&paramHeartbeatInfo{heartbeatInformation: []byte{9}}}},
+				},
+			}
+			raw, err := p.marshal(true)
+			require.NoError(t, err)
+			require.NoError(t, assoc.handleInbound(raw))
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+			assoc.lock.Lock()
+			defer assoc.lock.Unlock()
+			var sent []chunk
+			for _, p := range assoc.controlQueue.popAll() {
+				sent = append(sent, p.chunks...)
+			}
 
-	assoc.cumulativeTSNAckPoint = 99
-	pushInflightRetransmitFullPacketChunks(t, assoc, 100, 5) // TSN 100..104 exist
+			var errChunk *chunkError
+			var heartbeatAck *chunkHeartbeatAck
+			for _, c := range sent {
+				switch c := c.(type) {
+				case *chunkError:
+					errChunk = c
+				case *chunkHeartbeatAck:
+					heartbeatAck = c
+				}
+			}
 
-	assoc.tlrBeginLocked()
+			assert.Equal(t, tc.skip, heartbeatAck != nil, "HEARTBEAT after the unknown chunk processed")
+			if !tc.report {
+				assert.Nil(t, errChunk)
 
-	assert.True(t, assoc.tlrActive)
-	assert.True(t, assoc.tlrFirstRTT)
-	assert.False(t, assoc.tlrHadAdditionalLoss)
-	assert.Equal(t, uint32(104), assoc.tlrEndTSN)
-	assert.False(t, assoc.tlrStartTime.IsZero())
+				return
+			}
+			require.NotNil(t, errChunk)
+			require.Len(t, errChunk.errorCauses, 1)
+			cause, ok := errChunk.errorCauses[0].(*errorCauseUnrecognizedChunkType)
+			require.True(t, ok)
+			expected, err := unknown.marshal()
+			require.NoError(t, err)
+			assert.Equal(t, expected, cause.unrecognizedChunk)
+		})
+	}
 }
 
-func TestTLR_PhaseSwitchesToLaterOnAckProgress(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+// discardConn is a net.Conn that discards the packets written to it.
+type discardConn struct {
+	net.Conn
+}
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+func (c *discardConn) Write(b []byte) (int, error) {
+	return len(b), nil
+}
 
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = true
-	assoc.tlrStartTime = time.Now()
-	assoc.tlrEndTSN = assoc.cumulativeTSNAckPoint + 100 // ensure we don't finish
-	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
-	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+func (c *discardConn) Close() error {
+	return nil
+}
 
-	assoc.tlrMaybeFinishLocked(true) // ackProgress triggers phase change
+func TestAssociation_VerificationTag(t *testing.T) {
+	const (
+		myTag   = 0x1111
+		peerTag = 0x2222
+	)
+	heartbeat := func() chunk {
+		return &chunkHeartbeat{params: []param{&paramHeartbeatInfo{heartbeatInformation: []byte{1}}}}
+	}
 
-	assert.False(t, assoc.tlrFirstRTT)
-	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrCurrentBurstUnitsLocked())
+	for _, tc := range []struct {
+		name     string
+		chunk    chunk
+		tag      uint32
+		disabled bool
+		accepted bool
+	}{
+		{"matching tag", heartbeat(), myTag, false, true},
+		{"peer tag", heartbeat(), peerTag, false, false},
+		{"zero tag", heartbeat(), 0, false, false},
+		{"check disabled", heartbeat(), 0, true, true},
+		{"ABORT with own tag", &chunkAbort{}, myTag, false, true},
+		{"ABORT with peer tag", &chunkAbort{}, peerTag, false, false},
+		{"ABORT with reflected tag", &chunkAbort{reflectedTag: true}, peerTag, false, true},
+		{"ABORT with wrongly reflected tag", &chunkAbort{reflectedTag: true}, myTag, false, false},
+		{"SHUTDOWN COMPLETE with reflected tag", &chunkShutdownComplete{reflectedTag: true}, peerTag, false, true},
+		{"SHUTDOWN COMPLETE with peer tag", &chunkShutdownComplete{}, peerTag, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assoc := createTestAssociation(t, Config{
+				NetConn:                     &discardConn{},
+				DisableVerificationTagCheck: tc.disabled,
+			})
+			assoc.lock.Lock()
+			assoc.myVerificationTag = myTag
+			assoc.peerVerificationTag = peerTag
+			assoc.sourcePort = defaultSCTPSrcDstPort
+			assoc.destinationPort = defaultSCTPSrcDstPort
+			assoc.setState(shutdownAckSent)
+			if _, ok := tc.chunk.(*chunkHeartbeat); ok {
+				assoc.setState(established)
+			}
+			assoc.lock.Unlock()
+
+			p := &packet{
+				sourcePort:      defaultSCTPSrcDstPort,
+				destinationPort: defaultSCTPSrcDstPort,
+				verificationTag: tc.tag,
+				chunks:          []chunk{tc.chunk},
+			}
+			raw, err := p.marshal(true)
+			require.NoError(t, err)
+			err = assoc.handleInbound(raw)
+
+			switch tc.chunk.(type) {
+			case *chunkHeartbeat:
+				require.NoError(t, err)
+				var heartbeatAck bool
+				for _, p := range assoc.controlQueue.popAll() {
+					_, ok := p.chunks[0].(*chunkHeartbeatAck)
+					heartbeatAck = heartbeatAck || ok
+				}
+				assert.Equal(t, tc.accepted, heartbeatAck)
+			case *chunkAbort:
+				assert.Equal(t, tc.accepted, err != nil, "ABORT should close the association")
+			case *chunkShutdownComplete:
+				require.NoError(t, err)
+				assert.Equal(t, tc.accepted, assoc.getState() == closed)
+			}
+		})
+	}
 }
 
-func TestTLR_FirstRTTExpiresByTime_SRTTAndFallback(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+// sendTestPacket passes a packet carrying chunks to assoc as if received
+// from its peer.
+func sendTestPacket(tb testing.TB, assoc *Association, tag uint32, chunks ...chunk) error {
+	tb.Helper()
 
-	t.Run("UsesSRTTWhenAvailable", func(t *testing.T) {
-		assoc.lock.Lock()
-		defer assoc.lock.Unlock()
+	p := &packet{
+		sourcePort:      defaultSCTPSrcDstPort,
+		destinationPort: defaultSCTPSrcDstPort,
+		verificationTag: tag,
+		chunks:          chunks,
+	}
+	raw, err := p.marshal(true)
+	require.NoError(tb, err)
+
+	return assoc.handleInbound(raw)
+}
+
+// establishTestAssociation runs the handshake of the server association
+// assoc, as created by createTestAssociation, bundling chunks with the
+// COOKIE ECHO. The peer's initial TSN is 1000.
+func establishTestAssociation(tb testing.TB, assoc *Association, chunks ...chunk) {
+	tb.Helper()
+
+	assoc.handshakeCompletedCh = make(chan error, 1)
+	cookie := sendTestInit(tb, assoc)
+
+	chunks = append([]chunk{&chunkCookieEcho{cookie: cookie}}, chunks...)
+	require.NoError(tb, sendTestPacket(tb, assoc, assoc.myVerificationTag, chunks...))
+	require.Equal(tb, established, assoc.getState())
+	require.NoError(tb, <-assoc.handshakeCompletedCh)
+}
+
+// sendTestInit sends an INIT with initial TSN 1000 and params to the server
+// association assoc and returns the State Cookie of its INIT ACK.
+func sendTestInit(tb testing.TB, assoc *Association, params ...param) []byte {
+	tb.Helper()
+
+	init := &chunkInit{}
+	init.initialTSN = 1000
+	init.numOutboundStreams = 10
+	init.numInboundStreams = 10
+	init.initiateTag = 5678
+	init.advertisedReceiverWindowCredit = 512 * 1024
+	init.params = params
+	require.NoError(tb, sendTestPacket(tb, assoc, 0, init))
+
+	replies := assoc.controlQueue.popAll()
+	require.Len(tb, replies, 1)
+	initAck, ok := replies[0].chunks[0].(*chunkInitAck)
+	require.True(tb, ok)
+	var cookie []byte
+	for _, param := range initAck.params {
+		if c, ok := param.(*paramStateCookie); ok {
+			cookie = c.cookie
+		}
+	}
+	require.NotNil(tb, cookie)
 
-		assoc.srtt.Store(float64(100)) // 100ms
-		now := time.Now()
+	return cookie
+}
 
-		assoc.tlrActive = true
-		assoc.tlrFirstRTT = true
-		assoc.tlrStartTime = now.Add(-150 * time.Millisecond)
+// hmacCookieGenerator signs the CookieInfo with HMAC-SHA256 instead of
+// storing the cookie.
+type hmacCookieGenerator struct {
+	key       []byte
+	generated atomic.Int32
+	validated atomic.Int32
+}
 
-		assoc.tlrUpdatePhaseLocked(now)
-		assert.False(t, assoc.tlrFirstRTT)
-	})
+func (g *hmacCookieGenerator) sign(info CookieInfo) []byte {
+	mac := hmac.New(sha256.New, g.key)
+	_, _ = fmt.Fprintf(mac, "%d/%d/%d/%d/%d/%d", info.LocalVerificationTag, info.PeerVerificationTag,
+		info.SourcePort, info.DestinationPort, info.Created.UnixNano(), info.LifeSpanIncrement)
 
-	t.Run("FallsBackTo1sWhenNoSRTT", func(t *testing.T) {
-		assoc.lock.Lock()
-		defer assoc.lock.Unlock()
+	return mac.Sum(nil)
+}
 
-		assoc.srtt.Store(float64(0))
-		now := time.Now()
+func (g *hmacCookieGenerator) GenerateCookie(info CookieInfo) ([]byte, error) {
+	g.generated.Add(1)
 
-		assoc.tlrActive = true
-		assoc.tlrFirstRTT = true
-		assoc.tlrStartTime = now.Add(-500 * time.Millisecond)
+	return g.sign(info), nil
+}
 
-		assoc.tlrUpdatePhaseLocked(now)
-		assert.True(t, assoc.tlrFirstRTT)
+func (g *hmacCookieGenerator) ValidateCookie(cookie []byte, info CookieInfo) bool {
+	g.validated.Add(1)
 
-		assoc.tlrStartTime = now.Add(-1100 * time.Millisecond)
-		assoc.tlrUpdatePhaseLocked(now)
-		assert.False(t, assoc.tlrFirstRTT)
-	})
+	return hmac.Equal(cookie, g.sign(info))
 }
 
-func TestTLR_ApplyAdditionalLoss_FirstRTT_StepDownAndClamp(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestAssociation_CookieGenerator(t *testing.T) {
+	gen := &hmacCookieGenerator{key: []byte("secret")}
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+	assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieGenerator: gen})
+	establishTestAssociation(t, assoc)
+	assert.Equal(t, int32(1), gen.generated.Load())
+	assert.Equal(t, int32(1), gen.validated.Load())
+	body, created, _, ok := splitCookieTrailer(assoc.myCookie.cookie)
+	require.True(t, ok)
+	info := assoc.cookieInfo()
+	info.Created = created
+	assert.Equal(t, gen.sign(info), body)
 
-	assoc.srtt.Store(float64(1000))
-	now := time.Now()
+	for name, forge := range map[string]func(info CookieInfo) []byte{
+		"tampered": func(info CookieInfo) []byte {
+			cookie := gen.sign(info)
+			cookie[0]++
 
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = true
-	assoc.tlrStartTime = now
-	assoc.tlrHadAdditionalLoss = false
-	assoc.tlrGoodOps = 7
-	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
-	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+			return stampTestCookie(cookie, info.Created)
+		},
+		"other peer tag": func(info CookieInfo) []byte {
+			info.PeerVerificationTag++
 
-	assoc.tlrApplyAdditionalLossLocked(now.Add(10 * time.Millisecond))
-	assert.True(t, assoc.tlrHadAdditionalLoss)
-	assert.Equal(t, uint32(0), assoc.tlrGoodOps)
-	assert.Equal(t, int64(12), assoc.tlrBurstFirstRTTUnits) // 16-4
-	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrBurstLaterRTTUnits)
+			return stampTestCookie(gen.sign(info), info.Created)
+		},
+		"younger timestamp": func(info CookieInfo) []byte {
+			return stampTestCookie(gen.sign(info), info.Created.Add(time.Second))
+		},
+		"longer increment": func(info CookieInfo) []byte {
+			return appendCookieTrailer(gen.sign(info), info.Created, time.Minute)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieGenerator: gen})
+			sendTestInit(t, assoc)
+
+			info := assoc.cookieInfo()
+			info.Created = time.Now()
+			cookie := forge(info)
+			require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: cookie}))
+			assert.Equal(t, closed, assoc.getState(), "invalid cookie should be discarded")
+			assert.Zero(t, assoc.controlQueue.size(), "no COOKIE ACK should be sent")
+		})
+	}
+}
 
-	// should clamp at 8.
-	assoc.tlrApplyAdditionalLossLocked(now.Add(20 * time.Millisecond)) // 12 -> 8
-	assoc.tlrApplyAdditionalLossLocked(now.Add(30 * time.Millisecond)) // 8 -> 8 (clamped)
-	assert.Equal(t, int64(tlrBurstMinFirstRTT), assoc.tlrBurstFirstRTTUnits)
+// stampTestCookie appends the creation time and no lifetime increment to a
+// generated State Cookie.
+func stampTestCookie(cookie []byte, created time.Time) []byte {
+	return appendCookieTrailer(cookie, created, 0)
 }
 
-func TestTLR_ApplyAdditionalLoss_LaterRTT_StepDownAndClamp(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestAssociation_StaleCookie(t *testing.T) {
+	checkStaleCookieError := func(t *testing.T, assoc *Association) {
+		t.Helper()
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+		replies := assoc.controlQueue.popAll()
+		require.Len(t, replies, 1)
+		assert.Equal(t, uint32(5678), replies[0].verificationTag)
+		require.Len(t, replies[0].chunks, 1)
+		errChunk, ok := replies[0].chunks[0].(*chunkError)
+		require.True(t, ok)
+		require.Len(t, errChunk.errorCauses, 1)
+		stale, ok := errChunk.errorCauses[0].(*errorCauseStaleCookie)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, stale.staleness, uint32(60_000_000), "stale by at least a minute")
+		assert.Less(t, stale.staleness, uint32(70_000_000))
+		assert.Equal(t, closed, assoc.getState())
+	}
 
-	now := time.Now()
+	t.Run("default", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieLifetime: time.Minute})
+		cookie := sendTestInit(t, assoc)
+		body, _, _, ok := splitCookieTrailer(cookie)
+		require.True(t, ok)
 
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = false
-	assoc.tlrStartTime = now.Add(-10 * time.Second) // irrelevant when tlrFirstRTT=false
-	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
-	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+		old := stampTestCookie(body, time.Now().Add(-2*time.Minute))
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		checkStaleCookieError(t, assoc)
 
-	for range 10 {
-		assoc.tlrApplyAdditionalLossLocked(now)
-	}
+		// the peer restarts the handshake
+		establishTestAssociation(t, assoc)
+	})
 
-	assert.Equal(t, int64(tlrBurstDefaultFirstRTT), assoc.tlrBurstFirstRTTUnits)
-	assert.Equal(t, int64(tlrBurstMinLaterRTT), assoc.tlrBurstLaterRTTUnits) // clamped at 5
-}
+	t.Run("generator", func(t *testing.T) {
+		gen := &hmacCookieGenerator{key: []byte("secret")}
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieGenerator: gen})
+		assert.Equal(t, defaultCookieLifetime, assoc.cookieLifetime)
+		sendTestInit(t, assoc)
+
+		info := assoc.cookieInfo()
+		info.Created = time.Now().Add(-2 * time.Minute)
+		old := stampTestCookie(gen.sign(info), info.Created)
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		checkStaleCookieError(t, assoc)
+	})
 
-func TestTLR_MaybeFinish_EndsAndClearsState_AndGoodOpsReset(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+	t.Run("cookie preservative", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieLifetime: 30 * time.Second})
+		assoc.handshakeCompletedCh = make(chan error, 1)
+		// capped at the cookie lifetime
+		cookie := sendTestInit(t, assoc, &paramCookiePreservative{lifeSpanIncrement: 90_000})
+		assert.Equal(t, 30*time.Second, assoc.cookieLifeSpanIncrement)
+		body, _, increment, ok := splitCookieTrailer(cookie)
+		require.True(t, ok)
+		assert.Equal(t, 30*time.Second, increment, "the increment should be carried in the cookie")
+
+		old := appendCookieTrailer(body, time.Now().Add(-2*time.Minute), increment)
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		checkStaleCookieError(t, assoc)
+
+		old = appendCookieTrailer(body, time.Now().Add(-50*time.Second), increment)
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		assert.Equal(t, established, assoc.getState(), "cookie should be valid for the extended lifetime")
+		require.NoError(t, <-assoc.handshakeCompletedCh)
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+		// each INIT asks again
+		assoc = createTestAssociation(t, Config{NetConn: &discardConn{}, CookieLifetime: 30 * time.Second})
+		sendTestInit(t, assoc, &paramCookiePreservative{lifeSpanIncrement: 1500})
+		assert.Equal(t, 1500*time.Millisecond, assoc.cookieLifeSpanIncrement)
+		sendTestInit(t, assoc)
+		assert.Zero(t, assoc.cookieLifeSpanIncrement)
+	})
 
-	// pretend about to complete the 16th "good op".
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = false
-	assoc.tlrHadAdditionalLoss = false
-	assoc.tlrEndTSN = 200
-	assoc.cumulativeTSNAckPoint = 200
+	t.Run("cookie preservative with generator", func(t *testing.T) {
+		gen := &hmacCookieGenerator{key: []byte("secret")}
+		assoc := createTestAssociation(t, Config{
+			NetConn:         &discardConn{},
+			CookieLifetime:  30 * time.Second,
+			CookieGenerator: gen,
+		})
+		assoc.handshakeCompletedCh = make(chan error, 1)
+		sendTestInit(t, assoc, &paramCookiePreservative{lifeSpanIncrement: 20_000})
+
+		info := assoc.cookieInfo()
+		info.Created = time.Now().Add(-115 * time.Second)
+		info.LifeSpanIncrement = 20 * time.Second
+		old := appendCookieTrailer(gen.sign(info), info.Created, info.LifeSpanIncrement)
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		checkStaleCookieError(t, assoc)
+
+		// the cookie alone decides the lifetime, as for a stateless server
+		sendTestInit(t, assoc)
+		assert.Zero(t, assoc.cookieLifeSpanIncrement)
+		info = assoc.cookieInfo()
+		info.Created = time.Now().Add(-40 * time.Second)
+		info.LifeSpanIncrement = 20 * time.Second
+		old = appendCookieTrailer(gen.sign(info), info.Created, info.LifeSpanIncrement)
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, &chunkCookieEcho{cookie: old}))
+		assert.Equal(t, established, assoc.getState(), "cookie should be valid for its own increment")
+		require.NoError(t, <-assoc.handshakeCompletedCh)
+	})
 
-	assoc.tlrBurstFirstRTTUnits = tlrBurstMinFirstRTT
-	assoc.tlrBurstLaterRTTUnits = tlrBurstMinLaterRTT
-	assoc.tlrGoodOps = tlrGoodOpsResetThreshold - 1
+	t.Run("established", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, CookieLifetime: time.Millisecond})
+		establishTestAssociation(t, assoc)
+		assoc.controlQueue.popAll()
+		time.Sleep(5 * time.Millisecond)
+
+		// a retransmitted COOKIE ECHO is still acknowledged
+		echo := &chunkCookieEcho{cookie: assoc.myCookie.cookie}
+		require.NoError(t, sendTestPacket(t, assoc, assoc.myVerificationTag, echo))
+		replies := assoc.controlQueue.popAll()
+		require.Len(t, replies, 1)
+		_, ok := replies[0].chunks[0].(*chunkCookieAck)
+		assert.True(t, ok)
+	})
+}
 
-	assoc.tlrMaybeFinishLocked(false)
+func TestAssociation_CookieEchoBundledWithData(t *testing.T) {
+	assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
 
-	// finished -> cleared
-	assert.False(t, assoc.tlrActive)
-	assert.False(t, assoc.tlrFirstRTT)
-	assert.False(t, assoc.tlrHadAdditionalLoss)
-	assert.Equal(t, uint32(0), assoc.tlrEndTSN)
+	// A browser may send its first DATA in the packet carrying the COOKIE ECHO.
+	establishTestAssociation(t, assoc, &chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		tsn:               1000,
+		streamIdentifier:  1,
+		payloadType:       PayloadTypeWebRTCString,
+		userData:          []byte("hello"),
+	})
 
-	// reset after 16 good ops
-	assert.Equal(t, int64(tlrBurstDefaultFirstRTT), assoc.tlrBurstFirstRTTUnits)
-	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrBurstLaterRTTUnits)
-	assert.Equal(t, uint32(0), assoc.tlrGoodOps)
-}
+	replies := assoc.controlQueue.popAll()
+	require.Len(t, replies, 1)
+	assert.IsType(t, &chunkCookieAck{}, replies[0].chunks[0])
 
-func TestTLR_PopPendingDataChunksToSend_RespectsBurstBudget_FirstRTT_OnePacketPerChunk(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+	s, ok := assoc.GetStream(1)
+	require.True(t, ok, "the bundled DATA should open the stream")
+	buf := make([]byte, 16)
+	n, ppi, err := s.ReadSCTP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+	assert.Equal(t, PayloadTypeWebRTCString, ppi)
 
 	assoc.lock.Lock()
 	defer assoc.lock.Unlock()
+	assert.NotEqual(t, ackStateIdle, assoc.ackState, "a SACK should be scheduled")
+	assert.Equal(t, uint32(1000), assoc.createSelectiveAckChunk().cumulativeTSNAck, "the bundled DATA should be acked")
+}
+
+// FuzzAssociationHandleInbound feeds packets built from data to a server
+// association, either fresh or established. data is a sequence of packets,
+// each a length byte followed by that many bytes of chunks. Packets get a
+// valid checksum and verification tag so that the chunks reach the state
+// machine.
+func FuzzAssociationHandleInbound(f *testing.F) {
+	vectors := ChunkVectors()
+	for _, vector := range vectors {
+		f.Add(false, append([]byte{byte(len(vector.Raw))}, vector.Raw...))
+		f.Add(true, append([]byte{byte(len(vector.Raw))}, vector.Raw...))
+	}
+	var all []byte
+	for _, vector := range vectors {
+		all = append(all, byte(len(vector.Raw)))
+		all = append(all, vector.Raw...)
+	}
+	f.Add(true, all)
 
-	assoc.setCWND(1_000_000)
-	assoc.setRWND(1_000_000)
+	f.Fuzz(func(t *testing.T, establish bool, data []byte) {
+		assoc := createTestAssociation(t, Config{
+			NetConn:       &discardConn{},
+			LoggerFactory: &logging.DefaultLoggerFactory{},
+		})
+		defer assoc.close() //nolint:errcheck
+		if establish {
+			establishTestAssociation(t, assoc)
+		}
 
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = true
-	assoc.tlrStartTime = time.Now()
-	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT // 4 MTU
+		for len(data) > 0 {
+			n := min(int(data[0]), len(data)-1)
+			chunks := data[1 : 1+n]
+			data = data[1+n:]
+
+			raw := make([]byte, commonHeaderSize, int(commonHeaderSize)+len(chunks))
+			binary.BigEndian.PutUint16(raw[0:], defaultSCTPSrcDstPort)
+			binary.BigEndian.PutUint16(raw[2:], defaultSCTPSrcDstPort)
+			if len(chunks) == 0 || chunkType(chunks[0]) != ctInit {
+				assoc.lock.RLock()
+				binary.BigEndian.PutUint32(raw[4:], assoc.myVerificationTag)
+				assoc.lock.RUnlock()
+			}
+			raw = append(raw, chunks...)
+			binary.LittleEndian.PutUint32(raw[8:], generatePacketChecksum(raw))
 
-	pushPendingFullPacketChunks(t, assoc, 10)
+			if err := assoc.handleInbound(raw); err != nil {
+				// the association would be closed
+				return
+			}
+			state := assoc.getState()
+			require.NotContains(t, getAssociationStateString(state), "Invalid", "state %d", state)
+		}
+	})
+}
 
-	budget := assoc.tlrCurrentBurstBudgetScaledLocked()
-	consumed := false
+func TestAssociation_OutOfTheBlue(t *testing.T) {
+	const strayTag = 0x3333
 
-	chunks, _ := assoc.popPendingDataChunksToSend(&budget, &consumed)
+	for _, tc := range []struct {
+		name  string
+		chunk chunk
+		reply chunkType // 0 for none
+	}{
+		{"DATA", &chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          []byte("stray"),
+		}, ctAbort},
+		{"SACK", &chunkSelectiveAck{}, ctAbort},
+		{"HEARTBEAT", &chunkHeartbeat{
+			params: []param{&paramHeartbeatInfo{heartbeatInformation: []byte{1}}},
+		}, ctAbort},
+		{"SHUTDOWN", &chunkShutdown{}, ctAbort},
+		{"SHUTDOWN ACK", &chunkShutdownAck{}, ctShutdownComplete},
+		{"SHUTDOWN COMPLETE", &chunkShutdownComplete{}, 0},
+		{"ABORT", &chunkAbort{}, 0},
+		{"COOKIE ACK", &chunkCookieAck{}, 0},
+		{"ERROR with stale cookie", &chunkError{
+			errorCauses: []errorCause{&errorCauseStaleCookie{staleness: 1}},
+		}, 0},
+		{"ERROR", &chunkError{
+			errorCauses: []errorCause{&errorCauseUnrecognizedChunkType{unrecognizedChunk: []byte{0xb1, 0x00, 0x00, 0x04}}},
+		}, ctAbort},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+			require.Equal(t, closed, assoc.getState())
+
+			p := &packet{
+				sourcePort:      5001,
+				destinationPort: defaultSCTPSrcDstPort,
+				verificationTag: strayTag,
+				chunks:          []chunk{tc.chunk},
+			}
+			raw, err := p.marshal(true)
+			require.NoError(t, err)
+			require.NoError(t, assoc.handleInbound(raw))
 
-	// 4 MTU burst, each chunk == 1 full MTU packet, so 4 chunks moved.
-	assert.Equal(t, 4, len(chunks))
-	assert.Equal(t, 4, assoc.inflightQueue.size())
-	assert.Equal(t, 6, assoc.pendingQueue.size())
-	assert.True(t, consumed)
-	assert.Equal(t, int64(0), budget)
-}
+			replies := assoc.controlQueue.popAll()
+			if tc.reply == 0 {
+				assert.Empty(t, replies)
 
-func TestTLR_GetDataPacketsToRetransmit_RespectsBurstBudget_LaterRTT(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+				return
+			}
+			require.Len(t, replies, 1)
+			reply := replies[0]
+			assert.Equal(t, uint32(strayTag), reply.verificationTag, "tag should be reflected")
+			assert.Equal(t, uint16(defaultSCTPSrcDstPort), reply.sourcePort)
+			assert.Equal(t, uint16(5001), reply.destinationPort)
+			require.Len(t, reply.chunks, 1)
+
+			switch c := reply.chunks[0].(type) {
+			case *chunkAbort:
+				assert.Equal(t, ctAbort, tc.reply)
+				assert.True(t, c.reflectedTag)
+			case *chunkShutdownComplete:
+				assert.Equal(t, ctShutdownComplete, tc.reply)
+				assert.True(t, c.reflectedTag)
+			default:
+				assert.Fail(t, "unexpected reply", "%v", c)
+			}
+		})
+	}
 
-	assoc.lock.Lock()
-	defer assoc.lock.Unlock()
+	t.Run("reflected ABORT closes the peer", func(t *testing.T) {
+		// the peer of a stale association keeps sending with the old tag
+		peer := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		peer.setState(established)
+		peer.sourcePort = defaultSCTPSrcDstPort
+		peer.destinationPort = defaultSCTPSrcDstPort
+		peer.peerVerificationTag = strayTag
 
-	assoc.setCWND(1_000_000)
-	assoc.setRWND(1_000_000)
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		raw, err := peer.marshalPacket(peer.createPacket([]chunk{&chunkSelectiveAck{}}))
+		require.NoError(t, err)
+		require.NoError(t, assoc.handleInbound(raw))
 
-	assoc.tlrActive = true
-	assoc.tlrFirstRTT = false
-	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT // 2 MTU
-	assoc.cumulativeTSNAckPoint = 99
+		replies := assoc.controlQueue.popAll()
+		require.Len(t, replies, 1)
+		raw, err = assoc.marshalPacket(replies[0])
+		require.NoError(t, err)
+		assert.Error(t, peer.handleInbound(raw), "peer should accept the ABORT")
+	})
 
-	// 6 full-MTU packets are eligible for retransmit.
-	pushInflightRetransmitFullPacketChunks(t, assoc, 100, 6)
+	t.Run("flood is rate limited", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		p := &packet{
+			sourcePort:      5001,
+			destinationPort: defaultSCTPSrcDstPort,
+			verificationTag: strayTag,
+			chunks:          []chunk{&chunkSelectiveAck{}},
+		}
+		raw, err := p.marshal(true)
+		require.NoError(t, err)
 
-	budget := assoc.tlrCurrentBurstBudgetScaledLocked()
-	consumed := false
+		flood := func(n int) int {
+			t.Helper()
 
-	pkts := assoc.getDataPacketsToRetransmit(&budget, &consumed)
+			for range n {
+				require.NoError(t, assoc.handleInbound(raw))
+			}
 
-	// Later RTT burst is 2 MTU; each retransmit is a full MTU packet => 2 packets.
-	assert.Equal(t, 2, len(pkts))
-	nChunks := 0
-	for _, p := range pkts {
-		nChunks += len(p.chunks)
-	}
-	assert.Equal(t, 2, nChunks)
-	assert.True(t, consumed)
+			return len(assoc.controlQueue.popAll())
+		}
+
+		replies := flood(1000)
+		assert.GreaterOrEqual(t, replies, ootbReplyBurst)
+		assert.LessOrEqual(t, replies, ootbReplyBurst+1, "replies should be bounded by the burst")
+
+		// the bucket refills over time
+		assoc.lock.Lock()
+		assoc.ootbReplyRefilled = assoc.ootbReplyRefilled.Add(-time.Second)
+		assoc.lock.Unlock()
+		replies = flood(1000)
+		assert.GreaterOrEqual(t, replies, ootbReplyRate)
+		assert.LessOrEqual(t, replies, ootbReplyRate+1)
+	})
 }
 
-func TestPopPendingDataChunksToSend_UsesIDataChunkSizeForBudget(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestAssociation_MinCwndFloorDuringFastRecovery(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.minCwnd = 128 * 1024
+	assoc.setCWND(128 * 1024)
+	assoc.setRWND(128 * 1024)
+
+	first := mkChunk(100, time.Now())
+	first.missIndicator = 2
+	assoc.inflightQueue.pushNoCheck(first)
+	assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
 
 	assoc.lock.Lock()
 	defer assoc.lock.Unlock()
 
-	assoc.setCWND(1_000_000)
-	assoc.setRWND(1_000_000)
-	assoc.tlrActive = true
+	// The third miss report for TSN 100 starts fast recovery.
+	err := assoc.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               99,
+		advertisedReceiverWindowCredit: 128 * 1024,
+		gapAckBlocks:                   []gapAckBlock{{start: 2, end: 2}},
+	})
+	require.NoError(t, err)
+	assert.True(t, assoc.inFastRecovery)
+	assert.True(t, assoc.willRetransmitFast)
+	assert.Equal(t, uint32(64*1024), assoc.ssthresh, "ssthresh should still be halved")
+	assert.Equal(t, uint32(128*1024), assoc.CWND(), "cwnd should stay at the floor")
+	assert.True(t, assoc.minCwndWarned)
+
+	// Recovery completes normally once the exit point is acked.
+	err = assoc.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               101,
+		advertisedReceiverWindowCredit: 128 * 1024,
+	})
+	require.NoError(t, err)
+	assert.False(t, assoc.inFastRecovery)
+	assert.GreaterOrEqual(t, assoc.CWND(), uint32(128*1024))
+}
 
-	first := &chunkPayloadData{
-		beginningFragment: true,
-		endingFragment:    true,
-		userData:          []byte{0x01},
-	}
-	second := &chunkPayloadData{
-		iData:             true,
-		beginningFragment: true,
-		endingFragment:    true,
-		userData:          make([]byte, 100),
-	}
-	assoc.pendingQueue.push(first)
-	assoc.pendingQueue.push(second)
+func TestAssociation_FastRetransmitThreshold(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assert.Equal(t, defaultFastRetransmitThreshold, assoc.fastRtxThreshold)
+	assoc.fastRtxThreshold = 1
+	assoc.setCWND(64 * 1024)
+	assoc.setRWND(64 * 1024)
+	assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now()))
+	assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
 
-	firstBytes := first.chunkSizeInPacket()
-	oldSecondBytes := int(dataChunkHeaderSize) + len(second.userData)
-	oldSecondBytes += getPadding(oldSecondBytes)
-	budget := int64(int(commonHeaderSize)+firstBytes+oldSecondBytes) * tlrUnitsPerMTU
-	consumed := false
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
 
-	chunks, _ := assoc.popPendingDataChunksToSend(&budget, &consumed)
+	// A single miss report is enough with a threshold of 1.
+	err := assoc.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               99,
+		advertisedReceiverWindowCredit: 64 * 1024,
+		gapAckBlocks:                   []gapAckBlock{{start: 2, end: 2}},
+	})
+	require.NoError(t, err)
+	assert.True(t, assoc.inFastRecovery)
+	assert.True(t, assoc.willRetransmitFast)
 
-	require.Len(t, chunks, 1)
-	assert.Same(t, first, chunks[0])
-	assert.Equal(t, 1, assoc.inflightQueue.size())
-	assert.Equal(t, 1, assoc.pendingQueue.size())
+	c, ok := assoc.inflightQueue.get(100)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), c.missIndicator)
+
+	packets := assoc.gatherOutboundFastRetransmissionPackets(nil, nil, nil)
+	assert.Len(t, packets, 1, "TSN 100 should be fast retransmitted")
 }
 
-func TestGetDataPacketsToRetransmit_UsesIDataChunkSizeForBudget(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestProcessSelectiveAck_RenegedChunkRetransmitted(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.setCWND(64 * 1024)
+	assoc.setRWND(64 * 1024)
+	assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now()))
+	assoc.inflightQueue.pushNoCheck(mkChunk(101, time.Now()))
 
 	assoc.lock.Lock()
 	defer assoc.lock.Unlock()
 
-	assoc.setCWND(1_000_000)
-	assoc.setRWND(1_000_000)
-	assoc.tlrActive = true
-	assoc.cumulativeTSNAckPoint = 99
-
-	first := &chunkPayloadData{
-		tsn:        100,
-		userData:   []byte{0x01},
-		nSent:      1,
-		retransmit: true,
-	}
-	second := &chunkPayloadData{
-		tsn:        101,
-		iData:      true,
-		userData:   make([]byte, 100),
-		nSent:      1,
-		retransmit: true,
-	}
-	assoc.inflightQueue.pushNoCheck(first)
-	assoc.inflightQueue.pushNoCheck(second)
+	// TSN 101 is gap-acked first.
+	_, _, _, _, _, err := assoc.processSelectiveAck(&chunkSelectiveAck{ //nolint:dogsled
+		cumulativeTSNAck: 99,
+		gapAckBlocks:     []gapAckBlock{{start: 2, end: 2}},
+	})
+	require.NoError(t, err)
+	c, ok := assoc.inflightQueue.get(101)
+	require.True(t, ok)
+	assert.True(t, c.acked)
+	assert.Equal(t, 1, assoc.inflightQueue.getNumBytes())
 
-	firstBytes := first.chunkSizeInPacket()
-	oldSecondBytes := int(dataChunkHeaderSize) + len(second.userData)
-	oldSecondBytes += getPadding(oldSecondBytes)
-	budget := int64(int(commonHeaderSize)+firstBytes+oldSecondBytes) * tlrUnitsPerMTU
-	consumed := false
+	// The peer then drops it: the next SACKs no longer report TSN 101. The
+	// first one may be an older SACK delivered out of order.
+	_, _, _, _, _, err = assoc.processSelectiveAck(&chunkSelectiveAck{ //nolint:dogsled
+		cumulativeTSNAck: 99,
+	})
+	require.NoError(t, err)
+	assert.True(t, c.acked, "a SACK covering fewer TSNs should not be trusted")
+	assert.Zero(t, assoc.stats.getNumReneged())
 
+	_, _, _, _, _, err = assoc.processSelectiveAck(&chunkSelectiveAck{ //nolint:dogsled
+		cumulativeTSNAck: 99,
+	})
+	require.NoError(t, err)
+	assert.False(t, c.acked, "reneged chunk should be unmarked")
+	assert.True(t, c.reneged)
+	assert.Equal(t, uint64(1), assoc.stats.getNumReneged())
+	assert.Equal(t, 2, assoc.inflightQueue.getNumBytes(), "reneged bytes should count as outstanding again")
+
+	// T3-rtx expiry marks everything outstanding for retransmission.
+	assoc.inflightQueue.markAllToRetrasmit()
+	var consumed bool
+	budget := assoc.tlrCurrentBurstBudgetScaledLocked()
 	pkts := assoc.getDataPacketsToRetransmit(&budget, &consumed)
 
-	require.Len(t, pkts, 1)
-	require.Len(t, pkts[0].chunks, 1)
-	assert.Same(t, first, pkts[0].chunks[0])
-	assert.False(t, first.retransmit)
-	assert.True(t, second.retransmit)
+	var resent []uint32
+	for _, p := range pkts {
+		for _, ch := range p.chunks {
+			if d, isData := ch.(*chunkPayloadData); isData {
+				resent = append(resent, d.tsn)
+				assert.Equal(t, []byte("x"), d.userData, "reneged chunk should keep its payload")
+			}
+		}
+	}
+	assert.Equal(t, []uint32{100, 101}, resent)
+
+	// The retransmission is finally cum-acked; stream bytes are released once.
+	bytesAcked, _, _, _, _, err := assoc.processSelectiveAck(&chunkSelectiveAck{ //nolint:dogsled
+		cumulativeTSNAck: 101,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytesAcked[1])
+	assert.Equal(t, 0, assoc.inflightQueue.size())
+	assert.Equal(t, 0, assoc.inflightQueue.getNumBytes())
 }
 
-func TestGatherOutboundFastRetransmissionPackets_UsesFullDataChunkSizeForBudget(t *testing.T) {
-	assoc, peer := newTLRAssociationForTest(t)
-	defer shutdownTLRAssociationForTest(assoc, peer)
+func TestProcessSelectiveAck_OutOfOrderSackNotReneging(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.myNextTSN = 104
+	for tsn := uint32(100); tsn < 104; tsn++ {
+		assoc.inflightQueue.pushNoCheck(mkChunk(tsn, time.Now()))
+	}
 
 	assoc.lock.Lock()
 	defer assoc.lock.Unlock()
 
-	assoc.tlrActive = true
-	assoc.willRetransmitFast = true
-	assoc.cumulativeTSNAckPoint = 99
-
-	chunkPayload := &chunkPayloadData{
-		tsn:           100,
-		userData:      []byte{0x01},
-		nSent:         1,
-		missIndicator: 3,
+	sack := func(gapAckBlocks ...gapAckBlock) {
+		t.Helper()
+		_, _, _, _, _, err := assoc.processSelectiveAck(&chunkSelectiveAck{ //nolint:dogsled
+			cumulativeTSNAck: 99,
+			gapAckBlocks:     gapAckBlocks,
+		})
+		require.NoError(t, err)
 	}
-	assoc.inflightQueue.pushNoCheck(chunkPayload)
-
-	oldChunkBytes := payloadDataHeaderSize + len(chunkPayload.userData)
-	oldChunkBytes += getPadding(oldChunkBytes)
-	budget := int64(int(commonHeaderSize)+oldChunkBytes) * tlrUnitsPerMTU
-	consumed := true
 
-	rawPackets := assoc.gatherOutboundFastRetransmissionPackets(nil, &budget, &consumed)
+	// The SACK reporting TSNs 101 and 103 overtakes the older one reporting
+	// TSN 103 only, then the peer goes on reporting both.
+	sack(gapAckBlock{start: 2, end: 2}, gapAckBlock{start: 4, end: 4})
+	sack(gapAckBlock{start: 4, end: 4})
+	sack(gapAckBlock{start: 2, end: 2}, gapAckBlock{start: 4, end: 4})
 
-	assert.Empty(t, rawPackets)
-	assert.Equal(t, uint32(1), chunkPayload.nSent)
-	assert.Equal(t, uint64(0), assoc.stats.getNumFastRetrans())
+	c, ok := assoc.inflightQueue.get(101)
+	require.True(t, ok)
+	assert.True(t, c.acked)
+	assert.False(t, c.reneged)
+	assert.Zero(t, assoc.stats.getNumReneged())
+	assert.Equal(t, 2, assoc.inflightQueue.getNumBytes())
 }
 
-type dummyAddr struct{}
-
-func (dummyAddr) Network() string { return "dummy" }
-func (dummyAddr) String() string  { return "dummy" }
-
-var errFailingErrorCauseMarshal = errors.New("marshal failed")
+func TestRTOClearsFastRecovery(t *testing.T) {
+	assoc := newRackTestAssoc(t)
 
-type failingErrorCause struct{}
+	assoc.inFastRecovery = true
+	assoc.willRetransmitFast = true
+	assoc.fastRecoverExitPoint = assoc.cumulativeTSNAckPoint + 10
+	assoc.partialBytesAcked = 1234
+	assoc.setCWND(6 * assoc.MTU())
 
-func (failingErrorCause) unmarshal(_ []byte) error { return nil }
-func (failingErrorCause) marshal() ([]byte, error) { return nil, errFailingErrorCauseMarshal }
-func (failingErrorCause) length() uint16           { return 0 }
-func (failingErrorCause) String() string           { return "failing" }
-func (failingErrorCause) errorCauseCode() errorCauseCode {
-	return userInitiatedAbort
-}
+	assoc.onRetransmissionTimeout(timerT3RTX, 1)
 
-type recordConn struct {
-	mu       sync.Mutex
-	writes   [][]byte
-	done     chan struct{}
-	doneOnce sync.Once
+	assert.False(t, assoc.inFastRecovery, "RTO should exit fast recovery")
+	assert.False(t, assoc.willRetransmitFast, "RTO should clear pending fast retransmit")
+	assert.Equal(t, uint32(0), assoc.partialBytesAcked, "RTO should reset partial bytes acked")
+	assert.Equal(t, assoc.MTU(), assoc.CWND(), "RTO should reset cwnd to MTU")
+	assert.Equal(t, uint32(0), assoc.fastRecoverExitPoint, "RTO should clear fast recovery exit point")
 }
 
-func (c *recordConn) Read(_ []byte) (int, error) {
-	<-c.done
-
-	return 0, io.EOF
-}
+func newTLRAssociationForTest(t *testing.T) (*Association, net.Conn) {
+	t.Helper()
 
-func (c *recordConn) Write(p []byte) (int, error) {
-	cp := make([]byte, len(p))
-	copy(cp, p)
+	c1, c2 := net.Pipe()
 
-	c.mu.Lock()
-	c.writes = append(c.writes, cp)
-	c.mu.Unlock()
+	a := createTestAssociation(t, Config{
+		Name:          "tlr-test",
+		NetConn:       c1,
+		MTU:           1200,
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+		RTOMax:        1000,
+	})
 
-	return len(p), nil
+	return a, c2
 }
 
-func (c *recordConn) Close() error {
-	if c.done == nil {
-		return nil
+func shutdownTLRAssociationForTest(a *Association, peer net.Conn) {
+	if peer != nil {
+		_ = peer.Close()
 	}
 
-	c.doneOnce.Do(func() {
-		close(c.done)
-	})
-
-	return nil
-}
-func (c *recordConn) LocalAddr() net.Addr                { return dummyAddr{} }
-func (c *recordConn) RemoteAddr() net.Addr               { return dummyAddr{} }
-func (c *recordConn) SetDeadline(_ time.Time) error      { return nil }
-func (c *recordConn) SetReadDeadline(_ time.Time) error  { return nil }
-func (c *recordConn) SetWriteDeadline(_ time.Time) error { return nil }
-
-func (c *recordConn) firstWrite() ([]byte, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if len(c.writes) == 0 {
-		return nil, false
+	if a == nil {
+		return
 	}
 
-	return c.writes[0], true
+	a.closeWriteLoopOnce.Do(func() { close(a.closeWriteLoopCh) })
+	a.closeAllTimers()
+
+	_ = a.netConn.Close()
 }
 
-func TestAbortStillSendsWhenWriteLoopClosing(t *testing.T) {
-	conn := &recordConn{done: make(chan struct{})}
+func pushPendingFullPacketChunks(t *testing.T, a *Association, n int) {
+	t.Helper()
+
+	userLen := int(a.MTU()) - int(commonHeaderSize+dataChunkHeaderSize)
+	assert.True(t, userLen > 0)
+
+	for range n {
+		a.pendingQueue.push(&chunkPayloadData{
+			streamIdentifier:  0,
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          make([]byte, userLen),
+		})
+	}
+}
+
+func pushInflightRetransmitFullPacketChunks(t *testing.T, a *Association, startTSN uint32, n int) {
+	t.Helper()
+
+	userLen := int(a.MTU()) - int(commonHeaderSize+dataChunkHeaderSize)
+	assert.True(t, userLen > 0)
+
+	for i := range n {
+		tsn := startTSN + uint32(i) //nolint:gosec
+		a.inflightQueue.pushNoCheck(&chunkPayloadData{
+			tsn:              tsn,
+			streamIdentifier: 0,
+			userData:         make([]byte, userLen),
+			nSent:            1,
+			retransmit:       true,
+		})
+	}
+}
+
+func TestTLR_AllowSend_BudgetGatingAndFirstSendAlwaysAllowed(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = true
+	assoc.tlrStartTime = time.Now()
+	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
+	assoc.lock.Unlock()
+
+	t.Run("GatesAfterBudgetExhausted_FirstRTT_AllowsExactly4MTUsWorth", func(t *testing.T) {
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		budget := assoc.tlrCurrentBurstBudgetScaledLocked()
+		consumed := false
+
+		allowed := 0
+		mtu := int(assoc.MTU())
+		for assoc.tlrAllowSendLocked(&budget, &consumed, mtu) {
+			allowed++
+		}
+
+		assert.Equal(t, 4, allowed)
+	})
+
+	t.Run("FirstSendAllowedEvenIfBudgetTooSmall", func(t *testing.T) {
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		budget := int64(1000)
+		consumed := false
+
+		ok1 := assoc.tlrAllowSendLocked(&budget, &consumed, int(assoc.MTU()))
+		ok2 := assoc.tlrAllowSendLocked(&budget, &consumed, int(assoc.MTU()))
+
+		assert.True(t, ok1)
+		assert.False(t, ok2)
+		assert.True(t, consumed)
+		assert.Equal(t, int64(0), budget)
+	})
+}
+
+func TestTLR_Begin_SetsEndTSNToHighestOutstanding(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.cumulativeTSNAckPoint = 99
+	pushInflightRetransmitFullPacketChunks(t, assoc, 100, 5) // TSN 100..104 exist
+
+	assoc.tlrBeginLocked()
+
+	assert.True(t, assoc.tlrActive)
+	assert.True(t, assoc.tlrFirstRTT)
+	assert.False(t, assoc.tlrHadAdditionalLoss)
+	assert.Equal(t, uint32(104), assoc.tlrEndTSN)
+	assert.False(t, assoc.tlrStartTime.IsZero())
+}
+
+func TestTLR_PhaseSwitchesToLaterOnAckProgress(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = true
+	assoc.tlrStartTime = time.Now()
+	assoc.tlrEndTSN = assoc.cumulativeTSNAckPoint + 100 // ensure we don't finish
+	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
+	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+
+	assoc.tlrMaybeFinishLocked(true) // ackProgress triggers phase change
+
+	assert.False(t, assoc.tlrFirstRTT)
+	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrCurrentBurstUnitsLocked())
+}
+
+func TestTLR_FirstRTTExpiresByTime_SRTTAndFallback(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	t.Run("UsesSRTTWhenAvailable", func(t *testing.T) {
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.srtt.Store(float64(100)) // 100ms
+		now := time.Now()
+
+		assoc.tlrActive = true
+		assoc.tlrFirstRTT = true
+		assoc.tlrStartTime = now.Add(-150 * time.Millisecond)
+
+		assoc.tlrUpdatePhaseLocked(now)
+		assert.False(t, assoc.tlrFirstRTT)
+	})
+
+	t.Run("FallsBackTo1sWhenNoSRTT", func(t *testing.T) {
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.srtt.Store(float64(0))
+		now := time.Now()
+
+		assoc.tlrActive = true
+		assoc.tlrFirstRTT = true
+		assoc.tlrStartTime = now.Add(-500 * time.Millisecond)
+
+		assoc.tlrUpdatePhaseLocked(now)
+		assert.True(t, assoc.tlrFirstRTT)
+
+		assoc.tlrStartTime = now.Add(-1100 * time.Millisecond)
+		assoc.tlrUpdatePhaseLocked(now)
+		assert.False(t, assoc.tlrFirstRTT)
+	})
+}
+
+func TestTLR_ApplyAdditionalLoss_FirstRTT_StepDownAndClamp(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.srtt.Store(float64(1000))
+	now := time.Now()
+
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = true
+	assoc.tlrStartTime = now
+	assoc.tlrHadAdditionalLoss = false
+	assoc.tlrGoodOps = 7
+	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
+	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+
+	assoc.tlrApplyAdditionalLossLocked(now.Add(10 * time.Millisecond))
+	assert.True(t, assoc.tlrHadAdditionalLoss)
+	assert.Equal(t, uint32(0), assoc.tlrGoodOps)
+	assert.Equal(t, int64(12), assoc.tlrBurstFirstRTTUnits) // 16-4
+	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrBurstLaterRTTUnits)
+
+	// should clamp at 8.
+	assoc.tlrApplyAdditionalLossLocked(now.Add(20 * time.Millisecond)) // 12 -> 8
+	assoc.tlrApplyAdditionalLossLocked(now.Add(30 * time.Millisecond)) // 8 -> 8 (clamped)
+	assert.Equal(t, int64(tlrBurstMinFirstRTT), assoc.tlrBurstFirstRTTUnits)
+}
+
+func TestTLR_ApplyAdditionalLoss_LaterRTT_StepDownAndClamp(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	now := time.Now()
+
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = false
+	assoc.tlrStartTime = now.Add(-10 * time.Second) // irrelevant when tlrFirstRTT=false
+	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT
+	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT
+
+	for range 10 {
+		assoc.tlrApplyAdditionalLossLocked(now)
+	}
+
+	assert.Equal(t, int64(tlrBurstDefaultFirstRTT), assoc.tlrBurstFirstRTTUnits)
+	assert.Equal(t, int64(tlrBurstMinLaterRTT), assoc.tlrBurstLaterRTTUnits) // clamped at 5
+}
+
+func TestTLR_MaybeFinish_EndsAndClearsState_AndGoodOpsReset(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	// pretend about to complete the 16th "good op".
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = false
+	assoc.tlrHadAdditionalLoss = false
+	assoc.tlrEndTSN = 200
+	assoc.cumulativeTSNAckPoint = 200
+
+	assoc.tlrBurstFirstRTTUnits = tlrBurstMinFirstRTT
+	assoc.tlrBurstLaterRTTUnits = tlrBurstMinLaterRTT
+	assoc.tlrGoodOps = tlrGoodOpsResetThreshold - 1
+
+	assoc.tlrMaybeFinishLocked(false)
+
+	// finished -> cleared
+	assert.False(t, assoc.tlrActive)
+	assert.False(t, assoc.tlrFirstRTT)
+	assert.False(t, assoc.tlrHadAdditionalLoss)
+	assert.Equal(t, uint32(0), assoc.tlrEndTSN)
+
+	// reset after 16 good ops
+	assert.Equal(t, int64(tlrBurstDefaultFirstRTT), assoc.tlrBurstFirstRTTUnits)
+	assert.Equal(t, int64(tlrBurstDefaultLaterRTT), assoc.tlrBurstLaterRTTUnits)
+	assert.Equal(t, uint32(0), assoc.tlrGoodOps)
+}
+
+func TestTLR_PopPendingDataChunksToSend_RespectsBurstBudget_FirstRTT_OnePacketPerChunk(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = true
+	assoc.tlrStartTime = time.Now()
+	assoc.tlrBurstFirstRTTUnits = tlrBurstDefaultFirstRTT // 4 MTU
+
+	pushPendingFullPacketChunks(t, assoc, 10)
+
+	budget := assoc.tlrCurrentBurstBudgetScaledLocked()
+	consumed := false
+
+	chunks, _ := assoc.popPendingDataChunksToSend(&budget, &consumed)
+
+	// 4 MTU burst, each chunk == 1 full MTU packet, so 4 chunks moved.
+	assert.Equal(t, 4, len(chunks))
+	assert.Equal(t, 4, assoc.inflightQueue.size())
+	assert.Equal(t, 6, assoc.pendingQueue.size())
+	assert.True(t, consumed)
+	assert.Equal(t, int64(0), budget)
+}
+
+func TestTLR_GetDataPacketsToRetransmit_RespectsBurstBudget_LaterRTT(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+
+	assoc.tlrActive = true
+	assoc.tlrFirstRTT = false
+	assoc.tlrBurstLaterRTTUnits = tlrBurstDefaultLaterRTT // 2 MTU
+	assoc.cumulativeTSNAckPoint = 99
+
+	// 6 full-MTU packets are eligible for retransmit.
+	pushInflightRetransmitFullPacketChunks(t, assoc, 100, 6)
+
+	budget := assoc.tlrCurrentBurstBudgetScaledLocked()
+	consumed := false
+
+	pkts := assoc.getDataPacketsToRetransmit(&budget, &consumed)
+
+	// Later RTT burst is 2 MTU; each retransmit is a full MTU packet => 2 packets.
+	assert.Equal(t, 2, len(pkts))
+	nChunks := 0
+	for _, p := range pkts {
+		nChunks += len(p.chunks)
+	}
+	assert.Equal(t, 2, nChunks)
+	assert.True(t, consumed)
+}
+
+func TestPopPendingDataChunksToSend_UsesIDataChunkSizeForBudget(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+	assoc.tlrActive = true
+
+	first := &chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          []byte{0x01},
+	}
+	second := &chunkPayloadData{
+		iData:             true,
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          make([]byte, 100),
+	}
+	assoc.pendingQueue.push(first)
+	assoc.pendingQueue.push(second)
+
+	firstBytes := first.chunkSizeInPacket()
+	oldSecondBytes := int(dataChunkHeaderSize) + len(second.userData)
+	oldSecondBytes += getPadding(oldSecondBytes)
+	budget := int64(int(commonHeaderSize)+firstBytes+oldSecondBytes) * tlrUnitsPerMTU
+	consumed := false
+
+	chunks, _ := assoc.popPendingDataChunksToSend(&budget, &consumed)
+
+	require.Len(t, chunks, 1)
+	assert.Same(t, first, chunks[0])
+	assert.Equal(t, 1, assoc.inflightQueue.size())
+	assert.Equal(t, 1, assoc.pendingQueue.size())
+}
+
+func TestPopPendingDataChunksToSend_Nagle(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.nagle = true
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+
+	assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now()))
+	assoc.pendingQueue.push(&chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          []byte("small"),
+	})
+
+	chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+	assert.Empty(t, chunks, "small write should be held while data is outstanding")
+
+	assoc.pendingQueue.push(&chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          make([]byte, assoc.maxPayloadSize),
+	})
+	chunks, _ = assoc.popPendingDataChunksToSend(nil, nil)
+	assert.Len(t, chunks, 2, "a full packet of queued data should be sent")
+
+	assoc.inflightQueue = newPayloadQueue()
+	assoc.pendingQueue.push(&chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          []byte("small"),
+	})
+	chunks, _ = assoc.popPendingDataChunksToSend(nil, nil)
+	assert.Len(t, chunks, 1, "small write should be sent when nothing is outstanding")
+}
+
+func TestPopPendingDataChunksToSend_DroppedMessages(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+	s := assoc.createStream(1, false)
+	s.SetUnordered(true)
+	s.SetBufferedAmountHighThreshold(1000)
+	s.SetDropPolicy(DropPolicyDropOldest)
+
+	acked, err := s.WriteWithAck(make([]byte, 600), PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	_, err = s.Write(make([]byte, 2*assoc.maxPayloadSize)) // fragmented
+	require.NoError(t, err)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+	require.Len(t, chunks, 2, "only the fragments of the last message are sent")
+	assert.Equal(t, 2*int(assoc.maxPayloadSize), len(chunks[0].userData)+len(chunks[1].userData))
+	assert.Zero(t, assoc.pendingQueue.size())
+	assert.ErrorIs(t, <-acked, ErrMessageAbandoned)
+}
+
+func TestPopPendingDataChunksToSend_MaxOutstandingBytes(t *testing.T) {
+	assoc := newRackTestAssoc(t)
+	assoc.maxOutstandingBytes = 2500
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+
+	for range 5 {
+		assoc.pendingQueue.push(&chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          make([]byte, 1000),
+		})
+	}
+
+	chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, 2000, assoc.inflightQueue.getNumBytes())
+
+	// a chunk larger than the cap is still sent when nothing is outstanding.
+	assoc.inflightQueue = newPayloadQueue()
+	assoc.maxOutstandingBytes = 500
+	chunks, _ = assoc.popPendingDataChunksToSend(nil, nil)
+	assert.Len(t, chunks, 1)
+}
+
+func TestPopPendingDataChunksToSend_MaxBurst(t *testing.T) {
+	push := func(assoc *Association, n, size int) {
+		for range n {
+			assoc.pendingQueue.push(&chunkPayloadData{
+				beginningFragment: true,
+				endingFragment:    true,
+				userData:          make([]byte, size),
+			})
+		}
+	}
+
+	t.Run("one chunk per packet", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.maxBurst = 2
+
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.setCWND(1_000_000)
+		assoc.setRWND(1_000_000)
+		push(assoc, 5, 1000)
+
+		for _, expected := range []int{2, 2, 1} {
+			chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+			assert.Len(t, chunks, expected)
+			assert.Len(t, assoc.bundleDataChunksIntoPackets(chunks), expected)
+		}
+	})
+
+	t.Run("bundled chunks", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.maxBurst = 1
+
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.setCWND(1_000_000)
+		assoc.setRWND(1_000_000)
+		push(assoc, 30, 100)
+
+		chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+		assert.Greater(t, len(chunks), 1)
+		assert.Less(t, len(chunks), 30)
+		assert.Len(t, assoc.bundleDataChunksIntoPackets(chunks), 1)
+		assert.Equal(t, 30-len(chunks), assoc.pendingQueue.size())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.setCWND(1_000_000)
+		assoc.setRWND(1_000_000)
+		push(assoc, 5, 1000)
+
+		chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+		assert.Len(t, chunks, 5)
+	})
+}
+
+func TestAssociationReassemblyMemoryCallback(t *testing.T) {
+	var buffered atomic.Int64
+	var calls atomic.Int32
+	onChange := func(delta int) {
+		assert.NotZero(t, delta)
+		calls.Add(1)
+		buffered.Add(int64(delta))
+	}
+
+	aClient, aServer, err := association(t, udpPiper, WithOnReassemblyMemoryChange(onChange))
+	require.NoError(t, err)
+	defer func() {
+		_ = aClient.Close()
+		_ = aServer.Close()
+	}()
+
+	s0, err := aClient.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	msg := make([]byte, 100)
+	for range 3 {
+		_, err = s0.Write(msg)
+		require.NoError(t, err)
+	}
+
+	s1, err := aServer.AcceptStream()
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return buffered.Load() == 300
+	}, time.Second, 5*time.Millisecond, "buffered data should be reported")
+	assert.Equal(t, int64(s1.getNumBytesInReassemblyQueue()), buffered.Load())
+
+	buf := make([]byte, len(msg))
+	for i := range 3 {
+		_, err = s1.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(200-100*i), buffered.Load(), "consumed data should be reported")
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestAssociation_OpenStreamAlreadyExists(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+	assoc.setState(established)
+
+	s, err := assoc.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	_, err = assoc.OpenStream(1, PayloadTypeWebRTCString)
+	assert.ErrorIs(t, err, ErrStreamAlreadyExists)
+	assert.Equal(t, PayloadTypeWebRTCBinary, s.defaultPayloadType, "existing stream should be left untouched")
+
+	// a stream created by inbound data is not handed out by OpenStream either.
+	assoc.lock.Lock()
+	accepted := assoc.getOrCreateStream(2, true, PayloadTypeUnknown)
+	assoc.lock.Unlock()
+	require.NotNil(t, accepted)
+
+	_, err = assoc.OpenStream(2, PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrStreamAlreadyExists)
+	got, ok := assoc.GetStream(2)
+	assert.True(t, ok)
+	assert.Same(t, accepted, got)
+
+	s3, err := assoc.OpenStream(3, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(3), s3.StreamIdentifier())
+}
+
+func TestAssociation_GetStream(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+	assoc.setState(established)
+
+	s, ok := assoc.GetStream(1)
+	assert.False(t, ok)
+	assert.Nil(t, s)
+	assert.Empty(t, assoc.Streams(), "lookup should not register a stream")
+
+	opened, err := assoc.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	s, ok = assoc.GetStream(1)
+	assert.True(t, ok)
+	assert.Same(t, opened, s)
+}
+
+func TestAssociationStreams(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+	assoc.setState(established)
+	assert.Empty(t, assoc.Streams())
+
+	for _, id := range []uint16{7, 2, 5} {
+		_, err := assoc.OpenStream(id, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+	}
+
+	streams := assoc.Streams()
+	require.Len(t, streams, 3)
+	for i, id := range []uint16{2, 5, 7} {
+		assert.Equal(t, id, streams[i].StreamIdentifier())
+		assert.Equal(t, StreamStateOpen, streams[i].State())
+	}
+
+	// The snapshot is not affected by later changes.
+	assoc.lock.Lock()
+	delete(assoc.streams, 5)
+	assoc.lock.Unlock()
+	assert.Len(t, streams, 3)
+	assert.Len(t, assoc.Streams(), 2)
+}
+
+func TestAssociationReceiveBufferFull(t *testing.T) {
+	called := make(chan uint16, 4)
+	assoc, err := createServerAssociation(Config{
+		NetConn:              &dumbConn{},
+		LoggerFactory:        logging.NewDefaultLoggerFactory(),
+		MaxReceiveBufferSize: 100,
+	}, WithOnReceiveBufferFull(func(streamID uint16) {
+		called <- streamID
+	}))
+	require.NoError(t, err)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	chunk := func(tsn uint32) *chunkPayloadData {
+		return &chunkPayloadData{
+			streamIdentifier:  3,
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          make([]byte, 100),
+			tsn:               tsn,
+		}
+	}
+
+	tsn := assoc.peerLastTSN() + 1
+	assert.True(t, assoc.acceptPayloadData(chunk(tsn)))
+	assert.Equal(t, uint32(0), assoc.getMyReceiverWindowCredit())
+	assert.Zero(t, assoc.Stats().ReceiveBufferFullDrops)
+
+	// Both chunks are dropped, but the callback is rate-limited.
+	assert.True(t, assoc.acceptPayloadData(chunk(tsn+1)))
+	assert.True(t, assoc.acceptPayloadData(chunk(tsn+2)))
+	assert.Equal(t, uint64(2), assoc.Stats().ReceiveBufferFullDrops)
+
+	select {
+	case streamID := <-called:
+		assert.Equal(t, uint16(3), streamID)
+	case <-time.After(time.Second):
+		assert.Fail(t, "callback should be called on drop")
+	}
+	select {
+	case <-called:
+		assert.Fail(t, "callback should be rate-limited")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAssociationReceiveBufferAutotune(t *testing.T) {
+	newAssoc := func(t *testing.T, opts ...ServerOption) *Association {
+		t.Helper()
+
+		assoc, err := createServerAssociation(append([]ServerOption{Config{
+			NetConn:              &dumbConn{},
+			LoggerFactory:        logging.NewDefaultLoggerFactory(),
+			MaxReceiveBufferSize: 10000,
+		}}, opts...)...)
+		require.NoError(t, err)
+		assoc.srtt.Store(float64(1))
+
+		return assoc
+	}
+
+	receive := func(assoc *Association, tsn uint32) {
+		assoc.acceptPayloadData(&chunkPayloadData{
+			streamIdentifier:  1,
+			beginningFragment: true,
+			endingFragment:    true,
+			unordered:         true,
+			userData:          make([]byte, 4000),
+			tsn:               tsn,
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assoc := newAssoc(t)
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		tsn := assoc.peerLastTSN() + 1
+		receive(assoc, tsn)
+		time.Sleep(5 * time.Millisecond)
+		receive(assoc, tsn+1)
+		assert.Equal(t, uint32(10000), assoc.maxReceiveBufferSize)
+	})
+
+	t.Run("grows up to the limit", func(t *testing.T) {
+		assoc := newAssoc(t, WithReceiveBufferAutotune(12000))
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		tsn := assoc.peerLastTSN() + 1
+		receive(assoc, tsn)
+		time.Sleep(5 * time.Millisecond)
+		receive(assoc, tsn+1)
+		assert.Equal(t, uint32(12000), assoc.maxReceiveBufferSize, "8000 bytes per RTT should grow to the limit")
+		assert.Equal(t, uint32(12000)-8000, assoc.getMyReceiverWindowCredit())
+		assert.Equal(t, uint32(12000)-8000, assoc.createSelectiveAckChunk().advertisedReceiverWindowCredit)
+	})
+}
+
+func TestAssociationMaxOutstandingBytes(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	const maxOutstanding = 3000
+
+	br := test.NewBridge()
+	a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+	require.NoError(t, err)
+
+	s0, s1, err := establishSessionPair(br, a0, a1, 1)
+	require.NoError(t, err)
+
+	a0.lock.Lock()
+	a0.maxOutstandingBytes = maxOutstanding
+	a0.setCWND(1_000_000)
+	a0.lock.Unlock()
+
+	msg := make([]byte, 1000)
+	for range 10 {
+		_, err = s0.WriteSCTP(msg, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+	}
+
+	// the bridge is not ticked, so nothing is acked and inflight stays at the cap.
+	assert.Eventually(t, func() bool {
+		a0.lock.RLock()
+		defer a0.lock.RUnlock()
+
+		return a0.inflightQueue.getNumBytes() == maxOutstanding
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	a0.lock.RLock()
+	assert.Equal(t, maxOutstanding, a0.inflightQueue.getNumBytes())
+	a0.lock.RUnlock()
+
+	flushBuffers(br, a0, a1)
+
+	buf := make([]byte, len(msg))
+	for range 10 {
+		n, err := s1.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, len(msg), n)
+	}
+
+	closeAssociationPair(br, a0, a1)
+}
+
+func TestGetDataPacketsToRetransmit_UsesIDataChunkSizeForBudget(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.setCWND(1_000_000)
+	assoc.setRWND(1_000_000)
+	assoc.tlrActive = true
+	assoc.cumulativeTSNAckPoint = 99
+
+	first := &chunkPayloadData{
+		tsn:        100,
+		userData:   []byte{0x01},
+		nSent:      1,
+		retransmit: true,
+	}
+	second := &chunkPayloadData{
+		tsn:        101,
+		iData:      true,
+		userData:   make([]byte, 100),
+		nSent:      1,
+		retransmit: true,
+	}
+	assoc.inflightQueue.pushNoCheck(first)
+	assoc.inflightQueue.pushNoCheck(second)
+
+	firstBytes := first.chunkSizeInPacket()
+	oldSecondBytes := int(dataChunkHeaderSize) + len(second.userData)
+	oldSecondBytes += getPadding(oldSecondBytes)
+	budget := int64(int(commonHeaderSize)+firstBytes+oldSecondBytes) * tlrUnitsPerMTU
+	consumed := false
+
+	pkts := assoc.getDataPacketsToRetransmit(&budget, &consumed)
+
+	require.Len(t, pkts, 1)
+	require.Len(t, pkts[0].chunks, 1)
+	assert.Same(t, first, pkts[0].chunks[0])
+	assert.False(t, first.retransmit)
+	assert.True(t, second.retransmit)
+}
+
+func TestGatherOutboundFastRetransmissionPackets_UsesFullDataChunkSizeForBudget(t *testing.T) {
+	assoc, peer := newTLRAssociationForTest(t)
+	defer shutdownTLRAssociationForTest(assoc, peer)
+
+	assoc.lock.Lock()
+	defer assoc.lock.Unlock()
+
+	assoc.tlrActive = true
+	assoc.willRetransmitFast = true
+	assoc.cumulativeTSNAckPoint = 99
+
+	chunkPayload := &chunkPayloadData{
+		tsn:           100,
+		userData:      []byte{0x01},
+		nSent:         1,
+		missIndicator: 3,
+	}
+	assoc.inflightQueue.pushNoCheck(chunkPayload)
+
+	oldChunkBytes := payloadDataHeaderSize + len(chunkPayload.userData)
+	oldChunkBytes += getPadding(oldChunkBytes)
+	budget := int64(int(commonHeaderSize)+oldChunkBytes) * tlrUnitsPerMTU
+	consumed := true
+
+	rawPackets := assoc.gatherOutboundFastRetransmissionPackets(nil, &budget, &consumed)
+
+	assert.Empty(t, rawPackets)
+	assert.Equal(t, uint32(1), chunkPayload.nSent)
+	assert.Equal(t, uint64(0), assoc.stats.getNumFastRetrans())
+}
+
+type dummyAddr struct{}
+
+func (dummyAddr) Network() string { return "dummy" }
+func (dummyAddr) String() string  { return "dummy" }
+
+var errFailingErrorCauseMarshal = errors.New("marshal failed")
+
+type failingErrorCause struct{}
+
+func (failingErrorCause) unmarshal(_ []byte) error { return nil }
+func (failingErrorCause) marshal() ([]byte, error) { return nil, errFailingErrorCauseMarshal }
+func (failingErrorCause) length() uint16           { return 0 }
+func (failingErrorCause) String() string           { return "failing" }
+func (failingErrorCause) errorCauseCode() errorCauseCode {
+	return userInitiatedAbort
+}
+
+type recordConn struct {
+	mu       sync.Mutex
+	writes   [][]byte
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (c *recordConn) Read(_ []byte) (int, error) {
+	<-c.done
+
+	return 0, io.EOF
+}
+
+func (c *recordConn) Write(p []byte) (int, error) {
+	cp := make([]byte, len(p))
+	copy(cp, p)
+
+	c.mu.Lock()
+	c.writes = append(c.writes, cp)
+	c.mu.Unlock()
+
+	return len(p), nil
+}
+
+func (c *recordConn) Close() error {
+	if c.done == nil {
+		return nil
+	}
+
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+
+	return nil
+}
+func (c *recordConn) LocalAddr() net.Addr                { return dummyAddr{} }
+func (c *recordConn) RemoteAddr() net.Addr               { return dummyAddr{} }
+func (c *recordConn) SetDeadline(_ time.Time) error      { return nil }
+func (c *recordConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (c *recordConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+func (c *recordConn) firstWrite() ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.writes) == 0 {
+		return nil, false
+	}
+
+	return c.writes[0], true
+}
+
+func TestAbortStillSendsWhenWriteLoopClosing(t *testing.T) {
+	conn := &recordConn{done: make(chan struct{})}
+	defer conn.Close() // nolint:errcheck
+
+	cfg := Config{
+		NetConn:            conn,
+		LoggerFactory:      logging.NewDefaultLoggerFactory(),
+		EnableZeroChecksum: false,
+	}
+	cfg.applyDefaults()
+	assoc, err := createAssociationFromConfig(&cfg)
+	assert.NoError(t, err)
+	assoc.initServer()
+
+	// Simulate the problematic timing: writeLoop is sitting in its select and
+	// closeWriteLoopCh gets closed (e.g. readLoop exited) while an ABORT is pending.
+	assoc.lock.Lock()
+	assoc.willSendAbort = true
+	assoc.willSendAbortCause = &errorCauseUserInitiatedAbort{upperLayerAbortReason: []byte("x")}
+	assoc.lock.Unlock()
+
+	assoc.closeWriteLoopOnce.Do(func() { close(assoc.closeWriteLoopCh) })
+
+	require.Eventually(t, func() bool {
+		raw, ok := conn.firstWrite()
+		if !ok || len(raw) <= int(commonHeaderSize) {
+			return false
+		}
+
+		return raw[commonHeaderSize] == uint8(ctAbort)
+	}, 1*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, assoc.close())
+}
+
+type abortOrderingConn struct {
+	readDeadlineOnce sync.Once
+	readDeadlineCh   chan struct{}
+
+	wroteAbortOnce sync.Once
+	wroteAbortCh   chan struct{}
+}
+
+func newAbortOrderingConn() *abortOrderingConn {
+	return &abortOrderingConn{
+		readDeadlineCh: make(chan struct{}),
+		wroteAbortCh:   make(chan struct{}),
+	}
+}
+
+func (c *abortOrderingConn) Read(_ []byte) (int, error) {
+	<-c.readDeadlineCh
+
+	return 0, net.ErrClosed
+}
+
+func (c *abortOrderingConn) Write(b []byte) (int, error) {
+	isAbort := len(b) > int(commonHeaderSize) && b[commonHeaderSize] == byte(ctAbort)
+	if isAbort {
+		time.Sleep(100 * time.Millisecond)
+		c.wroteAbortOnce.Do(func() { close(c.wroteAbortCh) })
+	}
+
+	return len(b), nil
+}
+
+func (c *abortOrderingConn) Close() error { return nil }
+
+func (c *abortOrderingConn) LocalAddr() net.Addr  { return &net.IPAddr{} }
+func (c *abortOrderingConn) RemoteAddr() net.Addr { return &net.IPAddr{} }
+
+func (c *abortOrderingConn) SetDeadline(_ time.Time) error { return nil }
+
+func (c *abortOrderingConn) SetReadDeadline(_ time.Time) error {
+	c.readDeadlineOnce.Do(func() { close(c.readDeadlineCh) })
+
+	return nil
+}
+
+func (c *abortOrderingConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+func TestAbort_WaitsForAbortWriteAttempt(t *testing.T) {
+	conn := newAbortOrderingConn()
+
+	cfg := Config{
+		NetConn:        conn,
+		LoggerFactory:  logging.NewDefaultLoggerFactory(),
+		MaxMessageSize: 1200,
+	}
+	cfg.applyDefaults()
+	assoc, err := createAssociationFromConfig(&cfg)
+	assert.NoError(t, err)
+	assoc.initServer()
+
+	assoc.Abort("test")
+
+	select {
+	case <-conn.wroteAbortCh:
+		// ok
+	default:
+		require.Fail(t, "Abort returned before ABORT write attempt")
+	}
+}
+
+func TestAbortSentChClosedWhenAbortMarshalFails(t *testing.T) {
+	conn := &recordConn{done: make(chan struct{})}
 	defer conn.Close() // nolint:errcheck
 
-	cfg := Config{
-		NetConn:            conn,
-		LoggerFactory:      logging.NewDefaultLoggerFactory(),
-		EnableZeroChecksum: false,
-	}
-	cfg.applyDefaults()
-	assoc, err := createAssociationFromConfig(&cfg)
-	assert.NoError(t, err)
-	assoc.initServer()
+	cfg := Config{
+		NetConn:        conn,
+		LoggerFactory:  logging.NewDefaultLoggerFactory(),
+		MaxMessageSize: 1200,
+	}
+	cfg.applyDefaults()
+	assoc, err := createAssociationFromConfig(&cfg)
+	assert.NoError(t, err)
+	assoc.initServer()
+
+	assoc.lock.Lock()
+	assoc.willSendAbort = true
+	assoc.willSendAbortCause = failingErrorCause{}
+	assoc.lock.Unlock()
+
+	assoc.awakeWriteLoop()
+
+	select {
+	case <-assoc.abortSentCh:
+		// ok
+	case <-time.After(200 * time.Millisecond):
+		require.Fail(t, "abortSentCh was not closed when ABORT marshaling failed")
+	}
+}
+
+func TestAssociationAbortProtocolViolation(t *testing.T) {
+	const reason = "received DATA with interleaving negotiated"
+
+	assoc := &Association{
+		awakeWriteLoopCh: make(chan struct{}, 1),
+		name:             "test-association",
+		log:              logging.NewDefaultLoggerFactory().NewLogger("sctp-test"),
+	}
+
+	assoc.lock.Lock()
+	assoc.abortProtocolViolation(reason)
+	assoc.lock.Unlock()
+
+	require.True(t, assoc.willSendAbort)
+
+	cause, ok := assoc.willSendAbortCause.(*errorCauseProtocolViolation)
+	require.True(t, ok)
+	assert.Equal(t, protocolViolation, cause.errorCauseCode())
+	assert.Equal(t, []byte(reason), cause.additionalInformation)
+
+	select {
+	case <-assoc.awakeWriteLoopCh:
+	default:
+		require.Fail(t, "abortProtocolViolation did not wake the write loop")
+	}
+}
+
+func TestAssociationSNAPInvalidInit(t *testing.T) {
+	br := test.NewBridge()
+	tokenConfig := Config{
+		MaxReceiveBufferSize: 65535,
+		EnableZeroChecksum:   false,
+	}
+	init, err := GenerateOutOfBandToken(tokenConfig)
+	assert.NoError(t, err)
+
+	_, err = ClientWithOptions(
+		WithNetConn(br.GetConn0()),
+		WithSNAP([]byte{1, 2}, init))
+	assert.ErrorIs(t, err, ErrChunkHeaderTooSmall)
+
+	_, err = ClientWithOptions(
+		WithNetConn(br.GetConn1()),
+		WithSNAP(init, []byte{1, 2}))
+	assert.ErrorIs(t, err, ErrChunkHeaderTooSmall)
+}
+
+func TestAssociationSNAP(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	loggerFactory := logging.NewDefaultLoggerFactory()
+	br := test.NewBridge()
+
+	// Use GenerateOutOfBandToken to create the init chunks
+	tokenConfig := Config{
+		MaxReceiveBufferSize: 65535,
+		EnableZeroChecksum:   false,
+	}
+	initA, err := GenerateOutOfBandToken(tokenConfig)
+	assert.NoError(t, err)
+
+	initB, err := GenerateOutOfBandToken(tokenConfig)
+	assert.NoError(t, err)
+
+	assocA, err := ClientWithOptions(
+		WithName("a"),
+		WithNetConn(br.GetConn0()),
+		WithLoggerFactory(loggerFactory),
+		WithSNAP(initA, initB))
+	assert.NoError(t, err)
+	assert.NotNil(t, assocA)
+
+	assocB, err := ClientWithOptions(
+		WithName("b"),
+		WithNetConn(br.GetConn1()),
+		WithLoggerFactory(loggerFactory),
+		WithSNAP(initB, initA))
+	assert.NoError(t, err)
+	assert.NotNil(t, assocB)
+
+	const si uint16 = 1
+	const msg = "SNAP is snappy"
+
+	streamA, err := assocA.OpenStream(si, PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+
+	_, err = streamA.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+
+	br.Process()
+
+	accepted := make(chan *Stream)
+	go func() {
+		s, errAccept := assocB.AcceptStream()
+		assert.NoError(t, errAccept)
+		accepted <- s
+	}()
+
+	flushBuffers(br, assocA, assocB)
+
+	var streamB *Stream
+	select {
+	case streamB = <-accepted:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timed out waiting for accept stream")
+	}
+
+	buf := make([]byte, 64)
+	n, ppi, err := streamB.ReadSCTP(buf)
+	assert.NoError(t, err, "ReadSCTP failed")
+	assert.Equal(t, len(msg), n, "unexpected length of received data")
+	assert.Equal(t, PayloadTypeWebRTCBinary, ppi, "unexpected ppi")
+	assert.Equal(t, msg, string(buf[:n]))
+
+	closeAssociationPair(br, assocA, assocB)
+}
+
+func TestAssociationSNAPInterleavingNegotiationPayloadChunkType(t *testing.T) {
+	tests := []struct {
+		name                string
+		localInterleaving   bool
+		remoteInterleaving  bool
+		useInterleaving     bool
+		expectedPayloadType chunkType
+	}{
+		{
+			name:                "enabled enabled uses I-DATA",
+			localInterleaving:   true,
+			remoteInterleaving:  true,
+			useInterleaving:     true,
+			expectedPayloadType: ctIData,
+		},
+		{
+			name:                "enabled disabled uses DATA",
+			localInterleaving:   true,
+			remoteInterleaving:  false,
+			useInterleaving:     false,
+			expectedPayloadType: ctPayloadData,
+		},
+		{
+			name:                "disabled enabled uses DATA",
+			localInterleaving:   false,
+			remoteInterleaving:  true,
+			useInterleaving:     false,
+			expectedPayloadType: ctPayloadData,
+		},
+		{
+			name:                "disabled disabled uses DATA",
+			localInterleaving:   false,
+			remoteInterleaving:  false,
+			useInterleaving:     false,
+			expectedPayloadType: ctPayloadData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lim := test.TimeOut(time.Second * 10)
+			defer lim.Stop()
+
+			loggerFactory := logging.NewDefaultLoggerFactory()
+			br := test.NewBridge()
+
+			localInit, err := GenerateOutOfBandToken(WithEnableInterleaving(tt.localInterleaving))
+			require.NoError(t, err)
+
+			remoteInit, err := GenerateOutOfBandToken(WithEnableInterleaving(tt.remoteInterleaving))
+			require.NoError(t, err)
+
+			assocA, err := ClientWithOptions(
+				WithName("a"),
+				WithNetConn(br.GetConn0()),
+				WithLoggerFactory(loggerFactory),
+				WithSNAP(localInit, remoteInit))
+			require.NoError(t, err)
+			require.NotNil(t, assocA)
+
+			assocB, err := ClientWithOptions(
+				WithName("b"),
+				WithNetConn(br.GetConn1()),
+				WithLoggerFactory(loggerFactory),
+				WithSNAP(remoteInit, localInit))
+			require.NoError(t, err)
+			require.NotNil(t, assocB)
+			defer closeAssociationPair(br, assocA, assocB)
+
+			require.Equal(t, tt.useInterleaving, assocA.useInterleaving)
+			require.Equal(t, tt.useInterleaving, assocB.useInterleaving)
+
+			const si uint16 = 1
+			const msg = "SNAP interleaving"
+
+			streamA, err := assocA.OpenStream(si, PayloadTypeWebRTCBinary)
+			require.NoError(t, err)
+
+			chunkTypes := capturePayloadChunkTypes(br, 0)
+
+			n, err := streamA.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
+			require.NoError(t, err)
+			require.Equal(t, len(msg), n)
+
+			br.Process()
+			requireNextPayloadChunkType(t, chunkTypes, tt.expectedPayloadType)
+
+			accepted := make(chan *Stream)
+			go func() {
+				s, errAccept := assocB.AcceptStream()
+				assert.NoError(t, errAccept)
+				accepted <- s
+			}()
+
+			flushBuffers(br, assocA, assocB)
+
+			var streamB *Stream
+			select {
+			case streamB = <-accepted:
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "timed out waiting for accept stream")
+			}
+
+			buf := make([]byte, 64)
+			n, ppi, err := streamB.ReadSCTP(buf)
+			require.NoError(t, err, "ReadSCTP failed")
+			require.Equal(t, len(msg), n, "unexpected length of received data")
+			require.Equal(t, PayloadTypeWebRTCBinary, ppi, "unexpected ppi")
+			require.Equal(t, msg, string(buf[:n]))
+		})
+	}
+}
+
+func TestAssociation_IgnoreInboundChecksum(t *testing.T) {
+	for _, ignore := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ignore=%t", ignore), func(t *testing.T) {
+			assoc := createTestAssociation(t, Config{IgnoreInboundChecksum: ignore})
+			assoc.setState(established)
+			assoc.sourcePort = defaultSCTPSrcDstPort
+			assoc.destinationPort = defaultSCTPSrcDstPort
+
+			p := &packet{
+				sourcePort:      defaultSCTPSrcDstPort,
+				destinationPort: defaultSCTPSrcDstPort,
+				verificationTag: assoc.myVerificationTag,
+				chunks: []chunk{
+					&chunkHeartbeat{params: []param{&paramHeartbeatInfo{heartbeatInformation: []byte{9}}}},
+				},
+			}
+			raw, err := p.marshal(true)
+			require.NoError(t, err)
+			raw[8] ^= 0xff
+			require.NoError(t, assoc.handleInbound(raw))
+
+			assoc.lock.Lock()
+			defer assoc.lock.Unlock()
+			var heartbeatAck *chunkHeartbeatAck
+			for _, p := range assoc.controlQueue.popAll() {
+				for _, c := range p.chunks {
+					if ack, ok := c.(*chunkHeartbeatAck); ok {
+						heartbeatAck = ack
+					}
+				}
+			}
+			assert.Equal(t, ignore, heartbeatAck != nil, "HEARTBEAT with a bad checksum processed")
+		})
+	}
+}
+
+func TestAssociation_StreamStats(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	const si uint16 = 1
+	const msg1 = "ABC"
+	const msg2 = "DEFG"
+	br := test.NewBridge()
+
+	a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+	require.NoError(t, err, "failed to create associations")
+
+	// lock RTO value at 100 [msec]
+	a0.rtoMgr.setRTO(100.0, true)
+
+	s0, s1, err := establishSessionPair(br, a0, a1, si)
+	require.NoError(t, err, "failed to establish session pair")
+
+	br.DropNextNWrites(0, 1) // drop the first packet, so that it is retransmitted
+
+	_, err = s0.WriteSCTP([]byte(msg1), PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	_, err = s0.WriteSCTP([]byte(msg2), PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	// process packets for 200 msec
+	for range 20 {
+		br.Tick()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	buf := make([]byte, 32)
+	for range 2 {
+		_, _, err = s1.ReadSCTP(buf)
+		require.NoError(t, err)
+	}
+
+	// The hello message of establishSessionPair is counted too.
+	bytes := uint64(len("Hello") + len(msg1) + len(msg2))
+
+	sent := s0.Stats()
+	assert.Equal(t, uint64(3), sent.MessagesWritten)
+	assert.Equal(t, bytes, sent.BytesWritten)
+	assert.GreaterOrEqual(t, sent.Retransmissions, uint64(1))
+	assert.Zero(t, sent.MessagesRead)
+
+	received := s1.Stats()
+	assert.Equal(t, uint64(3), received.MessagesRead)
+	assert.Equal(t, bytes, received.BytesRead)
+	assert.Equal(t, uint64(3), received.DATAsReceived)
+	assert.Equal(t, bytes, received.BytesReceived)
+	assert.Zero(t, received.MessagesWritten)
+
+	assert.Equal(t, map[uint16]StreamStats{si: sent}, a0.Stats().Streams)
+	assert.Equal(t, map[uint16]StreamStats{si: received}, a1.Stats().Streams)
+
+	closeAssociationPair(br, a0, a1)
+}
+
+func TestAssociation_CwndLimits(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{})
+		assert.Equal(t, min32(4*assoc.MTU(), max32(2*assoc.MTU(), 4380)), assoc.CWND())
+
+		assoc.setCWND(math.MaxUint32)
+		assert.Equal(t, uint32(math.MaxUint32), assoc.CWND(), "cwnd should not be capped by default")
+	})
+
+	t.Run("initial and max", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{InitialCwnd: 10 * initialMTU, MaxCwnd: 20 * initialMTU})
+		assert.Equal(t, uint32(10*initialMTU), assoc.CWND())
+
+		assoc.setCWND(30 * initialMTU)
+		assert.Equal(t, uint32(20*initialMTU), assoc.CWND())
+	})
+
+	t.Run("slow start stops at max", func(t *testing.T) {
+		const maxCwnd = 6000
+		assoc := createTestAssociation(t, Config{MaxCwnd: maxCwnd})
+		assoc.setState(established)
+
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		// slow start only grows cwnd while data is waiting to be sent
+		assoc.pendingQueue.push(&chunkPayloadData{
+			streamIdentifier:  1,
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          []byte{1},
+		})
+		assoc.ssthresh = math.MaxUint32
+		for range 10 {
+			assoc.onCumulativeTSNAckPointAdvanced(int(assoc.CWND()))
+		}
+		assert.Equal(t, uint32(maxCwnd), assoc.CWND())
+	})
+}
+
+func TestAssociation_ByteCountingLimit(t *testing.T) {
+	// trajectory returns cwnd after each SACK when every SACK acknowledges a
+	// full window.
+	trajectory := func(limit uint32) []uint32 {
+		assoc := createTestAssociation(t, Config{ByteCountingLimit: limit})
+		assoc.setState(established)
+
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		// slow start only grows cwnd while data is waiting to be sent
+		assoc.pendingQueue.push(&chunkPayloadData{
+			streamIdentifier:  1,
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          []byte{1},
+		})
+		assoc.ssthresh = math.MaxUint32
+
+		var cwnds []uint32
+		for range 4 {
+			assoc.onCumulativeTSNAckPointAdvanced(int(assoc.CWND()))
+			cwnds = append(cwnds, assoc.CWND())
+		}
+
+		return cwnds
+	}
+
+	iw := min32(4*initialMTU, max32(2*initialMTU, 4380))
+	assert.Equal(t, []uint32{2 * iw, 4 * iw, 8 * iw, 16 * iw}, trajectory(0), "cwnd doubles without ABC")
+
+	step := uint32(2 * initialMTU)
+	assert.Equal(t, []uint32{iw + step, iw + 2*step, iw + 3*step, iw + 4*step}, trajectory(2),
+		"cwnd grows by 2 MTUs per SACK with ABC")
+}
+
+func TestAssociation_IdleCwndReset(t *testing.T) {
+	// popAfterIdle sends a chunk after an idle period of an hour and returns
+	// cwnd when the chunk was sent.
+	popAfterIdle := func(assoc *Association, inflight bool) uint32 {
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+
+		assoc.setCWND(100_000)
+		assoc.setRWND(1_000_000)
+		assoc.partialBytesAcked = 1000
+		assoc.lastDataSent = time.Now().Add(-time.Hour)
+		if inflight {
+			assoc.inflightQueue.pushNoCheck(&chunkPayloadData{tsn: 1, userData: []byte{1}})
+		}
+		assoc.pendingQueue.push(&chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			userData:          []byte{1},
+		})
+
+		chunks, _ := assoc.popPendingDataChunksToSend(nil, nil)
+		assert.Len(t, chunks, 1)
+		assert.WithinDuration(t, time.Now(), assoc.lastDataSent, time.Minute)
+
+		return assoc.CWND()
+	}
+
+	t.Run("reset after idle", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assert.Equal(t, assoc.getInitialCwnd(), popAfterIdle(assoc, false))
+		assert.Equal(t, uint32(0), assoc.partialBytesAcked)
+	})
+
+	t.Run("configured initial cwnd", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.initialCwnd = 20_000
+		assert.Equal(t, uint32(20_000), popAfterIdle(assoc, false))
+	})
+
+	t.Run("data outstanding", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assert.Equal(t, uint32(100_000), popAfterIdle(assoc, true))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.idleCwndReset = false
+		assert.Equal(t, uint32(100_000), popAfterIdle(assoc, false))
+	})
+
+	t.Run("not idle long enough", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.idleCwndResetTimeout = 2 * time.Hour
+		assert.Equal(t, uint32(100_000), popAfterIdle(assoc, false))
+	})
+}
+
+func TestAssociation_MaxStreams(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	udp1, udp2 := createUDPConnPair()
+	a1, a2, err := createAssociationPairWithConfig(udp1, udp2, Config{MaxStreams: 2})
+	require.NoError(t, err)
+	defer noErrorClose(t, a2.Close)
+	defer noErrorClose(t, a1.Close)
+
+	// let a1 exceed the negotiated count, as a misbehaving peer would
+	a1.lock.Lock()
+	a1.myMaxNumOutboundStreams = 3
+	a1.lock.Unlock()
+
+	for si := range uint16(3) {
+		s, err := a1.OpenStream(si, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		_, err = s.WriteSCTP([]byte("hello"), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+	}
+
+	for range 2 {
+		_, err = a2.AcceptStream()
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return a2.Stats().StreamLimitDrops == 1
+	}, time.Second, 10*time.Millisecond, "DATA of the third stream should be refused")
+	require.Eventually(t, func() bool {
+		return a1.BufferedAmount() == 0
+	}, time.Second, 10*time.Millisecond, "refused DATA should still be acknowledged")
+
+	a2.lock.RLock()
+	_, ok := a2.streams[2]
+	numStreams := len(a2.streams)
+	a2.lock.RUnlock()
+	assert.False(t, ok)
+	assert.Equal(t, 2, numStreams)
+}
+
+func TestAssociation_NegotiatedStreams(t *testing.T) {
+	t.Run("INIT", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{MaxStreams: 100})
+		outbound, inbound := assoc.NegotiatedStreams()
+		assert.Equal(t, uint16(100), outbound)
+		assert.Equal(t, uint16(100), inbound)
+
+		init := &chunkInit{}
+		init.initialTSN = 1234
+		init.numOutboundStreams = 5
+		init.numInboundStreams = 7
+		init.initiateTag = 5678
+		init.advertisedReceiverWindowCredit = 512 * 1024
+		_, err := assoc.handleInit(&packet{sourcePort: 5001, destinationPort: 5002}, init)
+		require.NoError(t, err)
+
+		outbound, inbound = assoc.NegotiatedStreams()
+		assert.Equal(t, uint16(7), outbound, "should be capped by the peer's inbound streams")
+		assert.Equal(t, uint16(5), inbound, "should be capped by the peer's outbound streams")
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		udp1, udp2 := createUDPConnPair()
+		a1, a2, err := createAssociationPairWithConfig(udp1, udp2, Config{MaxStreams: 10})
+		require.NoError(t, err)
+		defer noErrorClose(t, a2.Close)
+		defer noErrorClose(t, a1.Close)
+
+		for _, a := range []*Association{a1, a2} {
+			outbound, inbound := a.NegotiatedStreams()
+			assert.Equal(t, uint16(10), outbound)
+			assert.Equal(t, uint16(10), inbound)
+		}
+	})
+}
+
+func TestAssociation_OpenStreamOutOfRange(t *testing.T) {
+	udp1, udp2 := createUDPConnPair()
+	a1, a2, err := createAssociationPairWithConfig(udp1, udp2, Config{MaxStreams: 10})
+	require.NoError(t, err)
+	defer noErrorClose(t, a2.Close)
+	defer noErrorClose(t, a1.Close)
+
+	_, err = a1.OpenStream(10, PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrStreamIDOutOfRange)
+	_, err = a1.OpenStream(math.MaxUint16, PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrStreamIDOutOfRange)
+	assert.Empty(t, a1.Streams())
+
+	s, err := a1.OpenStream(9, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(9), s.StreamIdentifier())
+
+	t.Run("before handshake", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{MaxStreams: 10})
+		assoc.setState(cookieWait)
+		_, err := assoc.OpenStream(20, PayloadTypeWebRTCBinary)
+		assert.NoError(t, err, "should not be checked before the count is negotiated")
+	})
+}
+
+func TestAssociation_OnTrace(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	var mu sync.Mutex
+	events := map[TraceEventKind][]TraceEvent{}
+	config := Config{}
+	require.NoError(t, WithOnTrace(func(ev TraceEvent) {
+		mu.Lock()
+		events[ev.Kind] = append(events[ev.Kind], ev)
+		mu.Unlock()
+	}).applyClient(&config))
+
+	udp1, udp2 := createUDPConnPair()
+	a1, a2, err := createAssociationPairWithConfig(udp1, udp2, config)
+	require.NoError(t, err)
+	defer noErrorClose(t, a2.Close)
+	defer noErrorClose(t, a1.Close)
+
+	s, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+	_, err = s.WriteSCTP([]byte("hello"), PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(events[TraceEventCumulativeTSNAdvanced]) > 0 && len(events[TraceEventReceive]) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events[TraceEventSend], 1)
+	sent := events[TraceEventSend][0]
+	assert.Equal(t, TraceEvent{
+		Kind:             TraceEventSend,
+		TSN:              sent.TSN,
+		StreamIdentifier: 1,
+		NumSent:          1,
+		Length:           5,
+	}, sent)
+	require.Len(t, events[TraceEventReceive], 1)
+	assert.Equal(t, sent.TSN, events[TraceEventReceive][0].TSN)
+	assert.Equal(t, uint16(1), events[TraceEventReceive][0].StreamIdentifier)
+	require.Len(t, events[TraceEventSacked], 1)
+	assert.Equal(t, sent.TSN, events[TraceEventSacked][0].TSN)
+	assert.Equal(t, sent.TSN, events[TraceEventCumulativeTSNAdvanced][0].TSN)
+	assert.Equal(t, "CumulativeTSNAdvanced", TraceEventCumulativeTSNAdvanced.String())
+}
+
+func TestAssociation_SackPolicy(t *testing.T) {
+	newAssoc := func(policy SackPolicy) *Association {
+		assoc := createTestAssociation(t, Config{SackPolicy: policy})
+		assoc.payloadQueue.init(0)
+		assoc.setState(established)
+		t.Cleanup(func() { assoc.ackTimer.stop() })
+
+		return assoc
+	}
+	receive := func(assoc *Association) {
+		assoc.handleChunksStart()
+		assoc.handleData(&chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			tsn:               assoc.peerLastTSN() + 1,
+			streamIdentifier:  1,
+			userData:          []byte("ping"),
+		})
+		assoc.handleChunksEnd()
+	}
+	// gather returns the chunks of the packets sent by assoc.
+	gather := func(assoc *Association) [][]chunk {
+		raws, _ := assoc.gatherOutbound()
+		packets := make([][]chunk, 0, len(raws))
+		for _, raw := range raws {
+			pkt, err := assoc.unmarshalPacket(raw)
+			require.NoError(t, err)
+			packets = append(packets, pkt.chunks)
+		}
+
+		return packets
+	}
+	queueData := func(assoc *Association, size int) {
+		assoc.setCWND(64 * 1024)
+		assoc.setRWND(64 * 1024)
+		assoc.pendingQueue.push(&chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			streamIdentifier:  1,
+			userData:          make([]byte, size),
+		})
+	}
+
+	t.Run("delayed", func(t *testing.T) {
+		assoc := newAssoc(SackPolicyDelayed)
+		receive(assoc)
+		assert.Equal(t, ackStateDelay, assoc.ackState)
+
+		queueData(assoc, 10)
+		packets := gather(assoc)
+		require.Len(t, packets, 1)
+		assert.IsType(t, &chunkPayloadData{}, packets[0][0], "the delayed SACK should wait for the timer")
+		assert.Equal(t, ackStateDelay, assoc.ackState)
+	})
+
+	t.Run("immediate", func(t *testing.T) {
+		assoc := newAssoc(SackPolicyImmediate)
+		receive(assoc)
+		assert.Equal(t, ackStateImmediate, assoc.ackState)
+
+		packets := gather(assoc)
+		require.Len(t, packets, 1)
+		assert.IsType(t, &chunkSelectiveAck{}, packets[0][0])
+	})
+
+	t.Run("bundle", func(t *testing.T) {
+		assoc := newAssoc(SackPolicyBundle)
+		receive(assoc)
+		assert.Equal(t, ackStateDelay, assoc.ackState)
+		assert.Empty(t, gather(assoc), "without DATA the SACK should stay delayed")
+
+		queueData(assoc, 10)
+		packets := gather(assoc)
+		require.Len(t, packets, 1, "the SACK should ride along with the DATA")
+		require.Len(t, packets[0], 2)
+		assert.IsType(t, &chunkSelectiveAck{}, packets[0][0])
+		assert.IsType(t, &chunkPayloadData{}, packets[0][1])
+		assert.Equal(t, ackStateIdle, assoc.ackState)
+		assert.Equal(t, uint64(1), assoc.stats.getNumSACKsSent())
+	})
+
+	t.Run("bundle full packet", func(t *testing.T) {
+		assoc := newAssoc(SackPolicyBundle)
+		receive(assoc)
+		assoc.onAckTimeout()
+
+		queueData(assoc, int(assoc.maxPayloadSize))
+		packets := gather(assoc)
+		require.Len(t, packets, 2, "the SACK should not fit in a full DATA packet")
+		assert.IsType(t, &chunkPayloadData{}, packets[0][0])
+		assert.IsType(t, &chunkSelectiveAck{}, packets[1][0])
+	})
+}
+
+func TestAssociation_SackFrequency(t *testing.T) {
+	assoc := createTestAssociation(t, Config{SackFrequency: 3})
+	assoc.payloadQueue.init(0)
+	assoc.setState(established)
+	defer assoc.ackTimer.stop()
+
+	receive := func(tsn uint32) int {
+		assoc.handleChunksStart()
+		assoc.handleData(&chunkPayloadData{
+			beginningFragment: true,
+			endingFragment:    true,
+			tsn:               tsn,
+			streamIdentifier:  1,
+			userData:          []byte("ping"),
+		})
+		assoc.handleChunksEnd()
+
+		return assoc.ackState
+	}
+
+	assert.Equal(t, ackStateDelay, receive(1))
+	assert.Equal(t, ackStateDelay, receive(2))
+	assert.Equal(t, ackStateImmediate, receive(3), "the third packet should be acked right away")
+
+	assoc.ackState = ackStateIdle
+	assert.Equal(t, ackStateDelay, receive(4))
+	assert.Equal(t, ackStateImmediate, receive(6), "a gap should be acked right away")
+}
+
+func TestAssociation_PendingAndInflightBytes(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+	assert.Zero(t, assoc.PendingBytes())
+	assert.Zero(t, assoc.InflightBytes())
+
+	assoc.pendingQueue.push(&chunkPayloadData{
+		beginningFragment: true,
+		endingFragment:    true,
+		userData:          make([]byte, 10),
+	})
+	assoc.inflightQueue.pushNoCheck(&chunkPayloadData{tsn: 1, userData: make([]byte, 3)})
+	assoc.inflightQueue.pushNoCheck(&chunkPayloadData{tsn: 2, userData: make([]byte, 4)})
+
+	assert.Equal(t, 10, assoc.PendingBytes())
+	assert.Equal(t, 7, assoc.InflightBytes())
+	assert.Equal(t, assoc.PendingBytes()+assoc.InflightBytes(), assoc.BufferedAmount())
+}
+
+func TestAssociation_ReceiveGaps(t *testing.T) {
+	assoc := createTestAssociation(t, Config{})
+
+	assoc.lock.Lock()
+	assoc.payloadQueue.init(100)
+	assoc.lock.Unlock()
+
+	cumTSN, gaps := assoc.ReceiveGaps()
+	assert.Equal(t, uint32(100), cumTSN)
+	assert.Empty(t, gaps)
+
+	assoc.lock.Lock()
+	for _, tsn := range []uint32{102, 103, 106} {
+		assoc.payloadQueue.push(tsn)
+	}
+	assoc.lock.Unlock()
+
+	cumTSN, gaps = assoc.ReceiveGaps()
+	assert.Equal(t, uint32(100), cumTSN)
+	assert.Equal(t, []GapBlock{{Start: 102, End: 103}, {Start: 106, End: 106}}, gaps)
+
+	// reading the gaps does not change what the SACK reports
+	assoc.lock.Lock()
+	sack := assoc.createSelectiveAckChunk()
+	assoc.lock.Unlock()
+	assert.Equal(t, uint32(100), sack.cumulativeTSNAck)
+	assert.Equal(t, []gapAckBlock{{start: 2, end: 3}, {start: 6, end: 6}}, sack.gapAckBlocks)
+}
+
+func TestAssociation_StreamRecord(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	br := test.NewBridge()
+	a0, a1, err := createNewAssociationPairWithInterleaving(br, ackModeNoDelay, 0, true, true)
+	require.NoError(t, err, "failed to create associations")
+
+	s0, s1, err := establishSessionPair(br, a0, a1, 1)
+	require.NoError(t, err, "failed to establish session pair")
+
+	record := make([]byte, 3000)
+	for i := range record {
+		record[i] = byte(i)
+	}
+	for i := 0; i < len(record); i += 1000 {
+		n, writeErr := s0.WritePartial(record[i:i+1000], PayloadTypeWebRTCBinary)
+		require.NoError(t, writeErr)
+		assert.Equal(t, 1000, n)
+		flushBuffers(br, a0, a1)
+	}
+
+	// other writes on the stream wait for the end of the record
+	_, err = s0.WriteSCTP([]byte("next"), PayloadTypeWebRTCString)
+	assert.ErrorIs(t, err, ErrRecordOpen)
+
+	_, err = s0.WritePartial([]byte("x"), PayloadTypeWebRTCString)
+	assert.ErrorIs(t, err, ErrRecordPayloadTypeMismatch)
+
+	require.NoError(t, s0.WriteEnd())
+	assert.ErrorIs(t, s0.WriteEnd(), ErrNoOpenRecord)
+	_, err = s0.WriteSCTP([]byte("next"), PayloadTypeWebRTCString)
+	require.NoError(t, err)
+	flushBuffers(br, a0, a1)
+
+	buf := make([]byte, 4096)
+	n, ppi, err := s1.ReadSCTP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, record, buf[:n])
+	assert.Equal(t, PayloadTypeWebRTCBinary, ppi)
+
+	n, ppi, err = s1.ReadSCTP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "next", string(buf[:n]))
+	assert.Equal(t, PayloadTypeWebRTCString, ppi)
+
+	assert.Equal(t, 0, a0.BufferedAmount(), "incorrect bufferedAmount")
+	assert.Equal(t, uint64(3), s0.Stats().MessagesWritten, "hello, record and next should be counted")
+
+	closeAssociationPair(br, a0, a1)
+}
+
+func TestAssociation_WriteAfterStreamReset(t *testing.T) {
+	for _, resetByClient := range []bool{false, true} {
+		t.Run(fmt.Sprintf("resetByClient=%v", resetByClient), func(t *testing.T) {
+			lim := test.TimeOut(time.Second * 10)
+			defer lim.Stop()
+
+			br := test.NewBridge()
+			a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+			require.NoError(t, err, "failed to create associations")
+
+			s0, s1, err := establishSessionPair(br, a0, a1, 1)
+			require.NoError(t, err, "failed to establish session pair")
+
+			resetter, peer := s1, s0
+			if resetByClient {
+				resetter, peer = s0, s1
+			}
+
+			resetCh := make(chan struct{})
+			peer.OnReset(func() { close(resetCh) })
+
+			require.NoError(t, resetter.Close())
+			_, err = resetter.Write([]byte("late"))
+			assert.ErrorIs(t, err, ErrStreamReset)
+			assert.ErrorIs(t, err, ErrStreamClosed)
+			_, err = resetter.WriteMultiple([][]byte{[]byte("late")}, PayloadTypeWebRTCBinary)
+			assert.ErrorIs(t, err, ErrStreamReset)
+
+			for reset := false; !reset; {
+				br.Tick()
+				select {
+				case <-resetCh:
+					reset = true
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+
+			// the peer can no longer write once its incoming side is reset,
+			// but can still reset its outgoing side
+			_, err = peer.Write([]byte("reply"))
+			assert.ErrorIs(t, err, ErrStreamReset)
+			assert.ErrorIs(t, err, ErrStreamClosed)
+			_, err = peer.TryWrite([]byte("reply"), PayloadTypeWebRTCBinary)
+			assert.ErrorIs(t, err, ErrStreamReset)
+			_, err = peer.WriteMultiple([][]byte{[]byte("reply")}, PayloadTypeWebRTCBinary)
+			assert.ErrorIs(t, err, ErrStreamReset)
+
+			require.NoError(t, peer.Close())
+			_, err = peer.Write([]byte("late"))
+			assert.ErrorIs(t, err, ErrStreamReset)
+
+			closeAssociationPair(br, a0, a1)
+		})
+	}
+}
+
+func TestAssociation_ReconfigRetransmissionFailure(t *testing.T) {
+	assoc := createTestAssociation(t, Config{MaxReconfigRetrans: 2})
+	assoc.setState(established)
+	assert.Equal(t, uint(2), assoc.tReconfig.maxRetrans)
+
+	assoc.lock.Lock()
+	s := assoc.createStream(1, false)
+	assoc.reconfigs[7] = &chunkReconfig{
+		paramA: &paramOutgoingResetRequest{
+			reconfigRequestSequenceNumber: 7,
+			streamIdentifiers:             []uint16{1},
+		},
+	}
+	assoc.lock.Unlock()
+
+	assoc.onRetransmissionFailure(timerReconfig)
+
+	assoc.lock.RLock()
+	assert.Empty(t, assoc.reconfigs, "abandoned request should be dropped")
+	assert.False(t, assoc.willRetransmitReconfig)
+	_, ok := assoc.streams[1]
+	assoc.lock.RUnlock()
+	assert.False(t, ok, "stream should be unregistered")
+
+	assert.Equal(t, StreamStateClosed, s.State())
+	_, err := s.Read(make([]byte, 8))
+	assert.ErrorIs(t, err, ErrStreamResetTimeout)
+	_, err = s.Write([]byte("late"))
+	assert.ErrorIs(t, err, ErrStreamReset)
+}
+
+func TestStreamCloseWithContext(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		br := test.NewBridge()
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		require.NoError(t, err, "failed to create associations")
+
+		s0, s1, err := establishSessionPair(br, a0, a1, 1)
+		require.NoError(t, err, "failed to establish session pair")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		closeErr := make(chan error, 1)
+		go func() { closeErr <- s0.CloseWithContext(ctx) }()
+
+		// the identifier is still in use until the peer confirms the reset
+		_, err = a0.OpenStream(1, PayloadTypeWebRTCBinary)
+		assert.ErrorIs(t, err, ErrStreamAlreadyExists)
+
+		for done := false; !done; {
+			br.Tick()
+			select {
+			case err = <-closeErr:
+				done = true
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		require.NoError(t, err)
+
+		_, ok := a0.GetStream(1)
+		assert.False(t, ok, "stream should be removed")
+		assert.Equal(t, StreamStateClosed, s0.State())
+
+		_, err = s1.Read(make([]byte, 8))
+		assert.ErrorIs(t, err, io.EOF)
+
+		reopened, err := a0.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		_, err = reopened.Write([]byte("again"))
+		require.NoError(t, err)
+		flushBuffers(br, a0, a1)
+
+		accepted, err := a1.AcceptStream()
+		require.NoError(t, err)
+		buf := make([]byte, 8)
+		n, err := accepted.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "again", string(buf[:n]))
+
+		closeAssociationPair(br, a0, a1)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		lim := test.TimeOut(time.Second * 10)
+		defer lim.Stop()
+
+		br := test.NewBridge()
+		a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+		require.NoError(t, err, "failed to create associations")
 
-	// Simulate the problematic timing: writeLoop is sitting in its select and
-	// closeWriteLoopCh gets closed (e.g. readLoop exited) while an ABORT is pending.
-	assoc.lock.Lock()
-	assoc.willSendAbort = true
-	assoc.willSendAbortCause = &errorCauseUserInitiatedAbort{upperLayerAbortReason: []byte("x")}
-	assoc.lock.Unlock()
+		s0, _, err := establishSessionPair(br, a0, a1, 1)
+		require.NoError(t, err, "failed to establish session pair")
 
-	assoc.closeWriteLoopOnce.Do(func() { close(assoc.closeWriteLoopCh) })
+		// the RECONFIG is never delivered
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s0.CloseWithContext(ctx), context.DeadlineExceeded)
 
-	require.Eventually(t, func() bool {
-		raw, ok := conn.firstWrite()
-		if !ok || len(raw) <= int(commonHeaderSize) {
-			return false
-		}
+		_, ok := a0.GetStream(1)
+		assert.False(t, ok, "stream should be removed")
+		_, err = s0.Read(make([]byte, 8))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
 
-		return raw[commonHeaderSize] == uint8(ctAbort)
-	}, 1*time.Second, 5*time.Millisecond)
+		_, err = a0.OpenStream(1, PayloadTypeWebRTCBinary)
+		assert.NoError(t, err)
 
-	require.NoError(t, assoc.close())
+		closeAssociationPair(br, a0, a1)
+	})
 }
 
-type abortOrderingConn struct {
-	readDeadlineOnce sync.Once
-	readDeadlineCh   chan struct{}
+func TestStreamResetRejected(t *testing.T) {
+	// reject answers the outgoing reset request of assoc as the peer would.
+	reject := func(t *testing.T, assoc *Association) {
+		t.Helper()
 
-	wroteAbortOnce sync.Once
-	wroteAbortCh   chan struct{}
-}
+		_, _ = assoc.gatherOutbound()
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		require.Len(t, assoc.reconfigs, 1)
+		for rsn := range assoc.reconfigs {
+			_, err := assoc.handleReconfigParam(&paramReconfigResponse{
+				reconfigResponseSequenceNumber: rsn,
+				result:                         reconfigResultDenied,
+			})
+			require.NoError(t, err)
+		}
+		assert.Empty(t, assoc.reconfigs)
+	}
 
-func newAbortOrderingConn() *abortOrderingConn {
-	return &abortOrderingConn{
-		readDeadlineCh: make(chan struct{}),
-		wroteAbortCh:   make(chan struct{}),
+	newStream := func(t *testing.T) (*Association, *Stream) {
+		t.Helper()
+
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		assoc.setState(established)
+		assoc.setCWND(64 * 1024)
+		assoc.setRWND(64 * 1024)
+		t.Cleanup(func() { _ = assoc.close() })
+		s, err := assoc.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+
+		return assoc, s
 	}
+
+	t.Run("close can be retried", func(t *testing.T) {
+		assoc, s := newStream(t)
+
+		require.NoError(t, s.Close())
+		assert.Equal(t, StreamStateClosing, s.State())
+		reject(t, assoc)
+
+		assert.Equal(t, StreamStateOpen, s.State(), "the outgoing side should be open again")
+		_, err := s.Write([]byte("again"))
+		assert.NoError(t, err)
+		require.NoError(t, s.Close())
+		assert.Equal(t, StreamStateClosing, s.State())
+	})
+
+	t.Run("close with context", func(t *testing.T) {
+		assoc, s := newStream(t)
+
+		closeErr := make(chan error, 1)
+		go func() { closeErr <- s.CloseWithContext(context.Background()) }()
+		require.Eventually(t, func() bool {
+			assoc.lock.RLock()
+			defer assoc.lock.RUnlock()
+
+			return len(assoc.outgoingResets) > 0
+		}, time.Second, time.Millisecond, "the reset request should be queued")
+		reject(t, assoc)
+
+		assert.ErrorIs(t, <-closeErr, ErrStreamResetRejected)
+		got, ok := assoc.GetStream(1)
+		assert.True(t, ok, "stream should stay registered")
+		assert.Same(t, s, got)
+		assert.Equal(t, StreamStateOpen, s.State(), "the outgoing side should be open again")
+		_, err := s.Write([]byte("again"))
+		assert.NoError(t, err)
+	})
 }
 
-func (c *abortOrderingConn) Read(_ []byte) (int, error) {
-	<-c.readDeadlineCh
+func TestAssociation_UnorderedOvertakesOrdered(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
 
-	return 0, net.ErrClosed
+	br := test.NewBridge()
+	a0, a1, err := createNewAssociationPair(br, ackModeNoDelay, 0)
+	require.NoError(t, err, "failed to create associations")
+
+	s0, s1, err := establishSessionPair(br, a0, a1, 1)
+	require.NoError(t, err, "failed to establish session pair")
+
+	// messages large enough to be sent in separate packets
+	sbuf := make([]byte, 1000)
+
+	// the ordered message is lost and retransmitted later
+	br.DropNextNWrites(0, 1)
+	sbuf[0] = 0
+	_, err = s0.WriteSCTP(sbuf, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
+
+	// unordered chunks are sent first, so wait for the ordered one to leave
+	for {
+		a0.lock.RLock()
+		inflight := a0.inflightQueue.size()
+		a0.lock.RUnlock()
+		if inflight > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sbuf[0] = 1
+	_, err = s0.WriteWithOptions(sbuf, WriteOptions{
+		PayloadType: PayloadTypeWebRTCBinary,
+		Unordered:   true,
+	})
+	require.NoError(t, err)
+
+	rbuf := make([]byte, 1500)
+	read := make(chan byte, 2)
+	go func() {
+		for range 2 {
+			_, readErr := s1.Read(rbuf)
+			if readErr != nil {
+				return
+			}
+			read <- rbuf[0]
+		}
+	}()
+
+	flushBuffers(br, a0, a1)
+	assert.Equal(t, byte(1), <-read, "unordered message should be delivered first")
+	assert.Equal(t, byte(0), <-read)
+
+	closeAssociationPair(br, a0, a1)
 }
 
-func (c *abortOrderingConn) Write(b []byte) (int, error) {
-	isAbort := len(b) > int(commonHeaderSize) && b[commonHeaderSize] == byte(ctAbort)
-	if isAbort {
-		time.Sleep(100 * time.Millisecond)
-		c.wroteAbortOnce.Do(func() { close(c.wroteAbortCh) })
+func TestStream_WriteWithAck(t *testing.T) {
+	lim := test.TimeOut(time.Second * 10)
+	defer lim.Stop()
+
+	waitAck := func(t *testing.T, acked <-chan error) error {
+		t.Helper()
+
+		select {
+		case err := <-acked:
+			return err
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "timed out waiting for the message outcome")
+
+			return nil
+		}
 	}
 
-	return len(b), nil
+	t.Run("acknowledged", func(t *testing.T) {
+		udp1, udp2 := createUDPConnPair()
+		a1, a2, err := createAssociationPair(udp1, udp2)
+		require.NoError(t, err)
+		defer noErrorClose(t, a2.Close)
+		defer noErrorClose(t, a1.Close)
+
+		s, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		acked, err := s.WriteWithAck(make([]byte, 3000), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		assert.NoError(t, waitAck(t, acked))
+
+		a1.lock.RLock()
+		assert.Empty(t, a1.ackNotifies)
+		a1.lock.RUnlock()
+
+		acked, err = s.WriteWithAck(nil, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		assert.NoError(t, waitAck(t, acked), "an empty message has nothing to acknowledge")
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		establishTestAssociation(t, assoc)
+		s, err := assoc.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+
+		acked, err := s.WriteWithAck([]byte("a"), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		assoc.gatherOutbound()
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		tsn := assoc.myNextTSN - 1
+		c, ok := assoc.inflightQueue.get(tsn)
+		require.True(t, ok)
+		c.setAllInflight()
+		c.setAbandoned(true)
+
+		// the peer moves its cumulative TSN past the abandoned message
+		require.NoError(t, assoc.handleSack(&chunkSelectiveAck{
+			cumulativeTSNAck:               tsn,
+			advertisedReceiverWindowCredit: 512 * 1024,
+		}))
+		assert.ErrorIs(t, waitAck(t, acked), ErrMessageAbandoned)
+	})
+
+	t.Run("association closed", func(t *testing.T) {
+		udp1, udp2 := createUDPConnPair()
+		a1, a2, err := createAssociationPair(udp1, udp2)
+		require.NoError(t, err)
+		require.NoError(t, a2.Close())
+
+		s, err := a1.OpenStream(1, PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		acked, err := s.WriteWithAck([]byte("a"), PayloadTypeWebRTCBinary)
+		require.NoError(t, err)
+		require.NoError(t, a1.Close())
+		assert.ErrorIs(t, waitAck(t, acked), ErrAssociationClosed)
+	})
 }
 
-func (c *abortOrderingConn) Close() error { return nil }
+func TestAssociation_MaxPayloadSize(t *testing.T) {
+	assoc := createTestAssociation(t, Config{MTU: 1200})
+	assert.Equal(t, uint32(1200-12-16), assoc.MaxPayloadSize())
 
-func (c *abortOrderingConn) LocalAddr() net.Addr  { return &net.IPAddr{} }
-func (c *abortOrderingConn) RemoteAddr() net.Addr { return &net.IPAddr{} }
+	assoc.useInterleaving = true
+	assoc.updateMaxPayloadSize()
+	assert.Equal(t, uint32(1200-12-20), assoc.MaxPayloadSize(), "I-DATA header is larger")
+
+	assoc.useTimestamps = true
+	assoc.updateMaxPayloadSize()
+	assert.Equal(t, uint32(1200-12-20-timestampChunkSize), assoc.MaxPayloadSize())
+
+	s := assoc.createStream(1, false)
+	chunks, _ := s.packetize(make([]byte, 2*assoc.MaxPayloadSize()+1), WriteOptions{})
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[0].userData, int(assoc.MaxPayloadSize()))
+	assert.Len(t, chunks[2].userData, 1)
+}
+
+func TestAssociation_ReassemblyTimeoutWithoutMoreData(t *testing.T) {
+	var released atomic.Int64
+	assoc, err := createServerAssociation(Config{
+		NetConn:           &dumbConn{},
+		LoggerFactory:     logging.NewDefaultLoggerFactory(),
+		ReassemblyTimeout: 30 * time.Millisecond,
+	}, WithOnReassemblyMemoryChange(func(delta int) { released.Add(int64(-delta)) }))
+	require.NoError(t, err)
+	defer assoc.stopReassemblyTimer()
 
-func (c *abortOrderingConn) SetDeadline(_ time.Time) error { return nil }
+	// The peer sends the first fragment of a message on each of two streams,
+	// then nothing more.
+	assoc.lock.Lock()
+	assoc.setState(established)
+	var streams []*Stream
+	for i, id := range []uint16{1, 2} {
+		s := assoc.getOrCreateStream(id, true, PayloadTypeWebRTCBinary)
+		require.NotNil(t, s)
+		streams = append(streams, s)
+		require.True(t, assoc.pushPayloadDataToStream(s, &chunkPayloadData{
+			beginningFragment: true,
+			tsn:               uint32(i), //nolint:gosec // G115
+			streamIdentifier:  id,
+			userData:          []byte("never completed"),
+		}))
+	}
+	assoc.lock.Unlock()
+	assoc.flushReassemblyMemoryChange()
+	assert.Equal(t, int64(-2*len("never completed")), released.Load())
 
-func (c *abortOrderingConn) SetReadDeadline(_ time.Time) error {
-	c.readDeadlineOnce.Do(func() { close(c.readDeadlineCh) })
+	assert.Eventually(t, func() bool {
+		for _, s := range streams {
+			if s.getNumBytesInReassemblyQueue() != 0 {
+				return false
+			}
+		}
 
-	return nil
+		return released.Load() == 0
+	}, time.Second, 5*time.Millisecond, "incomplete messages should be discarded without more DATA")
+	for _, s := range streams {
+		assert.Equal(t, uint64(1), s.stats.snapshot().ReassemblyTimeouts)
+	}
+
+	// nothing is left to discard, so the timer is not rearmed
+	assert.Eventually(t, func() bool {
+		assoc.timerMu.Lock()
+		defer assoc.timerMu.Unlock()
+
+		return assoc.reassemblyTimer == nil
+	}, time.Second, 5*time.Millisecond)
 }
 
-func (c *abortOrderingConn) SetWriteDeadline(_ time.Time) error { return nil }
+func TestAssociation_ReceiveMTU(t *testing.T) {
+	transport := newChanTransport()
+	assoc := createTestAssociation(t, Config{Transport: transport, ReceiveMTU: 1200})
+	go assoc.readLoop()
 
-func TestAbort_WaitsForAbortWriteAttempt(t *testing.T) {
-	conn := newAbortOrderingConn()
+	transport.in <- make([]byte, 1201)
+	transport.in <- make([]byte, 1200)
+	assert.Eventually(t, func() bool {
+		return assoc.BytesReceived() == 1200
+	}, time.Second, time.Millisecond, "only the packet fitting the receive MTU should be read")
 
-	cfg := Config{
-		NetConn:        conn,
-		LoggerFactory:  logging.NewDefaultLoggerFactory(),
-		MaxMessageSize: 1200,
+	require.NoError(t, transport.Close())
+	<-assoc.readLoopCloseCh
+}
+
+func TestAssociation_CloseSendsFinalSack(t *testing.T) {
+	closeWithDelayedAck := func(t *testing.T, cfg Config, delayed bool) []*packet {
+		t.Helper()
+
+		transport := newChanTransport()
+		cfg.Transport = transport
+		assoc := createTestAssociation(t, cfg)
+		assoc.lock.Lock()
+		assoc.setState(established)
+		assoc.peerVerificationTag = 1
+		assoc.useTimestamps = cfg.EnableTimestamps
+		if delayed {
+			assoc.ackState = ackStateDelay
+		}
+		assoc.lock.Unlock()
+		assoc.initServer()
+
+		start := time.Now()
+		require.NoError(t, assoc.Close())
+		if delayed && !cfg.DisableFinalSack {
+			assert.Less(t, time.Since(start), finalSackTimeout, "Close should not wait once the SACK is written")
+		}
+
+		var written []*packet
+		for {
+			select {
+			case raw := <-transport.out:
+				pkt := &packet{}
+				require.NoError(t, pkt.unmarshal(true, raw))
+				written = append(written, pkt)
+			default:
+				return written
+			}
+		}
 	}
-	cfg.applyDefaults()
-	assoc, err := createAssociationFromConfig(&cfg)
-	assert.NoError(t, err)
-	assoc.initServer()
 
-	assoc.Abort("test")
+	t.Run("delayed SACK is sent", func(t *testing.T) {
+		written := closeWithDelayedAck(t, Config{}, true)
+		require.Len(t, written, 1)
+		require.Len(t, written[0].chunks, 1)
+		_, ok := written[0].chunks[0].(*chunkSelectiveAck)
+		assert.True(t, ok, "expected SACK, got %s", written[0].chunks[0])
+	})
 
-	select {
-	case <-conn.wroteAbortCh:
-		// ok
-	default:
-		require.Fail(t, "Abort returned before ABORT write attempt")
+	t.Run("delayed SACK with timestamps is sent", func(t *testing.T) {
+		written := closeWithDelayedAck(t, Config{EnableTimestamps: true}, true)
+		require.Len(t, written, 1)
+		require.Len(t, written[0].chunks, 2)
+		_, ok := written[0].chunks[0].(*chunkTimestamp)
+		assert.True(t, ok, "expected TIMESTAMP, got %s", written[0].chunks[0])
+		_, ok = written[0].chunks[1].(*chunkSelectiveAck)
+		assert.True(t, ok, "expected SACK, got %s", written[0].chunks[1])
+	})
+
+	t.Run("no delayed SACK", func(t *testing.T) {
+		assert.Empty(t, closeWithDelayedAck(t, Config{}, false))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Empty(t, closeWithDelayedAck(t, Config{DisableFinalSack: true}, true))
+	})
+}
+
+// readErrorTransport fails the given number of reads before reading from
+// the wrapped chanTransport.
+type readErrorTransport struct {
+	*chanTransport
+	errs atomic.Int32
+}
+
+var errTransientRead = errors.New("transient read error")
+
+func (t *readErrorTransport) ReadPacket(buf []byte) (int, error) {
+	if t.errs.Add(-1) >= 0 {
+		return 0, errTransientRead
 	}
+
+	return t.chanTransport.ReadPacket(buf)
 }
 
-func TestAbortSentChClosedWhenAbortMarshalFails(t *testing.T) {
-	conn := &recordConn{done: make(chan struct{})}
-	defer conn.Close() // nolint:errcheck
+func TestAssociation_OnReadError(t *testing.T) {
+	newAssoc := func(t *testing.T, errs int32, fn func(error) bool) (*Association, *readErrorTransport) {
+		t.Helper()
 
-	cfg := Config{
-		NetConn:        conn,
-		LoggerFactory:  logging.NewDefaultLoggerFactory(),
-		MaxMessageSize: 1200,
+		transport := &readErrorTransport{chanTransport: newChanTransport()}
+		transport.errs.Store(errs)
+		config := Config{Transport: transport}
+		if fn != nil {
+			require.NoError(t, WithOnReadError(fn).applyServer(&config))
+		}
+		assoc := createTestAssociation(t, config)
+		go assoc.readLoop()
+
+		return assoc, transport
 	}
-	cfg.applyDefaults()
-	assoc, err := createAssociationFromConfig(&cfg)
-	assert.NoError(t, err)
-	assoc.initServer()
+	waitClosed := func(t *testing.T, assoc *Association) {
+		t.Helper()
 
-	assoc.lock.Lock()
-	assoc.willSendAbort = true
-	assoc.willSendAbortCause = failingErrorCause{}
-	assoc.lock.Unlock()
+		select {
+		case <-assoc.readLoopCloseCh:
+		case <-time.After(time.Second):
+			require.FailNow(t, "read loop did not exit")
+		}
+	}
 
-	assoc.awakeWriteLoop()
+	t.Run("default", func(t *testing.T) {
+		assoc, _ := newAssoc(t, 1, nil)
+		waitClosed(t, assoc)
+	})
 
-	select {
-	case <-assoc.abortSentCh:
-		// ok
-	case <-time.After(200 * time.Millisecond):
-		require.Fail(t, "abortSentCh was not closed when ABORT marshaling failed")
+	t.Run("retried", func(t *testing.T) {
+		var calls atomic.Int32
+		assoc, transport := newAssoc(t, 3, func(err error) bool {
+			calls.Add(1)
+
+			return errors.Is(err, errTransientRead)
+		})
+
+		transport.in <- make([]byte, 100)
+		assert.Eventually(t, func() bool {
+			return assoc.BytesReceived() == 100
+		}, time.Second, time.Millisecond, "the read loop should survive transient errors")
+		assert.Equal(t, int32(3), calls.Load())
+
+		require.NoError(t, transport.Close())
+		waitClosed(t, assoc)
+		assert.Equal(t, int32(4), calls.Load(), "the callback should see the final error")
+	})
+
+	t.Run("closed while retrying", func(t *testing.T) {
+		assoc, _ := newAssoc(t, math.MaxInt32, func(error) bool { return true })
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, assoc.close())
+		waitClosed(t, assoc)
+	})
+}
+
+func TestAssociation_UnrecognizedParameters(t *testing.T) {
+	unknown := func(action paramHeaderUnrecognizedAction) *paramHeader {
+		return &paramHeader{typ: paramType(action)<<8 | 0x3fff, raw: []byte{1, 2, 3}}
 	}
+
+	t.Run("init", func(t *testing.T) {
+		for _, action := range []paramHeaderUnrecognizedAction{
+			paramHeaderUnrecognizedActionStop,
+			paramHeaderUnrecognizedActionStopAndReport,
+			paramHeaderUnrecognizedActionSkip,
+			paramHeaderUnrecognizedActionSkipAndReport,
+		} {
+			assoc := createTestAssociation(t, Config{NetConn: &discardConn{}, EnableECN: true})
+			init := &chunkInit{}
+			init.initialTSN = 1000
+			init.numOutboundStreams = 10
+			init.numInboundStreams = 10
+			init.initiateTag = 5678
+			init.advertisedReceiverWindowCredit = 512 * 1024
+			init.params = []param{unknown(action), &paramECNCapable{}}
+			require.NoError(t, sendTestPacket(t, assoc, 0, init))
+
+			replies := assoc.controlQueue.popAll()
+			require.Len(t, replies, 1)
+			initAck, ok := replies[0].chunks[0].(*chunkInitAck)
+			require.True(t, ok, "an INIT with unrecognized parameters is answered")
+
+			var reported []*paramUnrecognized
+			for _, p := range initAck.params {
+				if u, ok := p.(*paramUnrecognized); ok {
+					reported = append(reported, u)
+				}
+			}
+			if action&paramHeaderUnrecognizedActionStopAndReport != 0 {
+				require.Len(t, reported, 1)
+				assert.Equal(t, []byte{byte(action) | 0x3f, 0xff, 0x00, 0x07, 1, 2, 3}, reported[0].unrecognizedParam)
+			} else {
+				assert.Empty(t, reported)
+			}
+			assert.Equal(t, action&paramHeaderUnrecognizedActionSkip != 0, assoc.useECN,
+				"parameters after the unrecognized one")
+		}
+	})
+
+	t.Run("init ack", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		assoc.setState(cookieWait)
+
+		initAck := &chunkInitAck{}
+		initAck.initialTSN = 1000
+		initAck.numOutboundStreams = 10
+		initAck.numInboundStreams = 10
+		initAck.initiateTag = 5678
+		initAck.advertisedReceiverWindowCredit = 512 * 1024
+		initAck.params = []param{
+			unknown(paramHeaderUnrecognizedActionSkipAndReport),
+			unknown(paramHeaderUnrecognizedActionSkip),
+			unknown(paramHeaderUnrecognizedActionStopAndReport),
+		}
+		raw, err := initAck.marshal()
+		require.NoError(t, err)
+		parsed := &chunkInitAck{}
+		require.NoError(t, parsed.unmarshal(raw))
+		// a State Cookie after the last parameter would not be processed
+		parsed.params = append(parsed.params, &paramStateCookie{cookie: []byte{1, 2, 3, 4}})
+
+		require.NoError(t, assoc.handleInitAck(&packet{
+			sourcePort:      assoc.destinationPort,
+			destinationPort: assoc.sourcePort,
+		}, parsed))
+		assoc.t1Cookie.stop()
+
+		replies := assoc.controlQueue.popAll()
+		require.Len(t, replies, 1)
+		require.Len(t, replies[0].chunks, 2)
+		assert.IsType(t, &chunkCookieEcho{}, replies[0].chunks[0])
+		errChunk, ok := replies[0].chunks[1].(*chunkError)
+		require.True(t, ok, "the ERROR is bundled with the COOKIE ECHO")
+		require.Len(t, errChunk.errorCauses, 1)
+		cause, ok := errChunk.errorCauses[0].(*errorCauseUnrecognizedParameters)
+		require.True(t, ok)
+		assert.Equal(t, []byte{
+			0xff, 0xff, 0x00, 0x07, 1, 2, 3, 0,
+			0x7f, 0xff, 0x00, 0x07, 1, 2, 3, 0,
+		}, cause.unrecognizedParams)
+
+		// the ERROR is not sent again with a retransmitted COOKIE ECHO
+		require.NoError(t, assoc.sendCookieEcho())
+		replies = assoc.controlQueue.popAll()
+		require.Len(t, replies, 1)
+		assert.Len(t, replies[0].chunks, 1)
+	})
+
+	t.Run("host name address", func(t *testing.T) {
+		assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+		init := &chunkInit{}
+		init.initialTSN = 1000
+		init.numOutboundStreams = 10
+		init.numInboundStreams = 10
+		init.initiateTag = 5678
+		init.advertisedReceiverWindowCredit = 512 * 1024
+		hostName := &paramHeader{typ: hostNameAddr, raw: []byte("example.org\x00")}
+		init.params = []param{hostName}
+		require.NoError(t, sendTestPacket(t, assoc, 0, init))
+
+		assert.Zero(t, assoc.controlQueue.size(), "no INIT ACK is sent")
+		require.True(t, assoc.willSendAbort)
+		cause, ok := assoc.willSendAbortCause.(*errorCauseHeader)
+		require.True(t, ok)
+		assert.Equal(t, unresolvableAddress, cause.code)
+		expected, err := hostName.marshal()
+		require.NoError(t, err)
+		assert.Equal(t, expected, cause.raw)
+	})
 }
 
-func TestAssociationAbortProtocolViolation(t *testing.T) {
-	const reason = "received DATA with interleaving negotiated"
+func TestAssociation_PeerAddresses(t *testing.T) {
+	assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+	assert.Empty(t, assoc.PeerAddresses())
 
-	assoc := &Association{
-		awakeWriteLoopCh: make(chan struct{}, 1),
-		name:             "test-association",
-		log:              logging.NewDefaultLoggerFactory().NewLogger("sctp-test"),
+	sendTestInit(t, assoc,
+		&paramIPAddress{address: net.ParseIP("192.0.2.1")},
+		&paramIPAddress{address: net.ParseIP("2001:db8::1")},
+	)
+	addresses := assoc.PeerAddresses()
+	require.Len(t, addresses, 2)
+	assert.True(t, net.ParseIP("192.0.2.1").Equal(addresses[0]))
+	assert.True(t, net.ParseIP("2001:db8::1").Equal(addresses[1]))
+
+	// the returned addresses are copies
+	addresses[0][0] = 10
+	assert.True(t, net.ParseIP("192.0.2.1").Equal(assoc.PeerAddresses()[0]))
+
+	// a new INIT replaces them
+	sendTestInit(t, assoc)
+	assert.Empty(t, assoc.PeerAddresses())
+}
+
+func TestAssociation_PeerSupportedAddressTypes(t *testing.T) {
+	assoc := createTestAssociation(t, Config{NetConn: &discardConn{}})
+	assert.Nil(t, assoc.PeerSupportedAddressTypes())
+
+	// The parameters after Supported Address Types are processed too.
+	init := &chunkInit{}
+	init.initialTSN = 1000
+	init.numOutboundStreams = 10
+	init.numInboundStreams = 10
+	init.initiateTag = 5678
+	init.advertisedReceiverWindowCredit = 512 * 1024
+	init.params = []param{
+		&paramSupportedAddressTypes{addressTypes: []paramType{ipV4Addr, ipV6Addr}},
+		&paramIPAddress{address: net.ParseIP("192.0.2.1")},
 	}
+	require.NoError(t, sendTestPacket(t, assoc, 0, init))
 
-	assoc.lock.Lock()
-	assoc.abortProtocolViolation(reason)
-	assoc.lock.Unlock()
-
-	require.True(t, assoc.willSendAbort)
+	assert.Equal(t, []uint16{5, 6}, assoc.PeerSupportedAddressTypes())
+	assert.Len(t, assoc.PeerAddresses(), 1)
 
-	cause, ok := assoc.willSendAbortCause.(*errorCauseProtocolViolation)
+	replies := assoc.controlQueue.popAll()
+	require.Len(t, replies, 1)
+	initAck, ok := replies[0].chunks[0].(*chunkInitAck)
 	require.True(t, ok)
-	assert.Equal(t, protocolViolation, cause.errorCauseCode())
-	assert.Equal(t, []byte(reason), cause.additionalInformation)
-
-	select {
-	case <-assoc.awakeWriteLoopCh:
-	default:
-		require.Fail(t, "abortProtocolViolation did not wake the write loop")
+	for _, p := range initAck.params {
+		switch p.(type) {
+		case *paramUnrecognized, *paramIPAddress:
+			assert.Failf(t, "unexpected INIT ACK parameter", "%v", p)
+		}
 	}
+
+	// a new INIT replaces them
+	sendTestInit(t, assoc)
+	assert.Nil(t, assoc.PeerSupportedAddressTypes())
 }
 
-func TestAssociationSNAPInvalidInit(t *testing.T) {
-	br := test.NewBridge()
-	tokenConfig := Config{
-		MaxReceiveBufferSize: 65535,
-		EnableZeroChecksum:   false,
+func TestAssociation_WriteBatchSize(t *testing.T) {
+	queueHeartbeats := func(assoc *Association, n int) {
+		for range n {
+			assoc.controlQueue.push(assoc.createPacket([]chunk{&chunkHeartbeat{
+				params: []param{&paramHeartbeatInfo{heartbeatInformation: []byte{1}}},
+			}}))
+		}
 	}
-	init, err := GenerateOutOfBandToken(tokenConfig)
-	assert.NoError(t, err)
 
-	_, err = ClientWithOptions(
-		WithNetConn(br.GetConn0()),
-		WithSNAP([]byte{1, 2}, init))
-	assert.ErrorIs(t, err, ErrChunkHeaderTooSmall)
+	t.Run("no packets lost", func(t *testing.T) {
+		transport := newChanTransport()
+		assoc := createTestAssociation(t, Config{Transport: transport, WriteBatchSize: 2})
+		queueHeartbeats(assoc, 5)
 
-	_, err = ClientWithOptions(
-		WithNetConn(br.GetConn1()),
-		WithSNAP(init, []byte{1, 2}))
-	assert.ErrorIs(t, err, ErrChunkHeaderTooSmall)
+		done := make(chan struct{})
+		go func() {
+			assoc.writeLoop()
+			close(done)
+		}()
+		for range 5 {
+			select {
+			case <-transport.out:
+			case <-time.After(time.Second):
+				require.FailNow(t, "packet not written")
+			}
+		}
+
+		assoc.closeWriteLoopOnce.Do(func() { close(assoc.closeWriteLoopCh) })
+		<-done
+		assert.Equal(t, uint64(5), assoc.stats.getNumPacketsSent())
+	})
+
+	t.Run("stops between batches on close", func(t *testing.T) {
+		transport := newChanTransport()
+		assoc := createTestAssociation(t, Config{Transport: transport, WriteBatchSize: 2})
+		queueHeartbeats(assoc, 5)
+
+		assoc.closeWriteLoopOnce.Do(func() { close(assoc.closeWriteLoopCh) })
+		assoc.writeLoop()
+		assert.Len(t, transport.out, 2, "only the first batch should be written")
+	})
 }
 
-func TestAssociationSNAP(t *testing.T) {
-	lim := test.TimeOut(time.Second * 10)
-	defer lim.Stop()
+func TestAssociation_DuplicateTSNs(t *testing.T) {
+	// the receiver gets a DATA chunk twice and reports it as a duplicate
+	receiver := createTestAssociation(t, Config{NetConn: &discardConn{}})
+	establishTestAssociation(t, receiver)
+	data := &chunkPayloadData{tsn: 1000, beginningFragment: true, endingFragment: true, userData: []byte("x")}
+	require.NoError(t, sendTestPacket(t, receiver, receiver.myVerificationTag, data))
+	require.NoError(t, sendTestPacket(t, receiver, receiver.myVerificationTag, data))
+
+	receiver.lock.Lock()
+	sack := receiver.createSelectiveAckChunk()
+	receiver.lock.Unlock()
+	require.Equal(t, []uint32{1000}, sack.duplicateTSN)
+
+	// the sender counts it
+	sender := newRackTestAssoc(t)
+	sender.cumulativeTSNAckPoint = 999
+	sender.advancedPeerTSNAckPoint = 999
+	sender.myNextTSN = 1001
+	sender.inflightQueue.pushNoCheck(mkChunk(1000, time.Now()))
+
+	sender.lock.Lock()
+	require.NoError(t, sender.handleSack(sack))
+	sender.lock.Unlock()
+	assert.Equal(t, uint64(1), sender.Stats().DuplicateTSNs)
+	assert.Zero(t, sender.inflightQueue.size())
+
+	sender.lock.Lock()
+	require.NoError(t, sender.handleSack(&chunkSelectiveAck{
+		cumulativeTSNAck:               1000,
+		advertisedReceiverWindowCredit: sack.advertisedReceiverWindowCredit,
+		duplicateTSN:                   []uint32{1000, 1000},
+	}))
+	sender.lock.Unlock()
+	assert.Equal(t, uint64(3), sender.Stats().DuplicateTSNs)
+}
 
-	loggerFactory := logging.NewDefaultLoggerFactory()
-	br := test.NewBridge()
+func TestAssociation_DwellTimeStats(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			assoc := newRackTestAssoc(t)
+			assoc.dwellTimeStats = enabled
+			assoc.myNextTSN = 100
 
-	// Use GenerateOutOfBandToken to create the init chunks
-	tokenConfig := Config{
-		MaxReceiveBufferSize: 65535,
-		EnableZeroChecksum:   false,
-	}
-	initA, err := GenerateOutOfBandToken(tokenConfig)
-	assert.NoError(t, err)
+			chunks := []*chunkPayloadData{
+				{beginningFragment: true, endingFragment: true, userData: []byte("a")},
+				{beginningFragment: true, endingFragment: true, userData: []byte("b")},
+			}
+			require.NoError(t, assoc.sendPayloadData(context.Background(), chunks, false))
+			assert.Equal(t, enabled, !chunks[0].enqueued.IsZero())
 
-	initB, err := GenerateOutOfBandToken(tokenConfig)
-	assert.NoError(t, err)
+			assoc.lock.Lock()
+			defer assoc.lock.Unlock()
 
-	assocA, err := ClientWithOptions(
-		WithName("a"),
-		WithNetConn(br.GetConn0()),
-		WithLoggerFactory(loggerFactory),
-		WithSNAP(initA, initB))
-	assert.NoError(t, err)
-	assert.NotNil(t, assocA)
+			for i, c := range chunks {
+				if enabled {
+					// chunk i waited (i+1)*10ms before being sent
+					c.enqueued = c.enqueued.Add(-time.Duration(i+1) * 10 * time.Millisecond)
+				}
+				assoc.movePendingDataChunkToInflightQueue(assoc.pendingQueue.peek())
+			}
+			assert.Zero(t, assoc.pendingQueue.size())
+
+			// TSN 101 is gap-acked, then TSN 100 and 101 are acked together:
+			// each is measured once.
+			require.NoError(t, assoc.handleSack(&chunkSelectiveAck{
+				cumulativeTSNAck:               99,
+				advertisedReceiverWindowCredit: 64 * 1024,
+				gapAckBlocks:                   []gapAckBlock{{start: 2, end: 2}},
+			}))
+			require.NoError(t, assoc.handleSack(&chunkSelectiveAck{
+				cumulativeTSNAck:               101,
+				advertisedReceiverWindowCredit: 64 * 1024,
+			}))
+
+			stats := assoc.Stats()
+			if !enabled {
+				assert.Zero(t, stats.PendingDwell)
+				assert.Zero(t, stats.InflightDwell)
 
-	assocB, err := ClientWithOptions(
-		WithName("b"),
-		WithNetConn(br.GetConn1()),
-		WithLoggerFactory(loggerFactory),
-		WithSNAP(initB, initA))
-	assert.NoError(t, err)
-	assert.NotNil(t, assocB)
+				return
+			}
+			assert.Equal(t, uint64(2), stats.PendingDwell.Count)
+			assert.GreaterOrEqual(t, stats.PendingDwell.Min, 10*time.Millisecond)
+			assert.Less(t, stats.PendingDwell.Min, 20*time.Millisecond)
+			assert.GreaterOrEqual(t, stats.PendingDwell.Max, 20*time.Millisecond)
+			assert.GreaterOrEqual(t, stats.PendingDwell.Avg, 15*time.Millisecond)
+			assert.Equal(t, uint64(2), stats.InflightDwell.Count)
+			assert.LessOrEqual(t, stats.InflightDwell.Min, stats.InflightDwell.Max)
+
+			assoc.stats.reset()
+			assert.Zero(t, assoc.Stats().PendingDwell)
+		})
+	}
+}
 
-	const si uint16 = 1
-	const msg = "SNAP is snappy"
+func TestAssociation_InstantRTT(t *testing.T) {
+	sack := func(t *testing.T, assoc *Association, cumulativeTSNAck uint32) {
+		t.Helper()
 
-	streamA, err := assocA.OpenStream(si, PayloadTypeWebRTCBinary)
-	assert.NoError(t, err)
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		require.NoError(t, assoc.handleSack(&chunkSelectiveAck{
+			cumulativeTSNAck:               cumulativeTSNAck,
+			advertisedReceiverWindowCredit: 64 * 1024,
+		}))
+	}
 
-	_, err = streamA.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
-	assert.NoError(t, err)
+	t.Run("enabled", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.instantRTTEnabled = true
+		assoc.myNextTSN = 110 // the SRTT is next measured from TSN 110
+		now := time.Now()
+		assoc.inflightQueue.pushNoCheck(mkChunk(100, now.Add(-50*time.Millisecond)))
+		assoc.inflightQueue.pushNoCheck(mkChunk(101, now.Add(-10*time.Millisecond)))
+
+		// the newest chunk acked is sampled
+		sack(t, assoc, 101)
+		first := assoc.InstantRTT()
+		assert.GreaterOrEqual(t, first, 10.0)
+		assert.Less(t, first, 50.0)
+		srtt := assoc.SRTT()
+
+		// retransmitted chunks are not sampled
+		retransmitted := mkChunk(102, now.Add(-500*time.Millisecond))
+		retransmitted.nSent = 2
+		assoc.inflightQueue.pushNoCheck(retransmitted)
+		sack(t, assoc, 102)
+		assert.Equal(t, first, assoc.InstantRTT())
+
+		// later samples are smoothed, and do not change the SRTT within the
+		// round trip
+		assoc.inflightQueue.pushNoCheck(mkChunk(103, time.Now().Add(-200*time.Millisecond)))
+		sack(t, assoc, 103)
+		assert.Greater(t, assoc.InstantRTT(), first)
+		assert.Less(t, assoc.InstantRTT(), 200.0)
+		assert.Equal(t, srtt, assoc.SRTT())
+	})
 
-	br.Process()
+	t.Run("disabled", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.inflightQueue.pushNoCheck(mkChunk(100, time.Now().Add(-10*time.Millisecond)))
+		sack(t, assoc, 100)
+		assert.Zero(t, assoc.InstantRTT())
+	})
+}
 
-	accepted := make(chan *Stream)
-	go func() {
-		s, errAccept := assocB.AcceptStream()
-		assert.NoError(t, errAccept)
-		accepted <- s
-	}()
+func TestAssociation_FRTO(t *testing.T) {
+	// newAssoc returns an association with TSNs 100 to 109 in flight when
+	// the T3-rtx timer expires, and TSN 100 retransmitted after it.
+	newAssoc := func(t *testing.T, enabled bool) *Association {
+		t.Helper()
 
-	flushBuffers(br, assocA, assocB)
+		assoc := newRackTestAssoc(t)
+		assoc.frtoEnabled = enabled
+		assoc.myNextTSN = 110
+		for tsn := uint32(100); tsn < 110; tsn++ {
+			assoc.inflightQueue.pushNoCheck(mkChunk(tsn, time.Now()))
+		}
+		assoc.setCWND(10 * assoc.MTU())
+		assoc.ssthresh = 20 * assoc.MTU()
+		assoc.setRWND(64 * 1024)
 
-	var streamB *Stream
-	select {
-	case streamB = <-accepted:
-	case <-time.After(5 * time.Second):
-		assert.Fail(t, "timed out waiting for accept stream")
-	}
+		assoc.onRetransmissionTimeout(timerT3RTX, 1)
+		assert.Equal(t, assoc.MTU(), assoc.CWND())
 
-	buf := make([]byte, 64)
-	n, ppi, err := streamB.ReadSCTP(buf)
-	assert.NoError(t, err, "ReadSCTP failed")
-	assert.Equal(t, len(msg), n, "unexpected length of received data")
-	assert.Equal(t, PayloadTypeWebRTCBinary, ppi, "unexpected ppi")
-	assert.Equal(t, msg, string(buf[:n]))
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		c, ok := assoc.inflightQueue.get(100)
+		require.True(t, ok)
+		c.retransmit = false
+		c.nSent++
+		c.rtoGeneration = assoc.rtoGeneration
 
-	closeAssociationPair(br, assocA, assocB)
-}
+		return assoc
+	}
+	sack := func(t *testing.T, assoc *Association, cumTSN uint32) {
+		t.Helper()
 
-func TestAssociationSNAPInterleavingNegotiationPayloadChunkType(t *testing.T) {
-	tests := []struct {
-		name                string
-		localInterleaving   bool
-		remoteInterleaving  bool
-		useInterleaving     bool
-		expectedPayloadType chunkType
-	}{
-		{
-			name:                "enabled enabled uses I-DATA",
-			localInterleaving:   true,
-			remoteInterleaving:  true,
-			useInterleaving:     true,
-			expectedPayloadType: ctIData,
-		},
-		{
-			name:                "enabled disabled uses DATA",
-			localInterleaving:   true,
-			remoteInterleaving:  false,
-			useInterleaving:     false,
-			expectedPayloadType: ctPayloadData,
-		},
-		{
-			name:                "disabled enabled uses DATA",
-			localInterleaving:   false,
-			remoteInterleaving:  true,
-			useInterleaving:     false,
-			expectedPayloadType: ctPayloadData,
-		},
-		{
-			name:                "disabled disabled uses DATA",
-			localInterleaving:   false,
-			remoteInterleaving:  false,
-			useInterleaving:     false,
-			expectedPayloadType: ctPayloadData,
-		},
+		assoc.lock.Lock()
+		defer assoc.lock.Unlock()
+		require.NoError(t, assoc.handleSack(&chunkSelectiveAck{
+			cumulativeTSNAck:               cumTSN,
+			advertisedReceiverWindowCredit: 64 * 1024,
+		}))
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			lim := test.TimeOut(time.Second * 10)
-			defer lim.Stop()
+	t.Run("delay spike", func(t *testing.T) {
+		assoc := newAssoc(t, true)
 
-			loggerFactory := logging.NewDefaultLoggerFactory()
-			br := test.NewBridge()
+		// the original transmissions arrive late: TSN 101 was not
+		// retransmitted
+		sack(t, assoc, 101)
+		assert.Less(t, assoc.CWND(), 10*assoc.MTU())
+		sack(t, assoc, 104)
 
-			localInit, err := GenerateOutOfBandToken(WithEnableInterleaving(tt.localInterleaving))
-			require.NoError(t, err)
+		assert.Equal(t, uint64(1), assoc.Stats().SpuriousRTOs)
+		assert.Equal(t, 10*assoc.MTU(), assoc.CWND(), "cwnd should be restored")
+		assert.Equal(t, 20*assoc.MTU(), assoc.ssthresh, "ssthresh should be restored")
+		for tsn := uint32(105); tsn < 110; tsn++ {
+			c, ok := assoc.inflightQueue.get(tsn)
+			require.True(t, ok)
+			assert.False(t, c.retransmit, "TSN %d should not be retransmitted", tsn)
+		}
+	})
 
-			remoteInit, err := GenerateOutOfBandToken(WithEnableInterleaving(tt.remoteInterleaving))
-			require.NoError(t, err)
+	t.Run("everything acked at once", func(t *testing.T) {
+		assoc := newAssoc(t, true)
 
-			assocA, err := ClientWithOptions(
-				WithName("a"),
-				WithNetConn(br.GetConn0()),
-				WithLoggerFactory(loggerFactory),
-				WithSNAP(localInit, remoteInit))
-			require.NoError(t, err)
-			require.NotNil(t, assocA)
+		// ambiguous: the retransmission may have filled the only gap
+		sack(t, assoc, 109)
+		assert.Equal(t, frtoIdle, assoc.frto.phase)
+		assert.Zero(t, assoc.Stats().SpuriousRTOs)
+		assert.Less(t, assoc.CWND(), 10*assoc.MTU())
+	})
 
-			assocB, err := ClientWithOptions(
-				WithName("b"),
-				WithNetConn(br.GetConn1()),
-				WithLoggerFactory(loggerFactory),
-				WithSNAP(remoteInit, localInit))
-			require.NoError(t, err)
-			require.NotNil(t, assocB)
-			defer closeAssociationPair(br, assocA, assocB)
+	t.Run("loss", func(t *testing.T) {
+		assoc := newAssoc(t, true)
+
+		// only the retransmission is acked, then no progress
+		sack(t, assoc, 100)
+		sack(t, assoc, 100)
+		assert.Equal(t, frtoIdle, assoc.frto.phase)
+		assert.Zero(t, assoc.Stats().SpuriousRTOs)
+		assert.Less(t, assoc.CWND(), 10*assoc.MTU())
+		c, ok := assoc.inflightQueue.get(105)
+		require.True(t, ok)
+		assert.True(t, c.retransmit)
+	})
 
-			require.Equal(t, tt.useInterleaving, assocA.useInterleaving)
-			require.Equal(t, tt.useInterleaving, assocB.useInterleaving)
+	t.Run("bogus cumulative TSN ack", func(t *testing.T) {
+		assoc := newRackTestAssoc(t)
+		assoc.frto.phase = frtoFirstSack
 
-			const si uint16 = 1
-			const msg = "SNAP interleaving"
+		// nothing is inflight: the walk must stop right away
+		bogus := &chunkSelectiveAck{cumulativeTSNAck: assoc.cumulativeTSNAckPoint + 1<<31 - 1}
+		assert.False(t, assoc.frtoOriginalsAcked(bogus))
+	})
 
-			streamA, err := assocA.OpenStream(si, PayloadTypeWebRTCBinary)
-			require.NoError(t, err)
+	t.Run("disabled", func(t *testing.T) {
+		assoc := newAssoc(t, false)
 
-			chunkTypes := capturePayloadChunkTypes(br, 0)
+		sack(t, assoc, 101)
+		sack(t, assoc, 104)
+		assert.Zero(t, assoc.Stats().SpuriousRTOs)
+		assert.Less(t, assoc.CWND(), 10*assoc.MTU())
+	})
+}
 
-			n, err := streamA.WriteSCTP([]byte(msg), PayloadTypeWebRTCBinary)
-			require.NoError(t, err)
-			require.Equal(t, len(msg), n)
+func TestLossyConnAssociation(t *testing.T) {
+	lim := test.TimeOut(time.Second * 20)
+	defer lim.Stop()
 
-			br.Process()
-			requireNextPayloadChunkType(t, chunkTypes, tt.expectedPayloadType)
+	udp0, udp1 := createUDPConnPair()
+	config := sctptest.LossyConnConfig{LossRate: 0.05, Delay: 5 * time.Millisecond, Jitter: 5 * time.Millisecond}
+	config.Seed = 1
+	conn0, err := sctptest.NewLossyConn(udp0, config)
+	require.NoError(t, err)
+	config.Seed = 2
+	conn1, err := sctptest.NewLossyConn(udp1, config)
+	require.NoError(t, err)
 
-			accepted := make(chan *Stream)
-			go func() {
-				s, errAccept := assocB.AcceptStream()
-				assert.NoError(t, errAccept)
-				accepted <- s
-			}()
+	loggerFactory := logging.NewDefaultLoggerFactory()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-			flushBuffers(br, assocA, assocB)
+	serverCh := make(chan *Association, 1)
+	go func() {
+		server, serverErr := createClientWithContext(ctx, Config{NetConn: conn1, LoggerFactory: loggerFactory})
+		assert.NoError(t, serverErr)
+		serverCh <- server
+	}()
+	client, err := createClientWithContext(ctx, Config{NetConn: conn0, LoggerFactory: loggerFactory})
+	require.NoError(t, err)
+	server := <-serverCh
+	require.NotNil(t, server)
 
-			var streamB *Stream
-			select {
-			case streamB = <-accepted:
-			case <-time.After(5 * time.Second):
-				require.Fail(t, "timed out waiting for accept stream")
-			}
+	s0, err := client.OpenStream(1, PayloadTypeWebRTCBinary)
+	require.NoError(t, err)
 
-			buf := make([]byte, 64)
-			n, ppi, err := streamB.ReadSCTP(buf)
-			require.NoError(t, err, "ReadSCTP failed")
-			require.Equal(t, len(msg), n, "unexpected length of received data")
-			require.Equal(t, PayloadTypeWebRTCBinary, ppi, "unexpected ppi")
-			require.Equal(t, msg, string(buf[:n]))
-		})
+	const numMessages = 50
+	go func() {
+		buf := make([]byte, 1000)
+		for i := range numMessages {
+			buf[0] = byte(i)
+			_, writeErr := s0.WriteSCTP(buf, PayloadTypeWebRTCBinary)
+			assert.NoError(t, writeErr)
+		}
+	}()
+
+	s1, err := server.AcceptStream()
+	require.NoError(t, err)
+	buf := make([]byte, 1500)
+	for i := range numMessages {
+		n, err := s1.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, n)
+		assert.Equal(t, byte(i), buf[0], "messages should be delivered in order despite the impairments")
 	}
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, server.Close())
 }
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"math"
+	"time"
+)
+
+// timestampNow returns the current value of the local timestamp clock, in
+// microseconds. It wraps around about every 71 minutes.
+func (a *Association) timestampNow() uint32 {
+	return uint32(time.Since(a.tsBase).Microseconds()) //nolint:gosec // G115, wraps on purpose
+}
+
+// addTimestampChunk returns p with a TIMESTAMP chunk bundled first when
+// timestamps are in use and p carries DATA or SACK chunks. A packet carrying
+// a SACK echoes the timestamp of the first DATA packet received since the
+// previous SACK. A packet without DATA that has no room left for the
+// TIMESTAMP chunk is sent without it, and the echo waits for the next SACK.
+// The caller should hold the lock.
+func (a *Association) addTimestampChunk(p *packet) *packet {
+	if !a.useTimestamps {
+		return p
+	}
+
+	hasData, hasSack := false, false
+	for _, c := range p.chunks {
+		switch c.(type) {
+		case *chunkPayloadData:
+			hasData = true
+		case *chunkSelectiveAck:
+			hasSack = true
+		}
+	}
+	if !hasData && !hasSack {
+		return p
+	}
+	// DATA packets are built with room for the TIMESTAMP chunk, but a SACK
+	// with many gap blocks or duplicate TSNs may fill the MTU on its own.
+	if !hasData && !a.fitsTimestampChunk(p) {
+		return p
+	}
+
+	ts := &chunkTimestamp{tsVal: a.timestampNow()}
+	if hasSack && a.tsRecentValid {
+		ts.tsEcr = a.tsRecent
+		ts.echoActive = true
+		a.tsRecentValid = false
+	}
+
+	withTimestamp := *p
+	withTimestamp.chunks = append([]chunk{ts}, p.chunks...)
+
+	return &withTimestamp
+}
+
+// fitsTimestampChunk reports whether a TIMESTAMP chunk can be added to p
+// without exceeding the MTU.
+func (a *Association) fitsTimestampChunk(p *packet) bool {
+	size := int(commonHeaderSize + timestampChunkSize)
+	for _, c := range p.chunks {
+		raw, err := c.marshal()
+		if err != nil {
+			return false
+		}
+		size += len(raw) + getPadding(len(raw))
+	}
+
+	return size <= int(a.MTU())
+}
+
+// handleTimestamp records the timestamp of a packet carrying DATA so that
+// it is echoed in the next SACK, and the echoed timestamp, if any, so that
+// a SACK in the same packet can be used for an RTT sample.
+// The caller should hold the lock.
+func (a *Association) handleTimestamp(p *packet, c *chunkTimestamp) {
+	if !a.useTimestamps {
+		a.log.Tracef("[%s] TIMESTAMP received but not negotiated", a.name)
+
+		return
+	}
+
+	if !a.tsRecentValid {
+		for _, chunk := range p.chunks {
+			if _, ok := chunk.(*chunkPayloadData); ok {
+				a.tsRecent = c.tsVal
+				a.tsRecentValid = true
+
+				break
+			}
+		}
+	}
+
+	if c.echoActive {
+		a.tsEcr = c.tsEcr
+		a.tsEcrValid = true
+	}
+}
+
+// sampleTimestampRTT updates the RTO from the timestamp echoed in the packet
+// carrying the SACK being processed. Unlike measurements based on the send
+// time of DATA chunks, it also works when the acknowledged DATA was
+// retransmitted. The caller should hold the lock.
+func (a *Association) sampleTimestampRTT() {
+	if !a.useTimestamps || !a.tsEcrValid {
+		return
+	}
+	a.tsEcrValid = false
+
+	elapsed := a.timestampNow() - a.tsEcr
+	if elapsed > math.MaxInt32 {
+		// echo of a timestamp we have not sent yet
+		return
+	}
+
+	rttDuration := time.Duration(elapsed) * time.Microsecond
+	rtt := float64(elapsed) / 1000.0
+	srtt := a.rtoMgr.setNewRTT(rtt)
+	a.srtt.Store(srtt)
+	a.rack.rackMinRTTWnd.Push(time.Now(), rttDuration)
+
+	a.log.Tracef("[%s] SACK: timestamp-rtt=%f srtt=%f new-rto=%f",
+		a.name, rtt, srtt, a.rtoMgr.getRTO())
+}
@@ -33,8 +33,15 @@ the receiver.
 type chunkAbort struct {
 	chunkHeader
 	errorCauses []errorCause
+
+	// reflectedTag is the T bit: the packet carries the verification tag
+	// of the receiver instead of the sender.
+	reflectedTag bool
 }
 
+// chunkTBitmask is the T bit of ABORT and SHUTDOWN COMPLETE chunks.
+const chunkTBitmask = 1
+
 // Abort chunk errors.
 var (
 	ErrChunkTypeNotAbort     = errors.New("ChunkType is not of type ABORT")
@@ -49,6 +56,7 @@ func (a *chunkAbort) unmarshal(raw []byte) error {
 	if a.typ != ctAbort {
 		return fmt.Errorf("%w: actually is %s", ErrChunkTypeNotAbort, a.typ.String())
 	}
+	a.reflectedTag = a.flags&chunkTBitmask != 0
 
 	offset := chunkHeaderSize
 	for len(raw)-offset >= 4 {
@@ -67,6 +75,9 @@ func (a *chunkAbort) unmarshal(raw []byte) error {
 func (a *chunkAbort) marshal() ([]byte, error) {
 	a.chunkHeader.typ = ctAbort
 	a.flags = 0x00
+	if a.reflectedTag {
+		a.flags |= chunkTBitmask
+	}
 	a.raw = []byte{}
 	for _, ec := range a.errorCauses {
 		raw, err := ec.marshal()
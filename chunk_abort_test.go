@@ -57,4 +57,14 @@ func TestAbortChunk(t *testing.T) {
 				"errorCause code should match")
 		}
 	})
+
+	t.Run("T bit", func(t *testing.T) {
+		bytes, err := (&chunkAbort{reflectedTag: true}).marshal()
+		assert.NoError(t, err, "should succeed")
+		assert.Equal(t, byte(chunkTBitmask), bytes[1])
+
+		abort := &chunkAbort{}
+		assert.NoError(t, abort.unmarshal(bytes), "should succeed")
+		assert.True(t, abort.reflectedTag)
+	})
 }
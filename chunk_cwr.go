@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+chunkCWR represents an SCTP Chunk of type CWR (RFC 4960 Appendix A)
+
+The sender of DATA responds to an ECNE chunk with this chunk to inform the
+peer that the congestion window has been reduced.
+
+	 0                   1                   2                   3
+	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	| Chunk Type=13 | Flags=00000000|    Chunk Length = 8           |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                      Lowest TSN Number                        |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+type chunkCWR struct {
+	chunkHeader
+	lowestTSN uint32
+}
+
+// CWR chunk errors.
+var (
+	ErrChunkTypeNotCWR = errors.New("ChunkType is not of type CWR")
+)
+
+func (c *chunkCWR) unmarshal(raw []byte) error {
+	if err := c.chunkHeader.unmarshal(raw); err != nil {
+		return err
+	}
+
+	if c.typ != ctCWR {
+		return fmt.Errorf("%w: actually is %s", ErrChunkTypeNotCWR, c.typ.String())
+	}
+
+	if len(c.raw) != ecnLowestTSNLength {
+		return ErrInvalidChunkSize
+	}
+
+	c.lowestTSN = binary.BigEndian.Uint32(c.raw[0:])
+
+	return nil
+}
+
+func (c *chunkCWR) marshal() ([]byte, error) {
+	out := make([]byte, ecnLowestTSNLength)
+	binary.BigEndian.PutUint32(out[0:], c.lowestTSN)
+
+	c.typ = ctCWR
+	c.flags = 0
+	c.raw = out
+
+	return c.chunkHeader.marshal()
+}
+
+func (c *chunkCWR) check() (abort bool, err error) {
+	return false, nil
+}
+
+// String makes chunkCWR printable.
+func (c *chunkCWR) String() string {
+	return fmt.Sprintf("%s lowestTSN=%d", c.chunkHeader, c.lowestTSN)
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+chunkECNE represents an SCTP Chunk of type ECNE (RFC 4960 Appendix A)
+
+The receiver of a packet marked as Congestion Experienced sends this chunk
+to its peer to report the lowest TSN of the marked packet.
+
+	 0                   1                   2                   3
+	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	| Chunk Type=12 | Flags=00000000|    Chunk Length = 8           |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                      Lowest TSN Number                        |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+type chunkECNE struct {
+	chunkHeader
+	lowestTSN uint32
+}
+
+const (
+	ecnLowestTSNLength = 4
+)
+
+// ECNE chunk errors.
+var (
+	ErrChunkTypeNotECNE = errors.New("ChunkType is not of type ECNE")
+)
+
+func (c *chunkECNE) unmarshal(raw []byte) error {
+	if err := c.chunkHeader.unmarshal(raw); err != nil {
+		return err
+	}
+
+	if c.typ != ctECNE {
+		return fmt.Errorf("%w: actually is %s", ErrChunkTypeNotECNE, c.typ.String())
+	}
+
+	if len(c.raw) != ecnLowestTSNLength {
+		return ErrInvalidChunkSize
+	}
+
+	c.lowestTSN = binary.BigEndian.Uint32(c.raw[0:])
+
+	return nil
+}
+
+func (c *chunkECNE) marshal() ([]byte, error) {
+	out := make([]byte, ecnLowestTSNLength)
+	binary.BigEndian.PutUint32(out[0:], c.lowestTSN)
+
+	c.typ = ctECNE
+	c.flags = 0
+	c.raw = out
+
+	return c.chunkHeader.marshal()
+}
+
+func (c *chunkECNE) check() (abort bool, err error) {
+	return false, nil
+}
+
+// String makes chunkECNE printable.
+func (c *chunkECNE) String() string {
+	return fmt.Sprintf("%s lowestTSN=%d", c.chunkHeader, c.lowestTSN)
+}
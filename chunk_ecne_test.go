@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkECNE_Success(t *testing.T) {
+	raw := []byte{0x0c, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}
+
+	ecne := &chunkECNE{}
+	assert.NoError(t, ecne.unmarshal(raw))
+	assert.Equal(t, uint32(0x12345678), ecne.lowestTSN)
+
+	b, err := ecne.marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, raw, b)
+}
+
+func TestChunkCWR_Success(t *testing.T) {
+	raw := []byte{0x0d, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}
+
+	cwr := &chunkCWR{}
+	assert.NoError(t, cwr.unmarshal(raw))
+	assert.Equal(t, uint32(0x12345678), cwr.lowestTSN)
+
+	b, err := cwr.marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, raw, b)
+}
+
+func TestChunkECNE_Failure(t *testing.T) {
+	tt := []struct {
+		name   string
+		binary []byte
+	}{
+		{"length too short", []byte{0x0c, 0x00, 0x00, 0x07, 0x12, 0x34, 0x56, 0x78}},
+		{"payload too short", []byte{0x0c, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56}},
+		{"invalid type", []byte{0x0d, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}},
+	}
+
+	for i, tc := range tt {
+		actual := &chunkECNE{}
+		err := actual.unmarshal(tc.binary)
+		assert.Errorf(t, err, "expected unmarshal #%d: '%s' to fail.", i, tc.name)
+	}
+
+	cwr := &chunkCWR{}
+	assert.ErrorIs(t, cwr.unmarshal([]byte{0x0c, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}), ErrChunkTypeNotCWR)
+}
@@ -61,3 +61,32 @@ func TestChunkErrorUnrecognizedChunkType(t *testing.T) {
 		assert.True(t, reflect.DeepEqual(raw, expected), "unexpected serialization result")
 	})
 }
+
+func TestChunkErrorStaleCookie(t *testing.T) {
+	rawIn := []byte{byte(ctError), 0x00, 0x00, 0x0c, 0x00, 0x03, 0x00, 0x08, 0x00, 0x01, 0x86, 0xa0}
+
+	c := &chunkError{}
+	assert.NoError(t, c.unmarshal(rawIn))
+	assert.Equal(t, 1, len(c.errorCauses))
+	staleCookie, ok := c.errorCauses[0].(*errorCauseStaleCookie)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(100000), staleCookie.staleness)
+
+	raw, err := c.marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, rawIn, raw)
+
+	invalid := &chunkError{}
+	err = invalid.unmarshal([]byte{byte(ctError), 0x00, 0x00, 0x0a, 0x00, 0x03, 0x00, 0x06, 0x00, 0x01, 0x00, 0x00})
+	assert.ErrorIs(t, err, ErrBuildErrorChunkFailed)
+}
+
+func TestChunkErrorKnownCauseWithoutValueType(t *testing.T) {
+	rawIn := []byte{byte(ctError), 0x00, 0x00, 0x0c, 0x00, 0x04, 0x00, 0x04, 0x00, 0x09, 0x00, 0x04}
+
+	c := &chunkError{}
+	assert.NoError(t, c.unmarshal(rawIn))
+	assert.Equal(t, 2, len(c.errorCauses))
+	assert.Equal(t, outOfResource, c.errorCauses[0].errorCauseCode())
+	assert.Equal(t, noUserData, c.errorCauses[1].errorCauseCode())
+}
@@ -111,6 +111,19 @@ func (h *chunkHeartbeat) Marshal() ([]byte, error) {
 	return h.chunkHeader.marshal()
 }
 
+func (h *chunkHeartbeat) marshal() ([]byte, error) {
+	// mirror unmarshal, which accepts an empty body.
+	if len(h.params) == 0 {
+		h.chunkHeader.typ = ctHeartbeat
+		h.chunkHeader.flags = 0
+		h.chunkHeader.raw = nil
+
+		return h.chunkHeader.marshal()
+	}
+
+	return h.Marshal()
+}
+
 func (h *chunkHeartbeat) check() (abort bool, err error) {
 	return false, nil
 }
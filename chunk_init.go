@@ -38,7 +38,8 @@ var (
 	ErrInitInboundStreamRequestZero  = errors.New("INIT ACK inbound stream request must be > 0")
 	ErrInitOutboundStreamRequestZero = errors.New("INIT ACK outbound stream request must be > 0")
 	ErrInitAdvertisedReceiver1500    = errors.New("INIT ACK Advertised Receiver Window Credit (a_rwnd) must be >= 1500")
-	ErrInitUnknownParam              = errors.New("INIT with unknown param")
+	// Deprecated: this error is no longer used but is kept for compatibility.
+	ErrInitUnknownParam = errors.New("INIT with unknown param")
 )
 
 func (i *chunkInit) unmarshal(raw []byte) error {
@@ -127,13 +128,6 @@ func (i *chunkInit) check() (abort bool, err error) {
 		return true, ErrInitAdvertisedReceiver1500
 	}
 
-	for _, p := range i.unrecognizedParams {
-		if p.unrecognizedAction == paramHeaderUnrecognizedActionStop ||
-			p.unrecognizedAction == paramHeaderUnrecognizedActionStopAndReport {
-			return true, ErrInitUnknownParam
-		}
-	}
-
 	return false, nil
 }
 
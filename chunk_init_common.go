@@ -91,16 +91,24 @@ func (i *chunkInitCommon) unmarshal(raw []byte) error {
 	offset := initChunkMinLength
 	remaining := len(raw) - offset
 	for remaining > 0 {
-		if remaining > initOptionalVarHeaderLength {
+		if remaining >= initOptionalVarHeaderLength {
 			var pHeader paramHeader
 			if err := pHeader.unmarshal(raw[offset:]); err != nil {
 				return fmt.Errorf("%w: %v", ErrInitChunkParseParamTypeFailed, err) //nolint:errorlint
 			}
 
 			p, err := buildParam(pHeader.typ, raw[offset:])
-			if err != nil {
+			switch {
+			case errors.Is(err, ErrParamTypeUnhandled):
+				// https://www.rfc-editor.org/rfc/rfc9260#section-3.2.1
+				// The highest-order 2 bits of the type tell whether to
+				// report the parameter and whether to process the
+				// parameters after it.
 				i.unrecognizedParams = append(i.unrecognizedParams, pHeader)
-			} else {
+				if pHeader.stopOnUnrecognized() {
+					return nil
+				}
+			case err == nil:
 				i.params = append(i.params, p)
 			}
 
@@ -115,6 +123,31 @@ func (i *chunkInitCommon) unmarshal(raw []byte) error {
 	return nil
 }
 
+// reportedUnrecognizedParams returns the unrecognized parameters whose type
+// asks to report them to the sender of the chunk.
+func (i *chunkInitCommon) reportedUnrecognizedParams() []paramHeader {
+	var reported []paramHeader
+	for _, p := range i.unrecognizedParams {
+		if p.reportUnrecognized() {
+			reported = append(reported, p)
+		}
+	}
+
+	return reported
+}
+
+// hostNameAddress returns the Host Name Address parameter of the chunk, if
+// any. The parameter is deprecated and never recognized.
+func (i *chunkInitCommon) hostNameAddress() *paramHeader {
+	for idx := range i.unrecognizedParams {
+		if i.unrecognizedParams[idx].typ == hostNameAddr {
+			return &i.unrecognizedParams[idx]
+		}
+	}
+
+	return nil
+}
+
 func (i *chunkInitCommon) marshal() ([]byte, error) {
 	out := make([]byte, initChunkMinLength)
 	binary.BigEndian.PutUint32(out[0:], i.initiateTag)
@@ -31,3 +31,65 @@ func TestChunkInit_UnrecognizedParameters(t *testing.T) {
 	assert.Equal(t, 1, len(initCommonChunk.unrecognizedParams))
 	assert.Equal(t, paramHeaderUnrecognizedActionStop, initCommonChunk.unrecognizedParams[0].unrecognizedAction)
 }
+
+func TestChunkInit_TrailingEmptyParameter(t *testing.T) {
+	initCommon := &chunkInitCommon{
+		initiateTag:                    1,
+		advertisedReceiverWindowCredit: 1500,
+		numOutboundStreams:             1,
+		numInboundStreams:              1,
+		initialTSN:                     1,
+		params:                         []param{&paramECNCapable{}},
+	}
+	raw, err := initCommon.marshal()
+	assert.NoError(t, err)
+
+	parsed := &chunkInitCommon{}
+	assert.NoError(t, parsed.unmarshal(raw))
+	assert.True(t, hasECNCapable(parsed.params), "ECN capable param should be parsed")
+}
+
+func TestChunkInit_UnrecognizedParameterActions(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		action paramHeaderUnrecognizedAction
+		stop   bool
+		report bool
+	}{
+		{"stop", paramHeaderUnrecognizedActionStop, true, false},
+		{"stop and report", paramHeaderUnrecognizedActionStopAndReport, true, true},
+		{"skip", paramHeaderUnrecognizedActionSkip, false, false},
+		{"skip and report", paramHeaderUnrecognizedActionSkipAndReport, false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			initCommon := &chunkInitCommon{
+				initiateTag:                    1,
+				advertisedReceiverWindowCredit: 1500,
+				numOutboundStreams:             1,
+				numInboundStreams:              1,
+				initialTSN:                     1,
+				params: []param{
+					&paramHeader{typ: paramType(test.action)<<8 | 0x3fff, raw: []byte{1, 2, 3}},
+					&paramECNCapable{},
+				},
+			}
+			raw, err := initCommon.marshal()
+			assert.NoError(t, err)
+
+			parsed := &chunkInitCommon{}
+			assert.NoError(t, parsed.unmarshal(raw))
+			assert.Len(t, parsed.unrecognizedParams, 1)
+			assert.Equal(t, test.action, parsed.unrecognizedParams[0].unrecognizedAction)
+			assert.Equal(t, !test.stop, hasECNCapable(parsed.params), "parameters after the unrecognized one")
+			if test.report {
+				assert.Len(t, parsed.reportedUnrecognizedParams(), 1)
+			} else {
+				assert.Empty(t, parsed.reportedUnrecognizedParams())
+			}
+
+			init := &chunkInit{chunkInitCommon: *parsed}
+			_, err = init.check()
+			assert.NoError(t, err, "an INIT with unrecognized parameters is processed")
+		})
+	}
+}
@@ -66,16 +66,38 @@ type chunkPayloadData struct {
 	// Whether this data chunk was acknowledged (received by peer)
 	acked         bool
 	missIndicator uint32
+	// Whether this data chunk was gap-acked then dropped by the peer
+	reneged bool
 
 	// Partial-reliability parameters used only by sender
 	since        time.Time
 	nSent        uint32 // number of transmission made for this chunk
 	_abandoned   bool
 	_allInflight bool // valid only with the first fragment
+	_dropped     bool // dropped by the drop policy, valid only with the first fragment
+
+	// When the chunk was queued and first sent, only set with
+	// Config.EnableDwellTimeStats and cleared once measured
+	enqueued  time.Time
+	firstSent time.Time
+
+	// When the chunk was received, only set with Config.ReassemblyTimeout
+	arrived time.Time
+
+	// Stream reliability parameters when the message was written
+	reliabilityType  byte
+	reliabilityValue uint32
+
+	// Notified when the message is acknowledged or abandoned, set on the
+	// last fragment of a message written with WriteWithAck
+	ackNotify chan error
 
 	// Retransmission flag set when T1-RTX timeout occurred and this
 	// chunk is still in the inflight queue
 	retransmit bool
+	// Number of T3-rtx timeouts of the association when the chunk was
+	// last sent, to tell retransmissions from original transmissions
+	rtoGeneration uint32
 
 	head *chunkPayloadData // link to the head of the fragment
 
@@ -286,6 +308,14 @@ func (p *chunkPayloadData) setAbandoned(abandoned bool) {
 	p._abandoned = abandoned
 }
 
+func (p *chunkPayloadData) dropped() bool {
+	if p.head != nil {
+		return p.head._dropped
+	}
+
+	return p._dropped
+}
+
 func (p *chunkPayloadData) setAllInflight() {
 	if p.endingFragment {
 		if p.head != nil {
@@ -19,6 +19,9 @@ chunkShutdownComplete represents an SCTP Chunk of type chunkShutdownComplete
 */
 type chunkShutdownComplete struct {
 	chunkHeader
+
+	// reflectedTag is the T bit, as in chunkAbort.
+	reflectedTag bool
 }
 
 // Shutdown complete chunk errors.
@@ -34,12 +37,17 @@ func (c *chunkShutdownComplete) unmarshal(raw []byte) error {
 	if c.typ != ctShutdownComplete {
 		return fmt.Errorf("%w: actually is %s", ErrChunkTypeNotShutdownComplete, c.typ.String())
 	}
+	c.reflectedTag = c.flags&chunkTBitmask != 0
 
 	return nil
 }
 
 func (c *chunkShutdownComplete) marshal() ([]byte, error) {
 	c.typ = ctShutdownComplete
+	c.flags = 0x00
+	if c.reflectedTag {
+		c.flags |= chunkTBitmask
+	}
 
 	return c.chunkHeader.marshal()
 }
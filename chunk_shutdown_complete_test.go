@@ -16,6 +16,7 @@ func TestChunkShutdownComplete_Success(t *testing.T) {
 		binary []byte
 	}{
 		{[]byte{0x0e, 0x00, 0x00, 0x04}},
+		{[]byte{0x0e, 0x01, 0x00, 0x04}}, // T bit
 	}
 
 	for i, tc := range tt {
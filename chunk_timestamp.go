@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+chunkTimestamp represents an SCTP Chunk of type TIMESTAMP
+
+This chunk is only sent when both endpoints listed it in their Supported
+Extensions parameter. It is bundled first in packets carrying DATA or SACK
+chunks, in the same way as TCP timestamps (RFC 7323). The receiver of DATA
+echoes the timestamp value back in the packet carrying the SACK, which lets
+the sender measure the RTT even for retransmitted DATA.
+
+	 0                   1                   2                   3
+	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	| Type = 0xB0   |  Reserved   |E|      Chunk Length = 12        |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                        Timestamp Value                        |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                     Timestamp Echo Reply                      |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+E: set when Timestamp Echo Reply is valid.
+*/
+type chunkTimestamp struct {
+	chunkHeader
+	tsVal      uint32
+	tsEcr      uint32
+	echoActive bool
+}
+
+const (
+	timestampChunkValueLength        = 8
+	timestampChunkSize        uint32 = chunkHeaderSize + timestampChunkValueLength
+	timestampChunkFlagEcho           = 0x01
+)
+
+// TIMESTAMP chunk errors.
+var (
+	ErrChunkTypeNotTimestamp = errors.New("ChunkType is not of type TIMESTAMP")
+)
+
+func (c *chunkTimestamp) unmarshal(raw []byte) error {
+	if err := c.chunkHeader.unmarshal(raw); err != nil {
+		return err
+	}
+
+	if c.typ != ctTimestamp {
+		return fmt.Errorf("%w: actually is %s", ErrChunkTypeNotTimestamp, c.typ.String())
+	}
+
+	if len(c.raw) != timestampChunkValueLength {
+		return ErrInvalidChunkSize
+	}
+
+	c.tsVal = binary.BigEndian.Uint32(c.raw[0:])
+	c.tsEcr = binary.BigEndian.Uint32(c.raw[4:])
+	c.echoActive = c.flags&timestampChunkFlagEcho != 0
+
+	return nil
+}
+
+func (c *chunkTimestamp) marshal() ([]byte, error) {
+	out := make([]byte, timestampChunkValueLength)
+	binary.BigEndian.PutUint32(out[0:], c.tsVal)
+	binary.BigEndian.PutUint32(out[4:], c.tsEcr)
+
+	c.typ = ctTimestamp
+	c.flags = 0
+	if c.echoActive {
+		c.flags = timestampChunkFlagEcho
+	}
+	c.raw = out
+
+	return c.chunkHeader.marshal()
+}
+
+func (c *chunkTimestamp) check() (abort bool, err error) {
+	return false, nil
+}
+
+// String makes chunkTimestamp printable.
+func (c *chunkTimestamp) String() string {
+	return fmt.Sprintf("%s tsVal=%d tsEcr=%d echo=%t", c.chunkHeader, c.tsVal, c.tsEcr, c.echoActive)
+}
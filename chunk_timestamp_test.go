@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkTimestamp_Success(t *testing.T) {
+	raw := []byte{0xb0, 0x01, 0x00, 0x0c, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+
+	ts := &chunkTimestamp{}
+	assert.NoError(t, ts.unmarshal(raw))
+	assert.Equal(t, uint32(0x12345678), ts.tsVal)
+	assert.Equal(t, uint32(0x9abcdef0), ts.tsEcr)
+	assert.True(t, ts.echoActive)
+
+	b, err := ts.marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, raw, b)
+}
+
+func TestChunkTimestamp_Failure(t *testing.T) {
+	tt := []struct {
+		name   string
+		binary []byte
+	}{
+		{"length too short", []byte{0xb0, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}},
+		{"payload too short", []byte{0xb0, 0x00, 0x00, 0x0c, 0x12, 0x34, 0x56, 0x78}},
+	}
+
+	for i, tc := range tt {
+		actual := &chunkTimestamp{}
+		err := actual.unmarshal(tc.binary)
+		assert.Errorf(t, err, "expected unmarshal #%d: '%s' to fail.", i, tc.name)
+	}
+
+	ts := &chunkTimestamp{}
+	assert.ErrorIs(t, ts.unmarshal([]byte{0x0d, 0x00, 0x00, 0x08, 0x12, 0x34, 0x56, 0x78}), ErrChunkTypeNotTimestamp)
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"fmt"
+)
+
+/*
+chunkUnknown represents an SCTP chunk of a type this implementation does not
+recognize. The two highest-order bits of the chunk type tell the receiver how
+to handle it (RFC 9260 sec 3.2):
+
+	00 - Stop processing this SCTP packet; discard the unrecognized chunk and
+	     all further chunks.
+	01 - Stop processing this SCTP packet, discard the unrecognized chunk and
+	     all further chunks, and report the unrecognized chunk in an ERROR
+	     chunk using the "Unrecognized Chunk Type" error cause.
+	10 - Skip this chunk and continue processing.
+	11 - Skip this chunk and continue processing, but report it in an ERROR
+	     chunk using the "Unrecognized Chunk Type" error cause.
+*/
+type chunkUnknown struct {
+	chunkHeader
+}
+
+const (
+	chunkTypeBitSkip   = 0x80
+	chunkTypeBitReport = 0x40
+)
+
+func (c *chunkUnknown) unmarshal(raw []byte) error {
+	return c.chunkHeader.unmarshal(raw)
+}
+
+func (c *chunkUnknown) marshal() ([]byte, error) {
+	return c.chunkHeader.marshal()
+}
+
+func (c *chunkUnknown) check() (abort bool, err error) {
+	return false, nil
+}
+
+// skip reports whether the rest of the packet is processed after this chunk.
+func (c *chunkUnknown) skip() bool {
+	return c.typ&chunkTypeBitSkip != 0
+}
+
+// report reports whether the chunk is reported to the peer in an ERROR chunk.
+func (c *chunkUnknown) report() bool {
+	return c.typ&chunkTypeBitReport != 0
+}
+
+// String makes chunkUnknown printable.
+func (c *chunkUnknown) String() string {
+	return fmt.Sprintf("%s skip=%t report=%t", c.chunkHeader, c.skip(), c.report())
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// ChunkVector is the wire encoding of a chunk, without the common header of
+// the packet carrying it.
+type ChunkVector struct {
+	// Name is the chunk type name, such as "SACK", as in Chunk.Name.
+	Name string
+	Raw  []byte
+}
+
+// ChunkVectors returns an encoding of each chunk type supported by this
+// package, to test other SCTP implementations against it. The package
+// decodes each of them and encodes it back to the same bytes. A new slice
+// is returned on each call.
+func ChunkVectors() []ChunkVector {
+	return []ChunkVector{
+		{"DATA", []byte{
+			0x00, 0x03, 0x00, 0x14, // B and E bits
+			0x00, 0x00, 0x00, 0x01, // TSN
+			0x00, 0x02, 0x00, 0x03, // stream identifier, stream sequence number
+			0x00, 0x00, 0x00, 0x33, // payload protocol identifier: WebRTC string
+			0x61, 0x62, 0x63, 0x64, // user data
+		}},
+		{"I-DATA", []byte{
+			0x40, 0x03, 0x00, 0x18, // B and E bits
+			0x00, 0x00, 0x00, 0x01, // TSN
+			0x00, 0x02, 0x00, 0x00, // stream identifier, reserved
+			0x00, 0x00, 0x00, 0x03, // message identifier
+			0x00, 0x00, 0x00, 0x33, // payload protocol identifier: WebRTC string
+			0x61, 0x62, 0x63, 0x64, // user data
+		}},
+		{"INIT", []byte{
+			0x01, 0x00, 0x00, 0x18,
+			0x00, 0x00, 0x00, 0x01, // initiate tag
+			0x00, 0x10, 0x00, 0x00, // advertised receiver window credit
+			0xff, 0xff, 0xff, 0xff, // outbound and inbound streams
+			0x00, 0x00, 0x00, 0x01, // initial TSN
+			0xc0, 0x00, 0x00, 0x04, // Forward-TSN-Supported parameter
+		}},
+		{"INIT-ACK", []byte{
+			0x02, 0x00, 0x00, 0x1c,
+			0x00, 0x00, 0x00, 0x02, // initiate tag
+			0x00, 0x10, 0x00, 0x00, // advertised receiver window credit
+			0xff, 0xff, 0xff, 0xff, // outbound and inbound streams
+			0x00, 0x00, 0x00, 0x01, // initial TSN
+			0x00, 0x07, 0x00, 0x08, // State Cookie parameter
+			0x61, 0x62, 0x63, 0x64,
+		}},
+		{"SACK", []byte{
+			0x03, 0x00, 0x00, 0x18,
+			0x00, 0x00, 0x00, 0x01, // cumulative TSN ack
+			0x00, 0x10, 0x00, 0x00, // advertised receiver window credit
+			0x00, 0x01, 0x00, 0x01, // gap ack blocks, duplicate TSNs
+			0x00, 0x02, 0x00, 0x03, // gap ack block
+			0x00, 0x00, 0x00, 0x05, // duplicate TSN
+		}},
+		{"HEARTBEAT", []byte{
+			0x04, 0x00, 0x00, 0x0c,
+			0x00, 0x01, 0x00, 0x08, // Heartbeat Info parameter
+			0x01, 0x02, 0x03, 0x04,
+		}},
+		{"HEARTBEAT-ACK", []byte{
+			0x05, 0x00, 0x00, 0x0c,
+			0x00, 0x01, 0x00, 0x08, // Heartbeat Info parameter
+			0x01, 0x02, 0x03, 0x04,
+		}},
+		{"ABORT", []byte{
+			0x06, 0x00, 0x00, 0x0c,
+			0x00, 0x0c, 0x00, 0x08, // User-Initiated Abort cause
+			0x62, 0x79, 0x65, 0x21,
+		}},
+		{"SHUTDOWN", []byte{
+			0x07, 0x00, 0x00, 0x08,
+			0x00, 0x00, 0x00, 0x01, // cumulative TSN ack
+		}},
+		{"SHUTDOWN-ACK", []byte{
+			0x08, 0x00, 0x00, 0x04,
+		}},
+		{"ERROR", []byte{
+			0x09, 0x00, 0x00, 0x0c,
+			0x00, 0x03, 0x00, 0x08, // Stale Cookie Error cause
+			0x00, 0x00, 0x03, 0xe8, // measure of staleness
+		}},
+		{"COOKIE-ECHO", []byte{
+			0x0a, 0x00, 0x00, 0x08,
+			0x61, 0x62, 0x63, 0x64, // cookie
+		}},
+		{"COOKIE-ACK", []byte{
+			0x0b, 0x00, 0x00, 0x04,
+		}},
+		{"ECNE", []byte{
+			0x0c, 0x00, 0x00, 0x08,
+			0x00, 0x00, 0x00, 0x01, // lowest TSN
+		}},
+		{"CWR", []byte{
+			0x0d, 0x00, 0x00, 0x08,
+			0x00, 0x00, 0x00, 0x01, // lowest TSN
+		}},
+		{"SHUTDOWN-COMPLETE", []byte{
+			0x0e, 0x00, 0x00, 0x04,
+		}},
+		{"RECONFIG", []byte{
+			0x82, 0x00, 0x00, 0x18,
+			0x00, 0x0d, 0x00, 0x14, // Outgoing SSN Reset Request parameter
+			0x00, 0x00, 0x00, 0x01, // request sequence number
+			0x00, 0x00, 0x00, 0x02, // response sequence number
+			0x00, 0x00, 0x00, 0x03, // sender's last assigned TSN
+			0x00, 0x04, 0x00, 0x05, // stream identifiers
+		}},
+		{"TIMESTAMP", []byte{
+			0xb0, 0x01, 0x00, 0x0c, // E bit
+			0x00, 0x00, 0x00, 0x01, // timestamp value
+			0x00, 0x00, 0x00, 0x02, // timestamp echo reply
+		}},
+		{"FORWARD-TSN", []byte{
+			0xc0, 0x00, 0x00, 0x0c,
+			0x00, 0x00, 0x00, 0x01, // new cumulative TSN
+			0x00, 0x02, 0x00, 0x03, // stream identifier, stream sequence number
+		}},
+		{"I-FORWARD-TSN", []byte{
+			0xc2, 0x00, 0x00, 0x10,
+			0x00, 0x00, 0x00, 0x01, // new cumulative TSN
+			0x00, 0x02, 0x00, 0x01, // stream identifier, U bit
+			0x00, 0x00, 0x00, 0x03, // message identifier
+		}},
+	}
+}
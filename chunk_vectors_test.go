@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkVectors(t *testing.T) {
+	header := []byte{
+		0x13, 0x88, 0x13, 0x88, // source and destination port
+		0x00, 0x00, 0x00, 0x01, // verification tag
+		0x00, 0x00, 0x00, 0x00, // checksum
+	}
+
+	names := map[string]bool{}
+	for _, vector := range ChunkVectors() {
+		t.Run(vector.Name, func(t *testing.T) {
+			assert.False(t, names[vector.Name], "duplicate vector")
+			names[vector.Name] = true
+
+			raw := append(append([]byte{}, header...), vector.Raw...)
+			pkt := &packet{}
+			require.NoError(t, pkt.unmarshalUnchecked(false, raw))
+			require.Len(t, pkt.chunks, 1)
+
+			c := pkt.chunks[0]
+			h, ok := c.(chunkWithHeader)
+			require.True(t, ok)
+			assert.Equal(t, vector.Name, h.header().typ.String())
+			_, err := c.check()
+			assert.NoError(t, err)
+
+			out, err := c.marshal()
+			require.NoError(t, err)
+			assert.Equal(t, vector.Raw, out, "should encode back to the same bytes")
+		})
+	}
+
+	vectors := ChunkVectors()
+	vectors[0].Raw[0] = 0xff
+	assert.NotEqual(t, vectors[0].Raw, ChunkVectors()[0].Raw, "vectors should not be shared")
+}
@@ -25,11 +25,13 @@ const (
 	ctError            chunkType = 9
 	ctCookieEcho       chunkType = 10
 	ctCookieAck        chunkType = 11
+	ctECNE             chunkType = 12
 	ctCWR              chunkType = 13
 	ctShutdownComplete chunkType = 14
 	ctReconfig         chunkType = 130
 	ctForwardTSN       chunkType = 192
 	ctIForwardTSN      chunkType = 194
+	ctTimestamp        chunkType = 176
 )
 
 func (c chunkType) String() string { //nolint:cyclop
@@ -60,8 +62,10 @@ func (c chunkType) String() string { //nolint:cyclop
 		return "COOKIE-ECHO"
 	case ctCookieAck:
 		return "COOKIE-ACK"
-	case ctCWR:
+	case ctECNE:
 		return "ECNE" // Explicit Congestion Notification Echo
+	case ctCWR:
+		return "CWR" // Congestion Window Reduced
 	case ctShutdownComplete:
 		return "SHUTDOWN-COMPLETE"
 	case ctReconfig:
@@ -70,6 +74,8 @@ func (c chunkType) String() string { //nolint:cyclop
 		return "FORWARD-TSN"
 	case ctIForwardTSN:
 		return "I-FORWARD-TSN"
+	case ctTimestamp:
+		return "TIMESTAMP"
 	default:
 		return fmt.Sprintf("Unknown ChunkType: %d", c)
 	}
@@ -27,7 +27,8 @@ func TestChunkType_String(t *testing.T) {
 		{ctError, "ERROR"},
 		{ctCookieEcho, "COOKIE-ECHO"},
 		{ctCookieAck, "COOKIE-ACK"},
-		{ctCWR, "ECNE"},
+		{ctECNE, "ECNE"},
+		{ctCWR, "CWR"},
 		{ctShutdownComplete, "SHUTDOWN-COMPLETE"},
 		{ctReconfig, "RECONFIG"},
 		{ctForwardTSN, "FORWARD-TSN"},
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// CongestionEventType is the cause of a congestion window reduction.
+type CongestionEventType uint8
+
+const (
+	// CongestionEventFastRecovery is reported when fast recovery is entered
+	// after DATA was reported missing by SACKs.
+	CongestionEventFastRecovery CongestionEventType = iota + 1
+	// CongestionEventRTO is reported when the T3-rtx timer expires.
+	CongestionEventRTO
+	// CongestionEventECN is reported when the peer echoes an ECN
+	// Congestion Experienced mark.
+	CongestionEventECN
+)
+
+func (t CongestionEventType) String() string {
+	switch t {
+	case CongestionEventFastRecovery:
+		return "FastRecovery"
+	case CongestionEventRTO:
+		return "RTO"
+	case CongestionEventECN:
+		return "ECN"
+	default:
+		return "Unknown"
+	}
+}
+
+// CongestionEvent describes a congestion window reduction, see
+// WithOnCongestionEvent. Window sizes are in bytes.
+type CongestionEvent struct {
+	Type           CongestionEventType
+	CwndBefore     uint32
+	CwndAfter      uint32
+	SsthreshBefore uint32
+	SsthreshAfter  uint32
+}
@@ -40,6 +40,15 @@ func buildErrorCause(raw []byte) (errorCause, error) {
 		errCause = &errorCauseProtocolViolation{}
 	case userInitiatedAbort:
 		errCause = &errorCauseUserInitiatedAbort{}
+	case staleCookieError:
+		errCause = &errorCauseStaleCookie{}
+	case unrecognizedParameters:
+		errCause = &errorCauseUnrecognizedParameters{}
+	case invalidStreamIdentifier, missingMandatoryParameter, outOfResource,
+		unresolvableAddress, noUserData,
+		cookieReceivedWhileShuttingDown, restartOfAnAssociationWithNewAddresses:
+		// Known causes without specific handling keep their raw value.
+		errCause = &errorCauseHeader{}
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrBuildErrorCaseHandle, c.String())
 	}
@@ -99,3 +108,98 @@ func (e errorCauseCode) String() string { //nolint:cyclop
 		return fmt.Sprintf("Unknown CauseCode: %d", e)
 	}
 }
+
+// ErrorCause is an error cause reported by the peer in an ERROR chunk, or
+// sent to the peer by AbortWithCause.
+type ErrorCause struct {
+	// Code is the cause code, as listed in RFC 9260 section 3.3.10.
+	Code uint16
+
+	// Name is a human readable name of the cause code, such as
+	// "Stale Cookie Error".
+	Name string
+
+	// Info is the cause-specific information following the cause header,
+	// such as the unrecognized chunk for "Unrecognized Chunk Type".
+	Info []byte
+}
+
+func newErrorCause(cause errorCause) ErrorCause {
+	code := cause.errorCauseCode()
+	out := ErrorCause{
+		Code: uint16(code),
+		Name: code.String(),
+	}
+	if raw, err := cause.marshal(); err == nil && len(raw) > errorCauseHeaderLength {
+		out.Info = raw[errorCauseHeaderLength:]
+	}
+
+	return out
+}
+
+// String makes ErrorCause printable.
+func (e ErrorCause) String() string {
+	return e.Name
+}
+
+// detail formats e with its cause-specific information when it is known,
+// such as "Protocol Violation: bad peer", or returns its name.
+func (e ErrorCause) detail() string {
+	raw, err := e.toErrorCause().marshal()
+	if err != nil {
+		return e.Name
+	}
+	cause, err := buildErrorCause(raw)
+	if err != nil {
+		return e.Name
+	}
+
+	return cause.String()
+}
+
+// toErrorCause converts e back into a cause that can be sent in a chunk.
+func (e ErrorCause) toErrorCause() errorCause {
+	return &errorCauseHeader{code: errorCauseCode(e.Code), raw: e.Info}
+}
+
+// NewInvalidStreamIdentifierCause returns an "Invalid Stream Identifier"
+// cause for the given stream (RFC 9260 section 3.3.10.1).
+func NewInvalidStreamIdentifierCause(streamIdentifier uint16) ErrorCause {
+	info := make([]byte, 4)
+	binary.BigEndian.PutUint16(info, streamIdentifier)
+
+	return newErrorCause(&errorCauseHeader{code: invalidStreamIdentifier, raw: info})
+}
+
+// NewOutOfResourceCause returns an "Out of Resource" cause
+// (RFC 9260 section 3.3.10.4).
+func NewOutOfResourceCause() ErrorCause {
+	return newErrorCause(&errorCauseHeader{code: outOfResource})
+}
+
+// NewNoUserDataCause returns a "No User Data" cause for the DATA chunk with
+// the given TSN (RFC 9260 section 3.3.10.9).
+func NewNoUserDataCause(tsn uint32) ErrorCause {
+	info := make([]byte, 4)
+	binary.BigEndian.PutUint32(info, tsn)
+
+	return newErrorCause(&errorCauseHeader{code: noUserData, raw: info})
+}
+
+// NewUserInitiatedAbortCause returns a "User-Initiated Abort" cause carrying
+// the given upper layer abort reason (RFC 9260 section 3.3.10.12).
+func NewUserInitiatedAbortCause(reason string) ErrorCause {
+	return newErrorCause(&errorCauseUserInitiatedAbort{
+		errorCauseHeader:      errorCauseHeader{code: userInitiatedAbort},
+		upperLayerAbortReason: []byte(reason),
+	})
+}
+
+// NewProtocolViolationCause returns a "Protocol Violation" cause with the
+// given additional information (RFC 9260 section 3.3.10.13).
+func NewProtocolViolationCause(info string) ErrorCause {
+	return newErrorCause(&errorCauseProtocolViolation{
+		errorCauseHeader:      errorCauseHeader{code: protocolViolation},
+		additionalInformation: []byte(info),
+	})
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+Indicates the receipt of a valid State Cookie that has expired.
+
+	 0                   1                   2                   3
+	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|     Cause Code=3              |       Cause Length=8          |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                 Measure of Staleness (usec.)                  |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+type errorCauseStaleCookie struct {
+	errorCauseHeader
+	staleness uint32 // microseconds
+}
+
+const staleCookieValueLength = 4
+
+// Stale cookie error cause errors.
+var (
+	ErrStaleCookieUnmarshal = errors.New("unable to unmarshal Stale Cookie error")
+)
+
+func (e *errorCauseStaleCookie) marshal() ([]byte, error) {
+	e.code = staleCookieError
+	e.raw = make([]byte, staleCookieValueLength)
+	binary.BigEndian.PutUint32(e.raw, e.staleness)
+
+	return e.errorCauseHeader.marshal()
+}
+
+func (e *errorCauseStaleCookie) unmarshal(raw []byte) error {
+	if err := e.errorCauseHeader.unmarshal(raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrStaleCookieUnmarshal, err) //nolint:errorlint
+	}
+	if len(e.raw) != staleCookieValueLength {
+		return ErrStaleCookieUnmarshal
+	}
+
+	e.staleness = binary.BigEndian.Uint32(e.raw)
+
+	return nil
+}
+
+// String makes errorCauseStaleCookie printable.
+func (e *errorCauseStaleCookie) String() string {
+	return fmt.Sprintf("%s: staleness=%dus", e.errorCauseHeader, e.staleness)
+}
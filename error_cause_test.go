@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCauseConstructors(t *testing.T) {
+	for _, tc := range []struct {
+		cause ErrorCause
+		code  errorCauseCode
+		info  []byte
+		str   string
+	}{
+		{
+			NewInvalidStreamIdentifierCause(0x1234), invalidStreamIdentifier,
+			[]byte{0x12, 0x34, 0, 0}, "Invalid Stream Identifier",
+		},
+		{NewOutOfResourceCause(), outOfResource, nil, "Out Of Resource"},
+		{NewNoUserDataCause(0x01020304), noUserData, []byte{1, 2, 3, 4}, "No User Data"},
+		{NewUserInitiatedAbortCause("bye"), userInitiatedAbort, []byte("bye"), "User Initiated Abort: bye"},
+		{NewProtocolViolationCause("oops"), protocolViolation, []byte("oops"), "Protocol Violation: oops"},
+	} {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			assert.Equal(t, uint16(tc.code), tc.cause.Code)
+			assert.Equal(t, tc.code.String(), tc.cause.Name)
+			assert.Equal(t, tc.info, tc.cause.Info)
+
+			// The cause must survive a round trip through an ABORT chunk.
+			raw, err := (&chunkAbort{errorCauses: []errorCause{tc.cause.toErrorCause()}}).marshal()
+			require.NoError(t, err)
+			abort := &chunkAbort{}
+			require.NoError(t, abort.unmarshal(raw))
+			require.Len(t, abort.errorCauses, 1)
+			assert.Equal(t, tc.code, abort.errorCauses[0].errorCauseCode())
+			assert.Equal(t, tc.str, abort.errorCauses[0].String())
+		})
+	}
+}
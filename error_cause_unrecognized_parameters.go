@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+/*
+This error cause is returned to the originator of the INIT ACK chunk if
+the receiver does not recognize one or more optional parameters in the
+INIT ACK chunk.
+
+	 0                   1                   2                   3
+	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|     Cause Code=8              |      Cause Length             |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	/                  Unrecognized Parameters                      /
+	\                                                               \
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+type errorCauseUnrecognizedParameters struct {
+	errorCauseHeader
+	// unrecognizedParams holds the complete TLVs of the unrecognized
+	// parameters, each padded to a multiple of 4 bytes.
+	unrecognizedParams []byte
+}
+
+func (e *errorCauseUnrecognizedParameters) marshal() ([]byte, error) {
+	e.code = unrecognizedParameters
+	e.errorCauseHeader.raw = e.unrecognizedParams
+
+	return e.errorCauseHeader.marshal()
+}
+
+func (e *errorCauseUnrecognizedParameters) unmarshal(raw []byte) error {
+	err := e.errorCauseHeader.unmarshal(raw)
+	if err != nil {
+		return err
+	}
+
+	e.unrecognizedParams = e.errorCauseHeader.raw
+
+	return nil
+}
+
+// String makes errorCauseUnrecognizedParameters printable.
+func (e *errorCauseUnrecognizedParameters) String() string {
+	return e.errorCauseHeader.String()
+}
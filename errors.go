@@ -14,6 +14,9 @@ var (
 	// errZeroMTUOption indicates that the MTU option was set to zero.
 	errZeroMTUOption = errors.New("MTU option cannot be set to zero")
 
+	// errZeroAvgChunkSizeHint indicates that the average chunk size hint was set to zero.
+	errZeroAvgChunkSizeHint = errors.New("AvgChunkSizeHint option cannot be set to zero")
+
 	// errZeroMaxReceiveBufferOption indicates that the MTU option was set to zero.
 	errZeroMaxReceiveBufferOption = errors.New("MaxReceiveBuffer option cannot be set to zero")
 
@@ -23,6 +26,87 @@ var (
 	// errInvalidRTOMax indicates that the RTO max was set to 0 or a negative value.
 	errInvalidRTOMax = errors.New("RTO max was set to <= 0")
 
+	// errInvalidRTOJitter indicates that the RTO jitter was outside [0, 1).
+	errInvalidRTOJitter = errors.New("RTO jitter must be in [0, 1)")
+
+	// errInvalidPeerStallTimeout indicates that the peer stall timeout was set to a negative value.
+	errInvalidPeerStallTimeout = errors.New("peer stall timeout was set to a negative value")
+
+	// errInvalidReassemblyTimeout indicates that the reassembly timeout was set to a negative value.
+	errInvalidReassemblyTimeout = errors.New("reassembly timeout was set to a negative value")
+
+	// errInvalidMinT3RTX indicates that the T3-rtx floor was set to a negative value.
+	errInvalidMinT3RTX = errors.New("MinT3RTX was set to < 0")
+
+	// errInvalidHeartbeatInterval indicates that the idle heartbeat interval was set to a negative value.
+	errInvalidHeartbeatInterval = errors.New("HeartbeatInterval was set to < 0")
+
+	// errHeartbeatIntervalTooSmall indicates that a non-zero idle heartbeat interval was below one millisecond.
+	errHeartbeatIntervalTooSmall = errors.New("HeartbeatInterval was set to less than 1ms")
+
+	// errZeroHeartbeatMaxRetrans indicates that the heartbeat retransmission limit was set to zero.
+	errZeroHeartbeatMaxRetrans = errors.New("HeartbeatMaxRetrans option cannot be set to zero")
+
+	// errZeroReceiveBufferAutotuneMax indicates that the receive buffer autotuning limit was set to zero.
+	errZeroReceiveBufferAutotuneMax = errors.New("ReceiveBufferAutotuneMax option cannot be set to zero")
+
+	// errZeroAdvertisedRWND indicates that the advertised receiver window was set to zero.
+	errZeroAdvertisedRWND = errors.New("AdvertisedRWND option cannot be set to zero")
+
+	// errAdvertisedRWNDTooLarge indicates that the advertised receiver window exceeds the receive buffer size.
+	errAdvertisedRWNDTooLarge = errors.New("AdvertisedRWND cannot exceed MaxReceiveBufferSize")
+
+	// errReceiveMTUTooSmall indicates that the receive MTU was set below the smallest DTLS MTU.
+	errReceiveMTUTooSmall = errors.New("ReceiveMTU cannot be below 1200")
+
+	// errZeroWriteBatchSize indicates that the write loop batch size was set to zero.
+	errZeroWriteBatchSize = errors.New("WriteBatchSize option cannot be set to zero")
+
+	// errZeroMaxInitRetransmits indicates that the handshake retransmission limit was set to zero.
+	errZeroMaxInitRetransmits = errors.New("MaxInitRetransmits option cannot be set to zero")
+
+	// errZeroMaxShutdownRetrans indicates that the shutdown retransmission limit was set to zero.
+	errZeroMaxShutdownRetrans = errors.New("MaxShutdownRetrans option cannot be set to zero")
+
+	// errZeroMaxReconfigRequests indicates that the reconfig request limit was set to zero.
+	errZeroMaxReconfigRequests = errors.New("MaxReconfigRequests option cannot be set to zero")
+
+	// errInvalidReconfigRequestLifetime indicates that the reconfig request lifetime was not positive.
+	errInvalidReconfigRequestLifetime = errors.New("ReconfigRequestLifetime was set to <= 0")
+
+	// errZeroFastRetransmitThreshold indicates that the fast retransmit threshold was set to zero.
+	errZeroFastRetransmitThreshold = errors.New("FastRetransmitThreshold option cannot be set to zero")
+
+	// errZeroInitialCwnd indicates that the initial congestion window was set to zero.
+	errZeroInitialCwnd = errors.New("InitialCwnd option cannot be set to zero")
+
+	// errZeroMaxCwnd indicates that the congestion window cap was set to zero.
+	errZeroMaxCwnd = errors.New("MaxCwnd option cannot be set to zero")
+
+	// errInvalidIdleCwndResetTimeout indicates that the idle cwnd reset timeout was set to a negative value.
+	errInvalidIdleCwndResetTimeout = errors.New("IdleCwndResetTimeout was set to < 0")
+
+	// errInitialCwndBelowMinCwnd indicates that the initial congestion window is below the minimum.
+	errInitialCwndBelowMinCwnd = errors.New("InitialCwnd cannot be below MinCwnd")
+
+	// errMaxCwndTooSmall indicates that the congestion window cap is below the minimum or initial window.
+	errMaxCwndTooSmall = errors.New("MaxCwnd cannot be below MinCwnd or InitialCwnd")
+
+	// errInvalidProfile indicates that an unknown association profile was selected.
+	errInvalidProfile = errors.New("unknown association profile")
+
+	// errInvalidSackPolicy indicates that an unknown SACK policy was selected.
+	errInvalidSackPolicy = errors.New("unknown SACK policy")
+
+	// errInvalidSackFrequency indicates that the SACK frequency was set below one packet.
+	errInvalidSackFrequency = errors.New("SackFrequency was set to < 1")
+
+	// errInvalidCookieLifetime indicates that the cookie lifetime was not positive.
+	errInvalidCookieLifetime = errors.New("CookieLifetime was set to <= 0")
+
+	// errInvalidZeroChecksumEDMID indicates that the reserved error detection method identifier was selected.
+	errInvalidZeroChecksumEDMID = errors.New("zero checksum error detection method identifier is reserved")
+
 	// errInvalidRackMinRTTWnd indicates the length of the local minimum window used to determine the
 	// minRTT was set to <= 0.
 	errInvalidRackMinRTTWnd = errors.New("RackMinRTT was set to <= 0")
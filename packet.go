@@ -66,18 +66,24 @@ var (
 	ErrChecksumMismatch            = errors.New("checksum mismatch theirs")
 )
 
-func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
-	if len(raw) < packetHeaderSize {
-		return fmt.Errorf("%w: raw only %d bytes, %d is the minimum length", ErrPacketRawTooSmall, len(raw), packetHeaderSize)
-	}
+func (p *packet) unmarshal(doChecksum bool, raw []byte) error {
+	return p.unmarshalChunks(doChecksum, false, raw)
+}
 
-	offset := packetHeaderSize
+// unmarshalChunks parses raw into p. When lenient is set, a chunk that fails
+// to parse is dropped instead of failing the whole packet: parsing continues
+// after it if its length is valid and its type asks to skip unrecognized
+// chunks, and stops otherwise, keeping the chunks parsed so far.
+func (p *packet) unmarshalChunks(doChecksum, lenient bool, raw []byte) error {
+	if err := checkPacketSize(raw); err != nil {
+		return err
+	}
 
 	// Check if doing CRC32c is required.
 	// Without having SCTP AUTH implemented, this depends only on the type
 	// og the first chunk.
-	if offset+chunkHeaderSize <= len(raw) {
-		switch chunkType(raw[offset]) {
+	if packetHeaderSize+chunkHeaderSize <= len(raw) {
+		switch chunkType(raw[packetHeaderSize]) {
 		case ctInit, ctCookieEcho:
 			doChecksum = true
 		default:
@@ -91,6 +97,25 @@ func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
 		}
 	}
 
+	return p.unmarshalUnchecked(lenient, raw)
+}
+
+func checkPacketSize(raw []byte) error {
+	if len(raw) < packetHeaderSize {
+		return fmt.Errorf("%w: raw only %d bytes, %d is the minimum length", ErrPacketRawTooSmall, len(raw), packetHeaderSize)
+	}
+
+	return nil
+}
+
+// unmarshalUnchecked parses raw into p without verifying its checksum.
+func (p *packet) unmarshalUnchecked(lenient bool, raw []byte) error { //nolint:cyclop,gocognit
+	if err := checkPacketSize(raw); err != nil {
+		return err
+	}
+
+	offset := packetHeaderSize
+
 	p.sourcePort = binary.BigEndian.Uint16(raw[0:])
 	p.destinationPort = binary.BigEndian.Uint16(raw[2:])
 	p.verificationTag = binary.BigEndian.Uint32(raw[4:])
@@ -101,6 +126,10 @@ func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
 
 		// must have at least a full chunk header to continue.
 		if len(remaining) < chunkHeaderSize {
+			if lenient {
+				return nil
+			}
+
 			return fmt.Errorf("%w: offset %d remaining %d", ErrParseSCTPChunkNotEnoughData, offset, len(remaining))
 		}
 
@@ -120,6 +149,8 @@ func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
 			dataChunk = &chunkCookieAck{}
 		case ctHeartbeat:
 			dataChunk = &chunkHeartbeat{}
+		case ctHeartbeatAck:
+			dataChunk = &chunkHeartbeatAck{}
 		case ctPayloadData:
 			dataChunk = &chunkPayloadData{}
 		case ctIData:
@@ -140,21 +171,41 @@ func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
 			dataChunk = &chunkShutdownAck{}
 		case ctShutdownComplete:
 			dataChunk = &chunkShutdownComplete{}
+		case ctECNE:
+			dataChunk = &chunkECNE{}
+		case ctCWR:
+			dataChunk = &chunkCWR{}
+		case ctTimestamp:
+			dataChunk = &chunkTimestamp{}
 		default:
-			return fmt.Errorf("%w: %s", ErrUnmarshalUnknownChunkType, ctype.String())
+			dataChunk = &chunkUnknown{}
 		}
 
 		if err := dataChunk.unmarshal(remaining); err != nil {
-			return err
+			if !lenient {
+				return err
+			}
+
+			next, ok := nextChunkOffset(remaining)
+			if !ok || ctype&chunkTypeBitSkip == 0 {
+				return nil
+			}
+			offset += next
+
+			continue
 		}
 
 		p.chunks = append(p.chunks, dataChunk)
+		if unknown, ok := dataChunk.(*chunkUnknown); ok && !unknown.skip() {
+			// The chunks following it are discarded.
+			return nil
+		}
 		chunkValuePadding := getPadding(dataChunk.valueLength())
 		offset += chunkHeaderSize + dataChunk.valueLength() + chunkValuePadding
 	}
 
 	// if we overshot then should error.
-	if offset != len(raw) {
+	if !lenient && offset != len(raw) {
 		if offset > len(raw) {
 			overshoot := offset - len(raw)
 
@@ -171,6 +222,34 @@ func (p *packet) unmarshal(doChecksum bool, raw []byte) error { //nolint:cyclop
 	return nil
 }
 
+// nextChunkOffset returns the offset of the chunk following the one at the
+// start of raw, or false if the chunk length is invalid.
+func nextChunkOffset(raw []byte) (int, bool) {
+	length := int(binary.BigEndian.Uint16(raw[2:]))
+	if length < chunkHeaderSize || length > len(raw) {
+		return 0, false
+	}
+
+	return length + getPadding(length), true
+}
+
+// hasChunkType reports whether the marshaled packet raw carries a chunk of
+// type typ, without unmarshaling the chunks.
+func hasChunkType(raw []byte, typ chunkType) bool {
+	for offset := packetHeaderSize; offset+chunkHeaderSize <= len(raw); {
+		if chunkType(raw[offset]) == typ {
+			return true
+		}
+		next, ok := nextChunkOffset(raw[offset:])
+		if !ok {
+			return false
+		}
+		offset += next
+	}
+
+	return false
+}
+
 func (p *packet) marshal(doChecksum bool) ([]byte, error) {
 	raw := make([]byte, packetHeaderSize)
 
@@ -237,17 +316,20 @@ func (p *packet) String() string {
 }
 
 // TryMarshalUnmarshal attempts to marshal and unmarshal a message. Added for fuzzing.
+// The message is parsed both strictly and leniently.
 func TryMarshalUnmarshal(msg []byte) int {
-	p := &packet{}
-	err := p.unmarshal(false, msg)
-	if err != nil {
-		return 0
-	}
+	result := 0
+	for _, lenient := range []bool{false, true} {
+		p := &packet{}
+		if err := p.unmarshalChunks(false, lenient, msg); err != nil {
+			continue
+		}
 
-	_, err = p.marshal(false)
-	if err != nil {
-		return 0
+		if _, err := p.marshal(false); err != nil {
+			continue
+		}
+		result = 1
 	}
 
-	return 1
+	return result
 }
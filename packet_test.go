@@ -40,6 +40,130 @@ func TestPacketUnmarshal(t *testing.T) {
 	assert.NoError(t, pkt.unmarshal(true, rawChunk))
 }
 
+func TestPacketUnmarshalUnknownChunk(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		typ    chunkType
+		skip   bool
+		report bool
+	}{
+		{"stop", 0x0f, false, false},
+		{"stop and report", 0x4f, false, true},
+		{"skip", 0x8f, true, false},
+		{"skip and report", 0xcf, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in := &packet{
+				sourcePort:      defaultSCTPSrcDstPort,
+				destinationPort: defaultSCTPSrcDstPort,
+				chunks: []chunk{
+					&chunkUnknown{chunkHeader{typ: tc.typ, raw: []byte{1, 2, 3}}},
+					&chunkCookieAck{},
+				},
+			}
+			raw, err := in.marshal(true)
+			assert.NoError(t, err)
+
+			pkt := &packet{}
+			assert.NoError(t, pkt.unmarshal(true, raw))
+			if tc.skip {
+				assert.Len(t, pkt.chunks, 2, "the chunk after the unknown chunk should be parsed")
+			} else {
+				assert.Len(t, pkt.chunks, 1, "the chunks after the unknown chunk should be discarded")
+			}
+
+			unknown, ok := pkt.chunks[0].(*chunkUnknown)
+			if assert.True(t, ok) {
+				assert.Equal(t, tc.typ, unknown.typ)
+				assert.Equal(t, []byte{1, 2, 3}, unknown.raw)
+				assert.Equal(t, tc.skip, unknown.skip())
+				assert.Equal(t, tc.report, unknown.report())
+			}
+		})
+	}
+}
+
+func TestPacketUnmarshalLenient(t *testing.T) {
+	header := []byte{0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	cookieAck := []byte{byte(ctCookieAck), 0x00, 0x00, 0x04}
+	packetOf := func(chunks ...[]byte) []byte {
+		raw := append([]byte{}, header...)
+		for _, c := range chunks {
+			raw = append(raw, c...)
+		}
+
+		return raw
+	}
+
+	for _, tc := range []struct {
+		name   string
+		raw    []byte
+		chunks int
+	}{
+		{
+			"malformed chunk skipped",
+			// FORWARD-TSN too short for its new cumulative TSN
+			packetOf([]byte{byte(ctForwardTSN), 0x00, 0x00, 0x07, 0x00, 0x00, 0x01, 0x00}, cookieAck),
+			1,
+		},
+		{
+			"malformed chunk stops processing",
+			// SACK too short for its header
+			packetOf(cookieAck, []byte{byte(ctSack), 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01}, cookieAck),
+			1,
+		},
+		{
+			"invalid chunk length",
+			packetOf(cookieAck, []byte{byte(ctForwardTSN), 0x00, 0x00, 0x02}, cookieAck),
+			1,
+		},
+		{
+			"truncated trailing chunk",
+			packetOf(cookieAck, []byte{byte(ctForwardTSN), 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x01}),
+			1,
+		},
+		{
+			"trailing bytes shorter than a chunk header",
+			packetOf(cookieAck, []byte{0x00, 0x00}),
+			1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			strict := &packet{}
+			assert.Error(t, strict.unmarshal(false, tc.raw))
+
+			lenient := &packet{}
+			assert.NoError(t, lenient.unmarshalChunks(false, true, tc.raw))
+			assert.Len(t, lenient.chunks, tc.chunks)
+			for _, c := range lenient.chunks {
+				assert.IsType(t, &chunkCookieAck{}, c)
+			}
+			assert.Equal(t, 1, TryMarshalUnmarshal(tc.raw))
+		})
+	}
+}
+
+func FuzzTryMarshalUnmarshal(f *testing.F) {
+	f.Add([]byte{0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	f.Add([]byte{
+		0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		byte(ctCookieAck), 0x00, 0x00, 0x04, byte(ctForwardTSN), 0x00, 0x00, 0x02,
+	})
+	f.Add([]byte{
+		0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		byte(ctSack), 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x10, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	})
+	for _, vector := range ChunkVectors() {
+		header := []byte{0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+		f.Add(append(header, vector.Raw...))
+	}
+
+	f.Fuzz(func(_ *testing.T, data []byte) {
+		TryMarshalUnmarshal(data)
+	})
+}
+
 func TestPacketMarshal(t *testing.T) {
 	pkt := &packet{}
 
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+)
+
+// Packet is a decoded snapshot of an SCTP packet, as returned by
+// ParsePacket. It does not reference the buffer it was parsed from.
+type Packet struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	VerificationTag uint32
+	Checksum        uint32
+	Chunks          []Chunk
+}
+
+// Chunk is a decoded snapshot of a chunk of a Packet. The common header
+// fields are always set. The key fields of DATA, I-DATA, SACK, INIT,
+// INIT ACK, ERROR and ABORT chunks are decoded in the matching field;
+// the value of any other chunk can be inspected in Value.
+type Chunk struct {
+	// Type is the chunk type, as listed in RFC 9260 section 3.2.
+	Type uint8
+
+	// Name is a human readable name of the chunk type, such as "SACK".
+	Name string
+
+	Flags uint8
+
+	// Length is the chunk length field: the header and value, without
+	// padding.
+	Length uint16
+
+	// Value is the chunk value, without padding.
+	Value []byte
+
+	// Data is set for DATA and I-DATA chunks.
+	Data *DataChunkInfo
+
+	// SACK is set for SACK chunks.
+	SACK *SACKChunkInfo
+
+	// Init is set for INIT and INIT ACK chunks.
+	Init *InitChunkInfo
+
+	// Causes is set for ERROR and ABORT chunks.
+	Causes []ErrorCause
+}
+
+// DataChunkInfo holds the key fields of a DATA or I-DATA chunk.
+type DataChunkInfo struct {
+	TSN      uint32
+	StreamID uint16
+
+	// StreamSequenceNumber is only set for DATA chunks.
+	StreamSequenceNumber uint16
+
+	// MessageID and FragmentSequenceNumber are only set for I-DATA chunks.
+	MessageID              uint32
+	FragmentSequenceNumber uint32
+
+	PayloadType   PayloadProtocolIdentifier
+	Unordered     bool
+	Beginning     bool
+	Ending        bool
+	ImmediateSack bool
+	UserDataSize  int
+}
+
+// SACKChunkInfo holds the key fields of a SACK chunk.
+type SACKChunkInfo struct {
+	CumulativeTSNAck               uint32
+	AdvertisedReceiverWindowCredit uint32
+	GapAckBlocks                   []GapAckBlock
+	DuplicateTSNs                  []uint32
+}
+
+// GapAckBlock is a gap ack block of a SACK chunk. Start and End are offsets
+// from the cumulative TSN ack.
+type GapAckBlock struct {
+	Start uint16
+	End   uint16
+}
+
+// InitChunkInfo holds the fixed fields of an INIT or INIT ACK chunk.
+type InitChunkInfo struct {
+	InitiateTag                    uint32
+	AdvertisedReceiverWindowCredit uint32
+	NumOutboundStreams             uint16
+	NumInboundStreams              uint16
+	InitialTSN                     uint32
+}
+
+// chunkWithHeader is implemented by all chunks through their embedded
+// chunkHeader.
+type chunkWithHeader interface {
+	header() *chunkHeader
+}
+
+func (c *chunkHeader) header() *chunkHeader {
+	return c
+}
+
+// ParsePacket decodes raw, an SCTP packet such as the payload of a captured
+// DTLS record, for offline analysis. The checksum is verified unless it is
+// zero, except for packets starting with INIT or COOKIE ECHO where it is
+// always verified.
+func ParsePacket(raw []byte) (*Packet, error) {
+	pkt := &packet{}
+	if err := pkt.unmarshal(false, raw); err != nil {
+		return nil, err
+	}
+
+	out := &Packet{
+		SourcePort:      pkt.sourcePort,
+		DestinationPort: pkt.destinationPort,
+		VerificationTag: pkt.verificationTag,
+		Checksum:        binary.LittleEndian.Uint32(raw[8:]),
+		Chunks:          make([]Chunk, 0, len(pkt.chunks)),
+	}
+	for _, c := range pkt.chunks {
+		out.Chunks = append(out.Chunks, newChunkView(c))
+	}
+
+	return out, nil
+}
+
+func newChunkView(c chunk) Chunk {
+	view := Chunk{}
+	if h, ok := c.(chunkWithHeader); ok {
+		hdr := h.header()
+		view.Type = uint8(hdr.typ)
+		view.Name = hdr.typ.String()
+		view.Flags = hdr.flags
+		view.Length = uint16(chunkHeaderSize + len(hdr.raw)) //nolint:gosec // G115, bounded by the length field
+		view.Value = append([]byte(nil), hdr.raw...)
+	}
+
+	switch c := c.(type) {
+	case *chunkPayloadData:
+		view.Data = &DataChunkInfo{
+			TSN:           c.tsn,
+			StreamID:      c.streamIdentifier,
+			PayloadType:   c.payloadType,
+			Unordered:     c.unordered,
+			Beginning:     c.beginningFragment,
+			Ending:        c.endingFragment,
+			ImmediateSack: c.immediateSack,
+			UserDataSize:  len(c.userData),
+		}
+		if c.iData {
+			view.Data.MessageID = c.messageIdentifier
+			view.Data.FragmentSequenceNumber = c.fragmentSequenceNumber
+		} else {
+			view.Data.StreamSequenceNumber = c.streamSequenceNumber
+		}
+	case *chunkSelectiveAck:
+		sack := &SACKChunkInfo{
+			CumulativeTSNAck:               c.cumulativeTSNAck,
+			AdvertisedReceiverWindowCredit: c.advertisedReceiverWindowCredit,
+			DuplicateTSNs:                  append([]uint32(nil), c.duplicateTSN...),
+		}
+		for _, b := range c.gapAckBlocks {
+			sack.GapAckBlocks = append(sack.GapAckBlocks, GapAckBlock{Start: b.start, End: b.end})
+		}
+		view.SACK = sack
+	case *chunkInit:
+		view.Init = newInitChunkInfo(&c.chunkInitCommon)
+	case *chunkInitAck:
+		view.Init = newInitChunkInfo(&c.chunkInitCommon)
+	case *chunkError:
+		view.Causes = newErrorCauses(c.errorCauses)
+	case *chunkAbort:
+		view.Causes = newErrorCauses(c.errorCauses)
+	}
+
+	return view
+}
+
+func newInitChunkInfo(c *chunkInitCommon) *InitChunkInfo {
+	return &InitChunkInfo{
+		InitiateTag:                    c.initiateTag,
+		AdvertisedReceiverWindowCredit: c.advertisedReceiverWindowCredit,
+		NumOutboundStreams:             c.numOutboundStreams,
+		NumInboundStreams:              c.numInboundStreams,
+		InitialTSN:                     c.initialTSN,
+	}
+}
+
+func newErrorCauses(causes []errorCause) []ErrorCause {
+	out := make([]ErrorCause, 0, len(causes))
+	for _, cause := range causes {
+		view := newErrorCause(cause)
+		view.Info = append([]byte(nil), view.Info...)
+		out = append(out, view)
+	}
+
+	return out
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePacket(t *testing.T) {
+	t.Run("DATA, SACK and ERROR", func(t *testing.T) {
+		pkt := &packet{
+			sourcePort:      5000,
+			destinationPort: 5001,
+			verificationTag: 0x01020304,
+			chunks: []chunk{
+				&chunkPayloadData{
+					tsn:                  10,
+					streamIdentifier:     2,
+					streamSequenceNumber: 3,
+					payloadType:          PayloadTypeWebRTCBinary,
+					beginningFragment:    true,
+					endingFragment:       true,
+					unordered:            true,
+					userData:             []byte("hello"),
+				},
+				&chunkSelectiveAck{
+					cumulativeTSNAck:               20,
+					advertisedReceiverWindowCredit: 1000,
+					gapAckBlocks:                   []gapAckBlock{{start: 2, end: 3}},
+					duplicateTSN:                   []uint32{18},
+				},
+				&chunkError{
+					errorCauses: []errorCause{
+						&errorCauseProtocolViolation{
+							errorCauseHeader:      errorCauseHeader{code: protocolViolation},
+							additionalInformation: []byte("oops"),
+						},
+					},
+				},
+			},
+		}
+		raw, err := pkt.marshal(true)
+		require.NoError(t, err)
+
+		parsed, err := ParsePacket(raw)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(5000), parsed.SourcePort)
+		assert.Equal(t, uint16(5001), parsed.DestinationPort)
+		assert.Equal(t, uint32(0x01020304), parsed.VerificationTag)
+		assert.NotZero(t, parsed.Checksum)
+		require.Len(t, parsed.Chunks, 3)
+
+		data := parsed.Chunks[0]
+		assert.Equal(t, uint8(ctPayloadData), data.Type)
+		assert.Equal(t, "DATA", data.Name)
+		assert.Equal(t, uint8(payloadDataUnorderedBitmask|payloadDataBeginingFragmentBitmask|
+			payloadDataEndingFragmentBitmask), data.Flags)
+		assert.Equal(t, uint16(chunkHeaderSize+payloadDataHeaderSize+5), data.Length)
+		assert.Len(t, data.Value, payloadDataHeaderSize+5)
+		assert.Equal(t, &DataChunkInfo{
+			TSN:                  10,
+			StreamID:             2,
+			StreamSequenceNumber: 3,
+			PayloadType:          PayloadTypeWebRTCBinary,
+			Unordered:            true,
+			Beginning:            true,
+			Ending:               true,
+			UserDataSize:         5,
+		}, data.Data)
+		assert.Nil(t, data.SACK)
+
+		sack := parsed.Chunks[1]
+		assert.Equal(t, "SACK", sack.Name)
+		assert.Equal(t, &SACKChunkInfo{
+			CumulativeTSNAck:               20,
+			AdvertisedReceiverWindowCredit: 1000,
+			GapAckBlocks:                   []GapAckBlock{{Start: 2, End: 3}},
+			DuplicateTSNs:                  []uint32{18},
+		}, sack.SACK)
+
+		errChunk := parsed.Chunks[2]
+		assert.Equal(t, "ERROR", errChunk.Name)
+		require.Len(t, errChunk.Causes, 1)
+		assert.Equal(t, uint16(protocolViolation), errChunk.Causes[0].Code)
+		assert.Equal(t, []byte("oops"), errChunk.Causes[0].Info)
+
+		// The snapshot does not alias the parsed buffer.
+		for i := range raw {
+			raw[i] = 0
+		}
+		assert.Len(t, data.Value, payloadDataHeaderSize+5)
+		assert.Equal(t, byte('h'), data.Value[payloadDataHeaderSize])
+		assert.Equal(t, []byte("oops"), errChunk.Causes[0].Info)
+	})
+
+	t.Run("INIT", func(t *testing.T) {
+		pkt := &packet{
+			sourcePort:      5000,
+			destinationPort: 5000,
+			chunks: []chunk{
+				&chunkInit{chunkInitCommon: chunkInitCommon{
+					initiateTag:                    0xdeadbeef,
+					advertisedReceiverWindowCredit: 131072,
+					numOutboundStreams:             1024,
+					numInboundStreams:              2048,
+					initialTSN:                     77,
+				}},
+			},
+		}
+		raw, err := pkt.marshal(true)
+		require.NoError(t, err)
+
+		parsed, err := ParsePacket(raw)
+		require.NoError(t, err)
+		require.Len(t, parsed.Chunks, 1)
+		assert.Equal(t, "INIT", parsed.Chunks[0].Name)
+		assert.Equal(t, &InitChunkInfo{
+			InitiateTag:                    0xdeadbeef,
+			AdvertisedReceiverWindowCredit: 131072,
+			NumOutboundStreams:             1024,
+			NumInboundStreams:              2048,
+			InitialTSN:                     77,
+		}, parsed.Chunks[0].Init)
+
+		raw[len(raw)-1] ^= 0xff
+		_, err = ParsePacket(raw)
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		_, err := ParsePacket([]byte{0x13, 0x88})
+		assert.ErrorIs(t, err, ErrPacketRawTooSmall)
+	})
+}
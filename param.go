@@ -30,8 +30,16 @@ func buildParam(typeParam paramType, rawParam []byte) (param, error) { //nolint:
 		return (&paramRequestedHMACAlgorithm{}).unmarshal(rawParam)
 	case chunkList:
 		return (&paramChunkList{}).unmarshal(rawParam)
+	case ipV4Addr, ipV6Addr:
+		return (&paramIPAddress{}).unmarshal(rawParam)
 	case stateCookie:
 		return (&paramStateCookie{}).unmarshal(rawParam)
+	case unrecognizedParam:
+		return (&paramUnrecognized{}).unmarshal(rawParam)
+	case cookiePreservative:
+		return (&paramCookiePreservative{}).unmarshal(rawParam)
+	case supportedAddrTypes:
+		return (&paramSupportedAddressTypes{}).unmarshal(rawParam)
 	case heartbeatInfo:
 		return (&paramHeartbeatInfo{}).unmarshal(rawParam)
 	case outSSNResetReq:
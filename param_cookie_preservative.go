@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+//  The sender of the INIT shall use this parameter to suggest to the
+//  receiver of the INIT for a longer life-span of the State Cookie.
+//
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |          Type = 9             |          Length = 8           |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |         Suggested Cookie Life-Span Increment (msec.)          |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+type paramCookiePreservative struct {
+	paramHeader
+	lifeSpanIncrement uint32 // milliseconds
+}
+
+// Cookie Preservative parameter error.
+var (
+	ErrCookiePreservativeParamTooShort = errors.New("cookie preservative parameter too short")
+)
+
+func (c *paramCookiePreservative) marshal() ([]byte, error) {
+	c.typ = cookiePreservative
+	c.raw = make([]byte, 4)
+	binary.BigEndian.PutUint32(c.raw, c.lifeSpanIncrement)
+
+	return c.paramHeader.marshal()
+}
+
+func (c *paramCookiePreservative) unmarshal(raw []byte) (param, error) {
+	err := c.paramHeader.unmarshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.raw) < 4 {
+		return nil, ErrCookiePreservativeParamTooShort
+	}
+	c.lifeSpanIncrement = binary.BigEndian.Uint32(c.raw)
+
+	return c, nil
+}
+
+// String makes paramCookiePreservative printable.
+func (c *paramCookiePreservative) String() string {
+	return fmt.Sprintf("%s: %dms", c.paramHeader, c.lifeSpanIncrement)
+}
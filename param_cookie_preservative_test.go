@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamCookiePreservative(t *testing.T) {
+	binary := []byte{0x00, 0x09, 0x00, 0x08, 0x00, 0x00, 0x03, 0xe8}
+	parsed := &paramCookiePreservative{
+		paramHeader: paramHeader{
+			typ:                cookiePreservative,
+			unrecognizedAction: paramHeaderUnrecognizedActionStop,
+			len:                8,
+			raw:                []byte{0x00, 0x00, 0x03, 0xe8},
+		},
+		lifeSpanIncrement: 1000,
+	}
+
+	actual := &paramCookiePreservative{}
+	_, err := actual.unmarshal(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, parsed, actual)
+	b, err := actual.marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, binary, b)
+
+	p, err := buildParam(cookiePreservative, binary)
+	assert.NoError(t, err)
+	assert.Equal(t, parsed, p)
+
+	_, err = (&paramCookiePreservative{}).unmarshal([]byte{0x00, 0x09, 0x00, 0x04})
+	assert.ErrorIs(t, err, ErrCookiePreservativeParamTooShort)
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+//  The IPv4 and IPv6 Address parameters list a transport address of the
+//  sender of the INIT or INIT ACK.
+//
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |          Type = 5             |          Length = 8           |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                         IPv4 Address                          |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |            Type = 6           |          Length = 20          |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                                                               |
+// |                         IPv6 Address                          |
+// |                                                               |
+// |                                                               |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+type paramIPAddress struct {
+	paramHeader
+	address net.IP
+}
+
+// IP Address parameter errors.
+var (
+	ErrIPAddressParamInvalidLength = errors.New("IP address parameter has an invalid length")
+)
+
+func (p *paramIPAddress) marshal() ([]byte, error) {
+	if ip := p.address.To4(); ip != nil {
+		p.typ = ipV4Addr
+		p.raw = ip
+	} else {
+		p.typ = ipV6Addr
+		p.raw = p.address.To16()
+	}
+	if p.raw == nil {
+		return nil, ErrIPAddressParamInvalidLength
+	}
+
+	return p.paramHeader.marshal()
+}
+
+func (p *paramIPAddress) unmarshal(raw []byte) (param, error) {
+	err := p.paramHeader.unmarshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (p.typ == ipV4Addr && len(p.raw) != net.IPv4len) ||
+		(p.typ == ipV6Addr && len(p.raw) != net.IPv6len) {
+		return nil, fmt.Errorf("%w: %s of %d bytes", ErrIPAddressParamInvalidLength, p.typ, len(p.raw))
+	}
+	p.address = append(net.IP{}, p.raw...)
+
+	return p, nil
+}
+
+// String makes paramIPAddress printable.
+func (p *paramIPAddress) String() string {
+	return fmt.Sprintf("%s: %s", p.typ, p.address)
+}
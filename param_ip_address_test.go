@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamIPAddress(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		binary  []byte
+		typ     paramType
+		address net.IP
+	}{
+		{
+			name:    "IPv4",
+			binary:  []byte{0x00, 0x05, 0x00, 0x08, 192, 0, 2, 1},
+			typ:     ipV4Addr,
+			address: net.ParseIP("192.0.2.1"),
+		},
+		{
+			name: "IPv6",
+			binary: []byte{
+				0x00, 0x06, 0x00, 0x14,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+			},
+			typ:     ipV6Addr,
+			address: net.ParseIP("2001:db8::1"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := buildParam(tc.typ, tc.binary)
+			assert.NoError(t, err)
+			parsed, ok := p.(*paramIPAddress)
+			assert.True(t, ok)
+			assert.Equal(t, tc.typ, parsed.typ)
+			assert.True(t, tc.address.Equal(parsed.address))
+
+			b, err := (&paramIPAddress{address: tc.address}).marshal()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.binary, b)
+		})
+	}
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := (&paramIPAddress{}).unmarshal([]byte{0x00, 0x05, 0x00, 0x0c, 192, 0, 2, 1, 0, 0, 0, 0})
+		assert.ErrorIs(t, err, ErrIPAddressParamInvalidLength)
+		_, err = (&paramIPAddress{}).unmarshal([]byte{0x00, 0x06, 0x00, 0x08, 192, 0, 2, 1})
+		assert.ErrorIs(t, err, ErrIPAddressParamInvalidLength)
+		_, err = (&paramIPAddress{}).marshal()
+		assert.ErrorIs(t, err, ErrIPAddressParamInvalidLength)
+	})
+}
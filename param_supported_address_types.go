@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+//  The sender of the INIT uses this parameter to list all the address
+//  types it can support.
+//
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |          Type = 12            |          Length               |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |        Address Type #1        |        Address Type #2        |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                            ......                             |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+type paramSupportedAddressTypes struct {
+	paramHeader
+	addressTypes []paramType
+}
+
+// Supported Address Types parameter errors.
+var (
+	ErrSupportedAddressTypesParamInvalidLength = errors.New("supported address types parameter has an invalid length")
+)
+
+func (s *paramSupportedAddressTypes) marshal() ([]byte, error) {
+	s.typ = supportedAddrTypes
+	s.raw = make([]byte, 2*len(s.addressTypes))
+	for i, t := range s.addressTypes {
+		binary.BigEndian.PutUint16(s.raw[2*i:], uint16(t))
+	}
+
+	return s.paramHeader.marshal()
+}
+
+func (s *paramSupportedAddressTypes) unmarshal(raw []byte) (param, error) {
+	err := s.paramHeader.unmarshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.raw)%2 != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrSupportedAddressTypesParamInvalidLength, len(s.raw))
+	}
+	for i := 0; i < len(s.raw); i += 2 {
+		s.addressTypes = append(s.addressTypes, paramType(binary.BigEndian.Uint16(s.raw[i:])))
+	}
+
+	return s, nil
+}
+
+// String makes paramSupportedAddressTypes printable.
+func (s *paramSupportedAddressTypes) String() string {
+	return fmt.Sprintf("%s: %v", s.typ, s.addressTypes)
+}
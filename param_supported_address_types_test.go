@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamSupportedAddressTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		binary       []byte
+		addressTypes []paramType
+	}{
+		{
+			name:         "IPv4",
+			binary:       []byte{0x00, 0x0c, 0x00, 0x06, 0x00, 0x05},
+			addressTypes: []paramType{ipV4Addr},
+		},
+		{
+			name:         "IPv4 and IPv6",
+			binary:       []byte{0x00, 0x0c, 0x00, 0x08, 0x00, 0x05, 0x00, 0x06},
+			addressTypes: []paramType{ipV4Addr, ipV6Addr},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := buildParam(supportedAddrTypes, tc.binary)
+			assert.NoError(t, err)
+			parsed, ok := p.(*paramSupportedAddressTypes)
+			assert.True(t, ok)
+			assert.Equal(t, tc.addressTypes, parsed.addressTypes)
+
+			b, err := (&paramSupportedAddressTypes{addressTypes: tc.addressTypes}).marshal()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.binary, b)
+		})
+	}
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := (&paramSupportedAddressTypes{}).unmarshal([]byte{0x00, 0x0c, 0x00, 0x07, 0x00, 0x05, 0x00})
+		assert.ErrorIs(t, err, ErrSupportedAddressTypesParamInvalidLength)
+	})
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+//  This parameter is returned to the originator of the INIT chunk when the
+//  INIT contains an unrecognized parameter that has a type that indicates
+//  it SHOULD be reported to the sender.
+//
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |          Type = 8             |       Parameter Length        |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// \                                                               \
+// /                   Unrecognized Parameter                      /
+// \                                                               \
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+type paramUnrecognized struct {
+	paramHeader
+	// unrecognizedParam is the unrecognized parameter, complete with its
+	// Type, Length, and Value fields.
+	unrecognizedParam []byte
+}
+
+func (u *paramUnrecognized) marshal() ([]byte, error) {
+	u.typ = unrecognizedParam
+	u.raw = u.unrecognizedParam
+
+	return u.paramHeader.marshal()
+}
+
+func (u *paramUnrecognized) unmarshal(raw []byte) (param, error) {
+	err := u.paramHeader.unmarshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	u.unrecognizedParam = u.raw
+
+	return u, nil
+}
+
+// String makes paramUnrecognized printable.
+func (u *paramUnrecognized) String() string {
+	return u.paramHeader.String()
+}
@@ -32,8 +32,13 @@ var (
 	ErrZeroChecksumParamTooShort = errors.New("zero checksum parameter too short")
 )
 
+// Error Detection Method Identifiers, see RFC 9653 sec 9.2.
 const (
-	dtlsErrorDetectionMethod uint32 = 1
+	// ZeroChecksumEDMIDDTLS is the identifier for SCTP over DTLS (RFC 8261).
+	ZeroChecksumEDMIDDTLS uint32 = 1
+
+	// zeroChecksumEDMIDReserved is reserved and never a valid identifier.
+	zeroChecksumEDMIDReserved uint32 = 0
 )
 
 func (r *paramZeroChecksumAcceptable) marshal() ([]byte, error) {
@@ -97,6 +97,18 @@ func (p *paramHeader) unmarshal(raw []byte) error {
 	return nil
 }
 
+// stopOnUnrecognized reports whether the processing of the parameters of a
+// chunk stops at this parameter when its type is not recognized.
+func (p *paramHeader) stopOnUnrecognized() bool {
+	return p.unrecognizedAction&paramHeaderUnrecognizedActionSkip == 0
+}
+
+// reportUnrecognized reports whether this parameter is reported to the
+// sender of the chunk when its type is not recognized.
+func (p *paramHeader) reportUnrecognized() bool {
+	return p.unrecognizedAction&paramHeaderUnrecognizedActionStopAndReport != 0
+}
+
 func (p *paramHeader) length() int {
 	return p.len
 }
@@ -6,6 +6,7 @@ package sctp
 type payloadQueue struct {
 	chunks *queue[*chunkPayloadData]
 	nBytes int
+	nAcked int // chunks gap-acked but not cumulatively acked yet
 }
 
 func newPayloadQueue() *payloadQueue {
@@ -21,7 +22,11 @@ func (q *payloadQueue) pushNoCheck(p *chunkPayloadData) {
 func (q *payloadQueue) pop(tsn uint32) (*chunkPayloadData, bool) {
 	if q.chunks.Len() > 0 && tsn == q.chunks.Front().tsn {
 		c := q.chunks.PopFront()
-		q.nBytes -= len(c.userData)
+		if c.acked {
+			q.nAcked--
+		} else {
+			q.nBytes -= len(c.userData)
+		}
 
 		return c, true
 	}
@@ -43,19 +48,62 @@ func (q *payloadQueue) get(tsn uint32) (*chunkPayloadData, bool) {
 	return q.chunks.At(int(tsn - head)), true
 }
 
+// markAsAcked marks the chunk as gap-acked, and returns its size.
+//
+// The payload of a gap-acked chunk is deliberately kept until it is
+// cumulatively acked: RFC 9260 section 6.2 lets the receiver renege on Gap
+// Ack Blocks, in which case the chunk has to be retransmitted.
 func (q *payloadQueue) markAsAcked(tsn uint32) int {
 	var nBytesAcked int
-	if c, ok := q.get(tsn); ok {
+	if c, ok := q.get(tsn); ok && !c.acked {
 		c.acked = true
 		c.retransmit = false
 		nBytesAcked = len(c.userData)
 		q.nBytes -= nBytesAcked
-		c.userData = []byte{}
+		q.nAcked++
 	}
 
 	return nBytesAcked
 }
 
+// markAsReneged reverts markAsAcked on every acked chunk that is neither
+// covered by cumTSNAck nor by one of gapAckBlocks, so that it is counted as
+// outstanding and retransmitted again. gapAckBlocks must be sorted and not
+// overlap. It returns the reneged chunks.
+func (q *payloadQueue) markAsReneged(cumTSNAck uint32, gapAckBlocks []gapAckBlock) []*chunkPayloadData {
+	var reneged []*chunkPayloadData
+	block := 0
+	// the walk ends at the last acked chunk, so it is free without any
+	for i, nAcked := 0, q.nAcked; i < q.chunks.Len() && nAcked > 0; i++ {
+		c := q.chunks.At(i)
+		if !c.acked {
+			continue
+		}
+		nAcked--
+		if sna32LTE(c.tsn, cumTSNAck) {
+			continue
+		}
+		offset := c.tsn - cumTSNAck
+		for block < len(gapAckBlocks) && uint32(gapAckBlocks[block].end) < offset {
+			block++
+		}
+		if block < len(gapAckBlocks) && uint32(gapAckBlocks[block].start) <= offset {
+			continue
+		}
+		reneged = append(reneged, c)
+	}
+
+	for _, c := range reneged {
+		c.acked = false
+		c.reneged = true
+		c.missIndicator = 0
+		q.nBytes += len(c.userData)
+	}
+	q.nAcked -= len(reneged)
+
+	return reneged
+}
+
 func (q *payloadQueue) markAllToRetrasmit() {
 	for i := 0; i < q.chunks.Len(); i++ {
 		c := q.chunks.At(i)
@@ -66,6 +114,17 @@ func (q *payloadQueue) markAllToRetrasmit() {
 	}
 }
 
+// unmarkRetransmitBefore cancels the retransmission of the chunks last sent
+// before the given T3-rtx timeout generation.
+func (q *payloadQueue) unmarkRetransmitBefore(rtoGeneration uint32) {
+	for i := 0; i < q.chunks.Len(); i++ {
+		c := q.chunks.At(i)
+		if c.retransmit && c.rtoGeneration != rtoGeneration {
+			c.retransmit = false
+		}
+	}
+}
+
 func (q *payloadQueue) getNumBytes() int {
 	return q.nBytes
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"sort"
 	"sync/atomic"
+	"time"
 )
 
 func sortChunksByTSN(a []*chunkPayloadData) {
@@ -659,6 +660,86 @@ func (r *reassemblyQueue) forwardTSNForUnorderedMID(lastMID uint32) {
 	}
 }
 
+// discardIncomplete removes the incomplete messages with a fragment received
+// before cutoff, and returns the number of messages and bytes removed.
+// Ordered messages queued after them can then be read. An ordered message is
+// only removed once it is the next one to deliver: removing it earlier would
+// leave nothing to deliver at its SSN or MID, and stall the stream.
+func (r *reassemblyQueue) discardIncomplete(cutoff time.Time) (nMessages, nBytes int) { //nolint:cyclop
+	expired := func(chunks []*chunkPayloadData) bool {
+		for _, c := range chunks {
+			if c.arrived.Before(cutoff) {
+				return true
+			}
+		}
+
+		return false
+	}
+	discard := func(chunks []*chunkPayloadData) {
+		for _, c := range chunks {
+			nBytes += len(c.userData)
+			r.subtractNumBytes(len(c.userData))
+		}
+		nMessages++
+	}
+
+	// The sets in r.ordered are sorted by SSN, so skipping the discarded
+	// ones in turn resumes delivery at the first kept one.
+	keep := []*chunkSet{}
+	for _, set := range r.ordered {
+		if set.ssn == r.nextSSN && !set.isComplete() && expired(set.chunks) {
+			discard(set.chunks)
+			r.nextSSN++
+
+			continue
+		}
+		keep = append(keep, set)
+	}
+	r.ordered = keep
+
+	// r.unorderedChunks only holds fragments of incomplete messages, sorted
+	// by TSN. A message starts at a beginning fragment or a TSN gap.
+	keepChunks := []*chunkPayloadData{}
+	var last *chunkPayloadData // previous chunk, if discarded
+	for _, c := range r.unorderedChunks {
+		if !c.arrived.Before(cutoff) {
+			keepChunks = append(keepChunks, c)
+			last = nil
+
+			continue
+		}
+		nBytes += len(c.userData)
+		r.subtractNumBytes(len(c.userData))
+		if last == nil || c.beginningFragment || c.tsn != last.tsn+1 {
+			nMessages++
+		}
+		last = c
+	}
+	r.unorderedChunks = keepChunks
+
+	keepMID := []*chunkSetMID{}
+	for _, set := range r.orderedMID {
+		if set.mid == r.nextMID && !set.isComplete() && expired(set.chunks) {
+			discard(set.chunks)
+			delete(r.orderedMIDMap, set.mid)
+			r.nextMID++
+
+			continue
+		}
+		keepMID = append(keepMID, set)
+	}
+	r.orderedMID = keepMID
+
+	for mid, set := range r.unorderedMIDMap {
+		if expired(set.chunks) {
+			discard(set.chunks)
+			delete(r.unorderedMIDMap, mid)
+		}
+	}
+
+	return nMessages, nBytes
+}
+
 func (r *reassemblyQueue) subtractNumBytes(nBytes int) {
 	cur := atomic.LoadUint64(&r.nBytes)
 	if int(cur) >= nBytes { //nolint:gosec // G115
@@ -668,6 +749,26 @@ func (r *reassemblyQueue) subtractNumBytes(nBytes int) {
 	}
 }
 
+// hasIncomplete reports whether the queue holds fragments of messages not
+// fully received yet.
+func (r *reassemblyQueue) hasIncomplete() bool {
+	if len(r.unorderedChunks) > 0 || len(r.unorderedMIDMap) > 0 {
+		return true
+	}
+	for _, set := range r.ordered {
+		if !set.isComplete() {
+			return true
+		}
+	}
+	for _, set := range r.orderedMID {
+		if !set.isComplete() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (r *reassemblyQueue) getNumBytes() int {
 	return int(atomic.LoadUint64(&r.nBytes)) //nolint:gosec // G115
 }
@@ -6,6 +6,7 @@ package sctp
 import (
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -1130,6 +1131,162 @@ func TestReassemblyQueue(t *testing.T) { //nolint:maintidx
 		assert.Len(t, rq.unorderedMID, maxReassemblyQueueMIDEntries)
 		assert.Equal(t, 0, rq.getNumBytes(), "zero-byte messages must not hide descriptor growth")
 	})
+
+	t.Run("discard incomplete ordered", func(t *testing.T) {
+		rq := newReassemblyQueue(0)
+		old := time.Now().Add(-time.Minute)
+		now := time.Now()
+
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, tsn: 1, streamSequenceNumber: 0,
+			userData: []byte("ABC"), arrived: old,
+		})
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, endingFragment: true, tsn: 3, streamSequenceNumber: 1,
+			userData: []byte("DE"), arrived: now,
+		})
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, tsn: 4, streamSequenceNumber: 2,
+			userData: []byte("FGHI"), arrived: now,
+		})
+		assert.False(t, rq.isReadable())
+
+		nMessages, nBytes := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Equal(t, 1, nMessages)
+		assert.Equal(t, 3, nBytes)
+		assert.Equal(t, 6, rq.getNumBytes(), "recent messages should be kept")
+		assert.True(t, rq.isReadable(), "the next message should be readable")
+
+		buf := make([]byte, 16)
+		n, _, err := rq.read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "DE", string(buf[:n]))
+	})
+
+	t.Run("discard incomplete ordered not next", func(t *testing.T) {
+		rq := newReassemblyQueue(0)
+		old := time.Now().Add(-time.Minute)
+		now := time.Now()
+
+		// SSN 0 is still missing when SSN 1 expires
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, tsn: 2, streamSequenceNumber: 1,
+			userData: []byte("ABC"), arrived: old,
+		})
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, endingFragment: true, tsn: 4, streamSequenceNumber: 2,
+			userData: []byte("DE"), arrived: now,
+		})
+
+		nMessages, nBytes := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Zero(t, nMessages, "SSN 1 is not the next message yet")
+		assert.Zero(t, nBytes)
+
+		rq.push(&chunkPayloadData{
+			beginningFragment: true, endingFragment: true, tsn: 1, streamSequenceNumber: 0,
+			userData: []byte("Z"), arrived: now,
+		})
+		buf := make([]byte, 16)
+		n, _, err := rq.read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "Z", string(buf[:n]))
+		assert.False(t, rq.isReadable())
+
+		nMessages, nBytes = rq.discardIncomplete(now.Add(-time.Second))
+		assert.Equal(t, 1, nMessages)
+		assert.Equal(t, 3, nBytes)
+		assert.True(t, rq.isReadable(), "delivery should resume after SSN 1")
+		n, _, err = rq.read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "DE", string(buf[:n]))
+	})
+
+	t.Run("discard incomplete I-DATA not next", func(t *testing.T) {
+		rq := newReassemblyQueue(0)
+		old := time.Now().Add(-time.Minute)
+		now := time.Now()
+
+		// MID 0 is still missing when MID 1 expires
+		for _, c := range []*chunkPayloadData{
+			{iData: true, messageIdentifier: 1, beginningFragment: true, tsn: 2, userData: []byte("AB"), arrived: old},
+			{
+				iData: true, messageIdentifier: 2, beginningFragment: true, endingFragment: true,
+				tsn: 4, userData: []byte("C"), arrived: now,
+			},
+		} {
+			_, err := rq.pushWithError(c)
+			assert.NoError(t, err)
+		}
+
+		nMessages, _ := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Zero(t, nMessages, "MID 1 is not the next message yet")
+
+		_, err := rq.pushWithError(&chunkPayloadData{
+			iData: true, messageIdentifier: 0, beginningFragment: true, endingFragment: true,
+			tsn: 1, userData: []byte("Z"), arrived: now,
+		})
+		assert.NoError(t, err)
+		buf := make([]byte, 16)
+		n, _, err := rq.read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "Z", string(buf[:n]))
+
+		nMessages, nBytes := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Equal(t, 1, nMessages)
+		assert.Equal(t, 2, nBytes)
+		n, _, err = rq.read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "C", string(buf[:n]))
+	})
+
+	t.Run("discard incomplete unordered", func(t *testing.T) {
+		rq := newReassemblyQueue(0)
+		old := time.Now().Add(-time.Minute)
+		now := time.Now()
+
+		for _, c := range []*chunkPayloadData{
+			{unordered: true, beginningFragment: true, tsn: 1, userData: []byte("A"), arrived: old},
+			{unordered: true, tsn: 2, userData: []byte("B"), arrived: old},
+			{unordered: true, beginningFragment: true, tsn: 5, userData: []byte("C"), arrived: old},
+			{unordered: true, beginningFragment: true, tsn: 8, userData: []byte("D"), arrived: now},
+		} {
+			assert.False(t, rq.push(c))
+		}
+
+		nMessages, nBytes := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Equal(t, 2, nMessages)
+		assert.Equal(t, 3, nBytes)
+		assert.Equal(t, 1, rq.getNumBytes())
+		assert.Len(t, rq.unorderedChunks, 1)
+	})
+
+	t.Run("discard incomplete I-DATA", func(t *testing.T) {
+		rq := newReassemblyQueue(0)
+		old := time.Now().Add(-time.Minute)
+		now := time.Now()
+
+		for _, c := range []*chunkPayloadData{
+			{iData: true, messageIdentifier: 0, beginningFragment: true, tsn: 1, userData: []byte("AB"), arrived: old},
+			{
+				iData: true, messageIdentifier: 1, beginningFragment: true, endingFragment: true,
+				tsn: 3, userData: []byte("C"), arrived: now,
+			},
+			{
+				iData: true, unordered: true, messageIdentifier: 0, beginningFragment: true,
+				tsn: 4, userData: []byte("DEF"), arrived: old,
+			},
+		} {
+			_, err := rq.pushWithError(c)
+			assert.NoError(t, err)
+		}
+
+		nMessages, nBytes := rq.discardIncomplete(now.Add(-time.Second))
+		assert.Equal(t, 2, nMessages)
+		assert.Equal(t, 5, nBytes)
+		assert.Empty(t, rq.unorderedMIDMap)
+		assert.Equal(t, uint32(1), rq.nextMID)
+		assert.True(t, rq.isReadable())
+	})
 }
 
 func TestChunkSet(t *testing.T) {
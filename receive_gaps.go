@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// GapBlock is a range of consecutive TSNs received beyond the cumulative
+// TSN. Unlike GapAckBlock, Start and End are absolute TSNs, both inclusive.
+type GapBlock struct {
+	Start uint32
+	End   uint32
+}
+
+// ReceiveGaps returns a snapshot of the receive queue: the cumulative TSN,
+// up to which all DATA was received, and the blocks of DATA received beyond
+// it. The TSNs between the blocks are missing. These are the values the
+// next SACK would report; calling it has no effect on SACK generation.
+func (a *Association) ReceiveGaps() (cumulativeTSN uint32, gaps []GapBlock) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	cumulativeTSN = a.payloadQueue.getcumulativeTSN()
+	for _, b := range a.payloadQueue.getGapAckBlocks() {
+		gaps = append(gaps, GapBlock{
+			Start: cumulativeTSN + uint32(b.start),
+			End:   cumulativeTSN + uint32(b.end),
+		})
+	}
+
+	return cumulativeTSN, gaps
+}
@@ -28,6 +28,28 @@ func newReceivePayloadQueue(maxTSNOffset uint32) *receivePayloadQueue {
 	}
 }
 
+// grow enlarges the window of TSNs above the cumulative TSN that can be
+// queued. The window is never shrunk.
+func (q *receivePayloadQueue) grow(offset uint32) {
+	offset = ((offset + 63) / 64) * 64
+	if offset <= q.maxTSNOffset {
+		return
+	}
+
+	tsnBitmask := make([]uint64, offset/64)
+	if q.chunkSize > 0 {
+		for tsn := q.cumulativeTSN + 1; sna32LTE(tsn, q.tailTSN); tsn++ {
+			if q.hasChunk(tsn) {
+				index, bit := int(tsn/64)%len(tsnBitmask), tsn%64
+				tsnBitmask[index] |= (1 << bit)
+			}
+		}
+	}
+
+	q.tsnBitmask = tsnBitmask
+	q.maxTSNOffset = offset
+}
+
 func (q *receivePayloadQueue) init(cumulativeTSN uint32) {
 	q.cumulativeTSN = cumulativeTSN
 	q.tailTSN = cumulativeTSN
@@ -57,6 +79,11 @@ func (q *receivePayloadQueue) canPush(tsn uint32) bool {
 	return true
 }
 
+// isDuplicate reports whether the TSN was already received.
+func (q *receivePayloadQueue) isDuplicate(tsn uint32) bool {
+	return sna32LTE(tsn, q.cumulativeTSN) || q.hasChunk(tsn)
+}
+
 // push pushes a payload data. If the payload data is already in our queue or
 // older than our cumulativeTSN marker, it will be recored as duplications,
 // which can later be retrieved using popDuplicates.
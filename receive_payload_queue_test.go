@@ -37,6 +37,10 @@ func TestReceivePayloadQueue(t *testing.T) {
 	assert.False(t, payloadQueue.canPush(initTSN-1))
 	assert.False(t, payloadQueue.canPush(initTSN+maxOffset))
 	assert.False(t, payloadQueue.push(initTSN+maxOffset))
+	assert.True(t, payloadQueue.isDuplicate(initTSN-1))
+	assert.True(t, payloadQueue.isDuplicate(initTSN))
+	assert.False(t, payloadQueue.isDuplicate(initTSN+1))
+	assert.False(t, payloadQueue.isDuplicate(initTSN+maxOffset))
 	assert.True(t, payloadQueue.canPush(nextTSN-1))
 	assert.Equal(t, 2, payloadQueue.size())
 
@@ -186,6 +190,30 @@ func TestReceivePayloadQueueAdvanceCumulativeTSN(t *testing.T) {
 	assert.Equal(t, initTSN, ambiguousPayloadQueue.getcumulativeTSN())
 }
 
+func TestReceivePayloadQueueGrow(t *testing.T) {
+	payloadQueue := newReceivePayloadQueue(128)
+	initTSN := uint32(math.MaxUint32 - 10)
+	payloadQueue.init(initTSN)
+
+	assert.True(t, payloadQueue.push(initTSN+3))
+	assert.True(t, payloadQueue.push(initTSN+100))
+	assert.False(t, payloadQueue.canPush(initTSN+500))
+
+	payloadQueue.grow(64)
+	assert.Equal(t, uint32(128), payloadQueue.maxTSNOffset, "the window should never shrink")
+
+	payloadQueue.grow(1000)
+	assert.Equal(t, uint32(1024), payloadQueue.maxTSNOffset)
+	assert.True(t, payloadQueue.hasChunk(initTSN+3))
+	assert.True(t, payloadQueue.hasChunk(initTSN+100))
+	assert.False(t, payloadQueue.hasChunk(initTSN+4))
+	assert.Equal(t, 2, payloadQueue.size())
+
+	assert.True(t, payloadQueue.push(initTSN+500))
+	assert.EqualValues(t, []gapAckBlock{{start: 3, end: 3}, {start: 100, end: 100}, {start: 500, end: 500}},
+		payloadQueue.getGapAckBlocks())
+}
+
 func TestBitfunc(t *testing.T) {
 	idx, ok := getFirstNonZeroBit(0xf, 0, 20)
 	assert.True(t, ok)
@@ -31,6 +31,9 @@ const (
 	// Path.Max.Retrans.
 	pathMaxRetrans uint = 5
 
+	// Association.Max.Retrans.
+	assocMaxRetrans uint = 10
+
 	noMaxRetrans uint = 0
 )
 
@@ -130,11 +133,13 @@ const (
 
 // rtxTimer provides the retnransmission timer conforms with RFC 4960 Sec 6.3.1.
 type rtxTimer struct {
-	timer      *time.Timer
+	timer      ClockTimer
 	observer   rtxTimerObserver
 	id         int
 	maxRetrans uint
 	rtoMax     float64
+	jitter     float64 // fraction of the RTO randomly added or subtracted on start
+	jitterMin  float64 // floor of the jittered RTO, RTO.Min by default
 	mutex      sync.Mutex
 	rto        float64
 	nRtos      uint
@@ -147,17 +152,25 @@ type rtxTimer struct {
 // (it will never make onRetransmissionFailure() callback.
 func newRTXTimer(id int, observer rtxTimerObserver, maxRetrans uint,
 	rtoMax float64,
+) *rtxTimer {
+	return newRTXTimerWithClock(systemClock{}, id, observer, maxRetrans, rtoMax)
+}
+
+// newRTXTimerWithClock creates a new retransmission timer scheduled by clock.
+func newRTXTimerWithClock(clock Clock, id int, observer rtxTimerObserver, maxRetrans uint,
+	rtoMax float64,
 ) *rtxTimer {
 	timer := rtxTimer{
 		id:         id,
 		observer:   observer,
 		maxRetrans: maxRetrans,
 		rtoMax:     rtoMax,
+		jitterMin:  rtoMin,
 	}
 	if timer.rtoMax == 0 {
 		timer.rtoMax = defaultRTOMax
 	}
-	timer.timer = time.AfterFunc(math.MaxInt64, timer.timeout)
+	timer.timer = clock.AfterFunc(math.MaxInt64, timer.timeout)
 	timer.timer.Stop()
 
 	return &timer
@@ -199,6 +212,9 @@ func (t *rtxTimer) start(rto float64) bool {
 	// rto generated by rtoManager getRTO() method which caps the
 	// value at RTO.Min or at RTO.Max.
 	t.rto = rto
+	if t.jitter > 0 {
+		t.rto = jitterRTO(rto, t.jitter, t.jitterMin, t.rtoMax)
+	}
 	t.nRtos = 0
 	t.state = rtxTimerStarted
 	t.pending++
@@ -241,6 +257,20 @@ func (t *rtxTimer) isRunning() bool {
 	return t.state == rtxTimerStarted
 }
 
+// rtoJitterResolution is the number of random steps on each side of the RTO.
+const rtoJitterResolution = 1000
+
+// jitterRTO returns rto moved by a random amount within +/- jitter * rto so
+// that timers started with the same RTO expire at different times. The result
+// never drops below minRTO (or below rto itself when it is already lower,
+// as in tests) and never exceeds rtoMax.
+func jitterRTO(rto, jitter, minRTO, rtoMax float64) float64 {
+	step := globalMathRandomGenerator.Intn(2*rtoJitterResolution+1) - rtoJitterResolution
+	jittered := rto * (1 + jitter*float64(step)/rtoJitterResolution)
+
+	return math.Min(math.Max(jittered, math.Min(rto, minRTO)), math.Max(rto, rtoMax))
+}
+
 func calculateNextTimeout(rto float64, nRtos uint, rtoMax float64) float64 {
 	// RFC 4096 sec 6.3.3.  Handle T3-rtx Expiration
 	//   E2)  For the destination address for which the timer expires, set RTO
@@ -382,4 +382,53 @@ func TestRtxTimer(t *testing.T) { //nolint:maintidx
 		time.Sleep(100 * time.Millisecond)
 		assert.Equal(t, 0, rtoCount, "RTO should not occur")
 	})
+
+	t.Run("jitter stays within range and above RTO.Min", func(t *testing.T) {
+		rt := newRTXTimer(7, &testTimerObserver{
+			onRTO:        func(_ int, _ uint) {},
+			onRtxFailure: func(_ int) {},
+		}, pathMaxRetrans, 0)
+		defer rt.close()
+		rt.jitter = 0.5
+
+		var sawBelow, sawAbove bool
+		for i := 0; i < 1000; i++ {
+			assert.True(t, rt.start(4000))
+			rt.mutex.Lock()
+			rto := rt.rto
+			rt.mutex.Unlock()
+			rt.stop()
+
+			assert.GreaterOrEqual(t, rto, 2000.0)
+			assert.LessOrEqual(t, rto, 6000.0)
+			sawBelow = sawBelow || rto < 4000
+			sawAbove = sawAbove || rto > 4000
+		}
+		assert.True(t, sawBelow, "jitter should shorten some timeouts")
+		assert.True(t, sawAbove, "jitter should lengthen some timeouts")
+
+		for i := 0; i < 1000; i++ {
+			assert.True(t, rt.start(rtoMin))
+			rt.mutex.Lock()
+			rto := rt.rto
+			rt.mutex.Unlock()
+			rt.stop()
+
+			assert.GreaterOrEqual(t, rto, rtoMin, "jitter must not go below RTO.Min")
+			assert.LessOrEqual(t, rto, 1.5*rtoMin)
+		}
+
+		// a configured floor above RTO.Min, such as MinT3RTX
+		rt.jitterMin = 3000
+		for i := 0; i < 1000; i++ {
+			assert.True(t, rt.start(3000))
+			rt.mutex.Lock()
+			rto := rt.rto
+			rt.mutex.Unlock()
+			rt.stop()
+
+			assert.GreaterOrEqual(t, rto, 3000.0, "jitter must not go below the configured floor")
+			assert.LessOrEqual(t, rto, 4500.0)
+		}
+	})
 }
@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// SackPolicy selects when SACKs for received DATA are sent.
+type SackPolicy uint8
+
+const (
+	// SackPolicyDelayed delays SACKs as recommended by RFC 9260 section
+	// 6.2: a SACK is sent once the SACK frequency of the Profile is reached
+	// or the delayed-ack timer expires, and right away when a gap is
+	// detected.
+	SackPolicyDelayed SackPolicy = iota
+	// SackPolicyImmediate sends a SACK for every packet carrying DATA.
+	SackPolicyImmediate
+	// SackPolicyBundle delays SACKs like SackPolicyDelayed, but a SACK that
+	// is due or being delayed is sent in the first packet of new DATA
+	// instead of its own packet, when it fits in the MTU.
+	SackPolicyBundle
+)
+
+func (p SackPolicy) String() string {
+	switch p {
+	case SackPolicyDelayed:
+		return "Delayed"
+	case SackPolicyImmediate:
+		return "Immediate"
+	case SackPolicyBundle:
+		return "Bundle"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+// Package sctptest provides helpers to test SCTP associations over impaired
+// networks.
+package sctptest
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// errInvalidLossRate indicates a LossyConn loss rate outside of [0, 1].
+	errInvalidLossRate = errors.New("LossRate must be between 0 and 1")
+
+	// errInvalidLossyConnDelay indicates a negative LossyConn delay or jitter.
+	errInvalidLossyConnDelay = errors.New("Delay and Jitter cannot be negative")
+)
+
+// LossyConnConfig configures the impairments applied by a LossyConn.
+// The zero value passes packets through unchanged.
+type LossyConnConfig struct {
+	// LossRate is the fraction of written packets that are dropped,
+	// from 0 to 1.
+	LossRate float64
+
+	// ReorderWindow is the number of written packets that are held back and
+	// sent in random order. A held packet is only sent when later packets
+	// are written or on Flush.
+	ReorderWindow uint
+
+	// Delay is added to every packet. Jitter adds a random delay between 0
+	// and Jitter on top of it, which also reorders packets sent close
+	// together.
+	Delay  time.Duration
+	Jitter time.Duration
+
+	// Seed seeds the random generator, so that the same sequence of writes
+	// sees the same losses, reordering and delays.
+	Seed uint64
+}
+
+// LossyConn wraps a net.Conn and drops, reorders and delays the packets
+// written to it, to test congestion control and retransmissions. Reads are
+// passed through, so wrap both ends to impair both directions.
+type LossyConn struct {
+	net.Conn
+
+	config LossyConnConfig
+
+	lock   sync.Mutex
+	rng    *rand.Rand
+	held   [][]byte
+	err    error // error of a delayed write, returned by the next Write
+	closed bool
+}
+
+// NewLossyConn creates a LossyConn writing to conn.
+func NewLossyConn(conn net.Conn, config LossyConnConfig) (*LossyConn, error) {
+	if config.LossRate < 0 || config.LossRate > 1 {
+		return nil, errInvalidLossRate
+	}
+	if config.Delay < 0 || config.Jitter < 0 {
+		return nil, errInvalidLossyConnDelay
+	}
+
+	return &LossyConn{
+		Conn:   conn,
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed)), //nolint:gosec // G404, reproducible on purpose
+	}, nil
+}
+
+// Write impairs b as configured. Dropped packets are reported as written.
+func (c *LossyConn) Write(b []byte) (int, error) {
+	c.lock.Lock()
+	if err := c.err; err != nil {
+		c.err = nil
+		c.lock.Unlock()
+
+		return 0, err
+	}
+	if c.config.LossRate > 0 && c.rng.Float64() < c.config.LossRate {
+		c.lock.Unlock()
+
+		return len(b), nil
+	}
+
+	c.held = append(c.held, append([]byte(nil), b...))
+	var out [][]byte
+	for uint(len(c.held)) > c.config.ReorderWindow {
+		i := 0
+		if len(c.held) > 1 {
+			i = c.rng.IntN(len(c.held))
+		}
+		out = append(out, c.held[i])
+		c.held = append(c.held[:i], c.held[i+1:]...)
+	}
+	delays := c.delays(len(out))
+	c.lock.Unlock()
+
+	if err := c.send(out, delays); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// Flush sends the packets held back by the reorder window.
+func (c *LossyConn) Flush() error {
+	c.lock.Lock()
+	out := c.held
+	c.held = nil
+	delays := c.delays(len(out))
+	c.lock.Unlock()
+
+	return c.send(out, delays)
+}
+
+// Close drops the held and delayed packets and closes the wrapped conn.
+func (c *LossyConn) Close() error {
+	c.lock.Lock()
+	c.closed = true
+	c.held = nil
+	c.lock.Unlock()
+
+	return c.Conn.Close()
+}
+
+// delays draws the delay of the next n packets.
+// The caller should hold the lock.
+func (c *LossyConn) delays(n int) []time.Duration {
+	if c.config.Delay == 0 && c.config.Jitter == 0 {
+		return nil
+	}
+
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = c.config.Delay
+		if c.config.Jitter > 0 {
+			delays[i] += time.Duration(c.rng.Int64N(int64(c.config.Jitter)))
+		}
+	}
+
+	return delays
+}
+
+// send writes the packets now, or after their delay if delays is set.
+func (c *LossyConn) send(packets [][]byte, delays []time.Duration) error {
+	for i, packet := range packets {
+		if delays == nil {
+			if _, err := c.Conn.Write(packet); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		time.AfterFunc(delays[i], func() {
+			c.lock.Lock()
+			closed := c.closed
+			c.lock.Unlock()
+			if closed {
+				return
+			}
+
+			if _, err := c.Conn.Write(packet); err != nil {
+				c.lock.Lock()
+				c.err = err
+				c.lock.Unlock()
+			}
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctptest
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingConn is a net.Conn that records the packets written to it.
+type recordingConn struct {
+	net.Conn
+
+	mu      sync.Mutex
+	packets []byte // first byte of each packet
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packets = append(c.packets, b[0])
+
+	return len(b), nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) written() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]byte(nil), c.packets...)
+}
+
+// writeLossy writes n one-byte packets numbered from 0 to a LossyConn and
+// returns what reached the wrapped conn.
+func writeLossy(t *testing.T, config LossyConnConfig, n int) []byte {
+	t.Helper()
+
+	rec := &recordingConn{}
+	conn, err := NewLossyConn(rec, config)
+	require.NoError(t, err)
+
+	for i := range n {
+		written, err := conn.Write([]byte{byte(i)})
+		require.NoError(t, err)
+		assert.Equal(t, 1, written)
+	}
+	require.NoError(t, conn.Flush())
+
+	return rec.written()
+}
+
+func TestLossyConn(t *testing.T) {
+	t.Run("zero config passes through", func(t *testing.T) {
+		assert.Equal(t, []byte{0, 1, 2, 3, 4}, writeLossy(t, LossyConnConfig{}, 5))
+	})
+
+	t.Run("loss", func(t *testing.T) {
+		assert.Empty(t, writeLossy(t, LossyConnConfig{LossRate: 1}, 10))
+
+		config := LossyConnConfig{LossRate: 0.5, Seed: 1}
+		got := writeLossy(t, config, 100)
+		assert.Greater(t, len(got), 20)
+		assert.Less(t, len(got), 80)
+		assert.IsIncreasing(t, got, "loss should not reorder packets")
+		assert.Equal(t, got, writeLossy(t, config, 100), "same seed should drop the same packets")
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		config := LossyConnConfig{ReorderWindow: 3, Seed: 2}
+		got := writeLossy(t, config, 20)
+		assert.ElementsMatch(t, writeLossy(t, LossyConnConfig{}, 20), got, "no packet should be lost")
+		assert.NotEqual(t, writeLossy(t, LossyConnConfig{}, 20), got)
+		assert.Equal(t, got, writeLossy(t, config, 20), "same seed should reorder the same way")
+
+		// the i-th packet sent is picked from the first i+ReorderWindow+1 written
+		for i, b := range got {
+			assert.LessOrEqual(t, int(b), i+3)
+		}
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		rec := &recordingConn{}
+		conn, err := NewLossyConn(rec, LossyConnConfig{Delay: 50 * time.Millisecond, Jitter: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte{7})
+		require.NoError(t, err)
+		assert.Empty(t, rec.written())
+		assert.Eventually(t, func() bool {
+			return len(rec.written()) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("close drops delayed packets", func(t *testing.T) {
+		rec := &recordingConn{}
+		conn, err := NewLossyConn(rec, LossyConnConfig{Delay: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte{7})
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, rec.written())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewLossyConn(&recordingConn{}, LossyConnConfig{LossRate: 1.5})
+		assert.ErrorIs(t, err, errInvalidLossRate)
+		_, err = NewLossyConn(&recordingConn{}, LossyConnConfig{LossRate: -0.1})
+		assert.ErrorIs(t, err, errInvalidLossRate)
+		_, err = NewLossyConn(&recordingConn{}, LossyConnConfig{Jitter: -time.Millisecond})
+		assert.ErrorIs(t, err, errInvalidLossyConnDelay)
+	})
+}
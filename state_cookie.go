@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// defaultCookieLifetime is the RFC 9260 section 16 Valid.Cookie.Life.
+const defaultCookieLifetime = 60 * time.Second
+
+// cookieTrailerLength is the length of the trailer appended to every State
+// Cookie: the creation time, in nanoseconds since the Unix epoch, then the
+// lifetime increment, in milliseconds.
+const cookieTrailerLength = 8 + 4
+
+// CookieInfo is the association state a State Cookie is bound to. It is the
+// same when the cookie is generated for the INIT ACK and when it is validated
+// on COOKIE ECHO, as long as both are for the same INIT.
+type CookieInfo struct {
+	// LocalVerificationTag is the Initiate Tag sent in the INIT ACK.
+	LocalVerificationTag uint32
+	// PeerVerificationTag is the Initiate Tag received in the INIT.
+	PeerVerificationTag uint32
+	SourcePort          uint16
+	DestinationPort     uint16
+
+	// Created is when the cookie was generated. The association carries it
+	// next to the generated cookie to check the cookie lifetime, so it should
+	// be authenticated along with the other fields.
+	Created time.Time
+	// LifeSpanIncrement is the extra cookie lifetime granted to the peer
+	// for a Cookie Preservative in its INIT. It is carried like Created, so
+	// it should be authenticated too.
+	LifeSpanIncrement time.Duration
+}
+
+// CookieGenerator generates the State Cookie of the INIT ACK and validates
+// the cookie echoed back in COOKIE ECHO, as in RFC 9260 section 5.1.3. It can
+// be set in Config.CookieGenerator to use, for example, an HMAC over the
+// CookieInfo, so that a cookie cannot be forged or made to look younger
+// than it is. By default, a random cookie is generated once per association
+// and the echoed cookie must be identical to it, which leaves its creation
+// time and lifetime increment unauthenticated.
+type CookieGenerator interface {
+	// GenerateCookie returns the State Cookie for an INIT. It is called for
+	// every INIT received, including retransmitted ones.
+	GenerateCookie(info CookieInfo) ([]byte, error)
+
+	// ValidateCookie reports whether cookie, received in a COOKIE ECHO, was
+	// generated by GenerateCookie for info. The COOKIE ECHO is discarded if
+	// it is not. The cookie lifetime is checked by the association once the
+	// cookie is valid.
+	ValidateCookie(cookie []byte, info CookieInfo) bool
+}
+
+// cookieInfo returns the state the cookie of the current INIT is bound to.
+// The caller should hold the lock.
+func (a *Association) cookieInfo() CookieInfo {
+	return CookieInfo{
+		LocalVerificationTag: a.myVerificationTag,
+		PeerVerificationTag:  a.peerVerificationTag,
+		SourcePort:           a.sourcePort,
+		DestinationPort:      a.destinationPort,
+	}
+}
+
+// newStateCookie returns the State Cookie of the INIT ACK answering the
+// current INIT, stamped with the current time and the lifetime increment
+// granted for the INIT.
+// The caller should hold the lock.
+func (a *Association) newStateCookie() (*paramStateCookie, error) {
+	created := time.Now()
+
+	var cookie []byte
+	switch {
+	case a.cookieGenerator != nil:
+		info := a.cookieInfo()
+		info.Created = created
+		info.LifeSpanIncrement = a.cookieLifeSpanIncrement
+		var err error
+		if cookie, err = a.cookieGenerator.GenerateCookie(info); err != nil {
+			return nil, err
+		}
+	case a.myCookie != nil:
+		// keep the random part, so that the cookie of an earlier INIT ACK
+		// stays valid when the INIT is retransmitted
+		cookie, _, _, _ = splitCookieTrailer(a.myCookie.cookie)
+	default:
+		// NOTE: This generation process is not compliant with
+		// 5.1.3.  Generating State Cookie (https://www.rfc-editor.org/rfc/rfc4960#section-5.1.3)
+		// unless Config.CookieGenerator is set.
+		random, err := newRandomStateCookie()
+		if err != nil {
+			return nil, err
+		}
+		cookie = random.cookie
+	}
+
+	return &paramStateCookie{cookie: appendCookieTrailer(cookie, created, a.cookieLifeSpanIncrement)}, nil
+}
+
+// validCookie reports whether cookie is the State Cookie of one of our INIT
+// ACKs, and returns its creation time and lifetime increment.
+// The caller should hold the lock.
+func (a *Association) validCookie(cookie []byte) (time.Time, time.Duration, bool) {
+	body, created, increment, ok := splitCookieTrailer(cookie)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+
+	if a.cookieGenerator != nil {
+		info := a.cookieInfo()
+		info.Created = created
+		info.LifeSpanIncrement = increment
+
+		return created, increment, a.cookieGenerator.ValidateCookie(body, info)
+	}
+
+	myBody, _, _, _ := splitCookieTrailer(a.myCookie.cookie)
+
+	return created, increment, bytes.Equal(myBody, body)
+}
+
+// appendCookieTrailer returns a copy of cookie followed by its creation time
+// and lifetime increment.
+func appendCookieTrailer(cookie []byte, created time.Time, increment time.Duration) []byte {
+	stamped := make([]byte, len(cookie), len(cookie)+cookieTrailerLength)
+	copy(stamped, cookie)
+	stamped = binary.BigEndian.AppendUint64(stamped, uint64(created.UnixNano())) //nolint:gosec // G115
+
+	return binary.BigEndian.AppendUint32(stamped, uint32(increment.Milliseconds())) //nolint:gosec // G115
+}
+
+// splitCookieTrailer splits the creation time and lifetime increment off a
+// State Cookie.
+func splitCookieTrailer(cookie []byte) ([]byte, time.Time, time.Duration, bool) {
+	if len(cookie) < cookieTrailerLength {
+		return nil, time.Time{}, 0, false
+	}
+	n := len(cookie) - cookieTrailerLength
+	nsec := binary.BigEndian.Uint64(cookie[n:])
+	msec := binary.BigEndian.Uint32(cookie[n+8:])
+
+	return cookie[:n], time.Unix(0, int64(nsec)), time.Duration(msec) * time.Millisecond, true //nolint:gosec // G115
+}
+
+// staleCookieError returns the ERROR packet answering a COOKIE ECHO whose
+// cookie expired staleness ago (RFC 9260 section 5.1.5, step 3).
+// The caller should hold the lock.
+func (a *Association) staleCookieError(staleness time.Duration) []*packet {
+	usec := uint32(math.MaxUint32)
+	if staleness.Microseconds() < math.MaxUint32 {
+		usec = uint32(staleness.Microseconds()) //nolint:gosec // G115
+	}
+
+	return pack(a.createPacket([]chunk{&chunkError{
+		errorCauses: []errorCause{&errorCauseStaleCookie{staleness: usec}},
+	}}))
+}
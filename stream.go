@@ -4,6 +4,7 @@
 package sctp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -49,13 +50,37 @@ func (ss StreamState) String() string {
 	return "unknown"
 }
 
+// DropPolicy tells what a stream does with messages written while its
+// buffered amount is above BufferedAmountHighThreshold.
+type DropPolicy int
+
+// DropPolicy enums.
+const (
+	// DropPolicyBlock queues every message, blocking writes only if the
+	// association blocks writes. This is the default.
+	DropPolicyBlock DropPolicy = iota
+	// DropPolicyDropOldest drops the oldest unordered messages not sent yet
+	// to make room for the new one. Ordered messages are never dropped, as
+	// the peer would wait for them forever.
+	DropPolicyDropOldest
+	// DropPolicyDropNewest drops the new message instead of queueing it.
+	DropPolicyDropNewest
+)
+
 // SCTP stream errors.
 var (
 	ErrOutboundPacketTooLarge = errors.New("outbound packet larger than maximum message size")
 	ErrStreamClosed           = errors.New("stream closed")
 	ErrReadDeadlineExceeded   = fmt.Errorf("read deadline exceeded: %w", os.ErrDeadlineExceeded)
+	ErrWouldBlock             = errors.New("write would block")
+	ErrStreamReset            = fmt.Errorf("%w: outgoing side reset", ErrStreamClosed)
+	ErrMessageAbandoned       = errors.New("message abandoned before it was acknowledged")
 )
 
+// defaultBufferedAmountHighThreshold is the default amount of buffered
+// outgoing data above which TryWrite refuses new messages.
+const defaultBufferedAmountHighThreshold = 1024 * 1024
+
 // Stream represents an SCTP stream.
 type Stream struct {
 	association         *Association
@@ -63,6 +88,7 @@ type Stream struct {
 	streamIdentifier    uint16
 	defaultPayloadType  PayloadProtocolIdentifier
 	reassemblyQueue     *reassemblyQueue
+	reassemblyTimeout   time.Duration // 0 keeps incomplete messages forever
 	sequenceNumber      uint16
 	nextOrderedMID      uint32
 	nextUnorderedMID    uint32
@@ -76,10 +102,23 @@ type Stream struct {
 	reliabilityValue    uint32
 	bufferedAmount      uint64
 	bufferedAmountLow   uint64
+	bufferedAmountHigh  uint64
 	onBufferedAmountLow func()
+	onReset             func()
+	dropPolicy          DropPolicy
+	onMessageDropped    func(length int)
+	inboundReset        bool          // the peer has reset the incoming side
+	record              *streamRecord // open record of WritePartial, if any
+	outgoingResetDone   chan struct{} // closed when the outgoing reset completes
+	outgoingResetErr    error
 	state               StreamState
+	stats               streamStats
 	log                 logging.LeveledLogger
 	name                string
+
+	// Unordered messages written with DropPolicyDropOldest, oldest first,
+	// accessed with the association lock held
+	droppable [][]*chunkPayloadData
 }
 
 // StreamIdentifier returns the Stream identifier associated to the stream.
@@ -96,6 +135,11 @@ func (s *Stream) SetDefaultPayloadType(defaultPayloadType PayloadProtocolIdentif
 }
 
 // SetReliabilityParams sets reliability parameters for this stream.
+// Streams are reliable (ReliabilityTypeReliable) by default. With
+// ReliabilityTypeRexmit a message is abandoned once it has been sent relVal
+// times, and with ReliabilityTypeTimed once relVal milliseconds have passed
+// since it was first sent. The parameters apply to messages written
+// afterwards: messages already written keep those they were written with.
 func (s *Stream) SetReliabilityParams(unordered bool, relType byte, relVal uint32) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -103,6 +147,17 @@ func (s *Stream) SetReliabilityParams(unordered bool, relType byte, relVal uint3
 	s.setReliabilityParams(unordered, relType, relVal)
 }
 
+// SetUnordered sets whether messages are sent unordered, like the
+// unordered parameter of SetReliabilityParams, leaving the partial
+// reliability parameters unchanged. Data Channel Establishment Protocol
+// messages are always sent ordered.
+func (s *Stream) SetUnordered(unordered bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.unordered = unordered
+}
+
 // setReliabilityParams sets reliability parameters for this stream.
 // The caller should hold the lock.
 func (s *Stream) setReliabilityParams(unordered bool, relType byte, relVal uint32) {
@@ -114,8 +169,9 @@ func (s *Stream) setReliabilityParams(unordered bool, relType byte, relVal uint3
 }
 
 // Read reads a packet of len(p) bytes, dropping the Payload Protocol Identifier.
-// Returns EOF when the stream is reset or an error if the stream is closed
-// otherwise.
+// Messages received before the peer reset the stream are still returned;
+// EOF is returned once they have been read. An error is returned if the
+// stream is closed otherwise.
 func (s *Stream) Read(p []byte) (int, error) {
 	n, _, err := s.ReadSCTP(p)
 
@@ -124,9 +180,13 @@ func (s *Stream) Read(p []byte) (int, error) {
 
 // ReadSCTP reads a packet of len(payload) bytes and returns the associated Payload
 // Protocol Identifier.
-// Returns EOF when the stream is reset or an error if the stream is closed
-// otherwise.
+// Messages received before the peer reset the stream are still returned;
+// EOF is returned once they have been read. An error is returned if the
+// stream is closed otherwise.
 func (s *Stream) ReadSCTP(payload []byte) (int, PayloadProtocolIdentifier, error) {
+	// deferred first so it runs after the stream lock is released.
+	defer s.association.flushReassemblyMemoryChange()
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -139,7 +199,12 @@ func (s *Stream) ReadSCTP(payload []byte) (int, PayloadProtocolIdentifier, error
 	}()
 
 	for {
+		before := s.reassemblyQueue.getNumBytes()
 		n, ppi, err := s.reassemblyQueue.read(payload)
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
+		if err == nil {
+			s.stats.addRead(n)
+		}
 		if err == nil || errors.Is(err, io.ErrShortBuffer) {
 			return n, ppi, err
 		}
@@ -152,6 +217,80 @@ func (s *Stream) ReadSCTP(payload []byte) (int, PayloadProtocolIdentifier, error
 	}
 }
 
+// ReadMessages reads as many complete messages as are queued, up to
+// len(bufs), one message per slot, and returns the number of messages read.
+// Each filled slot is resliced to the length of its message. It blocks until
+// at least one message is available. If the first queued message does not
+// fit in bufs[0], io.ErrShortBuffer is returned and nothing is consumed; a
+// later message that does not fit its slot stops the batch and is left for
+// the next call. EOF and deadline errors are only returned once no messages
+// are left to read, as with ReadSCTP. If ppis is not nil, ppis[i] is set to
+// the Payload Protocol Identifier of the message in bufs[i], and at most
+// len(ppis) messages are read.
+func (s *Stream) ReadMessages(bufs [][]byte, ppis []PayloadProtocolIdentifier) (int, error) {
+	if ppis != nil && len(ppis) < len(bufs) {
+		bufs = bufs[:len(ppis)]
+	}
+	if len(bufs) == 0 {
+		return 0, nil
+	}
+
+	// deferred first so it runs after the stream lock is released.
+	defer s.association.flushReassemblyMemoryChange()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	defer func() {
+		// close readTimeoutCancel if the current read timeout routine is no longer effective
+		if s.readTimeoutCancel != nil && s.readErr != nil {
+			close(s.readTimeoutCancel)
+			s.readTimeoutCancel = nil
+		}
+	}()
+
+	for {
+		n, err := s.readMessagesLocked(bufs, ppis)
+		if n > 0 || errors.Is(err, io.ErrShortBuffer) {
+			return n, err
+		}
+
+		if s.readErr != nil {
+			return 0, s.readErr
+		}
+
+		s.readNotifier.Wait()
+	}
+}
+
+// readMessagesLocked moves complete messages from the reassembly queue into
+// bufs, and their PPIs into ppis if not nil, until the queue runs dry or a
+// message does not fit its slot. The caller must hold s.lock.
+func (s *Stream) readMessagesLocked(bufs [][]byte, ppis []PayloadProtocolIdentifier) (int, error) {
+	before := s.reassemblyQueue.getNumBytes()
+	defer func() {
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
+	}()
+
+	for i := range bufs {
+		n, ppi, err := s.reassemblyQueue.read(bufs[i])
+		if err != nil {
+			if i == 0 {
+				return 0, err
+			}
+
+			return i, nil
+		}
+		s.stats.addRead(n)
+		bufs[i] = bufs[i][:n]
+		if ppis != nil {
+			ppis[i] = ppi
+		}
+	}
+
+	return len(bufs), nil
+}
+
 // SetReadDeadline sets the read deadline in an identical way to net.Conn.
 func (s *Stream) SetReadDeadline(deadline time.Time) error {
 	s.lock.Lock()
@@ -205,10 +344,17 @@ func (s *Stream) handleData(pd *chunkPayloadData) error {
 	defer s.lock.Unlock()
 
 	var readable bool
+	before := s.reassemblyQueue.getNumBytes()
+	if s.reassemblyTimeout > 0 {
+		pd.arrived = time.Now()
+		s.discardIncompleteLocked(pd.arrived.Add(-s.reassemblyTimeout))
+	}
 	complete, err := s.reassemblyQueue.pushWithError(pd)
+	s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
 	if err != nil {
 		return err
 	}
+	s.stats.addReceived(len(pd.userData))
 	if complete {
 		readable = s.reassemblyQueue.isReadable()
 		s.log.Debugf("[%s] reassemblyQueue readable=%v", s.name, readable)
@@ -222,6 +368,37 @@ func (s *Stream) handleData(pd *chunkPayloadData) error {
 	return nil
 }
 
+// discardIncomplete discards the messages that timed out in the reassembly
+// queue, like discardIncompleteLocked, and reports whether fragments of
+// incomplete messages are left.
+func (s *Stream) discardIncomplete(cutoff time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	before := s.reassemblyQueue.getNumBytes()
+	s.discardIncompleteLocked(cutoff)
+	s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
+
+	return s.reassemblyQueue.hasIncomplete()
+}
+
+// discardIncompleteLocked discards the messages that timed out in the
+// reassembly queue, waking up the reader if one they held back can now be
+// read. The caller should hold s.lock.
+func (s *Stream) discardIncompleteLocked(cutoff time.Time) {
+	nMessages, nBytes := s.reassemblyQueue.discardIncomplete(cutoff)
+	if nMessages == 0 {
+		return
+	}
+
+	s.stats.addReassemblyTimeouts(nMessages, nBytes)
+	s.log.Debugf("[%s] discarded %d incomplete messages (%d bytes) after the reassembly timeout",
+		s.name, nMessages, nBytes)
+	if s.reassemblyQueue.isReadable() {
+		s.readNotifier.Signal()
+	}
+}
+
 func (s *Stream) handleForwardTSNForOrdered(ssn uint16) {
 	var readable bool
 
@@ -235,7 +412,9 @@ func (s *Stream) handleForwardTSNForOrdered(ssn uint16) {
 
 		// Remove all chunks older than or equal to the new TSN from
 		// the reassemblyQueue.
+		before := s.reassemblyQueue.getNumBytes()
 		s.reassemblyQueue.forwardTSNForOrdered(ssn)
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
 		readable = s.reassemblyQueue.isReadable()
 	}()
 
@@ -258,7 +437,9 @@ func (s *Stream) handleForwardTSNForUnordered(newCumulativeTSN uint32) {
 
 		// Remove all chunks older than or equal to the new TSN from
 		// the reassemblyQueue.
+		before := s.reassemblyQueue.getNumBytes()
 		s.reassemblyQueue.forwardTSNForUnordered(newCumulativeTSN)
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
 		readable = s.reassemblyQueue.isReadable()
 	}()
 
@@ -275,7 +456,9 @@ func (s *Stream) handleForwardTSNForOrderedMID(mid uint32) {
 		s.lock.Lock()
 		defer s.lock.Unlock()
 
+		before := s.reassemblyQueue.getNumBytes()
 		s.reassemblyQueue.forwardTSNForOrderedMID(mid)
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
 		readable = s.reassemblyQueue.isReadable()
 	}()
 
@@ -291,7 +474,9 @@ func (s *Stream) handleForwardTSNForUnorderedMID(mid uint32) {
 		s.lock.Lock()
 		defer s.lock.Unlock()
 
+		before := s.reassemblyQueue.getNumBytes()
 		s.reassemblyQueue.forwardTSNForUnorderedMID(mid)
+		s.association.addReassemblyMemoryDelta(s.reassemblyQueue.getNumBytes() - before)
 		readable = s.reassemblyQueue.isReadable()
 	}()
 
@@ -307,47 +492,327 @@ func (s *Stream) Write(payload []byte) (n int, err error) {
 	return s.WriteSCTP(payload, ppi)
 }
 
+// WriteContext writes payload as one message like WriteSCTP, but gives up
+// with ctx.Err() once ctx is done, whether or not the association blocks
+// writes: waiting for another write on the stream and for a pending blocking
+// write are both cancelled. The write deadline is not used. A message whose
+// write is cancelled is not sent.
+func (s *Stream) WriteContext(ctx context.Context, payload []byte, ppi PayloadProtocolIdentifier) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	maxMessageSize := s.association.MaxMessageSize()
+	if len(payload) > int(maxMessageSize) {
+		return 0, fmt.Errorf("%w: %v", ErrOutboundPacketTooLarge, maxMessageSize)
+	}
+
+	if err := s.writableErr(); err != nil {
+		return 0, err
+	}
+
+	if err := s.writeMessages(ctx, [][]byte{payload}, WriteOptions{PayloadType: ppi}); err != nil {
+		return 0, err
+	}
+
+	return len(payload), nil
+}
+
 // WriteSCTP writes len(payload) bytes from payload to the DTLS connection.
+// Once Close has reset the outgoing side or the peer has reset the stream, it
+// returns ErrStreamReset, which wraps ErrStreamClosed. Once the association
+// has started shutting down, it returns ErrShuttingDown.
 func (s *Stream) WriteSCTP(payload []byte, ppi PayloadProtocolIdentifier) (int, error) {
+	return s.WriteWithOptions(payload, WriteOptions{PayloadType: ppi})
+}
+
+// WriteOptions are the per-message options of WriteWithOptions.
+type WriteOptions struct {
+	// PayloadType is the Payload Protocol Identifier of the message.
+	PayloadType PayloadProtocolIdentifier
+
+	// Unordered sends the message unordered (U bit), so that the peer
+	// delivers it as soon as it is reassembled, even on an ordered stream.
+	Unordered bool
+
+	// ImmediateSack sets the I bit on the last fragment of the message
+	// (RFC 7053), asking the peer to acknowledge it without delay. This cuts
+	// the latency of the last message of a burst, such as a request waiting
+	// for its response.
+	ImmediateSack bool
+
+	// ackNotify is notified when the message is acknowledged, see
+	// WriteWithAck.
+	ackNotify chan error
+
+	// nonBlocking makes the write fail with ErrWouldBlock instead of
+	// waiting, see TryWrite.
+	nonBlocking bool
+}
+
+// WriteWithOptions writes len(payload) bytes from payload as one message,
+// like WriteSCTP, with the given options.
+func (s *Stream) WriteWithOptions(payload []byte, opts WriteOptions) (int, error) {
 	maxMessageSize := s.association.MaxMessageSize()
 	if len(payload) > int(maxMessageSize) {
 		return 0, fmt.Errorf("%w: %v", ErrOutboundPacketTooLarge, maxMessageSize)
 	}
 
-	if s.State() != StreamStateOpen {
-		return 0, ErrStreamClosed
+	if err := s.writableErr(); err != nil {
+		return 0, err
 	}
 
-	// the send could fail if the association is blocked for writing (timeout), it will left a hole
-	// in the stream sequence number space, so we need to lock the write to avoid concurrent send and decrement
-	// the sequence number in case of failure
-	if s.association.isBlockWrite() {
-		s.writeLock.Lock()
+	if err := s.writeMessages(s.writeContext(), [][]byte{payload}, opts); err != nil {
+		return 0, err
+	}
+
+	return len(payload), nil
+}
+
+// WriteWithAck writes payload as one message like WriteSCTP, and returns a
+// channel that receives nil once the peer has cumulatively acknowledged all
+// of the message, so that it can not be reneged anymore. It receives
+// ErrMessageAbandoned instead if the message was abandoned by partial
+// reliability, or ErrAssociationClosed if the association closes first.
+// The channel receives exactly one value.
+func (s *Stream) WriteWithAck(payload []byte, ppi PayloadProtocolIdentifier) (<-chan error, error) {
+	maxMessageSize := s.association.MaxMessageSize()
+	if len(payload) > int(maxMessageSize) {
+		return nil, fmt.Errorf("%w: %v", ErrOutboundPacketTooLarge, maxMessageSize)
+	}
+
+	if err := s.writableErr(); err != nil {
+		return nil, err
+	}
+
+	acked := make(chan error, 1)
+	opts := WriteOptions{PayloadType: ppi, ackNotify: acked}
+	if err := s.writeMessages(s.writeContext(), [][]byte{payload}, opts); err != nil {
+		return nil, err
+	}
+
+	return acked, nil
+}
+
+// WriteMultiple writes several messages with the same Payload Protocol
+// Identifier, queueing them together so that the association lock is taken
+// and the write loop woken up only once. Each message is still a distinct
+// SCTP message. It returns the number of messages written: messages after
+// one larger than the maximum message size are not written and an error is
+// returned. No message is written if queueing fails.
+func (s *Stream) WriteMultiple(payloads [][]byte, ppi PayloadProtocolIdentifier) (int, error) {
+	maxMessageSize := s.association.MaxMessageSize()
+	accepted := len(payloads)
+	var sizeErr error
+	for i, payload := range payloads {
+		if len(payload) > int(maxMessageSize) {
+			accepted = i
+			sizeErr = fmt.Errorf("%w: message %d: %v", ErrOutboundPacketTooLarge, i, maxMessageSize)
+
+			break
+		}
+	}
+	if accepted == 0 {
+		return 0, sizeErr
+	}
+
+	if err := s.writableErr(); err != nil {
+		return 0, err
+	}
+
+	if err := s.writeMessages(s.writeContext(), payloads[:accepted], WriteOptions{PayloadType: ppi}); err != nil {
+		return 0, err
+	}
+
+	return accepted, sizeErr
+}
+
+// TryWrite writes payload as one message like WriteSCTP, but never blocks.
+// It returns ErrWouldBlock without writing anything when the stream already
+// buffers outgoing data and the message would take the buffered amount
+// above BufferedAmountHighThreshold, or when a blocking write is pending.
+// The caller should then wait, e.g. for OnBufferedAmountLow, and retry.
+func (s *Stream) TryWrite(payload []byte, ppi PayloadProtocolIdentifier) (int, error) {
+	maxMessageSize := s.association.MaxMessageSize()
+	if len(payload) > int(maxMessageSize) {
+		return 0, fmt.Errorf("%w: %v", ErrOutboundPacketTooLarge, maxMessageSize)
+	}
+
+	if err := s.writableErr(); err != nil {
+		return 0, err
+	}
+
+	s.lock.RLock()
+	buffered, high := s.bufferedAmount, s.bufferedAmountHigh
+	s.lock.RUnlock()
+	if buffered > 0 && buffered+uint64(len(payload)) > high {
+		return 0, ErrWouldBlock
+	}
+
+	opts := WriteOptions{PayloadType: ppi, nonBlocking: true}
+	if err := s.writeMessages(context.Background(), [][]byte{payload}, opts); err != nil {
+		return 0, err
+	}
+
+	return len(payload), nil
+}
+
+// writeMessages fragments payloads and queues all of them for sending at
+// once, with the I bit set on the last fragment if opts.ImmediateSack is
+// true. If opts.nonBlocking is true, it returns ErrWouldBlock instead of
+// waiting for another write on the stream or for a pending blocking write;
+// otherwise waiting ends when ctx is done. If queueing fails, the
+// stream sequence numbers are rolled back.
+func (s *Stream) writeMessages(
+	ctx context.Context,
+	payloads [][]byte,
+	opts WriteOptions,
+) error {
+	// the send could fail if the association is blocked for writing (timeout) or ctx is done, it will left
+	// a hole in the stream sequence number space, so we need to lock the write to avoid concurrent send and
+	// decrement the sequence number in case of failure
+	if opts.nonBlocking {
+		if !s.writeLock.TryLock() {
+			return ErrWouldBlock
+		}
+	} else if err := lockContext(ctx, &s.writeLock); err != nil {
+		return err
+	}
+	defer s.writeLock.Unlock()
+	s.lock.RLock()
+	dropPolicy := s.dropPolicy
+	recordOpen := s.record != nil
+	s.lock.RUnlock()
+	if recordOpen {
+		return ErrRecordOpen
 	}
+	if dropPolicy == DropPolicyDropNewest {
+		var dropped []int
+		payloads, dropped = s.dropNewestMessages(payloads)
+		s.messagesDropped(dropped, false)
+		if len(payloads) == 0 && opts.ackNotify != nil {
+			opts.ackNotify <- ErrMessageAbandoned
+			opts.ackNotify = nil
+		}
+	}
+
 	useInterleaving := s.association.useInterleaving
-	chunks, unordered := s.packetize(payload, ppi)
-	n := len(payload)
-	err := s.association.sendPayloadData(s.writeDeadline, chunks)
-	if err != nil { //nolint:nestif
+	var chunks []*chunkPayloadData
+	var droppable [][]*chunkPayloadData
+	var nOrdered, nUnordered int
+	for _, payload := range payloads {
+		msgChunks, unordered := s.packetize(payload, opts)
+		chunks = append(chunks, msgChunks...)
+		if unordered {
+			nUnordered++
+			if len(msgChunks) > 0 {
+				droppable = append(droppable, msgChunks)
+			}
+		} else {
+			nOrdered++
+		}
+	}
+	if opts.ImmediateSack && len(chunks) > 0 {
+		chunks[len(chunks)-1].immediateSack = true
+	}
+	if opts.ackNotify != nil {
+		if len(chunks) == 0 {
+			// nothing to acknowledge
+			opts.ackNotify <- nil
+		} else {
+			chunks[len(chunks)-1].ackNotify = opts.ackNotify
+		}
+	}
+	err := s.association.sendPayloadData(ctx, chunks, opts.nonBlocking)
+	if err != nil {
 		s.lock.Lock()
-		s.bufferedAmount -= uint64(n)
+		for _, payload := range payloads {
+			s.bufferedAmount -= uint64(len(payload))
+		}
 		if useInterleaving {
-			if unordered {
-				s.nextUnorderedMID--
-			} else {
-				s.nextOrderedMID--
-			}
-		} else if !unordered {
-			s.sequenceNumber--
+			s.nextUnorderedMID -= uint32(nUnordered) //nolint:gosec // G115
+			s.nextOrderedMID -= uint32(nOrdered)     //nolint:gosec // G115
+		} else {
+			s.sequenceNumber -= uint16(nOrdered) //nolint:gosec // G115
 		}
 		s.lock.Unlock()
-		n = 0
+
+		return err
+	}
+
+	for _, payload := range payloads {
+		s.stats.addWritten(len(payload))
+	}
+
+	if dropPolicy == DropPolicyDropOldest {
+		s.lock.RLock()
+		var excess uint64
+		if s.bufferedAmount > s.bufferedAmountHigh {
+			excess = s.bufferedAmount - s.bufferedAmountHigh
+		}
+		s.lock.RUnlock()
+		s.messagesDropped(s.association.dropOldestMessages(s, excess, droppable), true)
+	}
+
+	return nil
+}
+
+// dropNewestMessages returns the payloads that fit below
+// BufferedAmountHighThreshold, in the manner of TryWrite, and the lengths
+// of the others.
+func (s *Stream) dropNewestMessages(payloads [][]byte) ([][]byte, []int) {
+	s.lock.RLock()
+	buffered, high := s.bufferedAmount, s.bufferedAmountHigh
+	s.lock.RUnlock()
+
+	var dropped []int
+	kept := payloads[:0:0]
+	for _, payload := range payloads {
+		if buffered > 0 && buffered+uint64(len(payload)) > high {
+			dropped = append(dropped, len(payload))
+
+			continue
+		}
+		buffered += uint64(len(payload))
+		kept = append(kept, payload)
+	}
+
+	return kept, dropped
+}
+
+// messagesDropped counts and reports the messages dropped by the drop
+// policy, and releases their bytes if they were buffered.
+func (s *Stream) messagesDropped(lengths []int, buffered bool) {
+	if len(lengths) == 0 {
+		return
+	}
+
+	s.lock.Lock()
+	for _, length := range lengths {
+		s.stats.addDropped(length)
+		if buffered {
+			s.bufferedAmount -= min(uint64(length), s.bufferedAmount)
+		}
+		s.log.Debugf("[%s] dropped a message of %d bytes", s.name, length)
+	}
+	f := s.onMessageDropped
+	s.lock.Unlock()
+
+	if f != nil {
+		for _, length := range lengths {
+			f(length)
+		}
 	}
+}
+
+// writeContext returns the context of writes without one: the write deadline
+// when writes block, or a context that is never done.
+func (s *Stream) writeContext() context.Context {
 	if s.association.isBlockWrite() {
-		s.writeLock.Unlock()
+		return s.writeDeadline
 	}
 
-	return n, err
+	return context.Background()
 }
 
 // SetWriteDeadline sets the write deadline in an identical way to net.Conn,
@@ -367,17 +832,18 @@ func (s *Stream) SetDeadline(t time.Time) error {
 	return s.SetWriteDeadline(t)
 }
 
-func (s *Stream) packetize(raw []byte, ppi PayloadProtocolIdentifier) ([]*chunkPayloadData, bool) {
+func (s *Stream) packetize(raw []byte, opts WriteOptions) ([]*chunkPayloadData, bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	offset := uint32(0)
 	remaining := uint32(len(raw)) //nolint:gosec // G115
+	ppi := opts.PayloadType
 
 	// From draft-ietf-rtcweb-data-protocol-09, section 6:
 	//   All Data Channel Establishment Protocol messages MUST be sent using
 	//   ordered delivery and reliable transmission.
-	unordered := ppi != PayloadTypeWebRTCDCEP && s.unordered
+	unordered := ppi != PayloadTypeWebRTCDCEP && (s.unordered || opts.Unordered)
 
 	useInterleaving := s.association.useInterleaving
 	var mid uint32
@@ -395,7 +861,7 @@ func (s *Stream) packetize(raw []byte, ppi PayloadProtocolIdentifier) ([]*chunkP
 	var head *chunkPayloadData
 	fsn := uint32(0)
 	for remaining != 0 {
-		fragmentSize := min32(s.association.maxPayloadSize, remaining)
+		fragmentSize := min32(s.association.MaxPayloadSize(), remaining)
 
 		// Copy the userdata since we'll have to store it until acked
 		// and the caller may re-use the buffer in the mean time
@@ -415,6 +881,8 @@ func (s *Stream) packetize(raw []byte, ppi PayloadProtocolIdentifier) ([]*chunkP
 			fragmentSequenceNumber: fsn,
 			iData:                  useInterleaving,
 			head:                   head,
+			reliabilityType:        s.reliabilityType,
+			reliabilityValue:       s.reliabilityValue,
 		}
 
 		if useInterleaving {
@@ -446,16 +914,43 @@ func (s *Stream) packetize(raw []byte, ppi PayloadProtocolIdentifier) ([]*chunkP
 	return chunks, unordered
 }
 
+// writableErr returns ErrStreamReset once Close has reset the outgoing
+// side or the peer has reset the stream, or nil if the stream can be
+// written to.
+func (s *Stream) writableErr() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.state != StreamStateOpen || s.inboundReset {
+		return ErrStreamReset
+	}
+
+	return nil
+}
+
 // Close closes the write-direction of the stream.
 // Future calls to Write are not permitted after calling Close.
+// A record left open by WritePartial is finished first.
+// If the peer rejects the reset, the stream is open again so that Close can
+// be retried; use CloseWithContext to learn the outcome.
 func (s *Stream) Close() error {
-	if sid, resetOutbound := func() (uint16, bool) {
+	s.lock.RLock()
+	hasRecord := s.record != nil
+	s.lock.RUnlock()
+	if hasRecord {
+		if err := s.WriteEnd(); err != nil && !errors.Is(err, ErrNoOpenRecord) {
+			s.log.Warnf("[%s] Close: failed to finish the open record: %v", s.name, err)
+		}
+	}
+
+	if resetOutbound := func() bool {
 		s.lock.Lock()
 		defer s.lock.Unlock()
 
 		s.log.Debugf("[%s] Close: state=%s", s.name, s.state.String())
 
 		if s.state == StreamStateOpen {
+			s.outgoingResetDone = make(chan struct{})
 			if s.readErr == nil {
 				s.state = StreamStateClosing
 			} else {
@@ -463,19 +958,102 @@ func (s *Stream) Close() error {
 			}
 			s.log.Debugf("[%s] state change: open => %s", s.name, s.state.String())
 
-			return s.streamIdentifier, true
+			return true
 		}
 
-		return s.streamIdentifier, false
+		return false
 	}(); resetOutbound {
 		// Reset the outgoing stream
 		// https://tools.ietf.org/html/rfc6525
-		return s.association.sendResetRequest(sid)
+		return s.association.sendResetRequest(s)
 	}
 
 	return nil
 }
 
+// CloseWithContext closes the stream like Close, then waits until the peer
+// confirms the reset of the outgoing side. The stream is then removed from
+// the association, so that its identifier can be opened again, and Read
+// returns io.EOF once buffered messages are read. If the peer rejects the
+// reset, ErrStreamResetRejected is returned and, as with Close, the stream
+// is open again so that closing can be retried. If the peer never answers
+// (ErrStreamResetTimeout) or ctx is done first, the stream is removed all
+// the same and the error returned.
+func (s *Stream) CloseWithContext(ctx context.Context) error {
+	if err := s.Close(); err != nil {
+		s.detach(err)
+
+		return err
+	}
+
+	s.lock.RLock()
+	done := s.outgoingResetDone
+	s.lock.RUnlock()
+
+	var err error
+	if done != nil {
+		select {
+		case <-done:
+			s.lock.RLock()
+			err = s.outgoingResetErr
+			s.lock.RUnlock()
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-s.association.closeWriteLoopCh:
+			err = ErrAssociationClosed
+		}
+	}
+	if errors.Is(err, ErrStreamResetRejected) {
+		return err
+	}
+	s.detach(err)
+
+	return err
+}
+
+// onOutgoingResetDone is called when the reset of the outgoing side
+// completes, with err if it failed.
+func (s *Stream) onOutgoingResetDone(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.outgoingResetDone == nil {
+		return
+	}
+	select {
+	case <-s.outgoingResetDone:
+	default:
+		s.outgoingResetErr = err
+		close(s.outgoingResetDone)
+	}
+}
+
+// detach closes the stream and removes it from the association, if it is
+// still registered. Read then returns err, or io.EOF if err is nil.
+func (s *Stream) detach(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+
+	a := s.association
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	s.lock.Lock()
+	s.state = StreamStateClosed
+	s.lock.Unlock()
+
+	if registered, ok := a.streams[s.streamIdentifier]; ok && registered == s {
+		a.unregisterStream(s, err)
+	}
+	delete(a.outgoingResets, s.streamIdentifier)
+}
+
+// Stats returns a snapshot of the stream's counters.
+func (s *Stream) Stats() StreamStats {
+	return s.stats.snapshot()
+}
+
 // BufferedAmount returns the number of bytes of data currently queued to be sent over this stream.
 func (s *Stream) BufferedAmount() uint64 {
 	s.lock.RLock()
@@ -502,6 +1080,25 @@ func (s *Stream) SetBufferedAmountLowThreshold(th uint64) {
 	s.bufferedAmountLow = th
 }
 
+// BufferedAmountHighThreshold returns the number of bytes of buffered outgoing
+// data above which TryWrite returns ErrWouldBlock, and messages are dropped
+// by the drop policy. Defaults to 1 MiB.
+func (s *Stream) BufferedAmountHighThreshold() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.bufferedAmountHigh
+}
+
+// SetBufferedAmountHighThreshold is used to update the threshold.
+// See BufferedAmountHighThreshold().
+func (s *Stream) SetBufferedAmountHighThreshold(th uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.bufferedAmountHigh = th
+}
+
 // OnBufferedAmountLow sets the callback handler which would be called when the number of
 // bytes of outgoing data buffered is lower than the threshold.
 func (s *Stream) OnBufferedAmountLow(f func()) {
@@ -511,6 +1108,43 @@ func (s *Stream) OnBufferedAmountLow(f func()) {
 	s.onBufferedAmountLow = f
 }
 
+// SetDropPolicy sets what happens to messages written while the buffered
+// amount is above BufferedAmountHighThreshold. Dropped messages are counted
+// in the stream stats and reported to OnMessageDropped, but the write still
+// succeeds. This suits live media, where stale messages are useless.
+// Defaults to DropPolicyBlock.
+func (s *Stream) SetDropPolicy(policy DropPolicy) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.dropPolicy = policy
+}
+
+// OnMessageDropped sets the callback handler which is called with the length
+// of each message dropped by the drop policy, see SetDropPolicy.
+// The callback is never called while association or stream locks are held.
+func (s *Stream) OnMessageDropped(f func(length int)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.onMessageDropped = f
+}
+
+// OnReset sets the callback handler which is called once when the peer resets
+// the incoming side of the stream. Reads keep returning buffered messages and
+// then io.EOF. If the stream was already reset, f is called immediately.
+// The callback is never called while association or stream locks are held.
+func (s *Stream) OnReset(f func()) {
+	s.lock.Lock()
+	s.onReset = f
+	alreadyReset := s.inboundReset
+	s.lock.Unlock()
+
+	if alreadyReset && f != nil {
+		f()
+	}
+}
+
 // This method is called by association's readLoop (go-)routine to notify this stream
 // of the specified amount of outgoing data has been delivered to the peer.
 func (s *Stream) onBufferReleased(nBytesReleased int) {
@@ -550,8 +1184,18 @@ func (s *Stream) getNumBytesInReassemblyQueue() int {
 
 func (s *Stream) onInboundStreamReset() {
 	s.lock.Lock()
-	defer s.lock.Unlock()
+	onReset := s.inboundResetLocked()
+	s.lock.Unlock()
+
+	if onReset != nil {
+		onReset()
+	}
+}
 
+// inboundResetLocked marks the incoming side as reset and returns the OnReset
+// callback to call if this is the first reset.
+// The caller should hold the stream lock.
+func (s *Stream) inboundResetLocked() func() {
 	s.log.Debugf("[%s] onInboundStreamReset: state=%s", s.name, s.state.String())
 
 	// No more inbound data to read. Unblock the read with io.EOF.
@@ -564,6 +1208,10 @@ func (s *Stream) onInboundStreamReset() {
 	//	reset, it also resets its corresponding outgoing stream.  Once this
 	//	is completed, the data channel is closed.
 
+	// Messages already in the reassembly queue are kept: ReadSCTP keeps
+	// returning them and only reports io.EOF once the queue is drained. The
+	// reset is only processed after all DATA up to the peer's last TSN has
+	// been received, so nothing sent before the reset is lost.
 	s.readErr = io.EOF
 	s.readNotifier.Broadcast()
 
@@ -571,6 +1219,13 @@ func (s *Stream) onInboundStreamReset() {
 		s.log.Debugf("[%s] state change: closing => closed", s.name)
 		s.state = StreamStateClosed
 	}
+
+	if s.inboundReset {
+		return nil
+	}
+	s.inboundReset = true
+
+	return s.onReset
 }
 
 func (s *Stream) resetOutgoingStreamSequenceNumbers() {
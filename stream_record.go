@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SCTP record errors.
+var (
+	ErrNoOpenRecord               = errors.New("no record open on the stream")
+	ErrRecordOpen                 = errors.New("a record is open on the stream")
+	ErrRecordPayloadTypeMismatch  = errors.New("payload type differs from the open record")
+	ErrRecordRequiresInterleaving = errors.New("records require user message interleaving (RFC 8260)")
+)
+
+// streamRecord is a message being written in parts with WritePartial.
+type streamRecord struct {
+	ppi       PayloadProtocolIdentifier
+	unordered bool
+	size      int // bytes written so far
+
+	// PR-SCTP parameters of the stream when the record started
+	reliabilityType  byte
+	reliabilityValue uint32
+
+	// set once the first fragment is queued
+	started bool
+	mid     uint32
+	fsn     uint32
+	head    *chunkPayloadData
+
+	// held is the data not queued yet. The last fragment is held back until
+	// WriteEnd so that it can carry the E bit.
+	held []byte
+}
+
+// WritePartial writes p as part of a message, or record, that is finished by
+// WriteEnd. Full fragments are queued for sending as soon as they are
+// written, so a large message can be streamed without buffering all of it.
+// The record is one message for the peer: all its parts must have the same
+// Payload Protocol Identifier, and it is ordered or unordered as the stream
+// was when the record started. Its total size is limited to the maximum
+// message size. Other writes on the stream fail with ErrRecordOpen until
+// WriteEnd.
+//
+// Records require user message interleaving (RFC 8260), as without it the
+// fragments of a message must be sent back to back, and an open record
+// would hold back every other message of the association. WritePartial
+// returns ErrRecordRequiresInterleaving if it was not negotiated.
+func (s *Stream) WritePartial(p []byte, ppi PayloadProtocolIdentifier) (int, error) {
+	if err := s.writableErr(); err != nil {
+		return 0, err
+	}
+	if !s.association.useInterleaving {
+		return 0, ErrRecordRequiresInterleaving
+	}
+
+	// see writeMessages
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	s.lock.Lock()
+	created := s.record == nil
+	if created {
+		s.record = &streamRecord{
+			ppi:              ppi,
+			unordered:        ppi != PayloadTypeWebRTCDCEP && s.unordered,
+			reliabilityType:  s.reliabilityType,
+			reliabilityValue: s.reliabilityValue,
+		}
+	}
+	rec := s.record
+	if rec.ppi != ppi {
+		s.lock.Unlock()
+
+		return 0, ErrRecordPayloadTypeMismatch
+	}
+	maxMessageSize := s.association.MaxMessageSize()
+	if rec.size+len(p) > int(maxMessageSize) {
+		if created {
+			s.record = nil
+		}
+		s.lock.Unlock()
+
+		return 0, fmt.Errorf("%w: %v", ErrOutboundPacketTooLarge, maxMessageSize)
+	}
+
+	prev := *rec
+	rec.held = append(rec.held, p...)
+	rec.size += len(p)
+	chunks := s.packetizeRecord(false)
+	s.lock.Unlock()
+
+	if err := s.sendRecordChunks(rec, chunks, prev, created); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// WriteEnd finishes the record written with WritePartial by sending its last
+// fragment. Nothing is sent for a record without data.
+func (s *Stream) WriteEnd() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	s.lock.Lock()
+	rec := s.record
+	if rec == nil {
+		s.lock.Unlock()
+
+		return ErrNoOpenRecord
+	}
+	if rec.size == 0 {
+		s.record = nil
+		s.lock.Unlock()
+
+		return nil
+	}
+
+	prev := *rec
+	chunks := s.packetizeRecord(true)
+	s.record = nil
+	s.lock.Unlock()
+
+	if err := s.sendRecordChunks(rec, chunks, prev, false); err != nil {
+		return err
+	}
+	s.stats.addWritten(rec.size)
+
+	return nil
+}
+
+// packetizeRecord creates the fragments of the open record from its held
+// data: all of it if last is true, else all but the last fragment.
+// The caller should hold the lock.
+func (s *Stream) packetizeRecord(last bool) []*chunkPayloadData {
+	rec := s.record
+	maxPayloadSize := int(s.association.MaxPayloadSize())
+
+	var chunks []*chunkPayloadData
+	for len(rec.held) > 0 && (last || len(rec.held) > maxPayloadSize) {
+		if !rec.started {
+			rec.started = true
+			s.allocateRecordSequence(rec)
+		}
+
+		fragmentSize := min(maxPayloadSize, len(rec.held))
+		userData := make([]byte, fragmentSize)
+		copy(userData, rec.held)
+
+		chunk := &chunkPayloadData{
+			streamIdentifier:       s.streamIdentifier,
+			userData:               userData,
+			unordered:              rec.unordered,
+			beginningFragment:      rec.fsn == 0,
+			endingFragment:         last && fragmentSize == len(rec.held),
+			payloadType:            rec.ppi,
+			streamSequenceNumber:   uint16(rec.mid), //nolint:gosec
+			messageIdentifier:      rec.mid,
+			fragmentSequenceNumber: rec.fsn,
+			iData:                  true,
+			head:                   rec.head,
+			reliabilityType:        rec.reliabilityType,
+			reliabilityValue:       rec.reliabilityValue,
+		}
+		if rec.head == nil {
+			rec.head = chunk
+		}
+		chunks = append(chunks, chunk)
+
+		rec.fsn++
+		rec.held = rec.held[fragmentSize:]
+		s.bufferedAmount += uint64(fragmentSize) //nolint:gosec // G115
+	}
+
+	if len(chunks) > 0 {
+		// do not keep the queued data referenced
+		rec.held = append([]byte(nil), rec.held...)
+		s.log.Tracef("[%s] bufferedAmount = %d", s.name, s.bufferedAmount)
+	}
+
+	return chunks
+}
+
+// allocateRecordSequence assigns the message identifier of a record, as
+// packetize does for a message.
+// The caller should hold the lock.
+func (s *Stream) allocateRecordSequence(rec *streamRecord) {
+	if rec.unordered {
+		rec.mid = s.nextUnorderedMID
+		s.nextUnorderedMID++
+	} else {
+		rec.mid = s.nextOrderedMID
+		s.nextOrderedMID++
+	}
+}
+
+// releaseRecordSequence undoes allocateRecordSequence.
+// The caller should hold the lock.
+func (s *Stream) releaseRecordSequence(rec *streamRecord) {
+	if rec.unordered {
+		s.nextUnorderedMID--
+	} else {
+		s.nextOrderedMID--
+	}
+}
+
+// sendRecordChunks queues the fragments of rec. If that fails, rec is
+// restored to prev, its state before they were created, and left open
+// unless it was created for them.
+func (s *Stream) sendRecordChunks(
+	rec *streamRecord,
+	chunks []*chunkPayloadData,
+	prev streamRecord,
+	created bool,
+) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	err := s.association.sendPayloadData(s.writeDeadline, chunks, false)
+	if err == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !prev.started && rec.started {
+		s.releaseRecordSequence(rec)
+	}
+	for _, c := range chunks {
+		s.bufferedAmount -= uint64(len(c.userData))
+	}
+	*rec = prev
+	if created {
+		s.record = nil
+	} else {
+		s.record = rec
+	}
+
+	return err
+}
@@ -4,8 +4,11 @@
 package sctp
 
 import (
+	"context"
+	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/pion/logging"
 	"github.com/stretchr/testify/assert"
@@ -228,11 +231,151 @@ func TestStreamHandleForwardTSNForMID(t *testing.T) {
 	})
 }
 
+func TestStreamReadMessages(t *testing.T) {
+	pushMessages := func(t *testing.T, stream *Stream, msgs ...string) {
+		t.Helper()
+
+		for i, msg := range msgs {
+			ssn := stream.reassemblyQueue.nextSSN + uint16(i) //nolint:gosec // G115
+			complete := stream.reassemblyQueue.push(&chunkPayloadData{
+				beginningFragment:    true,
+				endingFragment:       true,
+				tsn:                  100 + uint32(ssn),
+				streamSequenceNumber: ssn,
+				payloadType:          PayloadTypeWebRTCBinary,
+				userData:             []byte(msg),
+			})
+			assert.True(t, complete)
+		}
+	}
+
+	t.Run("fills one slot per message", func(t *testing.T) {
+		stream := newTestStream(t)
+		pushMessages(t, stream, "a", "bb", "ccc")
+
+		bufs := [][]byte{make([]byte, 8), make([]byte, 8)}
+		n, err := stream.ReadMessages(bufs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "a", string(bufs[0]))
+		assert.Equal(t, "bb", string(bufs[1]))
+
+		bufs = [][]byte{make([]byte, 8), make([]byte, 8)}
+		n, err = stream.ReadMessages(bufs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "ccc", string(bufs[0]))
+		assert.Equal(t, 0, stream.getNumBytesInReassemblyQueue())
+	})
+
+	t.Run("reports the PPI of each message", func(t *testing.T) {
+		stream := newTestStream(t)
+		for i, ppi := range []PayloadProtocolIdentifier{
+			PayloadTypeWebRTCString, PayloadTypeWebRTCBinary, PayloadTypeWebRTCString,
+		} {
+			assert.True(t, stream.reassemblyQueue.push(&chunkPayloadData{
+				beginningFragment:    true,
+				endingFragment:       true,
+				tsn:                  100 + uint32(i), //nolint:gosec // G115
+				streamSequenceNumber: uint16(i),       //nolint:gosec // G115
+				payloadType:          ppi,
+				userData:             []byte("m"),
+			}))
+		}
+
+		bufs := [][]byte{make([]byte, 8), make([]byte, 8), make([]byte, 8)}
+		ppis := make([]PayloadProtocolIdentifier, 2)
+		n, err := stream.ReadMessages(bufs, ppis)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n, "no more messages than PPI slots should be read")
+		assert.Equal(t, []PayloadProtocolIdentifier{PayloadTypeWebRTCString, PayloadTypeWebRTCBinary}, ppis)
+		assert.Equal(t, len("m"), stream.getNumBytesInReassemblyQueue())
+	})
+
+	t.Run("message that does not fit stops the batch", func(t *testing.T) {
+		stream := newTestStream(t)
+		pushMessages(t, stream, "a", "long message")
+
+		bufs := [][]byte{make([]byte, 4), make([]byte, 4)}
+		n, err := stream.ReadMessages(bufs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "a", string(bufs[0]))
+
+		n, err = stream.ReadMessages([][]byte{make([]byte, 4)}, nil)
+		assert.ErrorIs(t, err, io.ErrShortBuffer)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, len("long message"), stream.getNumBytesInReassemblyQueue(), "message should stay queued")
+
+		bufs = [][]byte{make([]byte, 16)}
+		n, err = stream.ReadMessages(bufs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, "long message", string(bufs[0]))
+	})
+
+	t.Run("returns queued messages before EOF", func(t *testing.T) {
+		stream := newTestStream(t)
+		pushMessages(t, stream, "a")
+		stream.onInboundStreamReset()
+
+		bufs := [][]byte{make([]byte, 4), make([]byte, 4)}
+		n, err := stream.ReadMessages(bufs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		n, err = stream.ReadMessages(bufs, nil)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("deadline unblocks a waiting read", func(t *testing.T) {
+		stream := newTestStream(t)
+		assert.NoError(t, stream.SetReadDeadline(time.Now().Add(20*time.Millisecond)))
+
+		n, err := stream.ReadMessages([][]byte{make([]byte, 4)}, nil)
+		assert.ErrorIs(t, err, ErrReadDeadlineExceeded)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func TestStreamReassemblyTimeout(t *testing.T) {
+	stream := newTestStream(t)
+	stream.reassemblyTimeout = 50 * time.Millisecond
+
+	// The ending fragment of SSN 0 is never sent.
+	assert.NoError(t, stream.handleData(&chunkPayloadData{
+		beginningFragment:    true,
+		tsn:                  1,
+		streamSequenceNumber: 0,
+		userData:             []byte("never completed"),
+	}))
+	time.Sleep(60 * time.Millisecond)
+
+	assert.NoError(t, stream.handleData(&chunkPayloadData{
+		beginningFragment:    true,
+		endingFragment:       true,
+		tsn:                  3,
+		streamSequenceNumber: 1,
+		userData:             []byte("next"),
+	}))
+
+	buf := make([]byte, 32)
+	n, _, err := stream.ReadSCTP(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", string(buf[:n]))
+	assert.Equal(t, 0, stream.getNumBytesInReassemblyQueue())
+
+	stats := stream.stats.snapshot()
+	assert.Equal(t, uint64(1), stats.ReassemblyTimeouts)
+	assert.Equal(t, uint64(len("never completed")), stats.ReassemblyTimeoutBytes)
+}
+
 func TestStreamPacketizeInterleavingMIDAllocation(t *testing.T) {
 	stream := newTestPacketizingStream(t, true, 3)
 	stream.unordered = true
 
-	unorderedChunks, unordered := stream.packetize([]byte("abcdef"), PayloadTypeWebRTCBinary)
+	unorderedChunks, unordered := stream.packetize([]byte("abcdef"), WriteOptions{PayloadType: PayloadTypeWebRTCBinary})
 	assert.True(t, unordered)
 	if assert.Len(t, unorderedChunks, 2) {
 		assert.True(t, unorderedChunks[0].beginningFragment)
@@ -249,7 +392,7 @@ func TestStreamPacketizeInterleavingMIDAllocation(t *testing.T) {
 		}
 	}
 
-	secondUnorderedChunks, unordered := stream.packetize([]byte("xy"), PayloadTypeWebRTCBinary)
+	secondUnorderedChunks, unordered := stream.packetize([]byte("xy"), WriteOptions{PayloadType: PayloadTypeWebRTCBinary})
 	assert.True(t, unordered)
 	if assert.Len(t, secondUnorderedChunks, 1) {
 		assert.True(t, secondUnorderedChunks[0].iData)
@@ -258,7 +401,7 @@ func TestStreamPacketizeInterleavingMIDAllocation(t *testing.T) {
 		assert.Equal(t, uint16(1), secondUnorderedChunks[0].streamSequenceNumber)
 	}
 
-	orderedChunks, unordered := stream.packetize([]byte("hi"), PayloadTypeWebRTCDCEP)
+	orderedChunks, unordered := stream.packetize([]byte("hi"), WriteOptions{PayloadType: PayloadTypeWebRTCDCEP})
 	assert.False(t, unordered)
 	if assert.Len(t, orderedChunks, 1) {
 		assert.True(t, orderedChunks[0].iData)
@@ -267,7 +410,7 @@ func TestStreamPacketizeInterleavingMIDAllocation(t *testing.T) {
 		assert.Equal(t, uint16(0), orderedChunks[0].streamSequenceNumber)
 	}
 
-	secondOrderedChunks, unordered := stream.packetize([]byte("ok"), PayloadTypeWebRTCDCEP)
+	secondOrderedChunks, unordered := stream.packetize([]byte("ok"), WriteOptions{PayloadType: PayloadTypeWebRTCDCEP})
 	assert.False(t, unordered)
 	if assert.Len(t, secondOrderedChunks, 1) {
 		assert.True(t, secondOrderedChunks[0].iData)
@@ -314,3 +457,445 @@ func TestStreamWriteSCTPRollsBackInterleavingMIDsOnSendError(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamOnReset(t *testing.T) {
+	s := newTestStream(t)
+
+	var calls int
+	s.OnReset(func() {
+		// the stream lock must not be held while the callback runs.
+		assert.Equal(t, StreamStateOpen, s.State())
+		calls++
+	})
+
+	s.onInboundStreamReset()
+	s.onInboundStreamReset()
+	assert.Equal(t, 1, calls, "OnReset should fire exactly once")
+
+	_, err := s.Read(make([]byte, 8))
+	assert.ErrorIs(t, err, io.EOF)
+
+	var late int
+	s.OnReset(func() { late++ })
+	assert.Equal(t, 1, late, "OnReset set after the reset should fire immediately")
+}
+
+func TestStreamWriteMultiple(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+
+	payloads := [][]byte{[]byte("a"), []byte("bc"), make([]byte, 2000), []byte("d")}
+	n, err := s.WriteMultiple(payloads, PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrOutboundPacketTooLarge)
+	assert.Equal(t, 2, n, "messages before the oversized one should be written")
+
+	assert.Equal(t, 2, s.association.pendingQueue.size())
+	for i := range 2 {
+		c := s.association.pendingQueue.peek()
+		if assert.NotNil(t, c) {
+			assert.Equal(t, uint16(i), c.streamSequenceNumber)
+			assert.Equal(t, payloads[i], c.userData)
+			assert.True(t, c.beginningFragment)
+			assert.True(t, c.endingFragment)
+			assert.NoError(t, s.association.pendingQueue.pop(c))
+		}
+	}
+	assert.Equal(t, uint16(2), s.sequenceNumber)
+	assert.Equal(t, uint64(3), s.BufferedAmount())
+	assert.Equal(t, uint64(2), s.Stats().MessagesWritten)
+
+	n, err = s.WriteMultiple([][]byte{make([]byte, 2000)}, PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrOutboundPacketTooLarge)
+	assert.Equal(t, 0, n)
+}
+
+func TestStreamWriteWithOptionsImmediateSack(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 400)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+
+	n, err := s.WriteWithOptions(make([]byte, 1000), WriteOptions{
+		PayloadType:   PayloadTypeWebRTCBinary,
+		ImmediateSack: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, n)
+
+	n, err = s.WriteWithOptions([]byte("a"), WriteOptions{PayloadType: PayloadTypeWebRTCString})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var chunks []*chunkPayloadData
+	for s.association.pendingQueue.size() > 0 {
+		c := s.association.pendingQueue.peek()
+		assert.NoError(t, s.association.pendingQueue.pop(c))
+		chunks = append(chunks, c)
+	}
+	if !assert.Len(t, chunks, 4) {
+		return
+	}
+	for i, c := range chunks {
+		assert.Equal(t, i == 2, c.immediateSack, "chunk %d", i)
+	}
+	assert.Equal(t, PayloadTypeWebRTCString, chunks[3].payloadType)
+
+	raw, err := chunks[2].marshal()
+	assert.NoError(t, err)
+	parsed := &chunkPayloadData{}
+	assert.NoError(t, parsed.unmarshal(raw))
+	assert.True(t, parsed.immediateSack)
+	assert.True(t, parsed.endingFragment)
+}
+
+func TestStreamWriteUnordered(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+
+	write := func(ppi PayloadProtocolIdentifier, unordered bool) *chunkPayloadData {
+		_, err := s.WriteWithOptions([]byte("a"), WriteOptions{PayloadType: ppi, Unordered: unordered})
+		assert.NoError(t, err)
+		c := s.association.pendingQueue.peek()
+		if assert.NotNil(t, c) {
+			assert.NoError(t, s.association.pendingQueue.pop(c))
+		}
+
+		return c
+	}
+
+	assert.True(t, write(PayloadTypeWebRTCBinary, true).unordered)
+	assert.Equal(t, uint16(0), s.sequenceNumber, "unordered messages should not use a SSN")
+	assert.False(t, write(PayloadTypeWebRTCBinary, false).unordered)
+	assert.False(t, write(PayloadTypeWebRTCDCEP, true).unordered, "DCEP should always be ordered")
+
+	s.SetUnordered(true)
+	assert.True(t, write(PayloadTypeWebRTCBinary, false).unordered)
+	s.SetUnordered(false)
+	assert.False(t, write(PayloadTypeWebRTCBinary, false).unordered)
+	assert.Equal(t, uint16(3), s.sequenceNumber)
+}
+
+func TestStreamSetReliabilityParams(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+	s.association.useForwardTSN = true
+
+	write := func() *chunkPayloadData {
+		_, err := s.WriteSCTP([]byte("a"), PayloadTypeWebRTCBinary)
+		assert.NoError(t, err)
+		c := s.association.pendingQueue.peek()
+		if assert.NotNil(t, c) {
+			assert.NoError(t, s.association.pendingQueue.pop(c))
+		}
+		c.nSent = 1
+		c.since = time.Now().Add(-time.Second)
+		c.setAllInflight()
+
+		return c
+	}
+
+	reliable := write()
+	assert.Equal(t, ReliabilityTypeReliable, reliable.reliabilityType, "streams should be reliable by default")
+
+	s.SetReliabilityParams(false, ReliabilityTypeRexmit, 1)
+	rexmit := write()
+	s.SetReliabilityParams(false, ReliabilityTypeTimed, 500)
+	timed := write()
+	s.SetReliabilityParams(false, ReliabilityTypeReliable, 0)
+
+	for _, c := range []*chunkPayloadData{reliable, rexmit, timed} {
+		s.association.checkPartialReliabilityStatus(c)
+	}
+	assert.False(t, reliable.abandoned())
+	assert.True(t, rexmit.abandoned(), "should keep the parameters it was written with")
+	assert.True(t, timed.abandoned(), "should keep the parameters it was written with")
+	assert.False(t, write().abandoned())
+
+	// a record keeps the parameters of the stream when it started
+	s.association.useInterleaving = true
+	s.SetReliabilityParams(false, ReliabilityTypeRexmit, 1)
+	_, err := s.WritePartial([]byte("a"), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+	s.SetReliabilityParams(false, ReliabilityTypeReliable, 0)
+	assert.NoError(t, s.WriteEnd())
+	record := s.association.pendingQueue.peek()
+	if assert.NotNil(t, record) {
+		assert.Equal(t, ReliabilityTypeRexmit, record.reliabilityType)
+		record.nSent = 1
+		record.setAllInflight()
+		s.association.checkPartialReliabilityStatus(record)
+		assert.True(t, record.abandoned(), "records should be abandoned on PR-SCTP streams")
+	}
+}
+
+func TestStreamTryWrite(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+	assert.Equal(t, uint64(defaultBufferedAmountHighThreshold), s.BufferedAmountHighThreshold())
+	s.SetBufferedAmountHighThreshold(1000)
+
+	// a message larger than the threshold is accepted when nothing is buffered
+	n, err := s.TryWrite(make([]byte, 1024), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, n)
+
+	n, err = s.TryWrite([]byte("a"), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrWouldBlock)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, uint64(1024), s.BufferedAmount())
+	assert.Equal(t, uint16(1), s.sequenceNumber)
+
+	s.onBufferReleased(1024)
+	n, err = s.TryWrite(make([]byte, 600), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+	assert.Equal(t, 600, n)
+	n, err = s.TryWrite(make([]byte, 400), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+	assert.Equal(t, 400, n)
+	_, err = s.TryWrite([]byte("a"), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrWouldBlock)
+	assert.Equal(t, 3, s.association.pendingQueue.size())
+
+	// another write in progress on the stream makes TryWrite fail instead
+	// of waiting, even when writes do not block
+	s.onBufferReleased(1000)
+	s.writeLock.Lock()
+	_, err = s.TryWrite([]byte("a"), PayloadTypeWebRTCBinary)
+	s.writeLock.Unlock()
+	assert.ErrorIs(t, err, ErrWouldBlock)
+	assert.Equal(t, uint64(0), s.BufferedAmount())
+
+	// a pending blocking write makes TryWrite fail instead of waiting
+	s.association.blockWrite = true
+	s.association.writePending = true
+	_, err = s.TryWrite([]byte("a"), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrWouldBlock)
+	assert.Equal(t, uint64(0), s.BufferedAmount())
+	assert.Equal(t, uint16(3), s.sequenceNumber)
+	assert.Equal(t, 3, s.association.pendingQueue.size())
+}
+
+func TestStreamDropPolicy(t *testing.T) {
+	newStream := func(t *testing.T, policy DropPolicy) (*Stream, *[]int) {
+		t.Helper()
+
+		s := newTestPacketizingStream(t, false, 1200)
+		s.association.pendingQueue = newPendingQueue(nil)
+		s.association.setState(established)
+		s.SetBufferedAmountHighThreshold(1000)
+		s.SetDropPolicy(policy)
+		var dropped []int
+		s.OnMessageDropped(func(length int) {
+			dropped = append(dropped, length)
+		})
+
+		return s, &dropped
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		s, dropped := newStream(t, DropPolicyDropNewest)
+		for _, size := range []int{600, 500, 400} {
+			n, err := s.Write(make([]byte, size))
+			assert.NoError(t, err)
+			assert.Equal(t, size, n)
+		}
+
+		assert.Equal(t, []int{500}, *dropped)
+		assert.Equal(t, uint64(1000), s.BufferedAmount())
+		assert.Equal(t, 2, s.association.pendingQueue.size())
+		assert.Equal(t, uint16(2), s.sequenceNumber, "no SSN is used by the dropped message")
+		stats := s.Stats()
+		assert.Equal(t, uint64(1), stats.MessagesDropped)
+		assert.Equal(t, uint64(500), stats.BytesDropped)
+		assert.Equal(t, uint64(2), stats.MessagesWritten)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		s, dropped := newStream(t, DropPolicyDropOldest)
+		s.SetUnordered(true)
+		for _, size := range []int{400, 300, 900, 100} {
+			_, err := s.Write(make([]byte, size))
+			assert.NoError(t, err)
+		}
+
+		// the 900 bytes message made room by dropping the first two
+		assert.Equal(t, []int{400, 300}, *dropped)
+		assert.Equal(t, uint64(1000), s.BufferedAmount())
+		assert.Equal(t, uint64(700), s.Stats().BytesDropped)
+		assert.Len(t, s.droppable, 2)
+
+		// dropped messages stay queued until the write loop discards them
+		assert.Equal(t, 4, s.association.pendingQueue.size())
+		assert.True(t, s.association.pendingQueue.peek().dropped())
+	})
+
+	t.Run("sent messages are forgotten", func(t *testing.T) {
+		s, dropped := newStream(t, DropPolicyDropOldest)
+		s.SetUnordered(true)
+		for _, size := range []int{400, 300} {
+			_, err := s.Write(make([]byte, size))
+			assert.NoError(t, err)
+		}
+		assert.Len(t, s.droppable, 2)
+
+		a := s.association
+		a.inflightQueue = newPayloadQueue()
+		a.lock.Lock()
+		a.movePendingDataChunkToInflightQueue(a.pendingQueue.peek())
+		assert.Len(t, s.droppable, 1, "a sent message should not stay referenced")
+		a.movePendingDataChunkToInflightQueue(a.pendingQueue.peek())
+		assert.Empty(t, s.droppable)
+		a.lock.Unlock()
+
+		_, err := s.Write(make([]byte, 900))
+		assert.NoError(t, err)
+		assert.Empty(t, *dropped, "sent messages cannot be dropped")
+		assert.Len(t, s.droppable, 1)
+	})
+
+	t.Run("ordered messages are not dropped", func(t *testing.T) {
+		s, dropped := newStream(t, DropPolicyDropOldest)
+		for range 3 {
+			_, err := s.Write(make([]byte, 600))
+			assert.NoError(t, err)
+		}
+
+		assert.Empty(t, *dropped)
+		assert.Equal(t, uint64(1800), s.BufferedAmount())
+	})
+}
+
+func TestStreamWriteContext(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+	s.association.setState(established)
+
+	writeTimeout := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := s.WriteContext(ctx, []byte("a"), PayloadTypeWebRTCBinary)
+
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.WriteContext(ctx, []byte("a"), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// waiting for the association lock is cancelled, and the write is not
+	// sent once the lock is released
+	held := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		s.association.lock.Lock()
+		close(held)
+		<-release
+		s.association.lock.Unlock()
+	}()
+	<-held
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = s.WriteContext(ctx, []byte("a"), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+	assert.Equal(t, uint64(0), s.BufferedAmount())
+	assert.Equal(t, uint16(0), s.sequenceNumber)
+
+	n, err := s.WriteContext(context.Background(), []byte("a"), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err, "a cancelled write should not keep the lock")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, s.association.pendingQueue.size())
+
+	// the write deadline only applies to blocking writes
+	assert.NoError(t, s.SetWriteDeadline(time.Now().Add(-time.Second)))
+	_, err = s.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.NoError(t, s.SetWriteDeadline(time.Time{}))
+
+	// waiting for the stream write lock and for a pending blocking write are cancelled too
+	s.association.blockWrite = true
+	s.writeLock.Lock()
+	assert.ErrorIs(t, writeTimeout(), context.DeadlineExceeded)
+	s.writeLock.Unlock()
+	s.association.writePending = true
+	assert.ErrorIs(t, writeTimeout(), context.DeadlineExceeded)
+	assert.Equal(t, uint16(2), s.sequenceNumber)
+	assert.Equal(t, 2, s.association.pendingQueue.size())
+}
+
+func TestStreamWriteWhileShuttingDown(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 1200)
+	s.association.pendingQueue = newPendingQueue(nil)
+
+	for _, state := range []uint32{shutdownPending, shutdownSent, shutdownReceived, shutdownAckSent} {
+		s.association.setState(state)
+		_, err := s.WriteSCTP([]byte("a"), PayloadTypeWebRTCBinary)
+		assert.ErrorIs(t, err, ErrShuttingDown, getAssociationStateString(state))
+		assert.NotErrorIs(t, err, ErrPayloadDataStateNotExist, getAssociationStateString(state))
+	}
+
+	for _, state := range []uint32{closed, cookieWait, cookieEchoed} {
+		s.association.setState(state)
+		_, err := s.WriteSCTP([]byte("a"), PayloadTypeWebRTCBinary)
+		assert.ErrorIs(t, err, ErrPayloadDataStateNotExist, getAssociationStateString(state))
+		assert.NotErrorIs(t, err, ErrShuttingDown, getAssociationStateString(state))
+	}
+
+	assert.Equal(t, 0, s.association.pendingQueue.size())
+	assert.Equal(t, uint16(0), s.sequenceNumber)
+}
+
+func TestStreamWritePartialRollsBackOnSendError(t *testing.T) {
+	s := newTestPacketizingStream(t, true, 100)
+
+	// held back until more data or WriteEnd
+	n, err := s.WritePartial(make([]byte, 80), PayloadTypeWebRTCBinary)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, n)
+
+	n, err = s.WritePartial(make([]byte, 80), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrPayloadDataStateNotExist)
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, s.WriteEnd(), ErrPayloadDataStateNotExist)
+
+	assert.Equal(t, uint32(0), s.nextOrderedMID)
+	assert.Equal(t, uint64(0), s.BufferedAmount())
+	if assert.NotNil(t, s.record, "record should stay open") {
+		assert.Equal(t, 80, s.record.size)
+		assert.False(t, s.record.started)
+	}
+
+	_, err = s.WritePartial(nil, PayloadTypeWebRTCString)
+	assert.ErrorIs(t, err, ErrRecordPayloadTypeMismatch)
+	_, err = s.WritePartial(make([]byte, 1000), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrOutboundPacketTooLarge)
+}
+
+func TestStreamWritePartialRequiresInterleaving(t *testing.T) {
+	s := newTestPacketizingStream(t, false, 100)
+
+	n, err := s.WritePartial(make([]byte, 80), PayloadTypeWebRTCBinary)
+	assert.ErrorIs(t, err, ErrRecordRequiresInterleaving)
+	assert.Equal(t, 0, n)
+	assert.Nil(t, s.record)
+	assert.ErrorIs(t, s.WriteEnd(), ErrNoOpenRecord)
+}
+
+func TestStreamWriteMultipleRollsBackOnSendError(t *testing.T) {
+	for _, useInterleaving := range []bool{false, true} {
+		s := newTestPacketizingStream(t, useInterleaving, 1200)
+
+		n, err := s.WriteMultiple([][]byte{[]byte("ab"), []byte("cd")}, PayloadTypeWebRTCBinary)
+		assert.ErrorIs(t, err, ErrPayloadDataStateNotExist)
+		assert.Equal(t, 0, n, "failed write should not report sent messages")
+		assert.Equal(t, uint16(0), s.sequenceNumber)
+		assert.Equal(t, uint32(0), s.nextOrderedMID)
+		assert.Equal(t, uint32(0), s.nextUnorderedMID)
+		assert.Equal(t, uint64(0), s.BufferedAmount())
+		assert.Zero(t, s.Stats().MessagesWritten)
+	}
+}
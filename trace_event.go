@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+// TraceEventKind is what happened to the DATA chunk of a TraceEvent.
+type TraceEventKind uint8
+
+const (
+	// TraceEventSend is reported when DATA is sent for the first time.
+	TraceEventSend TraceEventKind = iota + 1
+	// TraceEventRetransmit is reported when DATA is retransmitted after it
+	// was marked lost or on a T3-rtx timeout.
+	TraceEventRetransmit
+	// TraceEventFastRetransmit is reported when DATA is fast retransmitted.
+	TraceEventFastRetransmit
+	// TraceEventAbandoned is reported when partially reliable DATA is
+	// abandoned.
+	TraceEventAbandoned
+	// TraceEventSacked is reported when sent DATA is acknowledged by a SACK.
+	TraceEventSacked
+	// TraceEventCumulativeTSNAdvanced is reported when a SACK advances the
+	// cumulative TSN ack point. Only TSN is set, to the new ack point.
+	TraceEventCumulativeTSNAdvanced
+	// TraceEventReceive is reported when DATA is received.
+	TraceEventReceive
+)
+
+func (k TraceEventKind) String() string {
+	switch k {
+	case TraceEventSend:
+		return "Send"
+	case TraceEventRetransmit:
+		return "Retransmit"
+	case TraceEventFastRetransmit:
+		return "FastRetransmit"
+	case TraceEventAbandoned:
+		return "Abandoned"
+	case TraceEventSacked:
+		return "Sacked"
+	case TraceEventCumulativeTSNAdvanced:
+		return "CumulativeTSNAdvanced"
+	case TraceEventReceive:
+		return "Receive"
+	default:
+		return "Unknown"
+	}
+}
+
+// TraceEvent describes a DATA chunk event, see WithOnTrace.
+type TraceEvent struct {
+	Kind                 TraceEventKind
+	TSN                  uint32
+	StreamIdentifier     uint16
+	StreamSequenceNumber uint16
+	// NumSent is the number of times the chunk was sent, for send events.
+	NumSent uint32
+	Length  int
+}
+
+func newTraceEvent(kind TraceEventKind, c *chunkPayloadData) TraceEvent {
+	return TraceEvent{
+		Kind:                 kind,
+		TSN:                  c.tsn,
+		StreamIdentifier:     c.streamIdentifier,
+		StreamSequenceNumber: c.streamSequenceNumber,
+		NumSent:              c.nSent,
+		Length:               len(c.userData),
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package sctp
+
+import (
+	"net"
+	"time"
+)
+
+// Transport carries whole SCTP packets for an association. It can be set in
+// Config.Transport instead of a net.Conn, for example to drive an
+// association from a test without sockets.
+type Transport interface {
+	// ReadPacket blocks until a packet is received, copies it into buf and
+	// returns its length. It returns an error once the transport is closed.
+	ReadPacket(buf []byte) (int, error)
+
+	// WritePacket sends one packet. The association does not retain the
+	// packet after the call returns.
+	WritePacket(packet []byte) error
+
+	// Close closes the transport and unblocks ReadPacket.
+	Close() error
+}
+
+// netConnTransport adapts the net.Conn of Config.NetConn to Transport.
+type netConnTransport struct {
+	net.Conn
+}
+
+func (t netConnTransport) ReadPacket(buf []byte) (int, error) {
+	return t.Read(buf)
+}
+
+func (t netConnTransport) WritePacket(packet []byte) error {
+	_, err := t.Write(packet)
+
+	return err
+}
+
+// deadlineTransport is implemented by transports that support read and
+// write deadlines, such as the net.Conn adapter.
+type deadlineTransport interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// Clock schedules the retransmission and delayed acknowledgement timers of
+// an association. It can be set in Config.Clock to control the timers from
+// a test. RTT measurements and other timestamps still use the system clock.
+type Clock interface {
+	// AfterFunc waits for the duration to elapse and then calls f in its
+	// own goroutine, like time.AfterFunc.
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer is a timer created by a Clock. *time.Timer implements it.
+type ClockTimer interface {
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already expired or was stopped.
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d. It returns true
+	// if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
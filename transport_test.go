@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+//go:build !js
+
+package sctp
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock whose timers only fire when the test advances it.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	f      func()
+	when   time.Duration
+	active bool
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	t := &fakeTimer{clock: c, f: f}
+
+	c.mu.Lock()
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+
+	t.Reset(d)
+
+	return t
+}
+
+// Advance moves the clock forward and runs the callbacks of the timers that
+// became due, in the caller's goroutine.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now += d
+	var due []func()
+	for _, t := range c.timers {
+		if t.active && t.when <= c.now {
+			t.active = false
+			due = append(due, t.f)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, f := range due {
+		f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.active = false
+
+	return wasActive
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.active = true
+	if d > math.MaxInt64-t.clock.now {
+		t.when = math.MaxInt64
+	} else {
+		t.when = t.clock.now + d
+	}
+
+	return wasActive
+}
+
+// chanTransport is a Transport backed by channels.
+type chanTransport struct {
+	in        chan []byte
+	out       chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newChanTransport() *chanTransport {
+	return &chanTransport{
+		in:     make(chan []byte, 16),
+		out:    make(chan []byte, 16),
+		closed: make(chan struct{}),
+	}
+}
+
+func (t *chanTransport) ReadPacket(buf []byte) (int, error) {
+	select {
+	case p := <-t.in:
+		return copy(buf, p), nil
+	case <-t.closed:
+		return 0, io.EOF
+	}
+}
+
+func (t *chanTransport) WritePacket(packet []byte) error {
+	p := append([]byte(nil), packet...)
+	select {
+	case t.out <- p:
+		return nil
+	case <-t.closed:
+		return io.ErrClosedPipe
+	}
+}
+
+func (t *chanTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+
+	return nil
+}
+
+func TestAssociationTransportAndClock(t *testing.T) {
+	transport := newChanTransport()
+	clock := &fakeClock{}
+
+	type result struct {
+		assoc *Association
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		assoc, err := Client(Config{
+			Transport:     transport,
+			Clock:         clock,
+			LoggerFactory: logging.NewDefaultLoggerFactory(),
+		})
+		done <- result{assoc, err}
+	}()
+
+	// Each INIT retransmission is only sent once the fake clock is advanced
+	// past the T1-init timeout.
+	for i := 0; i <= int(maxInitRetrans); i++ {
+		select {
+		case raw := <-transport.out:
+			pkt := &packet{}
+			require.NoError(t, pkt.unmarshal(true, raw))
+			require.Len(t, pkt.chunks, 1)
+			_, ok := pkt.chunks[0].(*chunkInit)
+			require.True(t, ok, "expected INIT, got %s", pkt.chunks[0])
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "INIT not sent", "attempt %d", i)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	select {
+	case res := <-done:
+		assert.Nil(t, res.assoc)
+		var hsErr *HandshakeError
+		require.True(t, errors.As(res.err, &hsErr), "unexpected error: %v", res.err)
+		assert.ErrorIs(t, hsErr.Cause, ErrHandshakeTimeout)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "handshake did not time out")
+	}
+
+	select {
+	case <-transport.closed:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "transport not closed")
+	}
+	assert.Empty(t, transport.out, "no packet expected after the handshake failed")
+}
+
+func TestAssociationTransportConn(t *testing.T) {
+	conn := &dumbConn{}
+	assoc := createTestAssociation(t, Config{NetConn: conn})
+	assert.Same(t, conn, assoc.Transport())
+
+	// A Config.Transport replaces the net.Conn.
+	assoc = createTestAssociation(t, Config{NetConn: conn, Transport: newChanTransport()})
+	assert.Nil(t, assoc.Transport())
+}
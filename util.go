@@ -3,6 +3,11 @@
 
 package sctp
 
+import (
+	"context"
+	"sync"
+)
+
 const (
 	paddingMultiple = 4
 )
@@ -11,6 +16,45 @@ func getPadding(l int) int {
 	return (paddingMultiple - (l % paddingMultiple)) % paddingMultiple
 }
 
+// tryLocker is a sync.Locker that can also be acquired without waiting, such
+// as sync.Mutex and sync.RWMutex.
+type tryLocker interface {
+	sync.Locker
+	TryLock() bool
+}
+
+// lockContext acquires l, giving up with ctx.Err() if ctx is done first.
+// The lock is taken right away when it is free, even if ctx is already done.
+func lockContext(ctx context.Context, l tryLocker) error {
+	if l.TryLock() {
+		return nil
+	}
+	done := ctx.Done()
+	if done == nil {
+		l.Lock()
+
+		return nil
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		l.Lock()
+		select {
+		case locked <- struct{}{}:
+		case <-done:
+			// the caller gave up before the lock was handed over
+			l.Unlock()
+		}
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-done:
+		return ctx.Err()
+	}
+}
+
 func padByte(in []byte, cnt int) []byte {
 	if cnt < 0 {
 		cnt = 0
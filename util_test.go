@@ -4,7 +4,10 @@
 package sctp
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -104,3 +107,33 @@ func TestSerialNumberArithmetic(t *testing.T) {
 		}
 	})
 }
+
+func TestLockContext(t *testing.T) {
+	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, lockContext(ctx, &mu), "a free lock should be taken even if ctx is done")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, lockContext(ctx, &mu), context.DeadlineExceeded)
+
+	// the lock acquired after the caller gave up is released
+	mu.Unlock()
+	assert.Eventually(t, func() bool {
+		if !mu.TryLock() {
+			return false
+		}
+		mu.Unlock()
+
+		return true
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		mu.Unlock()
+	}()
+	assert.NoError(t, lockContext(context.Background(), &mu))
+	mu.Unlock()
+}
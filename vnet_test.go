@@ -517,6 +517,7 @@ func TestStreamClose(t *testing.T) { //nolint:cyclop
 
 		// begin client read-loop
 		buf := make([]byte, 1500)
+		allEchoed := make(chan struct{})
 		go func() {
 			defer close(clientShutDown)
 			for {
@@ -531,6 +532,9 @@ func TestStreamClose(t *testing.T) { //nolint:cyclop
 				log.Infof("client: received %d bytes (%d)", n, numClientReceived)
 				assert.Equal(t, 0, bytes.Compare(buf[:n], messages[numClientReceived]), "should receive HELLO")
 				numClientReceived++
+				if numClientReceived == numMessages {
+					close(allEchoed)
+				}
 			}
 		}()
 
@@ -540,11 +544,14 @@ func TestStreamClose(t *testing.T) { //nolint:cyclop
 			assert.NoError(t, err, "should succeed")
 		}
 
+		// The server can no longer echo once the stream is reset.
+		<-allEchoed
+
 		if dropReconfigChunk {
 			venv.dropNextReconfigChunk(1)
 		}
 
-		// Immediately close the stream
+		// Close the stream
 		err = stream.Close()
 		assert.NoError(t, err, "should succeed")
 		assert.Equal(t, StreamStateClosing, stream.State())
@@ -557,7 +564,7 @@ func TestStreamClose(t *testing.T) { //nolint:cyclop
 
 		_, err = stream.Write([]byte{1})
 
-		assert.Equal(t, err, ErrStreamClosed, "after closed should not allow write")
+		assert.ErrorIs(t, err, ErrStreamClosed, "after closed should not allow write")
 		// Check if RECONFIG was actually dropped
 		assert.Equal(t, 0, venv.numToDropReconfig, "should be zero")
 